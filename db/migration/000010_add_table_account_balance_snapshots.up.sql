@@ -0,0 +1,13 @@
+CREATE TABLE "account_balance_snapshots" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "balance" NUMERIC(20,0) NOT NULL,
+  "snapshot_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_balance_snapshots" ADD CONSTRAINT "account_snapshot_at_key" UNIQUE ("account_id", "snapshot_at");
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
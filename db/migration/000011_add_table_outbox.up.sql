@@ -0,0 +1,16 @@
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "event_uuid" UUID NOT NULL DEFAULT uuid_generate_v4(),
+  "event_type" varchar NOT NULL,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "sent_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "outbox" ADD CONSTRAINT "outbox_event_uuid_key" UNIQUE ("event_uuid");
+
+CREATE INDEX "idx_outbox_pending" ON "outbox" ("id") WHERE "sent_at" IS NULL;
@@ -0,0 +1,38 @@
+CREATE TABLE "webhook_subscriptions" (
+  "id" bigserial PRIMARY KEY,
+  "subscription_uuid" UUID NOT NULL DEFAULT uuid_generate_v4(),
+  "user_uuid" UUID NOT NULL,
+  "url" varchar NOT NULL,
+  "event_types" varchar[] NOT NULL,
+  "secret" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "deleted_at" timestamptz
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "delivery_uuid" UUID NOT NULL DEFAULT uuid_generate_v4(),
+  "subscription_id" bigint NOT NULL,
+  "event_uuid" UUID NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_status_code" int,
+  "last_error" varchar,
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id");
+
+CREATE UNIQUE INDEX "idx_webhook_subscription_uuid" ON "webhook_subscriptions" ("subscription_uuid");
+
+CREATE UNIQUE INDEX "idx_webhook_delivery_uuid" ON "webhook_deliveries" ("delivery_uuid");
+
+CREATE INDEX "idx_webhook_deliveries_due" ON "webhook_deliveries" ("next_attempt_at") WHERE "status" = 'pending';
+
+CREATE INDEX "idx_webhook_deliveries_subscription" ON "webhook_deliveries" ("subscription_id", "id");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "sessions_user_uuid_user_agent_client_ip_idx";
+DROP INDEX IF EXISTS "transactions_from_account_id_created_at_idx";
+DROP TABLE IF EXISTS "risk_decisions";
@@ -0,0 +1,32 @@
+CREATE TABLE "risk_decisions" (
+  "id" bigserial PRIMARY KEY,
+  "decision_uuid" UUID NOT NULL DEFAULT uuid_generate_v4(),
+  "user_uuid" UUID NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" NUMERIC(20, 0) NOT NULL,
+  "currency" varchar NOT NULL,
+  "decision" varchar NOT NULL,
+  "reasons" varchar[] NOT NULL,
+  "review_status" varchar,
+  "reviewed_by" UUID,
+  "reviewed_at" timestamptz,
+  "transaction_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("transaction_id") REFERENCES "transactions" ("id");
+
+CREATE UNIQUE INDEX "idx_risk_decision_uuid" ON "risk_decisions" ("decision_uuid");
+
+CREATE INDEX "idx_risk_decisions_pending_review" ON "risk_decisions" ("id") WHERE "decision" = 'review' AND "review_status" IS NULL;
+
+CREATE INDEX ON "transactions" ("from_account_id", "created_at");
+
+CREATE INDEX ON "sessions" ("user_uuid", "user_agent", "client_ip");
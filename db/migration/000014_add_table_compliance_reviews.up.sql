@@ -0,0 +1,33 @@
+CREATE TABLE "compliance_reviews" (
+  "id" bigserial PRIMARY KEY,
+  "review_uuid" UUID NOT NULL DEFAULT uuid_generate_v4(),
+  "kind" varchar NOT NULL,
+  "user_uuid" UUID NOT NULL,
+  "screened_name" varchar NOT NULL,
+  "list_reference" varchar NOT NULL,
+  "listed_name" varchar NOT NULL,
+  "score" double precision NOT NULL,
+  "from_account_id" bigint,
+  "to_account_id" bigint,
+  "amount" NUMERIC(20, 0),
+  "currency" varchar,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "reviewed_by" UUID,
+  "reviewed_at" timestamptz,
+  "transaction_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "compliance_reviews" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+ALTER TABLE "compliance_reviews" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "compliance_reviews" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "compliance_reviews" ADD FOREIGN KEY ("transaction_id") REFERENCES "transactions" ("id");
+
+CREATE UNIQUE INDEX "idx_compliance_review_uuid" ON "compliance_reviews" ("review_uuid");
+
+CREATE INDEX ON "compliance_reviews" ("status", "id");
+
+CREATE INDEX ON "compliance_reviews" ("user_uuid", "kind", "status");
@@ -0,0 +1,37 @@
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "audit_uuid" UUID NOT NULL DEFAULT uuid_generate_v4(),
+  "actor_uuid" UUID,
+  "actor_role" varchar NOT NULL DEFAULT '',
+  "action" varchar NOT NULL,
+  "entity_type" varchar NOT NULL,
+  "entity_id" varchar NOT NULL,
+  "before" jsonb,
+  "after" jsonb,
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "user_agent" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX "idx_audit_log_uuid" ON "audit_log" ("audit_uuid");
+
+CREATE INDEX ON "audit_log" ("entity_type", "entity_id", "id");
+
+CREATE INDEX ON "audit_log" ("actor_uuid", "id");
+
+CREATE INDEX ON "audit_log" ("action", "id");
+
+CREATE INDEX ON "audit_log" ("created_at");
+
+-- the audit log is append-only: rows can never be changed or removed
+CREATE FUNCTION "audit_log_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_log_no_update_delete" BEFORE UPDATE OR DELETE ON "audit_log"
+FOR EACH ROW EXECUTE FUNCTION "audit_log_append_only"();
+
+CREATE TRIGGER "audit_log_no_truncate" BEFORE TRUNCATE ON "audit_log"
+FOR EACH STATEMENT EXECUTE FUNCTION "audit_log_append_only"();
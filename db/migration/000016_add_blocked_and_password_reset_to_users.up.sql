@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN is_blocked boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN password_reset_required boolean NOT NULL DEFAULT false;
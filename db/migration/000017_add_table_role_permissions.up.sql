@@ -0,0 +1,45 @@
+CREATE TABLE "permissions" (
+  "name" varchar PRIMARY KEY,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "role_permissions" (
+  "role" varchar NOT NULL,
+  "permission" varchar NOT NULL REFERENCES "permissions" ("name"),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("role", "permission")
+);
+
+INSERT INTO "permissions" ("name", "description") VALUES
+  ('accounts:read:any', 'Read accounts and balances of any user'),
+  ('accounts:update:any', 'Update accounts of any user'),
+  ('audit:read', 'Query the audit log'),
+  ('compliance:review', 'List and decide compliance reviews'),
+  ('transfers:approve', 'List and decide transfers held by risk checks'),
+  ('users:manage', 'Block, unblock, change the role of and force a password reset of users'),
+  ('users:manage:privileged', 'Manage users holding privileged permissions and grant privileged roles'),
+  ('users:read', 'List and view users'),
+  ('users:update:self', 'Update own user profile through the gRPC API'),
+  ('webhooks:manage:any', 'Read, delete and redeliver webhooks of any user');
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+  ('admin', 'accounts:read:any'),
+  ('admin', 'accounts:update:any'),
+  ('admin', 'audit:read'),
+  ('admin', 'compliance:review'),
+  ('admin', 'transfers:approve'),
+  ('admin', 'users:manage'),
+  ('admin', 'users:read'),
+  ('admin', 'users:update:self'),
+  ('admin', 'webhooks:manage:any'),
+  ('superadmin', 'accounts:read:any'),
+  ('superadmin', 'accounts:update:any'),
+  ('superadmin', 'audit:read'),
+  ('superadmin', 'compliance:review'),
+  ('superadmin', 'transfers:approve'),
+  ('superadmin', 'users:manage'),
+  ('superadmin', 'users:manage:privileged'),
+  ('superadmin', 'users:read'),
+  ('superadmin', 'users:update:self'),
+  ('superadmin', 'webhooks:manage:any');
@@ -0,0 +1,28 @@
+CREATE TABLE "account_members" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "user_uuid" UUID NOT NULL,
+  "role" varchar NOT NULL,
+  "permission" varchar NOT NULL,
+  "transfer_limit" NUMERIC(20,0),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz,
+  "removed_at" timestamptz,
+  CONSTRAINT "account_member_role_check" CHECK ("role" IN ('owner', 'signatory')),
+  CONSTRAINT "account_member_permission_check" CHECK ("permission" IN ('view', 'transfer', 'transfer_limit')),
+  -- the limit is only set for, and required by, the transfer_limit permission
+  CONSTRAINT "account_member_transfer_limit_check" CHECK (("permission" = 'transfer_limit') = ("transfer_limit" IS NOT NULL)),
+  CONSTRAINT "account_member_owner_check" CHECK ("role" <> 'owner' OR "permission" = 'transfer')
+);
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+CREATE UNIQUE INDEX "idx_account_member_active" ON "account_members" ("account_id", "user_uuid") WHERE "removed_at" IS NULL;
+
+CREATE INDEX ON "account_members" ("user_uuid") WHERE "removed_at" IS NULL;
+
+-- every existing account is owned by the user it was opened for
+INSERT INTO "account_members" ("account_id", "user_uuid", "role", "permission")
+SELECT "id", "user_uuid", 'owner', 'transfer' FROM "accounts";
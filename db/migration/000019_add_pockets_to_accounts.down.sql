@@ -0,0 +1,12 @@
+-- pockets cannot exist without the columns, their history is removed with them
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "parent_account_id" IS NOT NULL);
+DELETE FROM "account_balance_snapshots" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "parent_account_id" IS NOT NULL);
+DELETE FROM "accounts" WHERE "parent_account_id" IS NOT NULL;
+
+DROP INDEX IF EXISTS "idx_account_pocket_name";
+DROP INDEX IF EXISTS "owner_currency_key";
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("user_uuid", "currency");
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "account_pocket_name_check";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "pocket_name";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "parent_account_id";
@@ -0,0 +1,15 @@
+ALTER TABLE "accounts" ADD COLUMN "parent_account_id" bigint;
+
+ALTER TABLE "accounts" ADD COLUMN "pocket_name" varchar;
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("parent_account_id") REFERENCES "accounts" ("id");
+
+-- a pocket is an account with a parent, and only pockets carry a name
+ALTER TABLE "accounts" ADD CONSTRAINT "account_pocket_name_check" CHECK (("parent_account_id" IS NULL) = ("pocket_name" IS NULL));
+
+-- a user still has a single main account per currency, pockets share the currency of their parent
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+
+CREATE UNIQUE INDEX "owner_currency_key" ON "accounts" ("user_uuid", "currency") WHERE "parent_account_id" IS NULL;
+
+CREATE UNIQUE INDEX "idx_account_pocket_name" ON "accounts" ("parent_account_id", "pocket_name") WHERE "deleted_at" IS NULL;
@@ -0,0 +1,11 @@
+-- every login starts a session family; refreshing rotates the session into a new one of the same family
+ALTER TABLE "sessions" ADD COLUMN "family_id" uuid;
+
+UPDATE "sessions" SET "family_id" = "id";
+
+ALTER TABLE "sessions" ALTER COLUMN "family_id" SET NOT NULL;
+
+-- set once the refresh token of the session has been exchanged for a new one
+ALTER TABLE "sessions" ADD COLUMN "rotated_at" timestamptz;
+
+CREATE INDEX ON "sessions" ("family_id");
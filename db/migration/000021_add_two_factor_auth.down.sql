@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "role_settings";
+
+DROP TABLE IF EXISTS "user_recovery_codes";
+
+DROP TABLE IF EXISTS "user_totp";
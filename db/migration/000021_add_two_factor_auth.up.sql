@@ -0,0 +1,29 @@
+CREATE TABLE "user_totp" (
+  "user_uuid" uuid PRIMARY KEY,
+  "secret" varchar NOT NULL,
+  -- set once the user proved the authenticator works, two-factor authentication is enabled from then on
+  "confirmed_at" timestamptz,
+  -- the time step of the last accepted code, so a code cannot be used twice
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "user_totp" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+CREATE TABLE "user_recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "user_uuid" uuid NOT NULL,
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "user_recovery_codes" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+CREATE INDEX ON "user_recovery_codes" ("user_uuid", "code_hash");
+
+CREATE TABLE "role_settings" (
+  "role" varchar PRIMARY KEY,
+  "mfa_required" boolean NOT NULL DEFAULT false,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
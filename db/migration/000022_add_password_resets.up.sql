@@ -0,0 +1,15 @@
+CREATE TABLE "password_resets" (
+  "id" bigserial PRIMARY KEY,
+  "user_uuid" uuid NOT NULL,
+  -- the emailed code is only stored hashed
+  "code_hash" varchar NOT NULL,
+  -- wrong codes entered, the code stops working once the limit is reached
+  "attempts" int NOT NULL DEFAULT 0,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "password_resets" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+CREATE INDEX ON "password_resets" ("user_uuid");
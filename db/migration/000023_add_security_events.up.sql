@@ -0,0 +1,13 @@
+-- security relevant events of a user's account, which the user can review
+CREATE TABLE "security_events" (
+  "id" bigserial PRIMARY KEY,
+  "user_uuid" uuid NOT NULL,
+  "event_type" varchar NOT NULL,
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "user_agent" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "security_events" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+CREATE INDEX ON "security_events" ("user_uuid", "created_at");
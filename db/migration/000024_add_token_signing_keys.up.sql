@@ -0,0 +1,14 @@
+-- key pairs that sign access and refresh tokens, the public halves are published as a JWKS
+CREATE TABLE "token_signing_keys" (
+  "kid" varchar PRIMARY KEY,
+  "algorithm" varchar NOT NULL,
+  "private_key" bytea NOT NULL,
+  "public_key" bytea NOT NULL,
+  -- new keys are published a while before they sign, so every verifier knows them in time
+  "activates_at" timestamptz NOT NULL,
+  -- set once a newer key took over, tokens signed with the key are verified until then
+  "expires_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "token_signing_keys" ("activates_at");
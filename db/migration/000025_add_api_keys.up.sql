@@ -0,0 +1,27 @@
+-- API keys let machine clients call the API as a user, limited to the scopes of the key
+CREATE TABLE "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "key_uuid" UUID NOT NULL DEFAULT uuid_generate_v4(),
+  "user_uuid" UUID NOT NULL,
+  "name" varchar NOT NULL,
+  -- the public part of the key, used to look it up
+  "prefix" varchar NOT NULL,
+  "secret_hash" varchar NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "created_by" UUID NOT NULL,
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "last_used_ip" varchar NOT NULL DEFAULT '',
+  "revoked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("user_uuid");
+
+CREATE UNIQUE INDEX ON "api_keys" ("key_uuid");
+
+CREATE UNIQUE INDEX ON "api_keys" ("prefix");
+
+CREATE INDEX ON "api_keys" ("user_uuid");
@@ -0,0 +1,11 @@
+-- previous password hashes of a user, so recent passwords cannot be reused
+CREATE TABLE "password_history" (
+  "id" bigserial PRIMARY KEY,
+  "user_uuid" uuid NOT NULL,
+  "hashed_password" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "password_history" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+CREATE INDEX ON "password_history" ("user_uuid", "id");
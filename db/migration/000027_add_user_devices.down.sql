@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "user_login_ips";
+DROP TABLE IF EXISTS "user_devices";
\ No newline at end of file
@@ -0,0 +1,30 @@
+-- the devices users signed in from. A device is identified by the fingerprint of its user agent
+CREATE TABLE "user_devices" (
+  "id" bigserial PRIMARY KEY,
+  "device_uuid" uuid NOT NULL DEFAULT uuid_generate_v4(),
+  "user_uuid" uuid NOT NULL,
+  "fingerprint" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "last_client_ip" varchar NOT NULL,
+  -- null until the device is trusted, and again after the user removed it from the trusted devices
+  "trusted_at" timestamptz,
+  "last_seen_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "user_devices" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
+
+CREATE UNIQUE INDEX ON "user_devices" ("device_uuid");
+
+CREATE UNIQUE INDEX ON "user_devices" ("user_uuid", "fingerprint");
+
+-- the client IPs users signed in from
+CREATE TABLE "user_login_ips" (
+  "user_uuid" uuid NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "last_seen_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("user_uuid", "client_ip")
+);
+
+ALTER TABLE "user_login_ips" ADD FOREIGN KEY ("user_uuid") REFERENCES "users" ("user_uuid");
@@ -0,0 +1,3 @@
+UPDATE "permissions"
+SET "description" = 'Read, delete and redeliver webhooks of any user'
+WHERE "name" = 'webhooks:manage:any';
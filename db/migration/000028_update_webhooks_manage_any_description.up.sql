@@ -0,0 +1,4 @@
+-- holders of webhooks:manage:any also receive the events of every user on their subscriptions
+UPDATE "permissions"
+SET "description" = 'Read, delete and redeliver webhooks of any user, and receive the webhook events of every user'
+WHERE "name" = 'webhooks:manage:any';
@@ -0,0 +1,3 @@
+-- the devices untrusted by the up migration cannot be told apart from the ones the users removed,
+-- so their trust is not restored
+SELECT 1;
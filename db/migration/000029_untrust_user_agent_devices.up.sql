@@ -0,0 +1,4 @@
+-- devices are identified by a device token the server issues instead of the fingerprint of their
+-- user agent. The devices trusted by user agent can never be matched again, so they are untrusted:
+-- the next login from them counts as a login from a new device.
+UPDATE "user_devices" SET "trusted_at" = NULL WHERE "trusted_at" IS NOT NULL;
@@ -0,0 +1,2 @@
+-- the plaintext keys expired by the up migration must not sign again, so they stay expired
+SELECT 1;
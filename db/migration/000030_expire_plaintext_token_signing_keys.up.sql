@@ -0,0 +1,4 @@
+-- the private token signing keys are encrypted with a key-encryption key from now on. The keys
+-- stored so far are plaintext and may have been read from the database or a backup, so they expire
+-- right away: the tokens they signed are rejected and the users sign in again.
+UPDATE "token_signing_keys" SET "expires_at" = now() WHERE "expires_at" IS NULL OR "expires_at" > now();
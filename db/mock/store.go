@@ -12,9 +12,9 @@ package mockdb
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
-	request "github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
 	uuid "github.com/google/uuid"
 	pgtype "github.com/jackc/pgx/v5/pgtype"
 	gomock "go.uber.org/mock/gomock"
@@ -58,6 +58,141 @@ func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
 }
 
+// AddAccountMember mocks base method.
+func (m *MockStore) AddAccountMember(arg0 context.Context, arg1 db.AddAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAccountMember indicates an expected call of AddAccountMember.
+func (mr *MockStoreMockRecorder) AddAccountMember(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountMember", reflect.TypeOf((*MockStore)(nil).AddAccountMember), arg0, arg1)
+}
+
+// ApproveTransferReviewTx mocks base method.
+func (m *MockStore) ApproveTransferReviewTx(arg0 context.Context, arg1 db.ApproveTransferReviewTxParam) (db.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveTransferReviewTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveTransferReviewTx indicates an expected call of ApproveTransferReviewTx.
+func (mr *MockStoreMockRecorder) ApproveTransferReviewTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveTransferReviewTx", reflect.TypeOf((*MockStore)(nil).ApproveTransferReviewTx), arg0, arg1)
+}
+
+// ArchiveUserPassword mocks base method.
+func (m *MockStore) ArchiveUserPassword(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveUserPassword", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveUserPassword indicates an expected call of ArchiveUserPassword.
+func (mr *MockStoreMockRecorder) ArchiveUserPassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveUserPassword", reflect.TypeOf((*MockStore)(nil).ArchiveUserPassword), arg0, arg1)
+}
+
+// BlockOtherUserSessions mocks base method.
+func (m *MockStore) BlockOtherUserSessions(arg0 context.Context, arg1 db.BlockOtherUserSessionsParams) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockOtherUserSessions", arg0, arg1)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockOtherUserSessions indicates an expected call of BlockOtherUserSessions.
+func (mr *MockStoreMockRecorder) BlockOtherUserSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockOtherUserSessions", reflect.TypeOf((*MockStore)(nil).BlockOtherUserSessions), arg0, arg1)
+}
+
+// BlockSessionFamily mocks base method.
+func (m *MockStore) BlockSessionFamily(arg0 context.Context, arg1 db.BlockSessionFamilyParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSessionFamily", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockSessionFamily indicates an expected call of BlockSessionFamily.
+func (mr *MockStoreMockRecorder) BlockSessionFamily(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSessionFamily", reflect.TypeOf((*MockStore)(nil).BlockSessionFamily), arg0, arg1)
+}
+
+// BlockUserSessions mocks base method.
+func (m *MockStore) BlockUserSessions(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockStoreMockRecorder) BlockUserSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockStore)(nil).BlockUserSessions), arg0, arg1)
+}
+
+// BlockUserTx mocks base method.
+func (m *MockStore) BlockUserTx(arg0 context.Context, arg1 uuid.UUID) (db.UpdateUserBlockedRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.UpdateUserBlockedRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockUserTx indicates an expected call of BlockUserTx.
+func (mr *MockStoreMockRecorder) BlockUserTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserTx", reflect.TypeOf((*MockStore)(nil).BlockUserTx), arg0, arg1)
+}
+
+// ClearComplianceReviewTx mocks base method.
+func (m *MockStore) ClearComplianceReviewTx(arg0 context.Context, arg1 db.ClearComplianceReviewTxParam) (db.ClearComplianceReviewTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearComplianceReviewTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ClearComplianceReviewTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClearComplianceReviewTx indicates an expected call of ClearComplianceReviewTx.
+func (mr *MockStoreMockRecorder) ClearComplianceReviewTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearComplianceReviewTx", reflect.TypeOf((*MockStore)(nil).ClearComplianceReviewTx), arg0, arg1)
+}
+
+// ConfirmUserTOTP mocks base method.
+func (m *MockStore) ConfirmUserTOTP(arg0 context.Context, arg1 db.ConfirmUserTOTPParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmUserTOTP indicates an expected call of ConfirmUserTOTP.
+func (mr *MockStoreMockRecorder) ConfirmUserTOTP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmUserTOTP", reflect.TypeOf((*MockStore)(nil).ConfirmUserTOTP), arg0, arg1)
+}
+
 // CountAccounts mocks base method.
 func (m *MockStore) CountAccounts(arg0 context.Context) (int64, error) {
 	m.ctrl.T.Helper()
@@ -88,6 +223,141 @@ func (mr *MockStoreMockRecorder) CountAccountsByUserUUID(arg0, arg1 any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAccountsByUserUUID", reflect.TypeOf((*MockStore)(nil).CountAccountsByUserUUID), arg0, arg1)
 }
 
+// CountAuditLogs mocks base method.
+func (m *MockStore) CountAuditLogs(arg0 context.Context, arg1 db.CountAuditLogsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAuditLogs", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAuditLogs indicates an expected call of CountAuditLogs.
+func (mr *MockStoreMockRecorder) CountAuditLogs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAuditLogs", reflect.TypeOf((*MockStore)(nil).CountAuditLogs), arg0, arg1)
+}
+
+// CountComplianceReviews mocks base method.
+func (m *MockStore) CountComplianceReviews(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountComplianceReviews", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountComplianceReviews indicates an expected call of CountComplianceReviews.
+func (mr *MockStoreMockRecorder) CountComplianceReviews(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountComplianceReviews", reflect.TypeOf((*MockStore)(nil).CountComplianceReviews), arg0, arg1)
+}
+
+// CountRiskDecisionsPendingReview mocks base method.
+func (m *MockStore) CountRiskDecisionsPendingReview(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRiskDecisionsPendingReview", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRiskDecisionsPendingReview indicates an expected call of CountRiskDecisionsPendingReview.
+func (mr *MockStoreMockRecorder) CountRiskDecisionsPendingReview(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRiskDecisionsPendingReview", reflect.TypeOf((*MockStore)(nil).CountRiskDecisionsPendingReview), arg0)
+}
+
+// CountTransfersBetweenAccounts mocks base method.
+func (m *MockStore) CountTransfersBetweenAccounts(arg0 context.Context, arg1 db.CountTransfersBetweenAccountsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTransfersBetweenAccounts", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTransfersBetweenAccounts indicates an expected call of CountTransfersBetweenAccounts.
+func (mr *MockStoreMockRecorder) CountTransfersBetweenAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTransfersBetweenAccounts", reflect.TypeOf((*MockStore)(nil).CountTransfersBetweenAccounts), arg0, arg1)
+}
+
+// CountUserComplianceHolds mocks base method.
+func (m *MockStore) CountUserComplianceHolds(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserComplianceHolds", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserComplianceHolds indicates an expected call of CountUserComplianceHolds.
+func (mr *MockStoreMockRecorder) CountUserComplianceHolds(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserComplianceHolds", reflect.TypeOf((*MockStore)(nil).CountUserComplianceHolds), arg0, arg1)
+}
+
+// CountUserDevices mocks base method.
+func (m *MockStore) CountUserDevices(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserDevices", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserDevices indicates an expected call of CountUserDevices.
+func (mr *MockStoreMockRecorder) CountUserDevices(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserDevices", reflect.TypeOf((*MockStore)(nil).CountUserDevices), arg0, arg1)
+}
+
+// CountUsers mocks base method.
+func (m *MockStore) CountUsers(arg0 context.Context, arg1 db.CountUsersParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsers", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsers indicates an expected call of CountUsers.
+func (mr *MockStoreMockRecorder) CountUsers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockStore)(nil).CountUsers), arg0, arg1)
+}
+
+// CountWebhookDeliveries mocks base method.
+func (m *MockStore) CountWebhookDeliveries(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountWebhookDeliveries indicates an expected call of CountWebhookDeliveries.
+func (mr *MockStoreMockRecorder) CountWebhookDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).CountWebhookDeliveries), arg0, arg1)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockStore) CreateAPIKey(arg0 context.Context, arg1 db.CreateAPIKeyParams) (db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockStoreMockRecorder) CreateAPIKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockStore)(nil).CreateAPIKey), arg0, arg1)
+}
+
 // CreateAccount mocks base method.
 func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.CreateAccountRow, error) {
 	m.ctrl.T.Helper()
@@ -103,6 +373,66 @@ func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
 }
 
+// CreateAccountTx mocks base method.
+func (m *MockStore) CreateAccountTx(arg0 context.Context, arg1 db.CreateAccountParams) (db.CreateAccountRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CreateAccountRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountTx indicates an expected call of CreateAccountTx.
+func (mr *MockStoreMockRecorder) CreateAccountTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountTx", reflect.TypeOf((*MockStore)(nil).CreateAccountTx), arg0, arg1)
+}
+
+// CreateAuditLog mocks base method.
+func (m *MockStore) CreateAuditLog(arg0 context.Context, arg1 db.CreateAuditLogParams) (db.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLog", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockStoreMockRecorder) CreateAuditLog(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockStore)(nil).CreateAuditLog), arg0, arg1)
+}
+
+// CreateComplianceReview mocks base method.
+func (m *MockStore) CreateComplianceReview(arg0 context.Context, arg1 db.CreateComplianceReviewParams) (db.ComplianceReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComplianceReview", arg0, arg1)
+	ret0, _ := ret[0].(db.ComplianceReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateComplianceReview indicates an expected call of CreateComplianceReview.
+func (mr *MockStoreMockRecorder) CreateComplianceReview(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComplianceReview", reflect.TypeOf((*MockStore)(nil).CreateComplianceReview), arg0, arg1)
+}
+
+// CreateDailyBalanceSnapshots mocks base method.
+func (m *MockStore) CreateDailyBalanceSnapshots(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDailyBalanceSnapshots", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDailyBalanceSnapshots indicates an expected call of CreateDailyBalanceSnapshots.
+func (mr *MockStoreMockRecorder) CreateDailyBalanceSnapshots(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDailyBalanceSnapshots", reflect.TypeOf((*MockStore)(nil).CreateDailyBalanceSnapshots), arg0, arg1)
+}
+
 // CreateEntry mocks base method.
 func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
 	m.ctrl.T.Helper()
@@ -118,6 +448,111 @@ func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
 }
 
+// CreateOutboxEvent mocks base method.
+func (m *MockStore) CreateOutboxEvent(arg0 context.Context, arg1 db.CreateOutboxEventParams) (db.Outbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.Outbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent.
+func (mr *MockStoreMockRecorder) CreateOutboxEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*MockStore)(nil).CreateOutboxEvent), arg0, arg1)
+}
+
+// CreatePasswordReset mocks base method.
+func (m *MockStore) CreatePasswordReset(arg0 context.Context, arg1 db.CreatePasswordResetParams) (db.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockStoreMockRecorder) CreatePasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockStore)(nil).CreatePasswordReset), arg0, arg1)
+}
+
+// CreatePasswordResetTx mocks base method.
+func (m *MockStore) CreatePasswordResetTx(arg0 context.Context, arg1 db.CreatePasswordResetParams) (db.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetTx", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordResetTx indicates an expected call of CreatePasswordResetTx.
+func (mr *MockStoreMockRecorder) CreatePasswordResetTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetTx", reflect.TypeOf((*MockStore)(nil).CreatePasswordResetTx), arg0, arg1)
+}
+
+// CreatePocket mocks base method.
+func (m *MockStore) CreatePocket(arg0 context.Context, arg1 db.CreatePocketParams) (db.CreatePocketRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePocket", arg0, arg1)
+	ret0, _ := ret[0].(db.CreatePocketRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePocket indicates an expected call of CreatePocket.
+func (mr *MockStoreMockRecorder) CreatePocket(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePocket", reflect.TypeOf((*MockStore)(nil).CreatePocket), arg0, arg1)
+}
+
+// CreateRecoveryCodes mocks base method.
+func (m *MockStore) CreateRecoveryCodes(arg0 context.Context, arg1 db.CreateRecoveryCodesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRecoveryCodes indicates an expected call of CreateRecoveryCodes.
+func (mr *MockStoreMockRecorder) CreateRecoveryCodes(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCodes", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCodes), arg0, arg1)
+}
+
+// CreateRiskDecision mocks base method.
+func (m *MockStore) CreateRiskDecision(arg0 context.Context, arg1 db.CreateRiskDecisionParams) (db.RiskDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRiskDecision", arg0, arg1)
+	ret0, _ := ret[0].(db.RiskDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRiskDecision indicates an expected call of CreateRiskDecision.
+func (mr *MockStoreMockRecorder) CreateRiskDecision(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRiskDecision", reflect.TypeOf((*MockStore)(nil).CreateRiskDecision), arg0, arg1)
+}
+
+// CreateSecurityEvent mocks base method.
+func (m *MockStore) CreateSecurityEvent(arg0 context.Context, arg1 db.CreateSecurityEventParams) (db.SecurityEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecurityEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.SecurityEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecurityEvent indicates an expected call of CreateSecurityEvent.
+func (mr *MockStoreMockRecorder) CreateSecurityEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecurityEvent", reflect.TypeOf((*MockStore)(nil).CreateSecurityEvent), arg0, arg1)
+}
+
 // CreateSession mocks base method.
 func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
@@ -133,6 +568,21 @@ func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
 }
 
+// CreateTokenSigningKey mocks base method.
+func (m *MockStore) CreateTokenSigningKey(arg0 context.Context, arg1 db.CreateTokenSigningKeyParams) (db.TokenSigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTokenSigningKey", arg0, arg1)
+	ret0, _ := ret[0].(db.TokenSigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTokenSigningKey indicates an expected call of CreateTokenSigningKey.
+func (mr *MockStoreMockRecorder) CreateTokenSigningKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTokenSigningKey", reflect.TypeOf((*MockStore)(nil).CreateTokenSigningKey), arg0, arg1)
+}
+
 // CreateTransaction mocks base method.
 func (m *MockStore) CreateTransaction(arg0 context.Context, arg1 db.CreateTransactionParams) (db.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -163,289 +613,1349 @@ func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
 }
 
-// CreateUserWithAccountTx mocks base method.
-func (m *MockStore) CreateUserWithAccountTx(arg0 context.Context, arg1 request.CreateUserRequest) (db.CreateUserWithAccountResult, error) {
+// CreateUserTOTP mocks base method.
+func (m *MockStore) CreateUserTOTP(arg0 context.Context, arg1 db.CreateUserTOTPParams) (db.UserTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserTOTP indicates an expected call of CreateUserTOTP.
+func (mr *MockStoreMockRecorder) CreateUserTOTP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserTOTP", reflect.TypeOf((*MockStore)(nil).CreateUserTOTP), arg0, arg1)
+}
+
+// CreateUserWithAccountTx mocks base method.
+func (m *MockStore) CreateUserWithAccountTx(arg0 context.Context, arg1 db.CreateUserWithAccountTxParam) (db.CreateUserWithAccountResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserWithAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CreateUserWithAccountResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserWithAccountTx indicates an expected call of CreateUserWithAccountTx.
+func (mr *MockStoreMockRecorder) CreateUserWithAccountTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserWithAccountTx", reflect.TypeOf((*MockStore)(nil).CreateUserWithAccountTx), arg0, arg1)
+}
+
+// CreateWebhookDeliveries mocks base method.
+func (m *MockStore) CreateWebhookDeliveries(arg0 context.Context, arg1 db.CreateWebhookDeliveriesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookDeliveries indicates an expected call of CreateWebhookDeliveries.
+func (mr *MockStoreMockRecorder) CreateWebhookDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).CreateWebhookDeliveries), arg0, arg1)
+}
+
+// CreateWebhookSubscription mocks base method.
+func (m *MockStore) CreateWebhookSubscription(arg0 context.Context, arg1 db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockStoreMockRecorder) CreateWebhookSubscription(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockStore)(nil).CreateWebhookSubscription), arg0, arg1)
+}
+
+// DisableTOTPTx mocks base method.
+func (m *MockStore) DisableTOTPTx(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTPTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTPTx indicates an expected call of DisableTOTPTx.
+func (mr *MockStoreMockRecorder) DisableTOTPTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTPTx", reflect.TypeOf((*MockStore)(nil).DisableTOTPTx), arg0, arg1)
+}
+
+// DisableUserTOTP mocks base method.
+func (m *MockStore) DisableUserTOTP(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableUserTOTP indicates an expected call of DisableUserTOTP.
+func (mr *MockStoreMockRecorder) DisableUserTOTP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUserTOTP", reflect.TypeOf((*MockStore)(nil).DisableUserTOTP), arg0, arg1)
+}
+
+// DispatchOutboxEventsTx mocks base method.
+func (m *MockStore) DispatchOutboxEventsTx(arg0 context.Context, arg1 db.DispatchOutboxEventsTxParam) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DispatchOutboxEventsTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DispatchOutboxEventsTx indicates an expected call of DispatchOutboxEventsTx.
+func (mr *MockStoreMockRecorder) DispatchOutboxEventsTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DispatchOutboxEventsTx", reflect.TypeOf((*MockStore)(nil).DispatchOutboxEventsTx), arg0, arg1)
+}
+
+// EnableTOTPTx mocks base method.
+func (m *MockStore) EnableTOTPTx(arg0 context.Context, arg1 db.EnableTOTPTxParam) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTPTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTPTx indicates an expected call of EnableTOTPTx.
+func (mr *MockStoreMockRecorder) EnableTOTPTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTPTx", reflect.TypeOf((*MockStore)(nil).EnableTOTPTx), arg0, arg1)
+}
+
+// ExecTx mocks base method.
+func (m *MockStore) ExecTx(arg0 context.Context, arg1 func(db.Store) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecTx indicates an expected call of ExecTx.
+func (mr *MockStoreMockRecorder) ExecTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecTx", reflect.TypeOf((*MockStore)(nil).ExecTx), arg0, arg1)
+}
+
+// GetAPIKeyByPrefix mocks base method.
+func (m *MockStore) GetAPIKeyByPrefix(arg0 context.Context, arg1 string) (db.GetAPIKeyByPrefixRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAPIKeyByPrefixRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByPrefix indicates an expected call of GetAPIKeyByPrefix.
+func (mr *MockStoreMockRecorder) GetAPIKeyByPrefix(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByPrefix", reflect.TypeOf((*MockStore)(nil).GetAPIKeyByPrefix), arg0, arg1)
+}
+
+// GetAccount mocks base method.
+func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.GetAccountRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAccountRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+}
+
+// GetAccountBalanceBefore mocks base method.
+func (m *MockStore) GetAccountBalanceBefore(arg0 context.Context, arg1 db.GetAccountBalanceBeforeParams) (db.GetAccountBalanceBeforeRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountBalanceBefore", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAccountBalanceBeforeRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountBalanceBefore indicates an expected call of GetAccountBalanceBefore.
+func (mr *MockStoreMockRecorder) GetAccountBalanceBefore(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountBalanceBefore", reflect.TypeOf((*MockStore)(nil).GetAccountBalanceBefore), arg0, arg1)
+}
+
+// GetAccountByUUID mocks base method.
+func (m *MockStore) GetAccountByUUID(arg0 context.Context, arg1 uuid.UUID) (db.GetAccountByUUIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByUUID", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAccountByUUIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByUUID indicates an expected call of GetAccountByUUID.
+func (mr *MockStoreMockRecorder) GetAccountByUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByUUID", reflect.TypeOf((*MockStore)(nil).GetAccountByUUID), arg0, arg1)
+}
+
+// GetAccountByUserUUID mocks base method.
+func (m *MockStore) GetAccountByUserUUID(arg0 context.Context, arg1 uuid.UUID) (db.GetAccountByUserUUIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByUserUUID", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAccountByUserUUIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByUserUUID indicates an expected call of GetAccountByUserUUID.
+func (mr *MockStoreMockRecorder) GetAccountByUserUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByUserUUID", reflect.TypeOf((*MockStore)(nil).GetAccountByUserUUID), arg0, arg1)
+}
+
+// GetAccountByUserUUIDAndCurrency mocks base method.
+func (m *MockStore) GetAccountByUserUUIDAndCurrency(arg0 context.Context, arg1 db.GetAccountByUserUUIDAndCurrencyParams) (db.GetAccountByUserUUIDAndCurrencyRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByUserUUIDAndCurrency", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAccountByUserUUIDAndCurrencyRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByUserUUIDAndCurrency indicates an expected call of GetAccountByUserUUIDAndCurrency.
+func (mr *MockStoreMockRecorder) GetAccountByUserUUIDAndCurrency(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByUserUUIDAndCurrency", reflect.TypeOf((*MockStore)(nil).GetAccountByUserUUIDAndCurrency), arg0, arg1)
+}
+
+// GetAccountByUserUUIDMany mocks base method.
+func (m *MockStore) GetAccountByUserUUIDMany(arg0 context.Context, arg1 uuid.UUID) ([]db.GetAccountByUserUUIDManyRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByUserUUIDMany", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetAccountByUserUUIDManyRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByUserUUIDMany indicates an expected call of GetAccountByUserUUIDMany.
+func (mr *MockStoreMockRecorder) GetAccountByUserUUIDMany(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByUserUUIDMany", reflect.TypeOf((*MockStore)(nil).GetAccountByUserUUIDMany), arg0, arg1)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.GetAccountForUpdateRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAccountForUpdateRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+}
+
+// GetAccountMember mocks base method.
+func (m *MockStore) GetAccountMember(arg0 context.Context, arg1 db.GetAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountMember indicates an expected call of GetAccountMember.
+func (mr *MockStoreMockRecorder) GetAccountMember(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountMember", reflect.TypeOf((*MockStore)(nil).GetAccountMember), arg0, arg1)
+}
+
+// GetAccountTransferVelocity mocks base method.
+func (m *MockStore) GetAccountTransferVelocity(arg0 context.Context, arg1 db.GetAccountTransferVelocityParams) (db.GetAccountTransferVelocityRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountTransferVelocity", arg0, arg1)
+	ret0, _ := ret[0].(db.GetAccountTransferVelocityRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountTransferVelocity indicates an expected call of GetAccountTransferVelocity.
+func (mr *MockStoreMockRecorder) GetAccountTransferVelocity(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountTransferVelocity", reflect.TypeOf((*MockStore)(nil).GetAccountTransferVelocity), arg0, arg1)
+}
+
+// GetActivePasswordReset mocks base method.
+func (m *MockStore) GetActivePasswordReset(arg0 context.Context, arg1 db.GetActivePasswordResetParams) (db.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActivePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActivePasswordReset indicates an expected call of GetActivePasswordReset.
+func (mr *MockStoreMockRecorder) GetActivePasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActivePasswordReset", reflect.TypeOf((*MockStore)(nil).GetActivePasswordReset), arg0, arg1)
+}
+
+// GetBalanceAsOf mocks base method.
+func (m *MockStore) GetBalanceAsOf(arg0 context.Context, arg1 int64, arg2 time.Time) (db.BalanceAsOfResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAsOf", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.BalanceAsOfResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAsOf indicates an expected call of GetBalanceAsOf.
+func (mr *MockStoreMockRecorder) GetBalanceAsOf(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAsOf", reflect.TypeOf((*MockStore)(nil).GetBalanceAsOf), arg0, arg1, arg2)
+}
+
+// GetComplianceReviewByUUID mocks base method.
+func (m *MockStore) GetComplianceReviewByUUID(arg0 context.Context, arg1 uuid.UUID) (db.ComplianceReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComplianceReviewByUUID", arg0, arg1)
+	ret0, _ := ret[0].(db.ComplianceReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComplianceReviewByUUID indicates an expected call of GetComplianceReviewByUUID.
+func (mr *MockStoreMockRecorder) GetComplianceReviewByUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComplianceReviewByUUID", reflect.TypeOf((*MockStore)(nil).GetComplianceReviewByUUID), arg0, arg1)
+}
+
+// GetDetailLoginByUsername mocks base method.
+func (m *MockStore) GetDetailLoginByUsername(arg0 context.Context, arg1 string) (db.GetDetailLoginByUsernameRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDetailLoginByUsername", arg0, arg1)
+	ret0, _ := ret[0].(db.GetDetailLoginByUsernameRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDetailLoginByUsername indicates an expected call of GetDetailLoginByUsername.
+func (mr *MockStoreMockRecorder) GetDetailLoginByUsername(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDetailLoginByUsername", reflect.TypeOf((*MockStore)(nil).GetDetailLoginByUsername), arg0, arg1)
+}
+
+// GetEntry mocks base method.
+func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+}
+
+// GetLatestBalanceSnapshot mocks base method.
+func (m *MockStore) GetLatestBalanceSnapshot(arg0 context.Context, arg1 db.GetLatestBalanceSnapshotParams) (db.AccountBalanceSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestBalanceSnapshot", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountBalanceSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestBalanceSnapshot indicates an expected call of GetLatestBalanceSnapshot.
+func (mr *MockStoreMockRecorder) GetLatestBalanceSnapshot(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestBalanceSnapshot", reflect.TypeOf((*MockStore)(nil).GetLatestBalanceSnapshot), arg0, arg1)
+}
+
+// GetLatestTokenSigningKeyForUpdate mocks base method.
+func (m *MockStore) GetLatestTokenSigningKeyForUpdate(arg0 context.Context) (db.TokenSigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestTokenSigningKeyForUpdate", arg0)
+	ret0, _ := ret[0].(db.TokenSigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestTokenSigningKeyForUpdate indicates an expected call of GetLatestTokenSigningKeyForUpdate.
+func (mr *MockStoreMockRecorder) GetLatestTokenSigningKeyForUpdate(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestTokenSigningKeyForUpdate", reflect.TypeOf((*MockStore)(nil).GetLatestTokenSigningKeyForUpdate), arg0)
+}
+
+// GetRiskDecisionByUUID mocks base method.
+func (m *MockStore) GetRiskDecisionByUUID(arg0 context.Context, arg1 uuid.UUID) (db.RiskDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskDecisionByUUID", arg0, arg1)
+	ret0, _ := ret[0].(db.RiskDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskDecisionByUUID indicates an expected call of GetRiskDecisionByUUID.
+func (mr *MockStoreMockRecorder) GetRiskDecisionByUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskDecisionByUUID", reflect.TypeOf((*MockStore)(nil).GetRiskDecisionByUUID), arg0, arg1)
+}
+
+// GetRoleSetting mocks base method.
+func (m *MockStore) GetRoleSetting(arg0 context.Context, arg1 string) (db.RoleSetting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleSetting", arg0, arg1)
+	ret0, _ := ret[0].(db.RoleSetting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleSetting indicates an expected call of GetRoleSetting.
+func (mr *MockStoreMockRecorder) GetRoleSetting(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleSetting", reflect.TypeOf((*MockStore)(nil).GetRoleSetting), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetTransaction mocks base method.
+func (m *MockStore) GetTransaction(arg0 context.Context, arg1 int64) (db.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockStoreMockRecorder) GetTransaction(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockStore)(nil).GetTransaction), arg0, arg1)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStore) GetUserByEmail(arg0 context.Context, arg1 string) (db.GetUserByEmailRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.GetUserByEmailRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStoreMockRecorder) GetUserByEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), arg0, arg1)
+}
+
+// GetUserByUserUUID mocks base method.
+func (m *MockStore) GetUserByUserUUID(arg0 context.Context, arg1 uuid.UUID) (db.GetUserByUserUUIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUserUUID", arg0, arg1)
+	ret0, _ := ret[0].(db.GetUserByUserUUIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUserUUID indicates an expected call of GetUserByUserUUID.
+func (mr *MockStoreMockRecorder) GetUserByUserUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUserUUID", reflect.TypeOf((*MockStore)(nil).GetUserByUserUUID), arg0, arg1)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockStore) GetUserByUsername(arg0 context.Context, arg1 string) (db.GetUserByUsernameRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", arg0, arg1)
+	ret0, _ := ret[0].(db.GetUserByUsernameRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockStoreMockRecorder) GetUserByUsername(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockStore)(nil).GetUserByUsername), arg0, arg1)
+}
+
+// GetUserByVerificationEmailCode mocks base method.
+func (m *MockStore) GetUserByVerificationEmailCode(arg0 context.Context, arg1 pgtype.Text) (db.GetUserByVerificationEmailCodeRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByVerificationEmailCode", arg0, arg1)
+	ret0, _ := ret[0].(db.GetUserByVerificationEmailCodeRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByVerificationEmailCode indicates an expected call of GetUserByVerificationEmailCode.
+func (mr *MockStoreMockRecorder) GetUserByVerificationEmailCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByVerificationEmailCode", reflect.TypeOf((*MockStore)(nil).GetUserByVerificationEmailCode), arg0, arg1)
+}
+
+// GetUserDevice mocks base method.
+func (m *MockStore) GetUserDevice(arg0 context.Context, arg1 db.GetUserDeviceParams) (db.UserDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserDevice", arg0, arg1)
+	ret0, _ := ret[0].(db.UserDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserDevice indicates an expected call of GetUserDevice.
+func (mr *MockStoreMockRecorder) GetUserDevice(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDevice", reflect.TypeOf((*MockStore)(nil).GetUserDevice), arg0, arg1)
+}
+
+// GetUserTOTP mocks base method.
+func (m *MockStore) GetUserTOTP(arg0 context.Context, arg1 uuid.UUID) (db.UserTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTOTP indicates an expected call of GetUserTOTP.
+func (mr *MockStoreMockRecorder) GetUserTOTP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTOTP", reflect.TypeOf((*MockStore)(nil).GetUserTOTP), arg0, arg1)
+}
+
+// GetWebhookDeliveryByUUID mocks base method.
+func (m *MockStore) GetWebhookDeliveryByUUID(arg0 context.Context, arg1 uuid.UUID) (db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveryByUUID", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveryByUUID indicates an expected call of GetWebhookDeliveryByUUID.
+func (mr *MockStoreMockRecorder) GetWebhookDeliveryByUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveryByUUID", reflect.TypeOf((*MockStore)(nil).GetWebhookDeliveryByUUID), arg0, arg1)
+}
+
+// GetWebhookSubscriptionByUUID mocks base method.
+func (m *MockStore) GetWebhookSubscriptionByUUID(arg0 context.Context, arg1 uuid.UUID) (db.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscriptionByUUID", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscriptionByUUID indicates an expected call of GetWebhookSubscriptionByUUID.
+func (mr *MockStoreMockRecorder) GetWebhookSubscriptionByUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscriptionByUUID", reflect.TypeOf((*MockStore)(nil).GetWebhookSubscriptionByUUID), arg0, arg1)
+}
+
+// IncrementPasswordResetAttempts mocks base method.
+func (m *MockStore) IncrementPasswordResetAttempts(arg0 context.Context, arg1 db.IncrementPasswordResetAttemptsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementPasswordResetAttempts", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementPasswordResetAttempts indicates an expected call of IncrementPasswordResetAttempts.
+func (mr *MockStoreMockRecorder) IncrementPasswordResetAttempts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementPasswordResetAttempts", reflect.TypeOf((*MockStore)(nil).IncrementPasswordResetAttempts), arg0, arg1)
+}
+
+// ListAPIKeysByUser mocks base method.
+func (m *MockStore) ListAPIKeysByUser(arg0 context.Context, arg1 uuid.UUID) ([]db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeysByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeysByUser indicates an expected call of ListAPIKeysByUser.
+func (mr *MockStoreMockRecorder) ListAPIKeysByUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeysByUser", reflect.TypeOf((*MockStore)(nil).ListAPIKeysByUser), arg0, arg1)
+}
+
+// ListAccountMembers mocks base method.
+func (m *MockStore) ListAccountMembers(arg0 context.Context, arg1 int64) ([]db.ListAccountMembersRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountMembers", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListAccountMembersRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountMembers indicates an expected call of ListAccountMembers.
+func (mr *MockStoreMockRecorder) ListAccountMembers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountMembers", reflect.TypeOf((*MockStore)(nil).ListAccountMembers), arg0, arg1)
+}
+
+// ListAccountOwnersForUpdate mocks base method.
+func (m *MockStore) ListAccountOwnersForUpdate(arg0 context.Context, arg1 int64) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountOwnersForUpdate", arg0, arg1)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountOwnersForUpdate indicates an expected call of ListAccountOwnersForUpdate.
+func (mr *MockStoreMockRecorder) ListAccountOwnersForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountOwnersForUpdate", reflect.TypeOf((*MockStore)(nil).ListAccountOwnersForUpdate), arg0, arg1)
+}
+
+// ListAccounts mocks base method.
+func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.ListAccountsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListAccountsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+}
+
+// ListAccountsByUserUUID mocks base method.
+func (m *MockStore) ListAccountsByUserUUID(arg0 context.Context, arg1 db.ListAccountsByUserUUIDParams) ([]db.ListAccountsByUserUUIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsByUserUUID", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListAccountsByUserUUIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsByUserUUID indicates an expected call of ListAccountsByUserUUID.
+func (mr *MockStoreMockRecorder) ListAccountsByUserUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsByUserUUID", reflect.TypeOf((*MockStore)(nil).ListAccountsByUserUUID), arg0, arg1)
+}
+
+// ListActiveSessionsByUser mocks base method.
+func (m *MockStore) ListActiveSessionsByUser(arg0 context.Context, arg1 uuid.UUID) ([]db.ListActiveSessionsByUserRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveSessionsByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListActiveSessionsByUserRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveSessionsByUser indicates an expected call of ListActiveSessionsByUser.
+func (mr *MockStoreMockRecorder) ListActiveSessionsByUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveSessionsByUser", reflect.TypeOf((*MockStore)(nil).ListActiveSessionsByUser), arg0, arg1)
+}
+
+// ListAuditLogs mocks base method.
+func (m *MockStore) ListAuditLogs(arg0 context.Context, arg1 db.ListAuditLogsParams) ([]db.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLogs", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditLogs indicates an expected call of ListAuditLogs.
+func (mr *MockStoreMockRecorder) ListAuditLogs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLogs", reflect.TypeOf((*MockStore)(nil).ListAuditLogs), arg0, arg1)
+}
+
+// ListComplianceReviews mocks base method.
+func (m *MockStore) ListComplianceReviews(arg0 context.Context, arg1 db.ListComplianceReviewsParams) ([]db.ListComplianceReviewsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListComplianceReviews", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListComplianceReviewsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListComplianceReviews indicates an expected call of ListComplianceReviews.
+func (mr *MockStoreMockRecorder) ListComplianceReviews(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListComplianceReviews", reflect.TypeOf((*MockStore)(nil).ListComplianceReviews), arg0, arg1)
+}
+
+// ListDueWebhookDeliveries mocks base method.
+func (m *MockStore) ListDueWebhookDeliveries(arg0 context.Context, arg1 int32) ([]db.ListDueWebhookDeliveriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListDueWebhookDeliveriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueWebhookDeliveries indicates an expected call of ListDueWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ListDueWebhookDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ListDueWebhookDeliveries), arg0, arg1)
+}
+
+// ListEntries mocks base method.
+func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+}
+
+// ListPendingOutboxEventsForUpdate mocks base method.
+func (m *MockStore) ListPendingOutboxEventsForUpdate(arg0 context.Context, arg1 int32) ([]db.Outbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingOutboxEventsForUpdate", arg0, arg1)
+	ret0, _ := ret[0].([]db.Outbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingOutboxEventsForUpdate indicates an expected call of ListPendingOutboxEventsForUpdate.
+func (mr *MockStoreMockRecorder) ListPendingOutboxEventsForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingOutboxEventsForUpdate", reflect.TypeOf((*MockStore)(nil).ListPendingOutboxEventsForUpdate), arg0, arg1)
+}
+
+// ListPocketsByParentID mocks base method.
+func (m *MockStore) ListPocketsByParentID(arg0 context.Context, arg1 int64) ([]db.ListPocketsByParentIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPocketsByParentID", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListPocketsByParentIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPocketsByParentID indicates an expected call of ListPocketsByParentID.
+func (mr *MockStoreMockRecorder) ListPocketsByParentID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPocketsByParentID", reflect.TypeOf((*MockStore)(nil).ListPocketsByParentID), arg0, arg1)
+}
+
+// ListRecentPasswordHashes mocks base method.
+func (m *MockStore) ListRecentPasswordHashes(arg0 context.Context, arg1 db.ListRecentPasswordHashesParams) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecentPasswordHashes", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecentPasswordHashes indicates an expected call of ListRecentPasswordHashes.
+func (mr *MockStoreMockRecorder) ListRecentPasswordHashes(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentPasswordHashes", reflect.TypeOf((*MockStore)(nil).ListRecentPasswordHashes), arg0, arg1)
+}
+
+// ListRiskDecisionsPendingReview mocks base method.
+func (m *MockStore) ListRiskDecisionsPendingReview(arg0 context.Context, arg1 db.ListRiskDecisionsPendingReviewParams) ([]db.ListRiskDecisionsPendingReviewRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRiskDecisionsPendingReview", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListRiskDecisionsPendingReviewRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRiskDecisionsPendingReview indicates an expected call of ListRiskDecisionsPendingReview.
+func (mr *MockStoreMockRecorder) ListRiskDecisionsPendingReview(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRiskDecisionsPendingReview", reflect.TypeOf((*MockStore)(nil).ListRiskDecisionsPendingReview), arg0, arg1)
+}
+
+// ListRolePermissions mocks base method.
+func (m *MockStore) ListRolePermissions(arg0 context.Context) ([]db.ListRolePermissionsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRolePermissions", arg0)
+	ret0, _ := ret[0].([]db.ListRolePermissionsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRolePermissions indicates an expected call of ListRolePermissions.
+func (mr *MockStoreMockRecorder) ListRolePermissions(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRolePermissions", reflect.TypeOf((*MockStore)(nil).ListRolePermissions), arg0)
+}
+
+// ListSecurityEventsByUser mocks base method.
+func (m *MockStore) ListSecurityEventsByUser(arg0 context.Context, arg1 db.ListSecurityEventsByUserParams) ([]db.SecurityEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecurityEventsByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.SecurityEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecurityEventsByUser indicates an expected call of ListSecurityEventsByUser.
+func (mr *MockStoreMockRecorder) ListSecurityEventsByUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecurityEventsByUser", reflect.TypeOf((*MockStore)(nil).ListSecurityEventsByUser), arg0, arg1)
+}
+
+// ListSessionsByUser mocks base method.
+func (m *MockStore) ListSessionsByUser(arg0 context.Context, arg1 uuid.UUID) ([]db.ListSessionsByUserRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessionsByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListSessionsByUserRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessionsByUser indicates an expected call of ListSessionsByUser.
+func (mr *MockStoreMockRecorder) ListSessionsByUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessionsByUser", reflect.TypeOf((*MockStore)(nil).ListSessionsByUser), arg0, arg1)
+}
+
+// ListTokenSigningKeys mocks base method.
+func (m *MockStore) ListTokenSigningKeys(arg0 context.Context) ([]db.TokenSigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTokenSigningKeys", arg0)
+	ret0, _ := ret[0].([]db.TokenSigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTokenSigningKeys indicates an expected call of ListTokenSigningKeys.
+func (mr *MockStoreMockRecorder) ListTokenSigningKeys(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTokenSigningKeys", reflect.TypeOf((*MockStore)(nil).ListTokenSigningKeys), arg0)
+}
+
+// ListTransactions mocks base method.
+func (m *MockStore) ListTransactions(arg0 context.Context, arg1 db.ListTransactionsParams) ([]db.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockStoreMockRecorder) ListTransactions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockStore)(nil).ListTransactions), arg0, arg1)
+}
+
+// ListTrustedUserDevices mocks base method.
+func (m *MockStore) ListTrustedUserDevices(arg0 context.Context, arg1 uuid.UUID) ([]db.UserDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrustedUserDevices", arg0, arg1)
+	ret0, _ := ret[0].([]db.UserDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrustedUserDevices indicates an expected call of ListTrustedUserDevices.
+func (mr *MockStoreMockRecorder) ListTrustedUserDevices(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrustedUserDevices", reflect.TypeOf((*MockStore)(nil).ListTrustedUserDevices), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockStore) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.ListUsersRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListUsersRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockStoreMockRecorder) ListUsers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), arg0, arg1)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockStore) ListWebhookDeliveries(arg0 context.Context, arg1 db.ListWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ListWebhookDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ListWebhookDeliveries), arg0, arg1)
+}
+
+// ListWebhookSubscriptionsByUserUUID mocks base method.
+func (m *MockStore) ListWebhookSubscriptionsByUserUUID(arg0 context.Context, arg1 uuid.UUID) ([]db.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookSubscriptionsByUserUUID", arg0, arg1)
+	ret0, _ := ret[0].([]db.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookSubscriptionsByUserUUID indicates an expected call of ListWebhookSubscriptionsByUserUUID.
+func (mr *MockStoreMockRecorder) ListWebhookSubscriptionsByUserUUID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookSubscriptionsByUserUUID", reflect.TypeOf((*MockStore)(nil).ListWebhookSubscriptionsByUserUUID), arg0, arg1)
+}
+
+// MarkOutboxEventFailed mocks base method.
+func (m *MockStore) MarkOutboxEventFailed(arg0 context.Context, arg1 db.MarkOutboxEventFailedParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventFailed", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkOutboxEventFailed indicates an expected call of MarkOutboxEventFailed.
+func (mr *MockStoreMockRecorder) MarkOutboxEventFailed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventFailed", reflect.TypeOf((*MockStore)(nil).MarkOutboxEventFailed), arg0, arg1)
+}
+
+// MarkOutboxEventSent mocks base method.
+func (m *MockStore) MarkOutboxEventSent(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventSent", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkOutboxEventSent indicates an expected call of MarkOutboxEventSent.
+func (mr *MockStoreMockRecorder) MarkOutboxEventSent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventSent", reflect.TypeOf((*MockStore)(nil).MarkOutboxEventSent), arg0, arg1)
+}
+
+// MarkWebhookDeliveryFailed mocks base method.
+func (m *MockStore) MarkWebhookDeliveryFailed(arg0 context.Context, arg1 db.MarkWebhookDeliveryFailedParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookDeliveryFailed", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkWebhookDeliveryFailed indicates an expected call of MarkWebhookDeliveryFailed.
+func (mr *MockStoreMockRecorder) MarkWebhookDeliveryFailed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookDeliveryFailed", reflect.TypeOf((*MockStore)(nil).MarkWebhookDeliveryFailed), arg0, arg1)
+}
+
+// MarkWebhookDeliverySucceeded mocks base method.
+func (m *MockStore) MarkWebhookDeliverySucceeded(arg0 context.Context, arg1 db.MarkWebhookDeliverySucceededParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookDeliverySucceeded", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkWebhookDeliverySucceeded indicates an expected call of MarkWebhookDeliverySucceeded.
+func (mr *MockStoreMockRecorder) MarkWebhookDeliverySucceeded(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookDeliverySucceeded", reflect.TypeOf((*MockStore)(nil).MarkWebhookDeliverySucceeded), arg0, arg1)
+}
+
+// PocketMoveTx mocks base method.
+func (m *MockStore) PocketMoveTx(arg0 context.Context, arg1 db.PocketMoveTxParam) (db.PocketMoveTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PocketMoveTx", arg0, arg1)
+	ret0, _ := ret[0].(db.PocketMoveTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PocketMoveTx indicates an expected call of PocketMoveTx.
+func (mr *MockStoreMockRecorder) PocketMoveTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PocketMoveTx", reflect.TypeOf((*MockStore)(nil).PocketMoveTx), arg0, arg1)
+}
+
+// PurgeExpiredSessions mocks base method.
+func (m *MockStore) PurgeExpiredSessions(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredSessions indicates an expected call of PurgeExpiredSessions.
+func (mr *MockStoreMockRecorder) PurgeExpiredSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredSessions", reflect.TypeOf((*MockStore)(nil).PurgeExpiredSessions), arg0, arg1)
+}
+
+// RecordUserLoginIP mocks base method.
+func (m *MockStore) RecordUserLoginIP(arg0 context.Context, arg1 db.RecordUserLoginIPParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordUserLoginIP", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordUserLoginIP indicates an expected call of RecordUserLoginIP.
+func (mr *MockStoreMockRecorder) RecordUserLoginIP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordUserLoginIP", reflect.TypeOf((*MockStore)(nil).RecordUserLoginIP), arg0, arg1)
+}
+
+// RedeliverWebhookDelivery mocks base method.
+func (m *MockStore) RedeliverWebhookDelivery(arg0 context.Context, arg1 uuid.UUID) (db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeliverWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeliverWebhookDelivery indicates an expected call of RedeliverWebhookDelivery.
+func (mr *MockStoreMockRecorder) RedeliverWebhookDelivery(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverWebhookDelivery", reflect.TypeOf((*MockStore)(nil).RedeliverWebhookDelivery), arg0, arg1)
+}
+
+// RehashUserPassword mocks base method.
+func (m *MockStore) RehashUserPassword(arg0 context.Context, arg1 db.RehashUserPasswordParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashUserPassword", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RehashUserPassword indicates an expected call of RehashUserPassword.
+func (mr *MockStoreMockRecorder) RehashUserPassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashUserPassword", reflect.TypeOf((*MockStore)(nil).RehashUserPassword), arg0, arg1)
+}
+
+// RemoveAccountMember mocks base method.
+func (m *MockStore) RemoveAccountMember(arg0 context.Context, arg1 db.RemoveAccountMemberParams) (db.AccountMember, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUserWithAccountTx", arg0, arg1)
-	ret0, _ := ret[0].(db.CreateUserWithAccountResult)
+	ret := m.ctrl.Call(m, "RemoveAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUserWithAccountTx indicates an expected call of CreateUserWithAccountTx.
-func (mr *MockStoreMockRecorder) CreateUserWithAccountTx(arg0, arg1 any) *gomock.Call {
+// RemoveAccountMember indicates an expected call of RemoveAccountMember.
+func (mr *MockStoreMockRecorder) RemoveAccountMember(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserWithAccountTx", reflect.TypeOf((*MockStore)(nil).CreateUserWithAccountTx), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAccountMember", reflect.TypeOf((*MockStore)(nil).RemoveAccountMember), arg0, arg1)
 }
 
-// GetAccount mocks base method.
-func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.GetAccountRow, error) {
+// RemoveAccountMemberTx mocks base method.
+func (m *MockStore) RemoveAccountMemberTx(arg0 context.Context, arg1 db.RemoveAccountMemberParams) (db.AccountMember, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.GetAccountRow)
+	ret := m.ctrl.Call(m, "RemoveAccountMemberTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccount indicates an expected call of GetAccount.
-func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 any) *gomock.Call {
+// RemoveAccountMemberTx indicates an expected call of RemoveAccountMemberTx.
+func (mr *MockStoreMockRecorder) RemoveAccountMemberTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAccountMemberTx", reflect.TypeOf((*MockStore)(nil).RemoveAccountMemberTx), arg0, arg1)
 }
 
-// GetAccountByUUID mocks base method.
-func (m *MockStore) GetAccountByUUID(arg0 context.Context, arg1 uuid.UUID) (db.GetAccountByUUIDRow, error) {
+// RenewVerificationEmailCode mocks base method.
+func (m *MockStore) RenewVerificationEmailCode(arg0 context.Context, arg1 db.RenewVerificationEmailCodeParams) (db.RenewVerificationEmailCodeRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountByUUID", arg0, arg1)
-	ret0, _ := ret[0].(db.GetAccountByUUIDRow)
+	ret := m.ctrl.Call(m, "RenewVerificationEmailCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RenewVerificationEmailCodeRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountByUUID indicates an expected call of GetAccountByUUID.
-func (mr *MockStoreMockRecorder) GetAccountByUUID(arg0, arg1 any) *gomock.Call {
+// RenewVerificationEmailCode indicates an expected call of RenewVerificationEmailCode.
+func (mr *MockStoreMockRecorder) RenewVerificationEmailCode(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByUUID", reflect.TypeOf((*MockStore)(nil).GetAccountByUUID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewVerificationEmailCode", reflect.TypeOf((*MockStore)(nil).RenewVerificationEmailCode), arg0, arg1)
 }
 
-// GetAccountByUserUUID mocks base method.
-func (m *MockStore) GetAccountByUserUUID(arg0 context.Context, arg1 uuid.UUID) (db.GetAccountByUserUUIDRow, error) {
+// ReplaceRecoveryCodesTx mocks base method.
+func (m *MockStore) ReplaceRecoveryCodesTx(arg0 context.Context, arg1 uuid.UUID, arg2 []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountByUserUUID", arg0, arg1)
-	ret0, _ := ret[0].(db.GetAccountByUserUUIDRow)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodesTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetAccountByUserUUID indicates an expected call of GetAccountByUserUUID.
-func (mr *MockStoreMockRecorder) GetAccountByUserUUID(arg0, arg1 any) *gomock.Call {
+// ReplaceRecoveryCodesTx indicates an expected call of ReplaceRecoveryCodesTx.
+func (mr *MockStoreMockRecorder) ReplaceRecoveryCodesTx(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByUserUUID", reflect.TypeOf((*MockStore)(nil).GetAccountByUserUUID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodesTx", reflect.TypeOf((*MockStore)(nil).ReplaceRecoveryCodesTx), arg0, arg1, arg2)
 }
 
-// GetAccountByUserUUIDAndCurrency mocks base method.
-func (m *MockStore) GetAccountByUserUUIDAndCurrency(arg0 context.Context, arg1 db.GetAccountByUserUUIDAndCurrencyParams) (db.GetAccountByUserUUIDAndCurrencyRow, error) {
+// RequirePasswordResetTx mocks base method.
+func (m *MockStore) RequirePasswordResetTx(arg0 context.Context, arg1 uuid.UUID) (db.RequireUserPasswordResetRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountByUserUUIDAndCurrency", arg0, arg1)
-	ret0, _ := ret[0].(db.GetAccountByUserUUIDAndCurrencyRow)
+	ret := m.ctrl.Call(m, "RequirePasswordResetTx", arg0, arg1)
+	ret0, _ := ret[0].(db.RequireUserPasswordResetRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountByUserUUIDAndCurrency indicates an expected call of GetAccountByUserUUIDAndCurrency.
-func (mr *MockStoreMockRecorder) GetAccountByUserUUIDAndCurrency(arg0, arg1 any) *gomock.Call {
+// RequirePasswordResetTx indicates an expected call of RequirePasswordResetTx.
+func (mr *MockStoreMockRecorder) RequirePasswordResetTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByUserUUIDAndCurrency", reflect.TypeOf((*MockStore)(nil).GetAccountByUserUUIDAndCurrency), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequirePasswordResetTx", reflect.TypeOf((*MockStore)(nil).RequirePasswordResetTx), arg0, arg1)
 }
 
-// GetAccountByUserUUIDMany mocks base method.
-func (m *MockStore) GetAccountByUserUUIDMany(arg0 context.Context, arg1 uuid.UUID) ([]db.GetAccountByUserUUIDManyRow, error) {
+// RequireUserPasswordReset mocks base method.
+func (m *MockStore) RequireUserPasswordReset(arg0 context.Context, arg1 uuid.UUID) (db.RequireUserPasswordResetRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountByUserUUIDMany", arg0, arg1)
-	ret0, _ := ret[0].([]db.GetAccountByUserUUIDManyRow)
+	ret := m.ctrl.Call(m, "RequireUserPasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(db.RequireUserPasswordResetRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountByUserUUIDMany indicates an expected call of GetAccountByUserUUIDMany.
-func (mr *MockStoreMockRecorder) GetAccountByUserUUIDMany(arg0, arg1 any) *gomock.Call {
+// RequireUserPasswordReset indicates an expected call of RequireUserPasswordReset.
+func (mr *MockStoreMockRecorder) RequireUserPasswordReset(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByUserUUIDMany", reflect.TypeOf((*MockStore)(nil).GetAccountByUserUUIDMany), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequireUserPasswordReset", reflect.TypeOf((*MockStore)(nil).RequireUserPasswordReset), arg0, arg1)
 }
 
-// GetAccountForUpdate mocks base method.
-func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.GetAccountForUpdateRow, error) {
+// ResetPasswordTx mocks base method.
+func (m *MockStore) ResetPasswordTx(arg0 context.Context, arg1 db.ResetPasswordTxParam) (db.UpdateUserPasswordRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
-	ret0, _ := ret[0].(db.GetAccountForUpdateRow)
+	ret := m.ctrl.Call(m, "ResetPasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.UpdateUserPasswordRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
-func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 any) *gomock.Call {
+// ResetPasswordTx indicates an expected call of ResetPasswordTx.
+func (mr *MockStoreMockRecorder) ResetPasswordTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPasswordTx", reflect.TypeOf((*MockStore)(nil).ResetPasswordTx), arg0, arg1)
 }
 
-// GetDetailLoginByUsername mocks base method.
-func (m *MockStore) GetDetailLoginByUsername(arg0 context.Context, arg1 string) (db.GetDetailLoginByUsernameRow, error) {
+// RetireTokenSigningKeys mocks base method.
+func (m *MockStore) RetireTokenSigningKeys(arg0 context.Context, arg1 pgtype.Timestamptz) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetDetailLoginByUsername", arg0, arg1)
-	ret0, _ := ret[0].(db.GetDetailLoginByUsernameRow)
+	ret := m.ctrl.Call(m, "RetireTokenSigningKeys", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetDetailLoginByUsername indicates an expected call of GetDetailLoginByUsername.
-func (mr *MockStoreMockRecorder) GetDetailLoginByUsername(arg0, arg1 any) *gomock.Call {
+// RetireTokenSigningKeys indicates an expected call of RetireTokenSigningKeys.
+func (mr *MockStoreMockRecorder) RetireTokenSigningKeys(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDetailLoginByUsername", reflect.TypeOf((*MockStore)(nil).GetDetailLoginByUsername), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetireTokenSigningKeys", reflect.TypeOf((*MockStore)(nil).RetireTokenSigningKeys), arg0, arg1)
 }
 
-// GetEntry mocks base method.
-func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+// ReviewComplianceReview mocks base method.
+func (m *MockStore) ReviewComplianceReview(arg0 context.Context, arg1 db.ReviewComplianceReviewParams) (db.ComplianceReview, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
-	ret0, _ := ret[0].(db.Entry)
+	ret := m.ctrl.Call(m, "ReviewComplianceReview", arg0, arg1)
+	ret0, _ := ret[0].(db.ComplianceReview)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntry indicates an expected call of GetEntry.
-func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 any) *gomock.Call {
+// ReviewComplianceReview indicates an expected call of ReviewComplianceReview.
+func (mr *MockStoreMockRecorder) ReviewComplianceReview(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewComplianceReview", reflect.TypeOf((*MockStore)(nil).ReviewComplianceReview), arg0, arg1)
 }
 
-// GetSession mocks base method.
-func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+// ReviewRiskDecision mocks base method.
+func (m *MockStore) ReviewRiskDecision(arg0 context.Context, arg1 db.ReviewRiskDecisionParams) (db.RiskDecision, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "ReviewRiskDecision", arg0, arg1)
+	ret0, _ := ret[0].(db.RiskDecision)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 any) *gomock.Call {
+// ReviewRiskDecision indicates an expected call of ReviewRiskDecision.
+func (mr *MockStoreMockRecorder) ReviewRiskDecision(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewRiskDecision", reflect.TypeOf((*MockStore)(nil).ReviewRiskDecision), arg0, arg1)
 }
 
-// GetTransaction mocks base method.
-func (m *MockStore) GetTransaction(arg0 context.Context, arg1 int64) (db.Transaction, error) {
+// RevokeAPIKey mocks base method.
+func (m *MockStore) RevokeAPIKey(arg0 context.Context, arg1 db.RevokeAPIKeyParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransaction", arg0, arg1)
-	ret0, _ := ret[0].(db.Transaction)
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransaction indicates an expected call of GetTransaction.
-func (mr *MockStoreMockRecorder) GetTransaction(arg0, arg1 any) *gomock.Call {
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockStoreMockRecorder) RevokeAPIKey(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockStore)(nil).GetTransaction), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockStore)(nil).RevokeAPIKey), arg0, arg1)
 }
 
-// GetUserByEmail mocks base method.
-func (m *MockStore) GetUserByEmail(arg0 context.Context, arg1 string) (db.GetUserByEmailRow, error) {
+// RevokePasswordResets mocks base method.
+func (m *MockStore) RevokePasswordResets(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1)
-	ret0, _ := ret[0].(db.GetUserByEmailRow)
+	ret := m.ctrl.Call(m, "RevokePasswordResets", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByEmail indicates an expected call of GetUserByEmail.
-func (mr *MockStoreMockRecorder) GetUserByEmail(arg0, arg1 any) *gomock.Call {
+// RevokePasswordResets indicates an expected call of RevokePasswordResets.
+func (mr *MockStoreMockRecorder) RevokePasswordResets(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokePasswordResets", reflect.TypeOf((*MockStore)(nil).RevokePasswordResets), arg0, arg1)
 }
 
-// GetUserByUserUUID mocks base method.
-func (m *MockStore) GetUserByUserUUID(arg0 context.Context, arg1 uuid.UUID) (db.GetUserByUserUUIDRow, error) {
+// RevokeRecoveryCodes mocks base method.
+func (m *MockStore) RevokeRecoveryCodes(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByUserUUID", arg0, arg1)
-	ret0, _ := ret[0].(db.GetUserByUserUUIDRow)
+	ret := m.ctrl.Call(m, "RevokeRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByUserUUID indicates an expected call of GetUserByUserUUID.
-func (mr *MockStoreMockRecorder) GetUserByUserUUID(arg0, arg1 any) *gomock.Call {
+// RevokeRecoveryCodes indicates an expected call of RevokeRecoveryCodes.
+func (mr *MockStoreMockRecorder) RevokeRecoveryCodes(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUserUUID", reflect.TypeOf((*MockStore)(nil).GetUserByUserUUID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRecoveryCodes", reflect.TypeOf((*MockStore)(nil).RevokeRecoveryCodes), arg0, arg1)
 }
 
-// GetUserByUsername mocks base method.
-func (m *MockStore) GetUserByUsername(arg0 context.Context, arg1 string) (db.GetUserByUsernameRow, error) {
+// RotateSession mocks base method.
+func (m *MockStore) RotateSession(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByUsername", arg0, arg1)
-	ret0, _ := ret[0].(db.GetUserByUsernameRow)
+	ret := m.ctrl.Call(m, "RotateSession", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByUsername indicates an expected call of GetUserByUsername.
-func (mr *MockStoreMockRecorder) GetUserByUsername(arg0, arg1 any) *gomock.Call {
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockStoreMockRecorder) RotateSession(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockStore)(nil).GetUserByUsername), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockStore)(nil).RotateSession), arg0, arg1)
 }
 
-// GetUserByVerificationEmailCode mocks base method.
-func (m *MockStore) GetUserByVerificationEmailCode(arg0 context.Context, arg1 pgtype.Text) (db.GetUserByVerificationEmailCodeRow, error) {
+// RotateTokenSigningKeyTx mocks base method.
+func (m *MockStore) RotateTokenSigningKeyTx(arg0 context.Context, arg1 db.RotateTokenSigningKeyTxParam) (db.TokenSigningKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByVerificationEmailCode", arg0, arg1)
-	ret0, _ := ret[0].(db.GetUserByVerificationEmailCodeRow)
+	ret := m.ctrl.Call(m, "RotateTokenSigningKeyTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TokenSigningKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByVerificationEmailCode indicates an expected call of GetUserByVerificationEmailCode.
-func (mr *MockStoreMockRecorder) GetUserByVerificationEmailCode(arg0, arg1 any) *gomock.Call {
+// RotateTokenSigningKeyTx indicates an expected call of RotateTokenSigningKeyTx.
+func (mr *MockStoreMockRecorder) RotateTokenSigningKeyTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByVerificationEmailCode", reflect.TypeOf((*MockStore)(nil).GetUserByVerificationEmailCode), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateTokenSigningKeyTx", reflect.TypeOf((*MockStore)(nil).RotateTokenSigningKeyTx), arg0, arg1)
 }
 
-// ListAccounts mocks base method.
-func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.ListAccountsRow, error) {
+// SetAccountMemberTx mocks base method.
+func (m *MockStore) SetAccountMemberTx(arg0 context.Context, arg1 db.AddAccountMemberParams) (db.AccountMember, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
-	ret0, _ := ret[0].([]db.ListAccountsRow)
+	ret := m.ctrl.Call(m, "SetAccountMemberTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccounts indicates an expected call of ListAccounts.
-func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 any) *gomock.Call {
+// SetAccountMemberTx indicates an expected call of SetAccountMemberTx.
+func (mr *MockStoreMockRecorder) SetAccountMemberTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountMemberTx", reflect.TypeOf((*MockStore)(nil).SetAccountMemberTx), arg0, arg1)
 }
 
-// ListAccountsByUserUUID mocks base method.
-func (m *MockStore) ListAccountsByUserUUID(arg0 context.Context, arg1 db.ListAccountsByUserUUIDParams) ([]db.ListAccountsByUserUUIDRow, error) {
+// SetComplianceReviewTransaction mocks base method.
+func (m *MockStore) SetComplianceReviewTransaction(arg0 context.Context, arg1 db.SetComplianceReviewTransactionParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccountsByUserUUID", arg0, arg1)
-	ret0, _ := ret[0].([]db.ListAccountsByUserUUIDRow)
+	ret := m.ctrl.Call(m, "SetComplianceReviewTransaction", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccountsByUserUUID indicates an expected call of ListAccountsByUserUUID.
-func (mr *MockStoreMockRecorder) ListAccountsByUserUUID(arg0, arg1 any) *gomock.Call {
+// SetComplianceReviewTransaction indicates an expected call of SetComplianceReviewTransaction.
+func (mr *MockStoreMockRecorder) SetComplianceReviewTransaction(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsByUserUUID", reflect.TypeOf((*MockStore)(nil).ListAccountsByUserUUID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetComplianceReviewTransaction", reflect.TypeOf((*MockStore)(nil).SetComplianceReviewTransaction), arg0, arg1)
 }
 
-// ListEntries mocks base method.
-func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
+// SetRiskDecisionTransaction mocks base method.
+func (m *MockStore) SetRiskDecisionTransaction(arg0 context.Context, arg1 db.SetRiskDecisionTransactionParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
-	ret0, _ := ret[0].([]db.Entry)
+	ret := m.ctrl.Call(m, "SetRiskDecisionTransaction", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListEntries indicates an expected call of ListEntries.
-func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 any) *gomock.Call {
+// SetRiskDecisionTransaction indicates an expected call of SetRiskDecisionTransaction.
+func (mr *MockStoreMockRecorder) SetRiskDecisionTransaction(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRiskDecisionTransaction", reflect.TypeOf((*MockStore)(nil).SetRiskDecisionTransaction), arg0, arg1)
 }
 
-// ListTransactions mocks base method.
-func (m *MockStore) ListTransactions(arg0 context.Context, arg1 db.ListTransactionsParams) ([]db.Transaction, error) {
+// SetRoleMFARequired mocks base method.
+func (m *MockStore) SetRoleMFARequired(arg0 context.Context, arg1 db.SetRoleMFARequiredParams) (db.RoleSetting, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTransactions", arg0, arg1)
-	ret0, _ := ret[0].([]db.Transaction)
+	ret := m.ctrl.Call(m, "SetRoleMFARequired", arg0, arg1)
+	ret0, _ := ret[0].(db.RoleSetting)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListTransactions indicates an expected call of ListTransactions.
-func (mr *MockStoreMockRecorder) ListTransactions(arg0, arg1 any) *gomock.Call {
+// SetRoleMFARequired indicates an expected call of SetRoleMFARequired.
+func (mr *MockStoreMockRecorder) SetRoleMFARequired(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockStore)(nil).ListTransactions), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoleMFARequired", reflect.TypeOf((*MockStore)(nil).SetRoleMFARequired), arg0, arg1)
 }
 
 // SoftDeleteAccount mocks base method.
@@ -462,6 +1972,21 @@ func (mr *MockStoreMockRecorder) SoftDeleteAccount(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteAccount", reflect.TypeOf((*MockStore)(nil).SoftDeleteAccount), arg0, arg1)
 }
 
+// SoftDeleteWebhookSubscription mocks base method.
+func (m *MockStore) SoftDeleteWebhookSubscription(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteWebhookSubscription", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteWebhookSubscription indicates an expected call of SoftDeleteWebhookSubscription.
+func (mr *MockStoreMockRecorder) SoftDeleteWebhookSubscription(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteWebhookSubscription", reflect.TypeOf((*MockStore)(nil).SoftDeleteWebhookSubscription), arg0, arg1)
+}
+
 // SubtractAccountBalance mocks base method.
 func (m *MockStore) SubtractAccountBalance(arg0 context.Context, arg1 db.SubtractAccountBalanceParams) (db.SubtractAccountBalanceRow, error) {
 	m.ctrl.T.Helper()
@@ -477,6 +2002,36 @@ func (mr *MockStoreMockRecorder) SubtractAccountBalance(arg0, arg1 any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubtractAccountBalance", reflect.TypeOf((*MockStore)(nil).SubtractAccountBalance), arg0, arg1)
 }
 
+// SumEntriesBetween mocks base method.
+func (m *MockStore) SumEntriesBetween(arg0 context.Context, arg1 db.SumEntriesBetweenParams) (pgtype.Numeric, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumEntriesBetween", arg0, arg1)
+	ret0, _ := ret[0].(pgtype.Numeric)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumEntriesBetween indicates an expected call of SumEntriesBetween.
+func (mr *MockStoreMockRecorder) SumEntriesBetween(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumEntriesBetween", reflect.TypeOf((*MockStore)(nil).SumEntriesBetween), arg0, arg1)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockStore) TouchAPIKey(arg0 context.Context, arg1 db.TouchAPIKeyParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockStoreMockRecorder) TouchAPIKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockStore)(nil).TouchAPIKey), arg0, arg1)
+}
+
 // TransferTx mocks base method.
 func (m *MockStore) TransferTx(arg0 context.Context, arg1 db.TransferTxParam) (db.TransferTxResult, error) {
 	m.ctrl.T.Helper()
@@ -492,6 +2047,36 @@ func (mr *MockStoreMockRecorder) TransferTx(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockStore)(nil).TransferTx), arg0, arg1)
 }
 
+// TrustUserDevice mocks base method.
+func (m *MockStore) TrustUserDevice(arg0 context.Context, arg1 db.TrustUserDeviceParams) (db.UserDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrustUserDevice", arg0, arg1)
+	ret0, _ := ret[0].(db.UserDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TrustUserDevice indicates an expected call of TrustUserDevice.
+func (mr *MockStoreMockRecorder) TrustUserDevice(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrustUserDevice", reflect.TypeOf((*MockStore)(nil).TrustUserDevice), arg0, arg1)
+}
+
+// UntrustUserDevice mocks base method.
+func (m *MockStore) UntrustUserDevice(arg0 context.Context, arg1 db.UntrustUserDeviceParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntrustUserDevice", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UntrustUserDevice indicates an expected call of UntrustUserDevice.
+func (mr *MockStoreMockRecorder) UntrustUserDevice(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntrustUserDevice", reflect.TypeOf((*MockStore)(nil).UntrustUserDevice), arg0, arg1)
+}
+
 // UpdateAccount mocks base method.
 func (m *MockStore) UpdateAccount(arg0 context.Context, arg1 db.UpdateAccountParams) (db.UpdateAccountRow, error) {
 	m.ctrl.T.Helper()
@@ -522,6 +2107,21 @@ func (mr *MockStoreMockRecorder) UpdateProfileAccount(arg0, arg1 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfileAccount", reflect.TypeOf((*MockStore)(nil).UpdateProfileAccount), arg0, arg1)
 }
 
+// UpdateProfileAccountTx mocks base method.
+func (m *MockStore) UpdateProfileAccountTx(arg0 context.Context, arg1 db.UpdateProfileAccountParams) (db.UpdateProfileAccountRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfileAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(db.UpdateProfileAccountRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfileAccountTx indicates an expected call of UpdateProfileAccountTx.
+func (mr *MockStoreMockRecorder) UpdateProfileAccountTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfileAccountTx", reflect.TypeOf((*MockStore)(nil).UpdateProfileAccountTx), arg0, arg1)
+}
+
 // UpdateUser mocks base method.
 func (m *MockStore) UpdateUser(arg0 context.Context, arg1 db.UpdateUserParams) (db.UpdateUserRow, error) {
 	m.ctrl.T.Helper()
@@ -537,6 +2137,21 @@ func (mr *MockStoreMockRecorder) UpdateUser(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), arg0, arg1)
 }
 
+// UpdateUserBlocked mocks base method.
+func (m *MockStore) UpdateUserBlocked(arg0 context.Context, arg1 db.UpdateUserBlockedParams) (db.UpdateUserBlockedRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserBlocked", arg0, arg1)
+	ret0, _ := ret[0].(db.UpdateUserBlockedRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserBlocked indicates an expected call of UpdateUserBlocked.
+func (mr *MockStoreMockRecorder) UpdateUserBlocked(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserBlocked", reflect.TypeOf((*MockStore)(nil).UpdateUserBlocked), arg0, arg1)
+}
+
 // UpdateUserPassword mocks base method.
 func (m *MockStore) UpdateUserPassword(arg0 context.Context, arg1 db.UpdateUserPasswordParams) (db.UpdateUserPasswordRow, error) {
 	m.ctrl.T.Helper()
@@ -552,6 +2167,36 @@ func (mr *MockStoreMockRecorder) UpdateUserPassword(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), arg0, arg1)
 }
 
+// UpdateUserRole mocks base method.
+func (m *MockStore) UpdateUserRole(arg0 context.Context, arg1 db.UpdateUserRoleParams) (db.UpdateUserRoleRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", arg0, arg1)
+	ret0, _ := ret[0].(db.UpdateUserRoleRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockStoreMockRecorder) UpdateUserRole(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockStore)(nil).UpdateUserRole), arg0, arg1)
+}
+
+// UpdateUserTx mocks base method.
+func (m *MockStore) UpdateUserTx(arg0 context.Context, arg1 db.UpdateUserTxParam) (db.UpdateUserRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.UpdateUserRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTx indicates an expected call of UpdateUserTx.
+func (mr *MockStoreMockRecorder) UpdateUserTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTx", reflect.TypeOf((*MockStore)(nil).UpdateUserTx), arg0, arg1)
+}
+
 // UpdateUserVerificationEmail mocks base method.
 func (m *MockStore) UpdateUserVerificationEmail(arg0 context.Context, arg1 db.UpdateUserVerificationEmailParams) (db.UpdateUserVerificationEmailRow, error) {
 	m.ctrl.T.Helper()
@@ -566,3 +2211,63 @@ func (mr *MockStoreMockRecorder) UpdateUserVerificationEmail(arg0, arg1 any) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserVerificationEmail", reflect.TypeOf((*MockStore)(nil).UpdateUserVerificationEmail), arg0, arg1)
 }
+
+// UpsertUserDevice mocks base method.
+func (m *MockStore) UpsertUserDevice(arg0 context.Context, arg1 db.UpsertUserDeviceParams) (db.UserDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUserDevice", arg0, arg1)
+	ret0, _ := ret[0].(db.UserDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertUserDevice indicates an expected call of UpsertUserDevice.
+func (mr *MockStoreMockRecorder) UpsertUserDevice(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserDevice", reflect.TypeOf((*MockStore)(nil).UpsertUserDevice), arg0, arg1)
+}
+
+// UsePasswordReset mocks base method.
+func (m *MockStore) UsePasswordReset(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsePasswordReset indicates an expected call of UsePasswordReset.
+func (mr *MockStoreMockRecorder) UsePasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePasswordReset", reflect.TypeOf((*MockStore)(nil).UsePasswordReset), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockStore) UseTOTPStep(arg0 context.Context, arg1 db.UseTOTPStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockStoreMockRecorder) UseTOTPStep(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockStore)(nil).UseTOTPStep), arg0, arg1)
+}
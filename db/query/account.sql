@@ -14,20 +14,40 @@ SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, update
 WHERE deleted_at IS NULL AND id = $1 LIMIT 1;
 
 -- name: GetAccountByUUID :one
-SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status FROM accounts
+SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status, parent_account_id, pocket_name FROM accounts
 WHERE deleted_at IS NULL AND account_uuid = $1 LIMIT 1;
 
 -- name: GetAccountByUserUUIDAndCurrency :one
 SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status FROM accounts
-WHERE deleted_at IS NULL AND user_uuid = $1 AND currency = $2 LIMIT 1;
+WHERE deleted_at IS NULL AND parent_account_id IS NULL AND user_uuid = $1 AND currency = $2 LIMIT 1;
 
 -- name: GetAccountByUserUUID :one
 SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status FROM accounts
-WHERE deleted_at IS NULL AND user_uuid = $1 LIMIT 1;
+WHERE deleted_at IS NULL AND parent_account_id IS NULL AND user_uuid = $1 LIMIT 1;
 
 -- name: GetAccountByUserUUIDMany :many
 SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status FROM accounts
-WHERE deleted_at IS NULL AND user_uuid = $1;
+WHERE deleted_at IS NULL AND parent_account_id IS NULL AND user_uuid = $1;
+
+-- name: CreatePocket :one
+INSERT INTO accounts (
+  owner,
+  balance,
+  user_uuid,
+  currency,
+  status,
+  parent_account_id,
+  pocket_name
+)
+SELECT owner, 0, user_uuid, currency, 1, id, sqlc.arg(pocket_name)::varchar FROM accounts
+WHERE id = sqlc.arg(parent_account_id)
+FOR SHARE
+RETURNING id, owner, currency, balance, user_uuid, account_uuid, status, created_at, parent_account_id, pocket_name;
+
+-- name: ListPocketsByParentID :many
+SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status, parent_account_id, pocket_name FROM accounts
+WHERE deleted_at IS NULL AND parent_account_id = sqlc.arg(parent_account_id)::bigint
+ORDER BY id;
 
 -- name: GetAccountForUpdate :one
 SELECT id, owner,  currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status FROM accounts
@@ -37,26 +57,28 @@ FOR NO KEY UPDATE;
 -- name: ListAccounts :many
 SELECT accounts.id, owner, currency, balance, accounts.user_uuid, accounts.created_at, account_uuid, accounts.updated_at, accounts.deleted_at, status, u.email, u.full_name, u.username FROM accounts
 LEFT JOIN users u ON accounts.user_uuid = u.user_uuid
-WHERE accounts.deleted_at IS NULL
+WHERE accounts.deleted_at IS NULL AND accounts.parent_account_id IS NULL
 ORDER BY accounts.id
 LIMIT $1
 OFFSET $2;
 
 -- name: CountAccounts :one
 SELECT COUNT(*) FROM accounts
-WHERE deleted_at IS NULL;
+WHERE deleted_at IS NULL AND parent_account_id IS NULL;
 
 -- name: ListAccountsByUserUUID :many
 SELECT accounts.id, owner, currency, balance, accounts.user_uuid, accounts.created_at, account_uuid, accounts.updated_at, accounts.deleted_at, status, u.email, u.full_name, u.username FROM accounts
+JOIN account_members m ON m.account_id = accounts.id AND m.removed_at IS NULL
 LEFT JOIN users u ON accounts.user_uuid = u.user_uuid
-WHERE accounts.deleted_at IS NULL AND accounts.user_uuid = $1
+WHERE accounts.deleted_at IS NULL AND accounts.parent_account_id IS NULL AND m.user_uuid = $1
 ORDER BY accounts.id
 LIMIT $2
 OFFSET $3;
 
 -- name: CountAccountsByUserUUID :one
 SELECT COUNT(*) FROM accounts
-WHERE deleted_at IS NULL AND user_uuid = $1;
+JOIN account_members m ON m.account_id = accounts.id AND m.removed_at IS NULL
+WHERE accounts.deleted_at IS NULL AND accounts.parent_account_id IS NULL AND m.user_uuid = $1;
 
 
 -- name: UpdateAccount :one
@@ -0,0 +1,35 @@
+-- name: AddAccountMember :one
+INSERT INTO account_members (
+  account_id,
+  user_uuid,
+  role,
+  permission,
+  transfer_limit
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id, user_uuid) WHERE removed_at IS NULL
+DO UPDATE SET role = EXCLUDED.role, permission = EXCLUDED.permission, transfer_limit = EXCLUDED.transfer_limit, updated_at = now()
+RETURNING *;
+
+-- name: GetAccountMember :one
+SELECT * FROM account_members
+WHERE account_id = $1 AND user_uuid = $2 AND removed_at IS NULL
+LIMIT 1;
+
+-- name: ListAccountMembers :many
+SELECT account_members.id, account_members.user_uuid, account_members.role, account_members.permission, account_members.transfer_limit, account_members.created_at, u.username, u.full_name, u.email FROM account_members
+JOIN users u ON account_members.user_uuid = u.user_uuid
+WHERE account_members.account_id = $1 AND account_members.removed_at IS NULL
+ORDER BY account_members.id;
+
+-- name: ListAccountOwnersForUpdate :many
+SELECT user_uuid FROM account_members
+WHERE account_id = $1 AND role = 'owner' AND removed_at IS NULL
+FOR UPDATE;
+
+-- name: RemoveAccountMember :one
+UPDATE account_members
+SET removed_at = now()
+WHERE account_id = $1 AND user_uuid = $2 AND removed_at IS NULL
+RETURNING *;
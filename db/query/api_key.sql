@@ -0,0 +1,37 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+  user_uuid,
+  name,
+  prefix,
+  secret_hash,
+  scopes,
+  created_by,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT k.id, k.key_uuid, k.user_uuid, k.name, k.prefix, k.secret_hash, k.scopes, k.created_by, k.expires_at, k.last_used_at, k.last_used_ip, k.revoked_at, k.created_at, u.role, u.is_blocked
+FROM api_keys k
+JOIN users u ON u.user_uuid = k.user_uuid
+WHERE k.prefix = $1 LIMIT 1;
+
+-- name: ListAPIKeysByUser :many
+SELECT * FROM api_keys
+WHERE user_uuid = $1
+ORDER BY id DESC;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = now()
+WHERE key_uuid = $1
+AND user_uuid = $2
+AND revoked_at IS NULL;
+
+-- name: TouchAPIKey :execrows
+UPDATE api_keys
+SET last_used_at = now(),
+    last_used_ip = $2
+WHERE key_uuid = $1
+AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
@@ -0,0 +1,35 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+  actor_uuid,
+  actor_role,
+  action,
+  entity_type,
+  entity_id,
+  before,
+  after,
+  client_ip,
+  user_agent
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: ListAuditLogs :many
+SELECT * FROM audit_log
+WHERE (sqlc.narg(actor_uuid)::uuid IS NULL OR actor_uuid = sqlc.narg(actor_uuid))
+AND (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+AND (sqlc.narg(entity_type)::varchar IS NULL OR entity_type = sqlc.narg(entity_type))
+AND (sqlc.narg(entity_id)::varchar IS NULL OR entity_id = sqlc.narg(entity_id))
+AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
+
+-- name: CountAuditLogs :one
+SELECT COUNT(*) FROM audit_log
+WHERE (sqlc.narg(actor_uuid)::uuid IS NULL OR actor_uuid = sqlc.narg(actor_uuid))
+AND (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+AND (sqlc.narg(entity_type)::varchar IS NULL OR entity_type = sqlc.narg(entity_type))
+AND (sqlc.narg(entity_id)::varchar IS NULL OR entity_id = sqlc.narg(entity_id))
+AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time));
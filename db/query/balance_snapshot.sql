@@ -0,0 +1,40 @@
+-- name: CreateDailyBalanceSnapshots :execrows
+INSERT INTO account_balance_snapshots (
+  account_id,
+  balance,
+  snapshot_at
+)
+SELECT a.id,
+       a.balance - COALESCE((
+         SELECT SUM(CASE WHEN e.type_trans = 'credit' THEN e.amount ELSE -e.amount END)
+         FROM entries e
+         WHERE e.deleted_at IS NULL AND e.account_id = a.id AND e.created_at > sqlc.arg(snapshot_at)
+       ), 0),
+       sqlc.arg(snapshot_at)
+FROM accounts a
+WHERE a.created_at <= sqlc.arg(snapshot_at)
+ON CONFLICT (account_id, snapshot_at) DO UPDATE SET balance = EXCLUDED.balance;
+
+-- name: GetLatestBalanceSnapshot :one
+SELECT * FROM account_balance_snapshots
+WHERE account_id = $1 AND snapshot_at <= $2
+ORDER BY snapshot_at DESC
+LIMIT 1;
+
+-- name: SumEntriesBetween :one
+SELECT COALESCE(SUM(CASE WHEN type_trans = 'credit' THEN amount ELSE -amount END), 0)::NUMERIC AS net_amount
+FROM entries
+WHERE deleted_at IS NULL AND account_id = sqlc.arg(account_id)
+AND created_at > sqlc.arg(from_time) AND created_at <= sqlc.arg(to_time);
+
+-- name: GetAccountBalanceBefore :one
+SELECT a.id,
+       a.created_at,
+       (a.balance - COALESCE((
+         SELECT SUM(CASE WHEN e.type_trans = 'credit' THEN e.amount ELSE -e.amount END)
+         FROM entries e
+         WHERE e.deleted_at IS NULL AND e.account_id = a.id AND e.created_at > sqlc.arg(as_of)
+       ), 0))::NUMERIC AS balance
+FROM accounts a
+WHERE a.deleted_at IS NULL AND a.id = sqlc.arg(id)
+LIMIT 1;
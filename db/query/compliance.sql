@@ -0,0 +1,50 @@
+-- name: CreateComplianceReview :one
+INSERT INTO compliance_reviews (
+  kind,
+  user_uuid,
+  screened_name,
+  list_reference,
+  listed_name,
+  score,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: GetComplianceReviewByUUID :one
+SELECT * FROM compliance_reviews
+WHERE review_uuid = $1 LIMIT 1;
+
+-- name: ListComplianceReviews :many
+SELECT c.review_uuid, c.kind, c.user_uuid, c.screened_name, c.list_reference, c.listed_name, c.score,
+fa.account_uuid AS from_account_uuid, ta.account_uuid AS to_account_uuid, c.amount, c.currency,
+c.status, c.reviewed_by, c.reviewed_at, c.created_at
+FROM compliance_reviews c
+LEFT JOIN accounts fa ON fa.id = c.from_account_id
+LEFT JOIN accounts ta ON ta.id = c.to_account_id
+WHERE c.status = $1
+ORDER BY c.id
+LIMIT $2
+OFFSET $3;
+
+-- name: CountComplianceReviews :one
+SELECT COUNT(*) FROM compliance_reviews
+WHERE status = $1;
+
+-- name: CountUserComplianceHolds :one
+SELECT COUNT(*) FROM compliance_reviews
+WHERE user_uuid = $1 AND kind IN ('signup', 'rename') AND status IN ('pending', 'confirmed');
+
+-- name: ReviewComplianceReview :one
+UPDATE compliance_reviews
+SET status = sqlc.arg(status), reviewed_by = sqlc.arg(reviewed_by), reviewed_at = now()
+WHERE review_uuid = sqlc.arg(review_uuid) AND status = 'pending'
+RETURNING *;
+
+-- name: SetComplianceReviewTransaction :execrows
+UPDATE compliance_reviews
+SET transaction_id = $2
+WHERE id = $1;
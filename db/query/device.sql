@@ -0,0 +1,56 @@
+-- name: GetUserDevice :one
+SELECT * FROM user_devices
+WHERE user_uuid = $1 AND fingerprint = $2 LIMIT 1;
+
+-- name: CountUserDevices :one
+SELECT COUNT(*) FROM user_devices
+WHERE user_uuid = $1;
+
+-- name: UpsertUserDevice :one
+INSERT INTO user_devices (
+  user_uuid,
+  fingerprint,
+  user_agent,
+  last_client_ip,
+  trusted_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_uuid, fingerprint) DO UPDATE
+SET user_agent = EXCLUDED.user_agent,
+    last_client_ip = EXCLUDED.last_client_ip,
+    last_seen_at = now(),
+    trusted_at = COALESCE(user_devices.trusted_at, EXCLUDED.trusted_at)
+RETURNING *;
+
+-- name: TrustUserDevice :one
+UPDATE user_devices
+SET trusted_at = COALESCE(trusted_at, now())
+WHERE device_uuid = $1
+AND user_uuid = $2
+RETURNING *;
+
+-- name: ListTrustedUserDevices :many
+SELECT * FROM user_devices
+WHERE user_uuid = $1
+AND trusted_at IS NOT NULL
+ORDER BY last_seen_at DESC;
+
+-- name: UntrustUserDevice :execrows
+UPDATE user_devices
+SET trusted_at = NULL
+WHERE device_uuid = $1
+AND user_uuid = $2
+AND trusted_at IS NOT NULL;
+
+-- name: RecordUserLoginIP :one
+-- xmax is 0 for a row the statement inserted rather than updated
+INSERT INTO user_login_ips (
+  user_uuid,
+  client_ip
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_uuid, client_ip) DO UPDATE
+SET last_seen_at = now()
+RETURNING (xmax = 0)::boolean AS first_seen;
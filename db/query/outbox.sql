@@ -0,0 +1,27 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+  event_uuid,
+  event_type,
+  aggregate_type,
+  aggregate_id,
+  payload
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListPendingOutboxEventsForUpdate :many
+SELECT * FROM outbox
+WHERE sent_at IS NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventSent :execrows
+UPDATE outbox
+SET sent_at = now()
+WHERE id = $1 AND sent_at IS NULL;
+
+-- name: MarkOutboxEventFailed :execrows
+UPDATE outbox
+SET attempts = attempts + 1, last_error = $2
+WHERE id = $1 AND sent_at IS NULL;
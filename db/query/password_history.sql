@@ -0,0 +1,16 @@
+-- name: ArchiveUserPassword :execrows
+INSERT INTO password_history (
+  user_uuid,
+  hashed_password
+)
+SELECT user_uuid, hashed_password FROM users
+WHERE user_uuid = $1;
+
+-- name: ListRecentPasswordHashes :many
+SELECT hashed_password FROM users
+WHERE user_uuid = sqlc.arg(user_uuid)
+UNION ALL
+(SELECT hashed_password FROM password_history
+WHERE user_uuid = sqlc.arg(user_uuid)
+ORDER BY id DESC
+LIMIT sqlc.arg(history_limit));
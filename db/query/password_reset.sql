@@ -0,0 +1,37 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (
+  user_uuid,
+  code_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetActivePasswordReset :one
+SELECT * FROM password_resets
+WHERE user_uuid = $1
+AND used_at IS NULL
+AND expires_at > now()
+AND attempts < sqlc.arg(max_attempts)::int
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: IncrementPasswordResetAttempts :execrows
+UPDATE password_resets
+SET attempts = attempts + 1
+WHERE id = sqlc.arg(id)
+AND attempts < sqlc.arg(max_attempts)::int;
+
+-- name: UsePasswordReset :execrows
+UPDATE password_resets
+SET used_at = now()
+WHERE id = $1
+AND used_at IS NULL
+AND expires_at > now();
+
+-- name: RevokePasswordResets :execrows
+UPDATE password_resets
+SET used_at = now()
+WHERE user_uuid = $1
+AND used_at IS NULL;
@@ -0,0 +1,51 @@
+-- name: GetAccountTransferVelocity :one
+SELECT COUNT(*) AS transfer_count, COALESCE(SUM(amount), 0)::NUMERIC AS total_amount
+FROM transactions
+WHERE deleted_at IS NULL AND from_account_id = sqlc.arg(from_account_id) AND created_at > sqlc.arg(since);
+
+-- name: CountTransfersBetweenAccounts :one
+SELECT COUNT(*) FROM transactions
+WHERE deleted_at IS NULL AND from_account_id = $1 AND to_account_id = $2;
+
+-- name: CreateRiskDecision :one
+INSERT INTO risk_decisions (
+  user_uuid,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  decision,
+  reasons
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetRiskDecisionByUUID :one
+SELECT * FROM risk_decisions
+WHERE decision_uuid = $1 LIMIT 1;
+
+-- name: ListRiskDecisionsPendingReview :many
+SELECT r.decision_uuid, r.user_uuid, fa.account_uuid AS from_account_uuid, ta.account_uuid AS to_account_uuid,
+r.amount, r.currency, r.decision, r.reasons, r.created_at
+FROM risk_decisions r
+JOIN accounts fa ON fa.id = r.from_account_id
+JOIN accounts ta ON ta.id = r.to_account_id
+WHERE r.decision = 'review' AND r.review_status IS NULL
+ORDER BY r.id
+LIMIT $1
+OFFSET $2;
+
+-- name: CountRiskDecisionsPendingReview :one
+SELECT COUNT(*) FROM risk_decisions
+WHERE decision = 'review' AND review_status IS NULL;
+
+-- name: ReviewRiskDecision :one
+UPDATE risk_decisions
+SET review_status = sqlc.arg(review_status), reviewed_by = sqlc.arg(reviewed_by), reviewed_at = now()
+WHERE decision_uuid = sqlc.arg(decision_uuid) AND decision = 'review' AND review_status IS NULL
+RETURNING *;
+
+-- name: SetRiskDecisionTransaction :execrows
+UPDATE risk_decisions
+SET transaction_id = $2
+WHERE id = $1;
@@ -0,0 +1,14 @@
+-- name: GetRoleSetting :one
+SELECT * FROM role_settings
+WHERE role = $1 LIMIT 1;
+
+-- name: SetRoleMFARequired :one
+INSERT INTO role_settings (
+  role,
+  mfa_required
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (role)
+DO UPDATE SET mfa_required = EXCLUDED.mfa_required, updated_at = now()
+RETURNING *;
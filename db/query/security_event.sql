@@ -0,0 +1,17 @@
+-- name: CreateSecurityEvent :one
+INSERT INTO security_events (
+  user_uuid,
+  event_type,
+  client_ip,
+  user_agent
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ListSecurityEventsByUser :many
+SELECT * FROM security_events
+WHERE user_uuid = $1
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
@@ -1,6 +1,7 @@
 -- name: CreateSession :one
 INSERT INTO sessions (
   id,
+  family_id,
   user_uuid,
   refresh_token,
   user_agent,
@@ -8,9 +9,61 @@ INSERT INTO sessions (
   is_blocked,
   expires_at
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions
-WHERE id = $1 LIMIT 1;
\ No newline at end of file
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSessionsByUser :many
+SELECT id, user_agent, client_ip, is_blocked, expires_at, created_at
+FROM sessions
+WHERE user_uuid = $1
+ORDER BY created_at DESC;
+
+-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE user_uuid = $1
+AND is_blocked = false;
+
+-- name: BlockSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1
+AND user_uuid = $2
+AND is_blocked = false;
+
+-- name: RotateSession :execrows
+UPDATE sessions
+SET rotated_at = now()
+WHERE id = $1
+AND rotated_at IS NULL
+AND is_blocked = false;
+
+-- name: BlockOtherUserSessions :many
+UPDATE sessions
+SET is_blocked = true
+WHERE user_uuid = $1
+AND family_id <> $2
+AND is_blocked = false
+RETURNING family_id;
+
+-- name: ListActiveSessionsByUser :many
+SELECT s.family_id, s.user_agent, s.client_ip, s.expires_at,
+(SELECT MIN(f.created_at) FROM sessions f WHERE f.family_id = s.family_id)::timestamptz AS created_at
+FROM sessions s
+WHERE s.user_uuid = $1
+AND s.is_blocked = false
+AND s.rotated_at IS NULL
+AND s.expires_at > now()
+ORDER BY created_at DESC;
+
+-- name: PurgeExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at < sqlc.arg(expired_before)
+AND family_id NOT IN (
+  SELECT family_id FROM sessions
+  WHERE expires_at >= sqlc.arg(expired_before)
+);
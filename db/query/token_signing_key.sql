@@ -0,0 +1,29 @@
+-- name: CreateTokenSigningKey :one
+INSERT INTO token_signing_keys (
+  kid,
+  algorithm,
+  private_key,
+  public_key,
+  activates_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: ListTokenSigningKeys :many
+SELECT * FROM token_signing_keys
+WHERE expires_at IS NULL
+OR expires_at > now()
+ORDER BY activates_at DESC;
+
+-- name: GetLatestTokenSigningKeyForUpdate :one
+SELECT * FROM token_signing_keys
+WHERE expires_at IS NULL OR expires_at > now()
+ORDER BY activates_at DESC
+LIMIT 1
+FOR UPDATE;
+
+-- name: RetireTokenSigningKeys :execrows
+UPDATE token_signing_keys
+SET expires_at = $1
+WHERE expires_at IS NULL;
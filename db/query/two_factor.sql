@@ -0,0 +1,53 @@
+-- name: CreateUserTOTP :one
+INSERT INTO user_totp (
+  user_uuid,
+  secret
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_uuid)
+DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = now()
+WHERE user_totp.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetUserTOTP :one
+SELECT * FROM user_totp
+WHERE user_uuid = $1 LIMIT 1;
+
+-- name: ConfirmUserTOTP :execrows
+UPDATE user_totp
+SET confirmed_at = now(), last_used_step = $2
+WHERE user_uuid = $1
+AND confirmed_at IS NULL;
+
+-- name: DisableUserTOTP :execrows
+UPDATE user_totp
+SET confirmed_at = NULL
+WHERE user_uuid = $1
+AND confirmed_at IS NOT NULL;
+
+-- name: UseTOTPStep :execrows
+UPDATE user_totp
+SET last_used_step = $2
+WHERE user_uuid = $1
+AND last_used_step < $2;
+
+-- name: CreateRecoveryCodes :execrows
+INSERT INTO user_recovery_codes (
+  user_uuid,
+  code_hash
+)
+SELECT sqlc.arg(user_uuid), unnest(sqlc.arg(code_hashes)::varchar[]);
+
+-- name: RevokeRecoveryCodes :execrows
+UPDATE user_recovery_codes
+SET used_at = now()
+WHERE user_uuid = $1
+AND used_at IS NULL;
+
+-- name: UseRecoveryCode :execrows
+UPDATE user_recovery_codes
+SET used_at = now()
+WHERE user_uuid = $1
+AND code_hash = $2
+AND used_at IS NULL;
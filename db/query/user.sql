@@ -7,6 +7,9 @@ SELECT  user_uuid
        ,full_name
        ,email
        ,role
+       ,is_blocked
+       ,password_reset_required
+       ,verified_email_at
        ,created_at
        ,updated_at
        ,deleted_at
@@ -44,7 +47,7 @@ AND email = $1
 LIMIT 1;
 
 -- name: GetDetailLoginByUsername :one
-SELECT hashed_password, user_uuid, role, username, email, full_name
+SELECT hashed_password, user_uuid, role, username, email, full_name, is_blocked, password_reset_required, verified_email_at
 FROM users
 WHERE deleted_at = '0001-01-01 00:00:00+00'
 AND username = $1
@@ -52,13 +55,13 @@ LIMIT 1;
 
 -- name: UpdateUser :one
  UPDATE users
-SET hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password), password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at), full_name = COALESCE(sqlc.narg(full_name), full_name), email = COALESCE(sqlc.narg(email), email)
-WHERE user_uuid = sqlc.arg(user_uuid) RETURNING user_uuid, username, full_name, email, role, created_at, updated_at, deleted_at;
+SET hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password), password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at), password_reset_required = password_reset_required AND sqlc.narg(hashed_password) IS NULL, full_name = COALESCE(sqlc.narg(full_name), full_name), email = COALESCE(sqlc.narg(email), email), verified_email_at = CASE WHEN sqlc.narg(email) <> email THEN '0001-01-01 00:00:00Z' ELSE verified_email_at END, verification_email_code = CASE WHEN sqlc.narg(email) <> email THEN NULL ELSE verification_email_code END, verification_email_expired_at = CASE WHEN sqlc.narg(email) <> email THEN NULL ELSE verification_email_expired_at END
+WHERE user_uuid = sqlc.arg(user_uuid) RETURNING user_uuid, username, full_name, email, role, password_changed_at, created_at, updated_at, deleted_at;
 
 -- name: UpdateUserPassword :one
  UPDATE users
-SET hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password), password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at)
-WHERE user_uuid = sqlc.arg(user_uuid) RETURNING user_uuid, username, full_name, email, role, created_at, updated_at, deleted_at;
+SET hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password), password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at), password_reset_required = password_reset_required AND sqlc.narg(hashed_password) IS NULL
+WHERE user_uuid = sqlc.arg(user_uuid) RETURNING user_uuid, username, full_name, email, role, password_changed_at, created_at, updated_at, deleted_at;
 
 
 -- name: UpdateUserVerificationEmail :one
@@ -66,5 +69,65 @@ UPDATE users
 SET verification_email_code = $1, verification_email_expired_at = $2, verified_email_at = $3
 WHERE user_uuid = $4 RETURNING user_uuid, verification_email_code, verification_email_expired_at;
 
+-- name: RenewVerificationEmailCode :one
+UPDATE users
+SET verification_email_code = sqlc.arg(verification_email_code), verification_email_expired_at = sqlc.arg(verification_email_expired_at)
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND user_uuid = sqlc.arg(user_uuid)
+AND verified_email_at = '0001-01-01 00:00:00+00'
+AND (verification_email_expired_at IS NULL OR verification_email_expired_at <= sqlc.arg(issued_code_expires_before))
+RETURNING user_uuid, email, verification_email_expired_at;
+
 -- name: GetUserByVerificationEmailCode :one
 SELECT user_uuid, verification_email_code, verification_email_expired_at, verified_email_at FROM users WHERE  verification_email_code = $1 LIMIT 1;
+
+-- name: ListUsers :many
+SELECT  user_uuid
+       ,username
+       ,full_name
+       ,email
+       ,role
+       ,is_blocked
+       ,password_reset_required
+       ,verified_email_at
+       ,created_at
+FROM users
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND (sqlc.narg(search)::varchar IS NULL OR username ILIKE '%' || sqlc.narg(search) || '%' OR email ILIKE '%' || sqlc.narg(search) || '%' OR full_name ILIKE '%' || sqlc.narg(search) || '%')
+AND (sqlc.narg(role)::varchar IS NULL OR role = sqlc.narg(role))
+AND (sqlc.narg(is_blocked)::boolean IS NULL OR is_blocked = sqlc.narg(is_blocked))
+ORDER BY id
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
+
+-- name: CountUsers :one
+SELECT COUNT(*)
+FROM users
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND (sqlc.narg(search)::varchar IS NULL OR username ILIKE '%' || sqlc.narg(search) || '%' OR email ILIKE '%' || sqlc.narg(search) || '%' OR full_name ILIKE '%' || sqlc.narg(search) || '%')
+AND (sqlc.narg(role)::varchar IS NULL OR role = sqlc.narg(role))
+AND (sqlc.narg(is_blocked)::boolean IS NULL OR is_blocked = sqlc.narg(is_blocked));
+
+-- name: UpdateUserBlocked :one
+UPDATE users
+SET is_blocked = $2, updated_at = now()
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND user_uuid = $1 RETURNING user_uuid, username, full_name, email, role, is_blocked, password_reset_required;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2, updated_at = now()
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND user_uuid = $1 RETURNING user_uuid, username, full_name, email, role, is_blocked, password_reset_required;
+
+-- name: RequireUserPasswordReset :one
+UPDATE users
+SET password_reset_required = true, updated_at = now()
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND user_uuid = $1 RETURNING user_uuid, username, full_name, email, role, is_blocked, password_reset_required;
+
+-- name: RehashUserPassword :execrows
+UPDATE users
+SET hashed_password = sqlc.arg(new_hashed_password)
+WHERE user_uuid = sqlc.arg(user_uuid)
+AND hashed_password = sqlc.arg(old_hashed_password);
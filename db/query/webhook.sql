@@ -0,0 +1,79 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+  user_uuid,
+  url,
+  event_types,
+  secret
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhookSubscriptionByUUID :one
+SELECT * FROM webhook_subscriptions
+WHERE deleted_at IS NULL AND subscription_uuid = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptionsByUserUUID :many
+SELECT * FROM webhook_subscriptions
+WHERE deleted_at IS NULL AND user_uuid = $1
+ORDER BY id;
+
+-- name: SoftDeleteWebhookSubscription :execrows
+UPDATE webhook_subscriptions
+SET deleted_at = now()
+WHERE deleted_at IS NULL AND subscription_uuid = $1;
+
+-- name: CreateWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries (
+  subscription_id,
+  event_uuid,
+  event_type,
+  payload
+)
+SELECT s.id, sqlc.arg(event_uuid), sqlc.arg(event_type), sqlc.arg(payload)
+FROM webhook_subscriptions s
+JOIN users u ON u.user_uuid = s.user_uuid
+WHERE s.deleted_at IS NULL
+AND sqlc.arg(event_type)::varchar = ANY(s.event_types)
+AND (s.user_uuid = ANY(sqlc.arg(owner_uuids)::uuid[]) OR EXISTS (
+  SELECT 1 FROM role_permissions rp
+  WHERE rp.role = u.role AND rp.permission = 'webhooks:manage:any'
+));
+
+-- name: ListDueWebhookDeliveries :many
+SELECT d.id, d.delivery_uuid, d.event_type, d.payload, d.attempts, s.url, s.secret
+FROM webhook_deliveries d
+JOIN webhook_subscriptions s ON s.id = d.subscription_id
+WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND s.deleted_at IS NULL
+ORDER BY d.next_attempt_at
+LIMIT $1;
+
+-- name: MarkWebhookDeliverySucceeded :execrows
+UPDATE webhook_deliveries
+SET status = 'succeeded', attempts = attempts + 1, last_status_code = $2, last_error = NULL, delivered_at = now()
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :execrows
+UPDATE webhook_deliveries
+SET status = $2, attempts = attempts + 1, next_attempt_at = $3, last_status_code = $4, last_error = $5
+WHERE id = $1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: CountWebhookDeliveries :one
+SELECT COUNT(*) FROM webhook_deliveries
+WHERE subscription_id = $1;
+
+-- name: GetWebhookDeliveryByUUID :one
+SELECT * FROM webhook_deliveries
+WHERE delivery_uuid = $1 LIMIT 1;
+
+-- name: RedeliverWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = now()
+WHERE delivery_uuid = $1
+RETURNING *;
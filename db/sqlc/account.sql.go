@@ -58,7 +58,7 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 
 const countAccounts = `-- name: CountAccounts :one
 SELECT COUNT(*) FROM accounts
-WHERE deleted_at IS NULL
+WHERE deleted_at IS NULL AND parent_account_id IS NULL
 `
 
 func (q *Queries) CountAccounts(ctx context.Context) (int64, error) {
@@ -70,7 +70,8 @@ func (q *Queries) CountAccounts(ctx context.Context) (int64, error) {
 
 const countAccountsByUserUUID = `-- name: CountAccountsByUserUUID :one
 SELECT COUNT(*) FROM accounts
-WHERE deleted_at IS NULL AND user_uuid = $1
+JOIN account_members m ON m.account_id = accounts.id AND m.removed_at IS NULL
+WHERE accounts.deleted_at IS NULL AND accounts.parent_account_id IS NULL AND m.user_uuid = $1
 `
 
 func (q *Queries) CountAccountsByUserUUID(ctx context.Context, userUuid uuid.UUID) (int64, error) {
@@ -131,6 +132,58 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (C
 	return i, err
 }
 
+const createPocket = `-- name: CreatePocket :one
+INSERT INTO accounts (
+  owner,
+  balance,
+  user_uuid,
+  currency,
+  status,
+  parent_account_id,
+  pocket_name
+)
+SELECT owner, 0, user_uuid, currency, 1, id, $1::varchar FROM accounts
+WHERE id = $2
+FOR SHARE
+RETURNING id, owner, currency, balance, user_uuid, account_uuid, status, created_at, parent_account_id, pocket_name
+`
+
+type CreatePocketParams struct {
+	PocketName      string `json:"pocket_name"`
+	ParentAccountID int64  `json:"parent_account_id"`
+}
+
+type CreatePocketRow struct {
+	ID              int64          `json:"id"`
+	Owner           string         `json:"owner"`
+	Currency        string         `json:"currency"`
+	Balance         pgtype.Numeric `json:"balance"`
+	UserUuid        uuid.UUID      `json:"user_uuid"`
+	AccountUuid     uuid.UUID      `json:"account_uuid"`
+	Status          int16          `json:"status"`
+	CreatedAt       time.Time      `json:"created_at"`
+	ParentAccountID pgtype.Int8    `json:"parent_account_id"`
+	PocketName      pgtype.Text    `json:"pocket_name"`
+}
+
+func (q *Queries) CreatePocket(ctx context.Context, arg CreatePocketParams) (CreatePocketRow, error) {
+	row := q.db.QueryRow(ctx, createPocket, arg.PocketName, arg.ParentAccountID)
+	var i CreatePocketRow
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Currency,
+		&i.Balance,
+		&i.UserUuid,
+		&i.AccountUuid,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ParentAccountID,
+		&i.PocketName,
+	)
+	return i, err
+}
+
 const getAccount = `-- name: GetAccount :one
 SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status FROM accounts
 WHERE deleted_at IS NULL AND id = $1 LIMIT 1
@@ -168,21 +221,23 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (GetAccountRow, erro
 }
 
 const getAccountByUUID = `-- name: GetAccountByUUID :one
-SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status FROM accounts
+SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status, parent_account_id, pocket_name FROM accounts
 WHERE deleted_at IS NULL AND account_uuid = $1 LIMIT 1
 `
 
 type GetAccountByUUIDRow struct {
-	ID          int64              `json:"id"`
-	Owner       string             `json:"owner"`
-	Currency    string             `json:"currency"`
-	Balance     pgtype.Numeric     `json:"balance"`
-	UserUuid    uuid.UUID          `json:"user_uuid"`
-	CreatedAt   time.Time          `json:"created_at"`
-	AccountUuid uuid.UUID          `json:"account_uuid"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	DeletedAt   pgtype.Timestamptz `json:"deleted_at"`
-	Status      int16              `json:"status"`
+	ID              int64              `json:"id"`
+	Owner           string             `json:"owner"`
+	Currency        string             `json:"currency"`
+	Balance         pgtype.Numeric     `json:"balance"`
+	UserUuid        uuid.UUID          `json:"user_uuid"`
+	CreatedAt       time.Time          `json:"created_at"`
+	AccountUuid     uuid.UUID          `json:"account_uuid"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt       pgtype.Timestamptz `json:"deleted_at"`
+	Status          int16              `json:"status"`
+	ParentAccountID pgtype.Int8        `json:"parent_account_id"`
+	PocketName      pgtype.Text        `json:"pocket_name"`
 }
 
 func (q *Queries) GetAccountByUUID(ctx context.Context, accountUuid uuid.UUID) (GetAccountByUUIDRow, error) {
@@ -199,13 +254,15 @@ func (q *Queries) GetAccountByUUID(ctx context.Context, accountUuid uuid.UUID) (
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.Status,
+		&i.ParentAccountID,
+		&i.PocketName,
 	)
 	return i, err
 }
 
 const getAccountByUserUUID = `-- name: GetAccountByUserUUID :one
 SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status FROM accounts
-WHERE deleted_at IS NULL AND user_uuid = $1 LIMIT 1
+WHERE deleted_at IS NULL AND parent_account_id IS NULL AND user_uuid = $1 LIMIT 1
 `
 
 type GetAccountByUserUUIDRow struct {
@@ -241,7 +298,7 @@ func (q *Queries) GetAccountByUserUUID(ctx context.Context, userUuid uuid.UUID)
 
 const getAccountByUserUUIDAndCurrency = `-- name: GetAccountByUserUUIDAndCurrency :one
 SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status FROM accounts
-WHERE deleted_at IS NULL AND user_uuid = $1 AND currency = $2 LIMIT 1
+WHERE deleted_at IS NULL AND parent_account_id IS NULL AND user_uuid = $1 AND currency = $2 LIMIT 1
 `
 
 type GetAccountByUserUUIDAndCurrencyParams struct {
@@ -282,7 +339,7 @@ func (q *Queries) GetAccountByUserUUIDAndCurrency(ctx context.Context, arg GetAc
 
 const getAccountByUserUUIDMany = `-- name: GetAccountByUserUUIDMany :many
 SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status FROM accounts
-WHERE deleted_at IS NULL AND user_uuid = $1
+WHERE deleted_at IS NULL AND parent_account_id IS NULL AND user_uuid = $1
 `
 
 type GetAccountByUserUUIDManyRow struct {
@@ -369,7 +426,7 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (GetAccount
 const listAccounts = `-- name: ListAccounts :many
 SELECT accounts.id, owner, currency, balance, accounts.user_uuid, accounts.created_at, account_uuid, accounts.updated_at, accounts.deleted_at, status, u.email, u.full_name, u.username FROM accounts
 LEFT JOIN users u ON accounts.user_uuid = u.user_uuid
-WHERE accounts.deleted_at IS NULL
+WHERE accounts.deleted_at IS NULL AND accounts.parent_account_id IS NULL
 ORDER BY accounts.id
 LIMIT $1
 OFFSET $2
@@ -432,8 +489,9 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]L
 
 const listAccountsByUserUUID = `-- name: ListAccountsByUserUUID :many
 SELECT accounts.id, owner, currency, balance, accounts.user_uuid, accounts.created_at, account_uuid, accounts.updated_at, accounts.deleted_at, status, u.email, u.full_name, u.username FROM accounts
+JOIN account_members m ON m.account_id = accounts.id AND m.removed_at IS NULL
 LEFT JOIN users u ON accounts.user_uuid = u.user_uuid
-WHERE accounts.deleted_at IS NULL AND accounts.user_uuid = $1
+WHERE accounts.deleted_at IS NULL AND accounts.parent_account_id IS NULL AND m.user_uuid = $1
 ORDER BY accounts.id
 LIMIT $2
 OFFSET $3
@@ -495,6 +553,60 @@ func (q *Queries) ListAccountsByUserUUID(ctx context.Context, arg ListAccountsBy
 	return items, nil
 }
 
+const listPocketsByParentID = `-- name: ListPocketsByParentID :many
+SELECT id, owner, currency, balance, user_uuid, created_at, account_uuid, updated_at, deleted_at, status, parent_account_id, pocket_name FROM accounts
+WHERE deleted_at IS NULL AND parent_account_id = $1::bigint
+ORDER BY id
+`
+
+type ListPocketsByParentIDRow struct {
+	ID              int64              `json:"id"`
+	Owner           string             `json:"owner"`
+	Currency        string             `json:"currency"`
+	Balance         pgtype.Numeric     `json:"balance"`
+	UserUuid        uuid.UUID          `json:"user_uuid"`
+	CreatedAt       time.Time          `json:"created_at"`
+	AccountUuid     uuid.UUID          `json:"account_uuid"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt       pgtype.Timestamptz `json:"deleted_at"`
+	Status          int16              `json:"status"`
+	ParentAccountID pgtype.Int8        `json:"parent_account_id"`
+	PocketName      pgtype.Text        `json:"pocket_name"`
+}
+
+func (q *Queries) ListPocketsByParentID(ctx context.Context, parentAccountID int64) ([]ListPocketsByParentIDRow, error) {
+	rows, err := q.db.Query(ctx, listPocketsByParentID, parentAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPocketsByParentIDRow{}
+	for rows.Next() {
+		var i ListPocketsByParentIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.Balance,
+			&i.UserUuid,
+			&i.CreatedAt,
+			&i.AccountUuid,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Status,
+			&i.ParentAccountID,
+			&i.PocketName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const softDeleteAccount = `-- name: SoftDeleteAccount :exec
 UPDATE accounts
 SET deleted_at = now()
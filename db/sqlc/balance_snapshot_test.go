@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDailyBalanceSnapshots(t *testing.T) {
+	account := generateAccount(t)
+	snapshotAt := time.Now().UTC().Truncate(time.Second)
+
+	rows, err := testStore.CreateDailyBalanceSnapshots(context.Background(), snapshotAt)
+	require.NoError(t, err)
+	require.NotZero(t, rows)
+
+	snapshot, err := testStore.GetLatestBalanceSnapshot(context.Background(), GetLatestBalanceSnapshotParams{
+		AccountID:  account.ID,
+		SnapshotAt: snapshotAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, snapshot.AccountID)
+	require.Equal(t, helper.NumericToString(account.Balance), helper.NumericToString(snapshot.Balance))
+	require.WithinDuration(t, snapshotAt, snapshot.SnapshotAt, time.Second)
+}
+
+func TestGetBalanceAsOf(t *testing.T) {
+	account1 := generateAccount(t)
+	account2 := generateAccount(t)
+
+	beforeTransfer := time.Now()
+	// make sure the transfer is booked strictly after beforeTransfer
+	time.Sleep(10 * time.Millisecond)
+
+	amount := int64(10)
+	_, err := testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Type:          "transfer",
+	})
+	require.NoError(t, err)
+
+	// without snapshot the balance is walked back from the current balance
+	result, err := testStore.GetBalanceAsOf(context.Background(), account1.ID, beforeTransfer)
+	require.NoError(t, err)
+	require.Nil(t, result.SnapshotAt)
+	require.Equal(t, helper.NumericToString(account1.Balance), helper.NumericToString(result.Balance))
+
+	// before the account existed the balance is zero
+	result, err = testStore.GetBalanceAsOf(context.Background(), account1.ID, account1.CreatedAt.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, "0", helper.NumericToString(result.Balance))
+
+	// with a snapshot taken before the transfer, the transfer is applied on top of it
+	_, err = testStore.CreateDailyBalanceSnapshots(context.Background(), beforeTransfer)
+	require.NoError(t, err)
+
+	result, err = testStore.GetBalanceAsOf(context.Background(), account2.ID, time.Now())
+	require.NoError(t, err)
+	require.NotNil(t, result.SnapshotAt)
+
+	expected := helper.NumericToBigInt(account2.Balance).Int64() + amount
+	require.Equal(t, expected, helper.NumericToBigInt(result.Balance).Int64())
+}
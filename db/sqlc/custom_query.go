@@ -2,11 +2,16 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
-	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/event"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -15,14 +20,27 @@ import (
 // and commits the transaction if the function returns nil.
 // If the function returns an error, it rolls back the transaction and returns the error.
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	// tx, err := store.db.BeginTx(ctx, nil)
+	return store.beginTx(ctx, func(tx pgx.Tx) error {
+		return fn(New(tx))
+	})
+}
+
+// ExecTx executes fn within a transaction, with a store whose queries and transactions are part of
+// it, so changes made through several calls are committed or rolled back together. Transactions of
+// the store passed to fn are savepoints of the transaction.
+func (store *SQLStore) ExecTx(ctx context.Context, fn func(store Store) error) error {
+	return store.beginTx(ctx, func(tx pgx.Tx) error {
+		return fn(&SQLStore{conPool: tx, Queries: New(tx)})
+	})
+}
+
+func (store *SQLStore) beginTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
 	tx, err := store.conPool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 
-	q := New(tx)
-	err = fn(q)
+	err = fn(tx)
 
 	if err != nil {
 		if rbErr := tx.Rollback(ctx); rbErr != nil {
@@ -44,50 +62,620 @@ func (store *SQLStore) TransferTx(ctx context.Context, param TransferTxParam) (T
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
-		arg := CreateTransactionParams{
-			FromAccountID: param.FromAccountID,
-			ToAccountID:   param.ToAccountID,
-			Amount:        pgtype.Numeric{Int: big.NewInt(param.Amount), Exp: 0, Valid: true},
-		}
-		result.Transaction, err = q.CreateTransaction(ctx, arg)
+		result, err = transfer(ctx, q, param)
+		return err
+	})
 
-		if err != nil {
-			return err
-		}
+	return result, err
+}
+
+// ErrBalanceNotEnough is returned when a move would leave the source account with a negative balance.
+var ErrBalanceNotEnough = errors.New("balance not enough")
+
+// PocketMoveTx moves funds between an account and one of its pockets. The move books a debit and a
+// credit entry so balance history stays complete, but it is not a transfer: no transaction record
+// or TransferCompleted event is written, so risk rules and transfer history do not see it.
+// The move is rolled back with ErrBalanceNotEnough when the source account cannot cover it.
+func (store *SQLStore) PocketMoveTx(ctx context.Context, param PocketMoveTxParam) (PocketMoveTxResult, error) {
+	var result PocketMoveTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		amount := pgtype.Numeric{Int: big.NewInt(param.Amount), Exp: 0, Valid: true}
 
 		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
 			AccountID: param.FromAccountID,
-			Amount:    pgtype.Numeric{Int: big.NewInt(param.Amount), Exp: 0, Valid: true},
+			Amount:    amount,
 			TypeTrans: "debit",
 		})
-
 		if err != nil {
 			return err
 		}
 
 		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
 			AccountID: param.ToAccountID,
-			Amount:    pgtype.Numeric{Int: big.NewInt(param.Amount), Exp: 0, Valid: true},
+			Amount:    amount,
 			TypeTrans: "credit",
 		})
 		if err != nil {
 			return err
 		}
 
-		// TODO update account balance
 		result.FromAccount, result.ToAccount, err = addBalance(ctx, q, param.FromAccountID, param.Amount, param.ToAccountID, param.Amount)
 		if err != nil {
 			return err
 		}
 
+		if numericToBigInt(result.FromAccount.Balance).Sign() < 0 {
+			return ErrBalanceNotEnough
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// ErrAccountHasPockets is returned when the currency of an account that has pockets would change.
+var ErrAccountHasPockets = errors.New("currency cannot change while the account has pockets")
+
+// UpdateProfileAccountTx updates the profile of an account. The account is locked first, so its
+// currency only changes while it has no pockets, even when a pocket is created at the same time. It
+// returns ErrAccountHasPockets otherwise, as the pockets would keep the previous currency.
+func (store *SQLStore) UpdateProfileAccountTx(ctx context.Context, arg UpdateProfileAccountParams) (UpdateProfileAccountRow, error) {
+	var result UpdateProfileAccountRow
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountByUUID(ctx, arg.AccountUuid)
+		if err != nil {
+			return err
+		}
+
+		locked, err := q.GetAccountForUpdate(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		if locked.Currency != arg.Currency {
+			pockets, err := q.ListPocketsByParentID(ctx, locked.ID)
+			if err != nil {
+				return err
+			}
+			if len(pockets) > 0 {
+				return ErrAccountHasPockets
+			}
+		}
+
+		result, err = q.UpdateProfileAccount(ctx, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// ApproveTransferReviewTx approves a transfer that the risk engine held for review and executes it.
+// The decision is claimed and the transfer is booked in the same transaction, so a decision that
+// was already reviewed returns pgx.ErrNoRows and the transfer is never executed twice. Like
+// ClearComplianceReviewTx the transfer is only booked once the requester is checked to still be a
+// member who may make it, and rolled back with ErrBalanceNotEnough when the account cannot cover it
+// anymore.
+func (store *SQLStore) ApproveTransferReviewTx(ctx context.Context, param ApproveTransferReviewTxParam) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		decision, err := q.ReviewRiskDecision(ctx, ReviewRiskDecisionParams{
+			ReviewStatus: pgtype.Text{String: "approved", Valid: true},
+			ReviewedBy:   pgtype.UUID{Bytes: param.Reviewer, Valid: true},
+			DecisionUuid: param.DecisionUUID,
+		})
+		if err != nil {
+			return err
+		}
+
+		member, err := q.GetAccountMember(ctx, GetAccountMemberParams{
+			AccountID: decision.FromAccountID,
+			UserUuid:  decision.UserUuid,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotAccountMember
+			}
+			return err
+		}
+		if param.CheckMember != nil {
+			if err := param.CheckMember(member); err != nil {
+				return err
+			}
+		}
+
+		result, err = transfer(ctx, q, TransferTxParam{
+			FromAccountID:  decision.FromAccountID,
+			ToAccountID:    decision.ToAccountID,
+			Amount:         numericToBigInt(decision.Amount).Int64(),
+			Type:           "transfer",
+			RiskDecisionID: decision.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if numericToBigInt(result.FromAccount.Balance).Sign() < 0 {
+			return ErrBalanceNotEnough
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// ErrNotAccountMember is returned when the user who requested a held transfer is no longer a member
+// of the account.
+var ErrNotAccountMember = errors.New("requester is no longer a member of the account")
+
+// ClearComplianceReviewTx clears a compliance review whose screening match was a false positive.
+// For a held transfer the risk decision is stored in the same transaction as the review is claimed,
+// so a review that was already decided returns pgx.ErrNoRows and leaves no decision behind. An
+// allowed transfer is booked once the requester is checked to still be a member who may make it,
+// and rolled back with ErrBalanceNotEnough when the account cannot cover it anymore. Other
+// transfers wait for the review of their risk decision or stay denied.
+func (store *SQLStore) ClearComplianceReviewTx(ctx context.Context, param ClearComplianceReviewTxParam) (ClearComplianceReviewTxResult, error) {
+	var result ClearComplianceReviewTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Review, err = q.ReviewComplianceReview(ctx, ReviewComplianceReviewParams{
+			Status:     "cleared",
+			ReviewedBy: pgtype.UUID{Bytes: param.Reviewer, Valid: true},
+			ReviewUuid: param.ReviewUUID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if result.Review.Kind != "transfer" {
+			return nil
+		}
+
+		result.RiskDecision, err = q.CreateRiskDecision(ctx, param.RiskDecision)
+		if err != nil {
+			return err
+		}
+		if result.RiskDecision.Decision != "allow" {
+			return nil
+		}
+
+		member, err := q.GetAccountMember(ctx, GetAccountMemberParams{
+			AccountID: result.Review.FromAccountID.Int64,
+			UserUuid:  result.Review.UserUuid,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotAccountMember
+			}
+			return err
+		}
+		if param.CheckMember != nil {
+			if err := param.CheckMember(member); err != nil {
+				return err
+			}
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxParam{
+			FromAccountID:  result.Review.FromAccountID.Int64,
+			ToAccountID:    result.Review.ToAccountID.Int64,
+			Amount:         numericToBigInt(result.Review.Amount).Int64(),
+			Type:           "transfer",
+			RiskDecisionID: result.RiskDecision.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if numericToBigInt(result.Transfer.FromAccount.Balance).Sign() < 0 {
+			return ErrBalanceNotEnough
+		}
+
+		_, err = q.SetComplianceReviewTransaction(ctx, SetComplianceReviewTransactionParams{
+			ID:            result.Review.ID,
+			TransactionID: pgtype.Int8{Int64: result.Transfer.Transaction.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// BlockUserTx blocks a user and every session the user has, so no refresh token of the user can
+// be used again.
+func (store *SQLStore) BlockUserTx(ctx context.Context, userUUID uuid.UUID) (UpdateUserBlockedRow, error) {
+	var result UpdateUserBlockedRow
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = q.UpdateUserBlocked(ctx, UpdateUserBlockedParams{
+			UserUuid:  userUUID,
+			IsBlocked: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.BlockUserSessions(ctx, userUUID)
+		return err
+	})
+
+	return result, err
+}
+
+// RequirePasswordResetTx makes a user reset the password before signing in again and blocks every
+// session the user has.
+func (store *SQLStore) RequirePasswordResetTx(ctx context.Context, userUUID uuid.UUID) (RequireUserPasswordResetRow, error) {
+	var result RequireUserPasswordResetRow
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = q.RequireUserPasswordReset(ctx, userUUID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.BlockUserSessions(ctx, userUUID)
+		return err
+	})
+
+	return result, err
+}
+
+// EnableTOTPTx confirms the pending TOTP secret of a user with the step of the first valid code
+// and replaces the recovery codes of the user. It returns pgx.ErrNoRows when there is no pending secret.
+func (store *SQLStore) EnableTOTPTx(ctx context.Context, param EnableTOTPTxParam) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		confirmed, err := q.ConfirmUserTOTP(ctx, ConfirmUserTOTPParams{
+			UserUuid:     param.UserUUID,
+			LastUsedStep: param.Step,
+		})
+		if err != nil {
+			return err
+		}
+		if confirmed == 0 {
+			return pgx.ErrNoRows
+		}
+
+		return replaceRecoveryCodes(ctx, q, param.UserUUID, param.CodeHashes)
+	})
+}
+
+// DisableTOTPTx turns two-factor authentication off for a user and revokes the recovery codes.
+// It returns pgx.ErrNoRows when two-factor authentication was not enabled.
+func (store *SQLStore) DisableTOTPTx(ctx context.Context, userUUID uuid.UUID) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		disabled, err := q.DisableUserTOTP(ctx, userUUID)
+		if err != nil {
+			return err
+		}
+		if disabled == 0 {
+			return pgx.ErrNoRows
+		}
+
+		_, err = q.RevokeRecoveryCodes(ctx, userUUID)
+		return err
+	})
+}
+
+// ReplaceRecoveryCodesTx revokes the unused recovery codes of a user and stores new ones.
+func (store *SQLStore) ReplaceRecoveryCodesTx(ctx context.Context, userUUID uuid.UUID, codeHashes []string) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		return replaceRecoveryCodes(ctx, q, userUUID, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, q *Queries, userUUID uuid.UUID, codeHashes []string) error {
+	_, err := q.RevokeRecoveryCodes(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateRecoveryCodes(ctx, CreateRecoveryCodesParams{
+		UserUuid:   userUUID,
+		CodeHashes: codeHashes,
+	})
+	return err
+}
+
+// CreatePasswordResetTx revokes the pending password reset codes of a user and stores a new one,
+// so only the code of the latest request works.
+func (store *SQLStore) CreatePasswordResetTx(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error) {
+	var result PasswordReset
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.RevokePasswordResets(ctx, arg.UserUuid)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.CreatePasswordReset(ctx, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// ResetPasswordTx uses up a password reset code, sets the new password, keeping the old one in the
+// password history, and blocks every session the user has. It returns pgx.ErrNoRows when the code was used or expired in the meantime.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, param ResetPasswordTxParam) (UpdateUserPasswordRow, error) {
+	var result UpdateUserPasswordRow
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		used, err := q.UsePasswordReset(ctx, param.ResetID)
+		if err != nil {
+			return err
+		}
+		if used == 0 {
+			return pgx.ErrNoRows
+		}
+
+		if _, err := q.ArchiveUserPassword(ctx, param.UserUUID); err != nil {
+			return err
+		}
+
+		result, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			HashedPassword:    pgtype.Text{String: param.HashedPassword, Valid: true},
+			PasswordChangedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+			UserUuid:          param.UserUUID,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.BlockUserSessions(ctx, param.UserUUID)
+		return err
+	})
+
+	return result, err
+}
+
+// UpdateUserTx updates the profile of a user. When the password changes the old one is kept in the
+// password history and every session of the user but the one of the caller is blocked, so refresh
+// tokens issued with the old password stop working.
+func (store *SQLStore) UpdateUserTx(ctx context.Context, param UpdateUserTxParam) (UpdateUserRow, error) {
+	var result UpdateUserRow
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if param.User.HashedPassword.Valid {
+			if _, err := q.ArchiveUserPassword(ctx, param.User.UserUuid); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		result, err = q.UpdateUser(ctx, param.User)
+		if err != nil {
+			return err
+		}
+
+		if param.User.HashedPassword.Valid {
+			_, err = q.BlockOtherUserSessions(ctx, BlockOtherUserSessionsParams{
+				UserUuid: param.User.UserUuid,
+				FamilyID: param.SessionFamilyID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// the user is never renamed without the hold
+		if match := param.SanctionsMatch; match != nil {
+			_, err = q.CreateComplianceReview(ctx, CreateComplianceReviewParams{
+				Kind:          "rename",
+				UserUuid:      result.UserUuid,
+				ScreenedName:  result.FullName,
+				ListReference: match.Reference,
+				ListedName:    match.ListedName,
+				Score:         match.Score,
+			})
+		}
+		return err
+	})
+
+	return result, err
+}
+
+// ErrLastAccountOwner is returned when a change would leave an account without an owner.
+var ErrLastAccountOwner = errors.New("account must keep an owner")
+
+// SetAccountMemberTx adds a member to an account or changes the role and permission of a member.
+// The owners of the account are locked first, so an owner is only demoted while another owner
+// remains, even when other owners are demoted or removed at the same time. It returns
+// ErrLastAccountOwner otherwise.
+func (store *SQLStore) SetAccountMemberTx(ctx context.Context, arg AddAccountMemberParams) (AccountMember, error) {
+	var result AccountMember
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.Role != "owner" {
+			if err := checkOtherOwner(ctx, q, arg.AccountID, arg.UserUuid); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		result, err = q.AddAccountMember(ctx, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// RemoveAccountMemberTx removes a member from an account. Like SetAccountMemberTx it returns
+// ErrLastAccountOwner instead of removing the last owner.
+func (store *SQLStore) RemoveAccountMemberTx(ctx context.Context, arg RemoveAccountMemberParams) (AccountMember, error) {
+	var result AccountMember
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if err := checkOtherOwner(ctx, q, arg.AccountID, arg.UserUuid); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = q.RemoveAccountMember(ctx, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// checkOtherOwner locks the owners of the account until the transaction ends and returns
+// ErrLastAccountOwner when userUUID is the only one.
+func checkOtherOwner(ctx context.Context, q *Queries, accountID int64, userUUID uuid.UUID) error {
+	owners, err := q.ListAccountOwnersForUpdate(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if len(owners) == 1 && owners[0] == userUUID {
+		return ErrLastAccountOwner
+	}
+
+	return nil
+}
+
+// RotateTokenSigningKeyTx retires the token signing keys in use and creates the key that takes over.
+// It returns the latest key unchanged when it is recent enough.
+func (store *SQLStore) RotateTokenSigningKeyTx(ctx context.Context, param RotateTokenSigningKeyTxParam) (TokenSigningKey, error) {
+	var result TokenSigningKey
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		latest, err := q.GetLatestTokenSigningKeyForUpdate(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if err == nil && latest.ActivatesAt.After(param.RotateBefore) {
+			result = latest
+			return nil
+		}
+
+		_, err = q.RetireTokenSigningKeys(ctx, pgtype.Timestamptz{Time: param.ExpiresAt, Valid: true})
+		if err != nil {
+			return err
+		}
+
+		result, err = q.CreateTokenSigningKey(ctx, param.Key)
+		return err
+	})
+
+	return result, err
+}
+
+// DispatchOutboxEventsTx publishes up to param.Limit pending outbox events in order and marks them
+// sent. The events are locked while they are published and events locked by another dispatcher are
+// skipped, so several instances never publish the same event. Publishing stops at the first event
+// that fails, which is recorded and retried with the next batch; the publish error is returned
+// once the events published before it are marked sent.
+func (store *SQLStore) DispatchOutboxEventsTx(ctx context.Context, param DispatchOutboxEventsTxParam) error {
+	var publishErr error
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		events, err := q.ListPendingOutboxEventsForUpdate(ctx, param.Limit)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			if err := param.Publish(e); err != nil {
+				publishErr = fmt.Errorf("cannot publish outbox event %s: %w", e.EventUuid, err)
+				_, err = q.MarkOutboxEventFailed(ctx, MarkOutboxEventFailedParams{
+					ID:        e.ID,
+					LastError: pgtype.Text{String: err.Error(), Valid: true},
+				})
+				return err
+			}
+
+			if _, err := q.MarkOutboxEventSent(ctx, e.ID); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	return publishErr
+}
+
+// transfer books a transfer using the given transaction's queries.
+func transfer(ctx context.Context, q *Queries, param TransferTxParam) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	arg := CreateTransactionParams{
+		FromAccountID: param.FromAccountID,
+		ToAccountID:   param.ToAccountID,
+		Amount:        pgtype.Numeric{Int: big.NewInt(param.Amount), Exp: 0, Valid: true},
+	}
+	result.Transaction, err = q.CreateTransaction(ctx, arg)
+
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: param.FromAccountID,
+		Amount:    pgtype.Numeric{Int: big.NewInt(param.Amount), Exp: 0, Valid: true},
+		TypeTrans: "debit",
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: param.ToAccountID,
+		Amount:    pgtype.Numeric{Int: big.NewInt(param.Amount), Exp: 0, Valid: true},
+		TypeTrans: "credit",
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// TODO update account balance
+	result.FromAccount, result.ToAccount, err = addBalance(ctx, q, param.FromAccountID, param.Amount, param.ToAccountID, param.Amount)
+	if err != nil {
+		return result, err
+	}
+
+	if param.RiskDecisionID != 0 {
+		_, err = q.SetRiskDecisionTransaction(ctx, SetRiskDecisionTransactionParams{
+			ID:            param.RiskDecisionID,
+			TransactionID: pgtype.Int8{Int64: result.Transaction.ID, Valid: true},
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// every member of both accounts is notified, not only the owners they were opened for
+	members, err := accountMemberUUIDs(ctx, q, param.FromAccountID, param.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	err = writeOutboxEvent(ctx, q, event.TransferCompleted{
+		TransactionUUID: result.Transaction.TransactionUuid,
+		FromAccountUUID: result.FromAccount.AccountUuid,
+		ToAccountUUID:   result.ToAccount.AccountUuid,
+		Amount:          numericToBigInt(result.Transaction.Amount).String(),
+		Currency:        result.FromAccount.Currency,
+	}, members...)
 
 	return result, err
 }
 
-func (store *SQLStore) CreateUserWithAccountTx(ctx context.Context, arg request.CreateUserRequest) (CreateUserWithAccountResult, error) {
+// CreateUserWithAccountTx creates a user with its first account. A user matching the sanctions list
+// is held for compliance review in the same transaction.
+func (store *SQLStore) CreateUserWithAccountTx(ctx context.Context, param CreateUserWithAccountTxParam) (CreateUserWithAccountResult, error) {
 	var result CreateUserWithAccountResult
+	arg := param.User
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 		user, err := q.CreateUser(ctx, CreateUserParams{
@@ -112,6 +700,42 @@ func (store *SQLStore) CreateUserWithAccountTx(ctx context.Context, arg request.
 			return err
 		}
 
+		err = addAccountOwner(ctx, q, account)
+		if err != nil {
+			return err
+		}
+
+		err = writeOutboxEvent(ctx, q, event.UserRegistered{
+			UserUUID: user.UserUuid,
+			Username: user.Username,
+			FullName: user.FullName,
+			Email:    user.Email,
+		}, user.UserUuid)
+		if err != nil {
+			return err
+		}
+
+		err = writeOutboxEvent(ctx, q, accountCreatedEvent(account), user.UserUuid)
+		if err != nil {
+			return err
+		}
+
+		// the user is never created without the hold
+		if match := param.SanctionsMatch; match != nil {
+			review, err := q.CreateComplianceReview(ctx, CreateComplianceReviewParams{
+				Kind:          "signup",
+				UserUuid:      user.UserUuid,
+				ScreenedName:  user.FullName,
+				ListReference: match.Reference,
+				ListedName:    match.ListedName,
+				Score:         match.Score,
+			})
+			if err != nil {
+				return err
+			}
+			result.ComplianceReview = &review
+		}
+
 		result.Account = response.AccountResponseSimple{
 			AccountUUID: account.AccountUuid,
 			Owner:       account.Owner,
@@ -132,6 +756,92 @@ func (store *SQLStore) CreateUserWithAccountTx(ctx context.Context, arg request.
 	return result, err
 }
 
+// CreateAccountTx creates an account owned by arg.UserUuid and records an AccountCreated event
+// in the outbox within the same transaction.
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (CreateAccountRow, error) {
+	var account CreateAccountRow
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		err = addAccountOwner(ctx, q, account)
+		if err != nil {
+			return err
+		}
+
+		return writeOutboxEvent(ctx, q, accountCreatedEvent(account), account.UserUuid)
+	})
+
+	return account, err
+}
+
+// addAccountOwner makes the user an account was opened for its first owner.
+func addAccountOwner(ctx context.Context, q *Queries, account CreateAccountRow) error {
+	_, err := q.AddAccountMember(ctx, AddAccountMemberParams{
+		AccountID:  account.ID,
+		UserUuid:   account.UserUuid,
+		Role:       "owner",
+		Permission: "transfer",
+	})
+	return err
+}
+
+// GetBalanceAsOf computes the balance of an account at the given point in time.
+// It starts from the latest daily balance snapshot taken at or before asOf and applies
+// the entries booked between the snapshot and asOf. When no snapshot exists yet, it walks
+// back from the current balance by reverting every entry booked after asOf.
+// An account that did not exist yet at asOf has a zero balance.
+func (store *SQLStore) GetBalanceAsOf(ctx context.Context, accountID int64, asOf time.Time) (BalanceAsOfResult, error) {
+	result := BalanceAsOfResult{
+		AccountID: accountID,
+		AsOf:      asOf,
+	}
+
+	snapshot, err := store.GetLatestBalanceSnapshot(ctx, GetLatestBalanceSnapshotParams{
+		AccountID:  accountID,
+		SnapshotAt: asOf,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+
+	if err == nil {
+		netAmount, err := store.SumEntriesBetween(ctx, SumEntriesBetweenParams{
+			AccountID: accountID,
+			FromTime:  snapshot.SnapshotAt,
+			ToTime:    asOf,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		balance := new(big.Int).Add(numericToBigInt(snapshot.Balance), numericToBigInt(netAmount))
+		result.Balance = pgtype.Numeric{Int: balance, Exp: 0, Valid: true}
+		result.SnapshotAt = &snapshot.SnapshotAt
+		return result, nil
+	}
+
+	// no snapshot yet, walk back from the current balance
+	account, err := store.GetAccountBalanceBefore(ctx, GetAccountBalanceBeforeParams{
+		AsOf: asOf,
+		ID:   accountID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if asOf.Before(account.CreatedAt) {
+		result.Balance = pgtype.Numeric{Int: big.NewInt(0), Exp: 0, Valid: true}
+		return result, nil
+	}
+
+	result.Balance = pgtype.Numeric{Int: numericToBigInt(account.Balance), Exp: 0, Valid: true}
+	return result, nil
+}
+
 // addBalance subtracts the specified amount from the account with accountID1 and adds the specified amount to the account with accountID2.
 // If accountID1 is less than accountID2, the subtraction operation is performed first, followed by the addition operation.
 // If accountID1 is greater than or equal to accountID2, the addition operation is performed first, followed by the subtraction operation.
@@ -179,3 +889,83 @@ func addBalance(ctx context.Context, q *Queries, accountID1, amount1, accountID2
 		return fromAccount, toAccount, nil
 	}
 }
+
+// writeOutboxEvent stores the event in the outbox using the transaction's queries,
+// so the event is only published if the surrounding transaction commits.
+// It also queues a webhook delivery for every subscription of the given users, the members of the
+// accounts the event is about (and of users whose role has webhooks:manage:any), that listens to
+// the event type.
+func writeOutboxEvent(ctx context.Context, q *Queries, e event.Event, users ...uuid.UUID) error {
+	envelope, err := event.NewEnvelope(e, time.Now())
+	if err != nil {
+		return err
+	}
+
+	outbox, err := q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventUuid:     envelope.ID,
+		EventType:     string(envelope.Type),
+		AggregateType: envelope.AggregateType,
+		AggregateID:   envelope.AggregateID,
+		Payload:       envelope.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	envelope.OccurredAt = outbox.CreatedAt
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateWebhookDeliveries(ctx, CreateWebhookDeliveriesParams{
+		EventUuid:  envelope.ID,
+		EventType:  string(envelope.Type),
+		Payload:    body,
+		OwnerUuids: users,
+	})
+
+	return err
+}
+
+// accountMemberUUIDs returns the users who are members of any of the accounts, owners and
+// signatories alike.
+func accountMemberUUIDs(ctx context.Context, q *Queries, accountIDs ...int64) ([]uuid.UUID, error) {
+	var users []uuid.UUID
+	for _, accountID := range accountIDs {
+		members, err := q.ListAccountMembers(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			users = append(users, member.UserUuid)
+		}
+	}
+
+	return users, nil
+}
+
+func accountCreatedEvent(account CreateAccountRow) event.AccountCreated {
+	return event.AccountCreated{
+		AccountUUID: account.AccountUuid,
+		UserUUID:    account.UserUuid,
+		Owner:       account.Owner,
+		Currency:    account.Currency,
+	}
+}
+
+// numericToBigInt converts a NUMERIC(20,0) value to a big.Int, applying its exponent.
+func numericToBigInt(n pgtype.Numeric) *big.Int {
+	if n.Int == nil {
+		return big.NewInt(0)
+	}
+
+	result := new(big.Int).Set(n.Int)
+	if n.Exp > 0 {
+		result.Mul(result, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.Exp)), nil))
+	} else if n.Exp < 0 {
+		result.Quo(result, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-n.Exp)), nil))
+	}
+
+	return result
+}
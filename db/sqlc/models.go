@@ -12,16 +12,89 @@ import (
 )
 
 type Account struct {
-	ID          int64              `json:"id"`
-	UserUuid    uuid.UUID          `json:"user_uuid"`
-	Owner       string             `json:"owner"`
-	Currency    string             `json:"currency"`
-	Balance     pgtype.Numeric     `json:"balance"`
-	Status      int16              `json:"status"`
-	CreatedAt   time.Time          `json:"created_at"`
-	AccountUuid uuid.UUID          `json:"account_uuid"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	DeletedAt   pgtype.Timestamptz `json:"deleted_at"`
+	ID              int64              `json:"id"`
+	UserUuid        uuid.UUID          `json:"user_uuid"`
+	Owner           string             `json:"owner"`
+	Currency        string             `json:"currency"`
+	Balance         pgtype.Numeric     `json:"balance"`
+	Status          int16              `json:"status"`
+	CreatedAt       time.Time          `json:"created_at"`
+	AccountUuid     uuid.UUID          `json:"account_uuid"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt       pgtype.Timestamptz `json:"deleted_at"`
+	ParentAccountID pgtype.Int8        `json:"parent_account_id"`
+	PocketName      pgtype.Text        `json:"pocket_name"`
+}
+
+type AccountBalanceSnapshot struct {
+	ID         int64          `json:"id"`
+	AccountID  int64          `json:"account_id"`
+	Balance    pgtype.Numeric `json:"balance"`
+	SnapshotAt time.Time      `json:"snapshot_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type AccountMember struct {
+	ID            int64              `json:"id"`
+	AccountID     int64              `json:"account_id"`
+	UserUuid      uuid.UUID          `json:"user_uuid"`
+	Role          string             `json:"role"`
+	Permission    string             `json:"permission"`
+	TransferLimit pgtype.Numeric     `json:"transfer_limit"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	RemovedAt     pgtype.Timestamptz `json:"removed_at"`
+}
+
+type ApiKey struct {
+	ID         int64              `json:"id"`
+	KeyUuid    uuid.UUID          `json:"key_uuid"`
+	UserUuid   uuid.UUID          `json:"user_uuid"`
+	Name       string             `json:"name"`
+	Prefix     string             `json:"prefix"`
+	SecretHash string             `json:"secret_hash"`
+	Scopes     []string           `json:"scopes"`
+	CreatedBy  uuid.UUID          `json:"created_by"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	LastUsedIp string             `json:"last_used_ip"`
+	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID         int64       `json:"id"`
+	AuditUuid  uuid.UUID   `json:"audit_uuid"`
+	ActorUuid  pgtype.UUID `json:"actor_uuid"`
+	ActorRole  string      `json:"actor_role"`
+	Action     string      `json:"action"`
+	EntityType string      `json:"entity_type"`
+	EntityID   string      `json:"entity_id"`
+	Before     []byte      `json:"before"`
+	After      []byte      `json:"after"`
+	ClientIp   string      `json:"client_ip"`
+	UserAgent  string      `json:"user_agent"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type ComplianceReview struct {
+	ID            int64              `json:"id"`
+	ReviewUuid    uuid.UUID          `json:"review_uuid"`
+	Kind          string             `json:"kind"`
+	UserUuid      uuid.UUID          `json:"user_uuid"`
+	ScreenedName  string             `json:"screened_name"`
+	ListReference string             `json:"list_reference"`
+	ListedName    string             `json:"listed_name"`
+	Score         float64            `json:"score"`
+	FromAccountID pgtype.Int8        `json:"from_account_id"`
+	ToAccountID   pgtype.Int8        `json:"to_account_id"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	Currency      pgtype.Text        `json:"currency"`
+	Status        string             `json:"status"`
+	ReviewedBy    pgtype.UUID        `json:"reviewed_by"`
+	ReviewedAt    pgtype.Timestamptz `json:"reviewed_at"`
+	TransactionID pgtype.Int8        `json:"transaction_id"`
+	CreatedAt     time.Time          `json:"created_at"`
 }
 
 type Entry struct {
@@ -36,15 +109,101 @@ type Entry struct {
 	TypeTrans   string             `json:"type_trans"`
 }
 
+type Outbox struct {
+	ID            int64              `json:"id"`
+	EventUuid     uuid.UUID          `json:"event_uuid"`
+	EventType     string             `json:"event_type"`
+	AggregateType string             `json:"aggregate_type"`
+	AggregateID   string             `json:"aggregate_id"`
+	Payload       []byte             `json:"payload"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"last_error"`
+	SentAt        pgtype.Timestamptz `json:"sent_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type PasswordHistory struct {
+	ID             int64     `json:"id"`
+	UserUuid       uuid.UUID `json:"user_uuid"`
+	HashedPassword string    `json:"hashed_password"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type PasswordReset struct {
+	ID        int64              `json:"id"`
+	UserUuid  uuid.UUID          `json:"user_uuid"`
+	CodeHash  string             `json:"code_hash"`
+	Attempts  int32              `json:"attempts"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type Permission struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RiskDecision struct {
+	ID            int64              `json:"id"`
+	DecisionUuid  uuid.UUID          `json:"decision_uuid"`
+	UserUuid      uuid.UUID          `json:"user_uuid"`
+	FromAccountID int64              `json:"from_account_id"`
+	ToAccountID   int64              `json:"to_account_id"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	Currency      string             `json:"currency"`
+	Decision      string             `json:"decision"`
+	Reasons       []string           `json:"reasons"`
+	ReviewStatus  pgtype.Text        `json:"review_status"`
+	ReviewedBy    pgtype.UUID        `json:"reviewed_by"`
+	ReviewedAt    pgtype.Timestamptz `json:"reviewed_at"`
+	TransactionID pgtype.Int8        `json:"transaction_id"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type RolePermission struct {
+	Role       string    `json:"role"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type RoleSetting struct {
+	Role        string    `json:"role"`
+	MfaRequired bool      `json:"mfa_required"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type SecurityEvent struct {
+	ID        int64     `json:"id"`
+	UserUuid  uuid.UUID `json:"user_uuid"`
+	EventType string    `json:"event_type"`
+	ClientIp  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Session struct {
-	ID           uuid.UUID `json:"id"`
-	UserUuid     uuid.UUID `json:"user_uuid"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           uuid.UUID          `json:"id"`
+	UserUuid     uuid.UUID          `json:"user_uuid"`
+	RefreshToken string             `json:"refresh_token"`
+	UserAgent    string             `json:"user_agent"`
+	ClientIp     string             `json:"client_ip"`
+	IsBlocked    bool               `json:"is_blocked"`
+	ExpiresAt    time.Time          `json:"expires_at"`
+	CreatedAt    time.Time          `json:"created_at"`
+	FamilyID     uuid.UUID          `json:"family_id"`
+	RotatedAt    pgtype.Timestamptz `json:"rotated_at"`
+}
+
+type TokenSigningKey struct {
+	Kid         string             `json:"kid"`
+	Algorithm   string             `json:"algorithm"`
+	PrivateKey  []byte             `json:"private_key"`
+	PublicKey   []byte             `json:"public_key"`
+	ActivatesAt time.Time          `json:"activates_at"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt   time.Time          `json:"created_at"`
 }
 
 type Transaction struct {
@@ -73,4 +232,68 @@ type User struct {
 	VerificationEmailCode      pgtype.Text        `json:"verification_email_code"`
 	VerifiedEmailAt            time.Time          `json:"verified_email_at"`
 	VerificationEmailExpiredAt pgtype.Timestamptz `json:"verification_email_expired_at"`
+	IsBlocked                  bool               `json:"is_blocked"`
+	PasswordResetRequired      bool               `json:"password_reset_required"`
+}
+
+type UserDevice struct {
+	ID           int64              `json:"id"`
+	DeviceUuid   uuid.UUID          `json:"device_uuid"`
+	UserUuid     uuid.UUID          `json:"user_uuid"`
+	Fingerprint  string             `json:"fingerprint"`
+	UserAgent    string             `json:"user_agent"`
+	LastClientIp string             `json:"last_client_ip"`
+	TrustedAt    pgtype.Timestamptz `json:"trusted_at"`
+	LastSeenAt   time.Time          `json:"last_seen_at"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+type UserLoginIp struct {
+	UserUuid   uuid.UUID `json:"user_uuid"`
+	ClientIp   string    `json:"client_ip"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type UserRecoveryCode struct {
+	ID        int64              `json:"id"`
+	UserUuid  uuid.UUID          `json:"user_uuid"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type UserTotp struct {
+	UserUuid     uuid.UUID          `json:"user_uuid"`
+	Secret       string             `json:"secret"`
+	ConfirmedAt  pgtype.Timestamptz `json:"confirmed_at"`
+	LastUsedStep int64              `json:"last_used_step"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64              `json:"id"`
+	DeliveryUuid   uuid.UUID          `json:"delivery_uuid"`
+	SubscriptionID int64              `json:"subscription_id"`
+	EventUuid      uuid.UUID          `json:"event_uuid"`
+	EventType      string             `json:"event_type"`
+	Payload        []byte             `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  time.Time          `json:"next_attempt_at"`
+	LastStatusCode pgtype.Int4        `json:"last_status_code"`
+	LastError      pgtype.Text        `json:"last_error"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID               int64              `json:"id"`
+	SubscriptionUuid uuid.UUID          `json:"subscription_uuid"`
+	UserUuid         uuid.UUID          `json:"user_uuid"`
+	Url              string             `json:"url"`
+	EventTypes       []string           `json:"event_types"`
+	Secret           string             `json:"secret"`
+	CreatedAt        time.Time          `json:"created_at"`
+	DeletedAt        pgtype.Timestamptz `json:"deleted_at"`
 }
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func generateOutboxEvent(t *testing.T) Outbox {
+	arg := CreateOutboxEventParams{
+		EventUuid:     uuid.New(),
+		EventType:     "AccountCreated",
+		AggregateType: "account",
+		AggregateID:   uuid.New().String(),
+		Payload:       []byte(`{"owner":"` + util.RandomName() + `"}`),
+	}
+
+	outbox, err := testStore.CreateOutboxEvent(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, outbox.ID)
+	require.Equal(t, arg.EventUuid, outbox.EventUuid)
+	require.Equal(t, arg.EventType, outbox.EventType)
+	require.Equal(t, arg.AggregateID, outbox.AggregateID)
+	require.JSONEq(t, string(arg.Payload), string(outbox.Payload))
+	require.Zero(t, outbox.Attempts)
+	require.False(t, outbox.SentAt.Valid)
+
+	return outbox
+}
+
+func TestCreateOutboxEvent(t *testing.T) {
+	generateOutboxEvent(t)
+}
+
+func TestOutboxEventLifecycle(t *testing.T) {
+	outbox := generateOutboxEvent(t)
+
+	rows, err := testStore.MarkOutboxEventFailed(context.Background(), MarkOutboxEventFailedParams{
+		ID:        outbox.ID,
+		LastError: pgtype.Text{String: "redis unavailable", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	pending, err := testStore.ListPendingOutboxEventsForUpdate(context.Background(), 1000)
+	require.NoError(t, err)
+	require.Contains(t, outboxIDs(pending), outbox.ID)
+
+	rows, err = testStore.MarkOutboxEventSent(context.Background(), outbox.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// marking an already sent event is a no-op
+	rows, err = testStore.MarkOutboxEventSent(context.Background(), outbox.ID)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	pending, err = testStore.ListPendingOutboxEventsForUpdate(context.Background(), 1000)
+	require.NoError(t, err)
+	require.NotContains(t, outboxIDs(pending), outbox.ID)
+}
+
+func TestDispatchOutboxEventsTx(t *testing.T) {
+	events := []Outbox{generateOutboxEvent(t), generateOutboxEvent(t), generateOutboxEvent(t)}
+
+	// concurrent dispatchers publish every event once
+	var mu sync.Mutex
+	published := map[int64]int{}
+	n := 3
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			errs <- testStore.DispatchOutboxEventsTx(context.Background(), DispatchOutboxEventsTxParam{
+				Limit: 1000,
+				Publish: func(event Outbox) error {
+					mu.Lock()
+					defer mu.Unlock()
+					published[event.ID]++
+					return nil
+				},
+			})
+		}()
+	}
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	for _, e := range events {
+		require.Equal(t, 1, published[e.ID])
+	}
+
+	// a failed event is recorded and stays pending
+	failed := generateOutboxEvent(t)
+	err := testStore.DispatchOutboxEventsTx(context.Background(), DispatchOutboxEventsTxParam{
+		Limit: 1000,
+		Publish: func(event Outbox) error {
+			if event.ID == failed.ID {
+				return errors.New("redis unavailable")
+			}
+			return nil
+		},
+	})
+	require.ErrorContains(t, err, "redis unavailable")
+
+	pending, err := testStore.ListPendingOutboxEventsForUpdate(context.Background(), 1000)
+	require.NoError(t, err)
+	require.Contains(t, outboxIDs(pending), failed.ID)
+}
+
+func outboxIDs(events []Outbox) []int64 {
+	ids := make([]int64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
@@ -6,45 +6,142 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	// xmax is 0 for a row the statement inserted rather than updated
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (AddAccountBalanceRow, error)
+	AddAccountMember(ctx context.Context, arg AddAccountMemberParams) (AccountMember, error)
+	ArchiveUserPassword(ctx context.Context, userUuid uuid.UUID) (int64, error)
+	BlockOtherUserSessions(ctx context.Context, arg BlockOtherUserSessionsParams) ([]uuid.UUID, error)
+	BlockSessionFamily(ctx context.Context, arg BlockSessionFamilyParams) (int64, error)
+	BlockUserSessions(ctx context.Context, userUuid uuid.UUID) (int64, error)
+	ConfirmUserTOTP(ctx context.Context, arg ConfirmUserTOTPParams) (int64, error)
 	CountAccounts(ctx context.Context) (int64, error)
 	CountAccountsByUserUUID(ctx context.Context, userUuid uuid.UUID) (int64, error)
+	CountAuditLogs(ctx context.Context, arg CountAuditLogsParams) (int64, error)
+	CountComplianceReviews(ctx context.Context, status string) (int64, error)
+	CountRiskDecisionsPendingReview(ctx context.Context) (int64, error)
+	CountTransfersBetweenAccounts(ctx context.Context, arg CountTransfersBetweenAccountsParams) (int64, error)
+	CountUserComplianceHolds(ctx context.Context, userUuid uuid.UUID) (int64, error)
+	CountUserDevices(ctx context.Context, userUuid uuid.UUID) (int64, error)
+	CountUsers(ctx context.Context, arg CountUsersParams) (int64, error)
+	CountWebhookDeliveries(ctx context.Context, subscriptionID int64) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (CreateAccountRow, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	CreateComplianceReview(ctx context.Context, arg CreateComplianceReviewParams) (ComplianceReview, error)
+	CreateDailyBalanceSnapshots(ctx context.Context, snapshotAt time.Time) (int64, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	CreatePocket(ctx context.Context, arg CreatePocketParams) (CreatePocketRow, error)
+	CreateRecoveryCodes(ctx context.Context, arg CreateRecoveryCodesParams) (int64, error)
+	CreateRiskDecision(ctx context.Context, arg CreateRiskDecisionParams) (RiskDecision, error)
+	CreateSecurityEvent(ctx context.Context, arg CreateSecurityEventParams) (SecurityEvent, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTokenSigningKey(ctx context.Context, arg CreateTokenSigningKeyParams) (TokenSigningKey, error)
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
+	CreateUserTOTP(ctx context.Context, arg CreateUserTOTPParams) (UserTotp, error)
+	CreateWebhookDeliveries(ctx context.Context, arg CreateWebhookDeliveriesParams) (int64, error)
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	DisableUserTOTP(ctx context.Context, userUuid uuid.UUID) (int64, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (GetAPIKeyByPrefixRow, error)
 	GetAccount(ctx context.Context, id int64) (GetAccountRow, error)
+	GetAccountBalanceBefore(ctx context.Context, arg GetAccountBalanceBeforeParams) (GetAccountBalanceBeforeRow, error)
 	GetAccountByUUID(ctx context.Context, accountUuid uuid.UUID) (GetAccountByUUIDRow, error)
 	GetAccountByUserUUID(ctx context.Context, userUuid uuid.UUID) (GetAccountByUserUUIDRow, error)
 	GetAccountByUserUUIDAndCurrency(ctx context.Context, arg GetAccountByUserUUIDAndCurrencyParams) (GetAccountByUserUUIDAndCurrencyRow, error)
 	GetAccountByUserUUIDMany(ctx context.Context, userUuid uuid.UUID) ([]GetAccountByUserUUIDManyRow, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (GetAccountForUpdateRow, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	GetAccountTransferVelocity(ctx context.Context, arg GetAccountTransferVelocityParams) (GetAccountTransferVelocityRow, error)
+	GetActivePasswordReset(ctx context.Context, arg GetActivePasswordResetParams) (PasswordReset, error)
+	GetComplianceReviewByUUID(ctx context.Context, reviewUuid uuid.UUID) (ComplianceReview, error)
 	GetDetailLoginByUsername(ctx context.Context, username string) (GetDetailLoginByUsernameRow, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetLatestBalanceSnapshot(ctx context.Context, arg GetLatestBalanceSnapshotParams) (AccountBalanceSnapshot, error)
+	GetLatestTokenSigningKeyForUpdate(ctx context.Context) (TokenSigningKey, error)
+	GetRiskDecisionByUUID(ctx context.Context, decisionUuid uuid.UUID) (RiskDecision, error)
+	GetRoleSetting(ctx context.Context, role string) (RoleSetting, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	GetTransaction(ctx context.Context, id int64) (Transaction, error)
 	GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error)
 	GetUserByUserUUID(ctx context.Context, userUuid uuid.UUID) (GetUserByUserUUIDRow, error)
 	GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error)
 	GetUserByVerificationEmailCode(ctx context.Context, verificationEmailCode pgtype.Text) (GetUserByVerificationEmailCodeRow, error)
+	GetUserDevice(ctx context.Context, arg GetUserDeviceParams) (UserDevice, error)
+	GetUserTOTP(ctx context.Context, userUuid uuid.UUID) (UserTotp, error)
+	GetWebhookDeliveryByUUID(ctx context.Context, deliveryUuid uuid.UUID) (WebhookDelivery, error)
+	GetWebhookSubscriptionByUUID(ctx context.Context, subscriptionUuid uuid.UUID) (WebhookSubscription, error)
+	IncrementPasswordResetAttempts(ctx context.Context, arg IncrementPasswordResetAttemptsParams) (int64, error)
+	ListAPIKeysByUser(ctx context.Context, userUuid uuid.UUID) ([]ApiKey, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]ListAccountMembersRow, error)
+	ListAccountOwnersForUpdate(ctx context.Context, accountID int64) ([]uuid.UUID, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]ListAccountsRow, error)
 	ListAccountsByUserUUID(ctx context.Context, arg ListAccountsByUserUUIDParams) ([]ListAccountsByUserUUIDRow, error)
+	ListActiveSessionsByUser(ctx context.Context, userUuid uuid.UUID) ([]ListActiveSessionsByUserRow, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListComplianceReviews(ctx context.Context, arg ListComplianceReviewsParams) ([]ListComplianceReviewsRow, error)
+	ListDueWebhookDeliveries(ctx context.Context, limit int32) ([]ListDueWebhookDeliveriesRow, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListPendingOutboxEventsForUpdate(ctx context.Context, limit int32) ([]Outbox, error)
+	ListPocketsByParentID(ctx context.Context, parentAccountID int64) ([]ListPocketsByParentIDRow, error)
+	ListRecentPasswordHashes(ctx context.Context, arg ListRecentPasswordHashesParams) ([]string, error)
+	ListRiskDecisionsPendingReview(ctx context.Context, arg ListRiskDecisionsPendingReviewParams) ([]ListRiskDecisionsPendingReviewRow, error)
+	ListRolePermissions(ctx context.Context) ([]ListRolePermissionsRow, error)
+	ListSecurityEventsByUser(ctx context.Context, arg ListSecurityEventsByUserParams) ([]SecurityEvent, error)
+	ListSessionsByUser(ctx context.Context, userUuid uuid.UUID) ([]ListSessionsByUserRow, error)
+	ListTokenSigningKeys(ctx context.Context) ([]TokenSigningKey, error)
 	ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]Transaction, error)
+	ListTrustedUserDevices(ctx context.Context, userUuid uuid.UUID) ([]UserDevice, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptionsByUserUUID(ctx context.Context, userUuid uuid.UUID) ([]WebhookSubscription, error)
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) (int64, error)
+	MarkOutboxEventSent(ctx context.Context, id int64) (int64, error)
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) (int64, error)
+	MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) (int64, error)
+	PurgeExpiredSessions(ctx context.Context, expiredBefore time.Time) (int64, error)
+	RecordUserLoginIP(ctx context.Context, arg RecordUserLoginIPParams) (bool, error)
+	RedeliverWebhookDelivery(ctx context.Context, deliveryUuid uuid.UUID) (WebhookDelivery, error)
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error)
+	RemoveAccountMember(ctx context.Context, arg RemoveAccountMemberParams) (AccountMember, error)
+	RenewVerificationEmailCode(ctx context.Context, arg RenewVerificationEmailCodeParams) (RenewVerificationEmailCodeRow, error)
+	RequireUserPasswordReset(ctx context.Context, userUuid uuid.UUID) (RequireUserPasswordResetRow, error)
+	RetireTokenSigningKeys(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error)
+	ReviewComplianceReview(ctx context.Context, arg ReviewComplianceReviewParams) (ComplianceReview, error)
+	ReviewRiskDecision(ctx context.Context, arg ReviewRiskDecisionParams) (RiskDecision, error)
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (int64, error)
+	RevokePasswordResets(ctx context.Context, userUuid uuid.UUID) (int64, error)
+	RevokeRecoveryCodes(ctx context.Context, userUuid uuid.UUID) (int64, error)
+	RotateSession(ctx context.Context, id uuid.UUID) (int64, error)
+	SetComplianceReviewTransaction(ctx context.Context, arg SetComplianceReviewTransactionParams) (int64, error)
+	SetRiskDecisionTransaction(ctx context.Context, arg SetRiskDecisionTransactionParams) (int64, error)
+	SetRoleMFARequired(ctx context.Context, arg SetRoleMFARequiredParams) (RoleSetting, error)
 	SoftDeleteAccount(ctx context.Context, id int64) error
+	SoftDeleteWebhookSubscription(ctx context.Context, subscriptionUuid uuid.UUID) (int64, error)
 	SubtractAccountBalance(ctx context.Context, arg SubtractAccountBalanceParams) (SubtractAccountBalanceRow, error)
+	SumEntriesBetween(ctx context.Context, arg SumEntriesBetweenParams) (pgtype.Numeric, error)
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) (int64, error)
+	TrustUserDevice(ctx context.Context, arg TrustUserDeviceParams) (UserDevice, error)
+	UntrustUserDevice(ctx context.Context, arg UntrustUserDeviceParams) (int64, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (UpdateAccountRow, error)
 	UpdateProfileAccount(ctx context.Context, arg UpdateProfileAccountParams) (UpdateProfileAccountRow, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error)
+	UpdateUserBlocked(ctx context.Context, arg UpdateUserBlockedParams) (UpdateUserBlockedRow, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (UpdateUserPasswordRow, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (UpdateUserRoleRow, error)
 	UpdateUserVerificationEmail(ctx context.Context, arg UpdateUserVerificationEmailParams) (UpdateUserVerificationEmailRow, error)
+	UpsertUserDevice(ctx context.Context, arg UpsertUserDeviceParams) (UserDevice, error)
+	UsePasswordReset(ctx context.Context, id int64) (int64, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
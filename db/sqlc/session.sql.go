@@ -12,9 +12,80 @@ import (
 	"github.com/google/uuid"
 )
 
+const blockOtherUserSessions = `-- name: BlockOtherUserSessions :many
+UPDATE sessions
+SET is_blocked = true
+WHERE user_uuid = $1
+AND family_id <> $2
+AND is_blocked = false
+RETURNING family_id
+`
+
+type BlockOtherUserSessionsParams struct {
+	UserUuid uuid.UUID `json:"user_uuid"`
+	FamilyID uuid.UUID `json:"family_id"`
+}
+
+func (q *Queries) BlockOtherUserSessions(ctx context.Context, arg BlockOtherUserSessionsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, blockOtherUserSessions, arg.UserUuid, arg.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var family_id uuid.UUID
+		if err := rows.Scan(&family_id); err != nil {
+			return nil, err
+		}
+		items = append(items, family_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const blockSessionFamily = `-- name: BlockSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1
+AND user_uuid = $2
+AND is_blocked = false
+`
+
+type BlockSessionFamilyParams struct {
+	FamilyID uuid.UUID `json:"family_id"`
+	UserUuid uuid.UUID `json:"user_uuid"`
+}
+
+func (q *Queries) BlockSessionFamily(ctx context.Context, arg BlockSessionFamilyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, blockSessionFamily, arg.FamilyID, arg.UserUuid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const blockUserSessions = `-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE user_uuid = $1
+AND is_blocked = false
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, userUuid uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, blockUserSessions, userUuid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (
   id,
+  family_id,
   user_uuid,
   refresh_token,
   user_agent,
@@ -22,12 +93,13 @@ INSERT INTO sessions (
   is_blocked,
   expires_at
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, user_uuid, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, user_uuid, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, rotated_at
 `
 
 type CreateSessionParams struct {
 	ID           uuid.UUID `json:"id"`
+	FamilyID     uuid.UUID `json:"family_id"`
 	UserUuid     uuid.UUID `json:"user_uuid"`
 	RefreshToken string    `json:"refresh_token"`
 	UserAgent    string    `json:"user_agent"`
@@ -39,6 +111,7 @@ type CreateSessionParams struct {
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
 	row := q.db.QueryRow(ctx, createSession,
 		arg.ID,
+		arg.FamilyID,
 		arg.UserUuid,
 		arg.RefreshToken,
 		arg.UserAgent,
@@ -56,12 +129,14 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.RotatedAt,
 	)
 	return i, err
 }
 
 const getSession = `-- name: GetSession :one
-SELECT id, user_uuid, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
+SELECT id, user_uuid, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, rotated_at FROM sessions
 WHERE id = $1 LIMIT 1
 `
 
@@ -77,6 +152,129 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.RotatedAt,
 	)
 	return i, err
 }
+
+const listActiveSessionsByUser = `-- name: ListActiveSessionsByUser :many
+SELECT s.family_id, s.user_agent, s.client_ip, s.expires_at,
+(SELECT MIN(f.created_at) FROM sessions f WHERE f.family_id = s.family_id)::timestamptz AS created_at
+FROM sessions s
+WHERE s.user_uuid = $1
+AND s.is_blocked = false
+AND s.rotated_at IS NULL
+AND s.expires_at > now()
+ORDER BY created_at DESC
+`
+
+type ListActiveSessionsByUserRow struct {
+	FamilyID  uuid.UUID `json:"family_id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListActiveSessionsByUser(ctx context.Context, userUuid uuid.UUID) ([]ListActiveSessionsByUserRow, error) {
+	rows, err := q.db.Query(ctx, listActiveSessionsByUser, userUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListActiveSessionsByUserRow{}
+	for rows.Next() {
+		var i ListActiveSessionsByUserRow
+		if err := rows.Scan(
+			&i.FamilyID,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionsByUser = `-- name: ListSessionsByUser :many
+SELECT id, user_agent, client_ip, is_blocked, expires_at, created_at
+FROM sessions
+WHERE user_uuid = $1
+ORDER BY created_at DESC
+`
+
+type ListSessionsByUserRow struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListSessionsByUser(ctx context.Context, userUuid uuid.UUID) ([]ListSessionsByUserRow, error) {
+	rows, err := q.db.Query(ctx, listSessionsByUser, userUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSessionsByUserRow{}
+	for rows.Next() {
+		var i ListSessionsByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeExpiredSessions = `-- name: PurgeExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at < $1
+AND family_id NOT IN (
+  SELECT family_id FROM sessions
+  WHERE expires_at >= $1
+)
+`
+
+func (q *Queries) PurgeExpiredSessions(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeExpiredSessions, expiredBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rotateSession = `-- name: RotateSession :execrows
+UPDATE sessions
+SET rotated_at = now()
+WHERE id = $1
+AND rotated_at IS NULL
+AND is_blocked = false
+`
+
+func (q *Queries) RotateSession(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, rotateSession, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
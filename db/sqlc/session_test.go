@@ -36,11 +36,12 @@ func generateSession(t *testing.T) Session {
 	require.NoError(t, err)
 
 	// create token
-	refreshToken, refreshPayload, err := tokenMaker.CreateToken(user.UserUuid.String(), duration, user.Role)
+	refreshToken, refreshPayload, err := tokenMaker.CreateToken(user.UserUuid.String(), uuid, token.KindRefresh, duration, user.Role, time.Now())
 	require.NoError(t, err)
 
 	input := CreateSessionParams{
-		ID:           uuid,
+		ID:           refreshPayload.ID,
+		FamilyID:     uuid,
 		UserUuid:     user.UserUuid,
 		RefreshToken: refreshToken,
 		UserAgent:    "user-agent",
@@ -53,6 +54,7 @@ func generateSession(t *testing.T) Session {
 	require.NoError(t, err)
 	require.NotEmpty(t, session)
 	require.Equal(t, input.ID, session.ID, "input and return id should be same")
+	require.Equal(t, input.FamilyID, session.FamilyID, "input and return family_id should be same")
 	require.Equal(t, input.UserUuid, session.UserUuid, "input and return user_uuid should be same")
 	require.Equal(t, input.RefreshToken, session.RefreshToken, "input and return refresh_token should be same")
 	require.Equal(t, input.UserAgent, session.UserAgent, "input and return user_agent should be same")
@@ -60,6 +62,7 @@ func generateSession(t *testing.T) Session {
 	require.Equal(t, input.IsBlocked, session.IsBlocked, "input and return is_blocked should be same")
 	require.WithinDuration(t, input.ExpiresAt, session.ExpiresAt.Local(), time.Second, "input and return expires_at should be same")
 	require.NotNil(t, session.CreatedAt)
+	require.False(t, session.RotatedAt.Valid)
 
 	return session
 }
@@ -80,3 +83,40 @@ func TestGetSession(t *testing.T) {
 	require.Equal(t, session.UserUuid, getFromSession.UserUuid, "create and get should be")
 	require.Equal(t, session.RefreshToken, getFromSession.RefreshToken, "create and get should be")
 }
+
+func TestRotateSession(t *testing.T) {
+	session := generateSession(t)
+
+	rows, err := testStore.RotateSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rotated, err := testStore.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, rotated.RotatedAt.Valid)
+
+	// a session can only be rotated once
+	rows, err = testStore.RotateSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestBlockSessionFamily(t *testing.T) {
+	session := generateSession(t)
+
+	rows, err := testStore.BlockSessionFamily(context.Background(), BlockSessionFamilyParams{
+		FamilyID: session.FamilyID,
+		UserUuid: session.UserUuid,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	blocked, err := testStore.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	// a blocked session cannot be rotated
+	rows, err = testStore.RotateSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
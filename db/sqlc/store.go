@@ -2,30 +2,60 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/fajaramaulana/simple_bank_project/internal/screening"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Store represents the interface for interacting with the database.
 type Store interface {
-	CreateUserWithAccountTx(ctx context.Context, arg request.CreateUserRequest) (CreateUserWithAccountResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (CreateAccountRow, error)
+	CreateUserWithAccountTx(ctx context.Context, param CreateUserWithAccountTxParam) (CreateUserWithAccountResult, error)
 	TransferTx(ctx context.Context, param TransferTxParam) (TransferTxResult, error)
+	PocketMoveTx(ctx context.Context, param PocketMoveTxParam) (PocketMoveTxResult, error)
+	UpdateProfileAccountTx(ctx context.Context, arg UpdateProfileAccountParams) (UpdateProfileAccountRow, error)
+	ApproveTransferReviewTx(ctx context.Context, param ApproveTransferReviewTxParam) (TransferTxResult, error)
+	ClearComplianceReviewTx(ctx context.Context, param ClearComplianceReviewTxParam) (ClearComplianceReviewTxResult, error)
+	GetBalanceAsOf(ctx context.Context, accountID int64, asOf time.Time) (BalanceAsOfResult, error)
+	BlockUserTx(ctx context.Context, userUUID uuid.UUID) (UpdateUserBlockedRow, error)
+	RequirePasswordResetTx(ctx context.Context, userUUID uuid.UUID) (RequireUserPasswordResetRow, error)
+	EnableTOTPTx(ctx context.Context, param EnableTOTPTxParam) error
+	DisableTOTPTx(ctx context.Context, userUUID uuid.UUID) error
+	ReplaceRecoveryCodesTx(ctx context.Context, userUUID uuid.UUID, codeHashes []string) error
+	CreatePasswordResetTx(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	ResetPasswordTx(ctx context.Context, param ResetPasswordTxParam) (UpdateUserPasswordRow, error)
+	UpdateUserTx(ctx context.Context, param UpdateUserTxParam) (UpdateUserRow, error)
+	RotateTokenSigningKeyTx(ctx context.Context, param RotateTokenSigningKeyTxParam) (TokenSigningKey, error)
+	DispatchOutboxEventsTx(ctx context.Context, param DispatchOutboxEventsTxParam) error
+	SetAccountMemberTx(ctx context.Context, arg AddAccountMemberParams) (AccountMember, error)
+	RemoveAccountMemberTx(ctx context.Context, arg RemoveAccountMemberParams) (AccountMember, error)
+	ExecTx(ctx context.Context, fn func(store Store) error) error
 	Querier
 }
 
+// txBeginner starts transactions. A pool starts a transaction, a transaction starts a savepoint.
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 // SQLStore provides all functions to execute SQL queries and transactions.
 type SQLStore struct {
-	conPool *pgxpool.Pool
+	conPool txBeginner
 	*Queries
 }
 
 type TransferTxParam struct {
-	FromAccountID int64  `json:"from_account_id"`
-	ToAccountID   int64  `json:"to_account_id"`
-	Amount        int64  `json:"amount"`
-	Type          string `json:"type"`
+	FromAccountID  int64  `json:"from_account_id"`
+	ToAccountID    int64  `json:"to_account_id"`
+	Amount         int64  `json:"amount"`
+	Type           string `json:"type"`
+	RiskDecisionID int64  `json:"risk_decision_id"`
 }
 
 type TransferTxResult struct {
@@ -36,9 +66,98 @@ type TransferTxResult struct {
 	ToEntry     Entry                     `json:"to_entry"`
 }
 
+type PocketMoveTxParam struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+}
+
+type PocketMoveTxResult struct {
+	FromAccount SubtractAccountBalanceRow `json:"from_account"`
+	ToAccount   AddAccountBalanceRow      `json:"to_account"`
+	FromEntry   Entry                     `json:"from_entry"`
+	ToEntry     Entry                     `json:"to_entry"`
+}
+
+type EnableTOTPTxParam struct {
+	UserUUID   uuid.UUID `json:"user_uuid"`
+	Step       int64     `json:"step"`
+	CodeHashes []string  `json:"code_hashes"`
+}
+
+type ResetPasswordTxParam struct {
+	ResetID        int64     `json:"reset_id"`
+	UserUUID       uuid.UUID `json:"user_uuid"`
+	HashedPassword string    `json:"hashed_password"`
+}
+
+type UpdateUserTxParam struct {
+	User UpdateUserParams `json:"user"`
+	// SessionFamilyID is the session of the caller, which stays open when the password changes.
+	SessionFamilyID uuid.UUID `json:"session_family_id"`
+	// SanctionsMatch holds the user in the compliance review queue when set, because the new full
+	// name matched the sanctions list.
+	SanctionsMatch *screening.Match `json:"sanctions_match"`
+}
+
+type RotateTokenSigningKeyTxParam struct {
+	Key CreateTokenSigningKeyParams `json:"key"`
+	// RotateBefore skips the rotation when the latest key activates after it, because another
+	// instance rotated in the meantime.
+	RotateBefore time.Time `json:"rotate_before"`
+	// ExpiresAt is when tokens signed with the retired keys stop being accepted.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type DispatchOutboxEventsTxParam struct {
+	Limit int32 `json:"limit"`
+	// Publish publishes a pending event. The event is marked sent when it returns nil.
+	Publish func(event Outbox) error `json:"-"`
+}
+
+type ApproveTransferReviewTxParam struct {
+	DecisionUUID uuid.UUID `json:"decision_uuid"`
+	Reviewer     uuid.UUID `json:"reviewer"`
+	// CheckMember checks that the member who requested the held transfer may still make it.
+	CheckMember func(member AccountMember) error `json:"-"`
+}
+
+type ClearComplianceReviewTxParam struct {
+	ReviewUUID uuid.UUID `json:"review_uuid"`
+	Reviewer   uuid.UUID `json:"reviewer"`
+	// RiskDecision is the decision of the risk rules on a held transfer, evaluated once the match is
+	// cleared. The transfer is only booked when the decision allows it.
+	RiskDecision CreateRiskDecisionParams `json:"risk_decision"`
+	// CheckMember checks that the member who requested a held transfer may still make it.
+	CheckMember func(member AccountMember) error `json:"-"`
+}
+
+type ClearComplianceReviewTxResult struct {
+	Review ComplianceReview `json:"review"`
+	// RiskDecision and Transfer are only set for transfers, Transfer only when the decision allowed it.
+	RiskDecision RiskDecision     `json:"risk_decision"`
+	Transfer     TransferTxResult `json:"transfer"`
+}
+
+type BalanceAsOfResult struct {
+	AccountID  int64          `json:"account_id"`
+	Balance    pgtype.Numeric `json:"balance"`
+	AsOf       time.Time      `json:"as_of"`
+	SnapshotAt *time.Time     `json:"snapshot_at"`
+}
+
+type CreateUserWithAccountTxParam struct {
+	User request.CreateUserRequest `json:"user"`
+	// SanctionsMatch holds the user in the compliance review queue when set, because the full name
+	// matched the sanctions list.
+	SanctionsMatch *screening.Match `json:"sanctions_match"`
+}
+
 type CreateUserWithAccountResult struct {
 	User    response.UserGetSimple         `json:"user"`
 	Account response.AccountResponseSimple `json:"account"`
+	// ComplianceReview is the review holding the user, if any.
+	ComplianceReview *ComplianceReview `json:"compliance_review,omitempty"`
 }
 
 // NewStore creates a new instance of the Store interface.
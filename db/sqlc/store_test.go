@@ -2,16 +2,20 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
 	"strconv"
 	"testing"
 
+	"github.com/fajaramaulana/simple_bank_project/internal/event"
 	helpergrpc "github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
 	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
 
@@ -167,7 +171,7 @@ func TestCreateUserWithAccountTx(t *testing.T) {
 		Currency: util.RandomCurrency(),
 	}
 
-	res, err := testStore.CreateUserWithAccountTx(context.Background(), arg)
+	res, err := testStore.CreateUserWithAccountTx(context.Background(), CreateUserWithAccountTxParam{User: arg})
 	require.NoError(t, err)
 	require.NotZero(t, res.User.UserUUID)
 	require.NotZero(t, res.Account.AccountUUID)
@@ -198,7 +202,7 @@ func TestCreateUserWithAccountTxExist(t *testing.T) {
 		Currency: util.RandomCurrency(),
 	}
 
-	_, err := testStore.CreateUserWithAccountTx(context.Background(), arg)
+	_, err := testStore.CreateUserWithAccountTx(context.Background(), CreateUserWithAccountTxParam{User: arg})
 	require.Error(t, err)
 }
 
@@ -268,8 +272,177 @@ func TestTransTxDeadlock(t *testing.T) {
 	require.Equal(t, accountTwoBalance, updatedAccount2Balance)
 }
 
+func TestPocketMoveTx(t *testing.T) {
+	account := generateAccount(t)
+
+	pocket, err := testStore.CreatePocket(context.Background(), CreatePocketParams{
+		PocketName:      util.RandomName(),
+		ParentAccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, pocket.ParentAccountID.Int64)
+	require.Equal(t, account.Currency, pocket.Currency)
+	require.Equal(t, "0", helper.NumericToBigInt(pocket.Balance).String())
+
+	result, err := testStore.PocketMoveTx(context.Background(), PocketMoveTxParam{
+		FromAccountID: account.ID,
+		ToAccountID:   pocket.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "10", helper.NumericToBigInt(result.ToAccount.Balance).String())
+	require.Equal(t, "debit", result.FromEntry.TypeTrans)
+	require.Equal(t, "credit", result.ToEntry.TypeTrans)
+
+	expected := new(big.Int).Sub(helper.NumericToBigInt(account.Balance), big.NewInt(10))
+	require.Equal(t, expected.String(), helper.NumericToBigInt(result.FromAccount.Balance).String())
+
+	// the pocket cannot move more than it holds
+	_, err = testStore.PocketMoveTx(context.Background(), PocketMoveTxParam{
+		FromAccountID: pocket.ID,
+		ToAccountID:   account.ID,
+		Amount:        11,
+	})
+	require.ErrorIs(t, err, ErrBalanceNotEnough)
+
+	pockets, err := testStore.ListPocketsByParentID(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, pockets, 1)
+	require.Equal(t, "10", helper.NumericToBigInt(pockets[0].Balance).String())
+}
+
 func getRandomOption(options []string) string {
 	n := len(options)
 	idx := rand.Intn(n)
 	return options[idx]
 }
+
+func TestAccountMemberTxKeepsOwner(t *testing.T) {
+	account := generateAccount(t)
+	owners := []uuid.UUID{account.UserUuid, GenerateUser(t).UserUuid}
+	for _, owner := range owners {
+		_, err := testStore.AddAccountMember(context.Background(), AddAccountMemberParams{
+			AccountID:  account.ID,
+			UserUuid:   owner,
+			Role:       "owner",
+			Permission: "transfer",
+		})
+		require.NoError(t, err)
+	}
+
+	// both owners are demoted at the same time, only one of them can be
+	errs := make(chan error)
+	for _, owner := range owners {
+		go func(owner uuid.UUID) {
+			_, err := testStore.SetAccountMemberTx(context.Background(), AddAccountMemberParams{
+				AccountID:  account.ID,
+				UserUuid:   owner,
+				Role:       "signatory",
+				Permission: "view",
+			})
+			errs <- err
+		}(owner)
+	}
+
+	var failed int
+	for range owners {
+		if err := <-errs; err != nil {
+			require.ErrorIs(t, err, ErrLastAccountOwner)
+			failed++
+		}
+	}
+	require.Equal(t, 1, failed)
+
+	remaining, err := testStore.ListAccountOwnersForUpdate(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+
+	_, err = testStore.RemoveAccountMemberTx(context.Background(), RemoveAccountMemberParams{AccountID: account.ID, UserUuid: remaining[0]})
+	require.ErrorIs(t, err, ErrLastAccountOwner)
+}
+
+func TestTransferTxNotifiesAccountMembers(t *testing.T) {
+	account1 := generateAccount(t)
+	account2 := generateAccount(t)
+
+	// a signatory of the sending account is notified like its owner
+	signatory := GenerateUser(t)
+	_, err := testStore.AddAccountMember(context.Background(), AddAccountMemberParams{
+		AccountID:  account1.ID,
+		UserUuid:   signatory.UserUuid,
+		Role:       "signatory",
+		Permission: "view",
+	})
+	require.NoError(t, err)
+
+	subscription, err := testStore.CreateWebhookSubscription(context.Background(), CreateWebhookSubscriptionParams{
+		UserUuid:   signatory.UserUuid,
+		Url:        "https://example.com/webhook",
+		EventTypes: []string{string(event.TypeTransferCompleted)},
+		Secret:     util.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		Type:          "transfer",
+	})
+	require.NoError(t, err)
+
+	deliveries, err := testStore.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, string(event.TypeTransferCompleted), deliveries[0].EventType)
+}
+
+func TestExecTxCommitsChangeWithAuditLog(t *testing.T) {
+	account := generateAccount(t)
+	before, err := testStore.ListAccountMembers(context.Background(), account.ID)
+	require.NoError(t, err)
+
+	setMember := func(fail error) error {
+		member := GenerateUser(t)
+		return testStore.ExecTx(context.Background(), func(store Store) error {
+			_, err := store.SetAccountMemberTx(context.Background(), AddAccountMemberParams{
+				AccountID:  account.ID,
+				UserUuid:   member.UserUuid,
+				Role:       "signatory",
+				Permission: "view",
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = store.CreateAuditLog(context.Background(), CreateAuditLogParams{
+				Action:     "account.member.set",
+				EntityType: "account",
+				EntityID:   account.AccountUuid.String(),
+			})
+			if err != nil {
+				return err
+			}
+
+			return fail
+		})
+	}
+
+	// a failure after both writes rolls back the change and its audit entry
+	require.ErrorContains(t, setMember(errors.New("connection lost")), "connection lost")
+	require.NoError(t, setMember(nil))
+
+	members, err := testStore.ListAccountMembers(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, members, len(before)+1)
+
+	logs, err := testStore.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		EntityID:  pgtype.Text{String: account.AccountUuid.String(), Valid: true},
+		PageLimit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+}
@@ -13,6 +13,28 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*)
+FROM users
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND ($1::varchar IS NULL OR username ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%' OR full_name ILIKE '%' || $1 || '%')
+AND ($2::varchar IS NULL OR role = $2)
+AND ($3::boolean IS NULL OR is_blocked = $3)
+`
+
+type CountUsersParams struct {
+	Search    pgtype.Text `json:"search"`
+	Role      pgtype.Text `json:"role"`
+	IsBlocked pgtype.Bool `json:"is_blocked"`
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, arg.Search, arg.Role, arg.IsBlocked)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users ( username, hashed_password, full_name, email ) VALUES ( $1, $2, $3, $4 ) RETURNING user_uuid, username, full_name, email, role
 `
@@ -51,7 +73,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateU
 }
 
 const getDetailLoginByUsername = `-- name: GetDetailLoginByUsername :one
-SELECT hashed_password, user_uuid, role, username, email, full_name
+SELECT hashed_password, user_uuid, role, username, email, full_name, is_blocked, password_reset_required, verified_email_at
 FROM users
 WHERE deleted_at = '0001-01-01 00:00:00+00'
 AND username = $1
@@ -59,12 +81,15 @@ LIMIT 1
 `
 
 type GetDetailLoginByUsernameRow struct {
-	HashedPassword string    `json:"hashed_password"`
-	UserUuid       uuid.UUID `json:"user_uuid"`
-	Role           string    `json:"role"`
-	Username       string    `json:"username"`
-	Email          string    `json:"email"`
-	FullName       string    `json:"full_name"`
+	HashedPassword        string    `json:"hashed_password"`
+	UserUuid              uuid.UUID `json:"user_uuid"`
+	Role                  string    `json:"role"`
+	Username              string    `json:"username"`
+	Email                 string    `json:"email"`
+	FullName              string    `json:"full_name"`
+	IsBlocked             bool      `json:"is_blocked"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+	VerifiedEmailAt       time.Time `json:"verified_email_at"`
 }
 
 func (q *Queries) GetDetailLoginByUsername(ctx context.Context, username string) (GetDetailLoginByUsernameRow, error) {
@@ -77,6 +102,9 @@ func (q *Queries) GetDetailLoginByUsername(ctx context.Context, username string)
 		&i.Username,
 		&i.Email,
 		&i.FullName,
+		&i.IsBlocked,
+		&i.PasswordResetRequired,
+		&i.VerifiedEmailAt,
 	)
 	return i, err
 }
@@ -129,6 +157,9 @@ SELECT  user_uuid
        ,full_name
        ,email
        ,role
+       ,is_blocked
+       ,password_reset_required
+       ,verified_email_at
        ,created_at
        ,updated_at
        ,deleted_at
@@ -139,14 +170,17 @@ LIMIT 1
 `
 
 type GetUserByUserUUIDRow struct {
-	UserUuid  uuid.UUID `json:"user_uuid"`
-	Username  string    `json:"username"`
-	FullName  string    `json:"full_name"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	DeletedAt time.Time `json:"deleted_at"`
+	UserUuid              uuid.UUID `json:"user_uuid"`
+	Username              string    `json:"username"`
+	FullName              string    `json:"full_name"`
+	Email                 string    `json:"email"`
+	Role                  string    `json:"role"`
+	IsBlocked             bool      `json:"is_blocked"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+	VerifiedEmailAt       time.Time `json:"verified_email_at"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+	DeletedAt             time.Time `json:"deleted_at"`
 }
 
 func (q *Queries) GetUserByUserUUID(ctx context.Context, userUuid uuid.UUID) (GetUserByUserUUIDRow, error) {
@@ -158,6 +192,9 @@ func (q *Queries) GetUserByUserUUID(ctx context.Context, userUuid uuid.UUID) (Ge
 		&i.FullName,
 		&i.Email,
 		&i.Role,
+		&i.IsBlocked,
+		&i.PasswordResetRequired,
+		&i.VerifiedEmailAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
@@ -230,10 +267,174 @@ func (q *Queries) GetUserByVerificationEmailCode(ctx context.Context, verificati
 	return i, err
 }
 
+const listUsers = `-- name: ListUsers :many
+SELECT  user_uuid
+       ,username
+       ,full_name
+       ,email
+       ,role
+       ,is_blocked
+       ,password_reset_required
+       ,verified_email_at
+       ,created_at
+FROM users
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND ($1::varchar IS NULL OR username ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%' OR full_name ILIKE '%' || $1 || '%')
+AND ($2::varchar IS NULL OR role = $2)
+AND ($3::boolean IS NULL OR is_blocked = $3)
+ORDER BY id
+LIMIT $4
+OFFSET $5
+`
+
+type ListUsersParams struct {
+	Search     pgtype.Text `json:"search"`
+	Role       pgtype.Text `json:"role"`
+	IsBlocked  pgtype.Bool `json:"is_blocked"`
+	PageLimit  int32       `json:"page_limit"`
+	PageOffset int32       `json:"page_offset"`
+}
+
+type ListUsersRow struct {
+	UserUuid              uuid.UUID `json:"user_uuid"`
+	Username              string    `json:"username"`
+	FullName              string    `json:"full_name"`
+	Email                 string    `json:"email"`
+	Role                  string    `json:"role"`
+	IsBlocked             bool      `json:"is_blocked"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+	VerifiedEmailAt       time.Time `json:"verified_email_at"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Search,
+		arg.Role,
+		arg.IsBlocked,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersRow{}
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.UserUuid,
+			&i.Username,
+			&i.FullName,
+			&i.Email,
+			&i.Role,
+			&i.IsBlocked,
+			&i.PasswordResetRequired,
+			&i.VerifiedEmailAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE users
+SET hashed_password = $1
+WHERE user_uuid = $2
+AND hashed_password = $3
+`
+
+type RehashUserPasswordParams struct {
+	NewHashedPassword string    `json:"new_hashed_password"`
+	UserUuid          uuid.UUID `json:"user_uuid"`
+	OldHashedPassword string    `json:"old_hashed_password"`
+}
+
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rehashUserPassword, arg.NewHashedPassword, arg.UserUuid, arg.OldHashedPassword)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const renewVerificationEmailCode = `-- name: RenewVerificationEmailCode :one
+UPDATE users
+SET verification_email_code = $1, verification_email_expired_at = $2
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND user_uuid = $3
+AND verified_email_at = '0001-01-01 00:00:00+00'
+AND (verification_email_expired_at IS NULL OR verification_email_expired_at <= $4)
+RETURNING user_uuid, email, verification_email_expired_at
+`
+
+type RenewVerificationEmailCodeParams struct {
+	VerificationEmailCode      pgtype.Text        `json:"verification_email_code"`
+	VerificationEmailExpiredAt pgtype.Timestamptz `json:"verification_email_expired_at"`
+	UserUuid                   uuid.UUID          `json:"user_uuid"`
+	IssuedCodeExpiresBefore    pgtype.Timestamptz `json:"issued_code_expires_before"`
+}
+
+type RenewVerificationEmailCodeRow struct {
+	UserUuid                   uuid.UUID          `json:"user_uuid"`
+	Email                      string             `json:"email"`
+	VerificationEmailExpiredAt pgtype.Timestamptz `json:"verification_email_expired_at"`
+}
+
+func (q *Queries) RenewVerificationEmailCode(ctx context.Context, arg RenewVerificationEmailCodeParams) (RenewVerificationEmailCodeRow, error) {
+	row := q.db.QueryRow(ctx, renewVerificationEmailCode,
+		arg.VerificationEmailCode,
+		arg.VerificationEmailExpiredAt,
+		arg.UserUuid,
+		arg.IssuedCodeExpiresBefore,
+	)
+	var i RenewVerificationEmailCodeRow
+	err := row.Scan(&i.UserUuid, &i.Email, &i.VerificationEmailExpiredAt)
+	return i, err
+}
+
+const requireUserPasswordReset = `-- name: RequireUserPasswordReset :one
+UPDATE users
+SET password_reset_required = true, updated_at = now()
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND user_uuid = $1 RETURNING user_uuid, username, full_name, email, role, is_blocked, password_reset_required
+`
+
+type RequireUserPasswordResetRow struct {
+	UserUuid              uuid.UUID `json:"user_uuid"`
+	Username              string    `json:"username"`
+	FullName              string    `json:"full_name"`
+	Email                 string    `json:"email"`
+	Role                  string    `json:"role"`
+	IsBlocked             bool      `json:"is_blocked"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+}
+
+func (q *Queries) RequireUserPasswordReset(ctx context.Context, userUuid uuid.UUID) (RequireUserPasswordResetRow, error) {
+	row := q.db.QueryRow(ctx, requireUserPasswordReset, userUuid)
+	var i RequireUserPasswordResetRow
+	err := row.Scan(
+		&i.UserUuid,
+		&i.Username,
+		&i.FullName,
+		&i.Email,
+		&i.Role,
+		&i.IsBlocked,
+		&i.PasswordResetRequired,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
  UPDATE users
-SET hashed_password = COALESCE($1, hashed_password), password_changed_at = COALESCE($2, password_changed_at), full_name = COALESCE($3, full_name), email = COALESCE($4, email)
-WHERE user_uuid = $5 RETURNING user_uuid, username, full_name, email, role, created_at, updated_at, deleted_at
+SET hashed_password = COALESCE($1, hashed_password), password_changed_at = COALESCE($2, password_changed_at), password_reset_required = password_reset_required AND $1 IS NULL, full_name = COALESCE($3, full_name), email = COALESCE($4, email), verified_email_at = CASE WHEN $4 <> email THEN '0001-01-01 00:00:00Z' ELSE verified_email_at END, verification_email_code = CASE WHEN $4 <> email THEN NULL ELSE verification_email_code END, verification_email_expired_at = CASE WHEN $4 <> email THEN NULL ELSE verification_email_expired_at END
+WHERE user_uuid = $5 RETURNING user_uuid, username, full_name, email, role, password_changed_at, created_at, updated_at, deleted_at
 `
 
 type UpdateUserParams struct {
@@ -245,14 +446,15 @@ type UpdateUserParams struct {
 }
 
 type UpdateUserRow struct {
-	UserUuid  uuid.UUID `json:"user_uuid"`
-	Username  string    `json:"username"`
-	FullName  string    `json:"full_name"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	DeletedAt time.Time `json:"deleted_at"`
+	UserUuid          uuid.UUID `json:"user_uuid"`
+	Username          string    `json:"username"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	DeletedAt         time.Time `json:"deleted_at"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error) {
@@ -270,6 +472,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 		&i.FullName,
 		&i.Email,
 		&i.Role,
+		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
@@ -277,10 +480,47 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 	return i, err
 }
 
+const updateUserBlocked = `-- name: UpdateUserBlocked :one
+UPDATE users
+SET is_blocked = $2, updated_at = now()
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND user_uuid = $1 RETURNING user_uuid, username, full_name, email, role, is_blocked, password_reset_required
+`
+
+type UpdateUserBlockedParams struct {
+	UserUuid  uuid.UUID `json:"user_uuid"`
+	IsBlocked bool      `json:"is_blocked"`
+}
+
+type UpdateUserBlockedRow struct {
+	UserUuid              uuid.UUID `json:"user_uuid"`
+	Username              string    `json:"username"`
+	FullName              string    `json:"full_name"`
+	Email                 string    `json:"email"`
+	Role                  string    `json:"role"`
+	IsBlocked             bool      `json:"is_blocked"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+}
+
+func (q *Queries) UpdateUserBlocked(ctx context.Context, arg UpdateUserBlockedParams) (UpdateUserBlockedRow, error) {
+	row := q.db.QueryRow(ctx, updateUserBlocked, arg.UserUuid, arg.IsBlocked)
+	var i UpdateUserBlockedRow
+	err := row.Scan(
+		&i.UserUuid,
+		&i.Username,
+		&i.FullName,
+		&i.Email,
+		&i.Role,
+		&i.IsBlocked,
+		&i.PasswordResetRequired,
+	)
+	return i, err
+}
+
 const updateUserPassword = `-- name: UpdateUserPassword :one
  UPDATE users
-SET hashed_password = COALESCE($1, hashed_password), password_changed_at = COALESCE($2, password_changed_at)
-WHERE user_uuid = $3 RETURNING user_uuid, username, full_name, email, role, created_at, updated_at, deleted_at
+SET hashed_password = COALESCE($1, hashed_password), password_changed_at = COALESCE($2, password_changed_at), password_reset_required = password_reset_required AND $1 IS NULL
+WHERE user_uuid = $3 RETURNING user_uuid, username, full_name, email, role, password_changed_at, created_at, updated_at, deleted_at
 `
 
 type UpdateUserPasswordParams struct {
@@ -290,14 +530,15 @@ type UpdateUserPasswordParams struct {
 }
 
 type UpdateUserPasswordRow struct {
-	UserUuid  uuid.UUID `json:"user_uuid"`
-	Username  string    `json:"username"`
-	FullName  string    `json:"full_name"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	DeletedAt time.Time `json:"deleted_at"`
+	UserUuid          uuid.UUID `json:"user_uuid"`
+	Username          string    `json:"username"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	DeletedAt         time.Time `json:"deleted_at"`
 }
 
 func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (UpdateUserPasswordRow, error) {
@@ -309,6 +550,7 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 		&i.FullName,
 		&i.Email,
 		&i.Role,
+		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
@@ -316,6 +558,43 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 	return i, err
 }
 
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2, updated_at = now()
+WHERE deleted_at = '0001-01-01 00:00:00+00'
+AND user_uuid = $1 RETURNING user_uuid, username, full_name, email, role, is_blocked, password_reset_required
+`
+
+type UpdateUserRoleParams struct {
+	UserUuid uuid.UUID `json:"user_uuid"`
+	Role     string    `json:"role"`
+}
+
+type UpdateUserRoleRow struct {
+	UserUuid              uuid.UUID `json:"user_uuid"`
+	Username              string    `json:"username"`
+	FullName              string    `json:"full_name"`
+	Email                 string    `json:"email"`
+	Role                  string    `json:"role"`
+	IsBlocked             bool      `json:"is_blocked"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (UpdateUserRoleRow, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.UserUuid, arg.Role)
+	var i UpdateUserRoleRow
+	err := row.Scan(
+		&i.UserUuid,
+		&i.Username,
+		&i.FullName,
+		&i.Email,
+		&i.Role,
+		&i.IsBlocked,
+		&i.PasswordResetRequired,
+	)
+	return i, err
+}
+
 const updateUserVerificationEmail = `-- name: UpdateUserVerificationEmail :one
 UPDATE users
 SET verification_email_code = $1, verification_email_expired_at = $2, verified_email_at = $3
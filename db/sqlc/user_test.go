@@ -88,6 +88,37 @@ func TestUpdateUserFullName(t *testing.T) {
 	require.Equal(t, user.Email, res.Email)
 }
 
+func TestUpdateUserEmailResetsVerification(t *testing.T) {
+	user := GenerateUser(t)
+
+	_, err := testStore.UpdateUserVerificationEmail(context.Background(), UpdateUserVerificationEmailParams{
+		VerificationEmailCode:      pgtype.Text{String: util.RandomString(32), Valid: true},
+		VerificationEmailExpiredAt: pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+		VerifiedEmailAt:            time.Now(),
+		UserUuid:                   user.UserUuid,
+	})
+	require.NoError(t, err)
+
+	// the same address stays verified
+	_, err = testStore.UpdateUser(context.Background(), UpdateUserParams{
+		UserUuid: user.UserUuid,
+		Email:    pgtype.Text{String: user.Email, Valid: true},
+	})
+	require.NoError(t, err)
+	verified, err := testStore.GetUserByUserUUID(context.Background(), user.UserUuid)
+	require.NoError(t, err)
+	require.False(t, verified.VerifiedEmailAt.IsZero())
+
+	_, err = testStore.UpdateUser(context.Background(), UpdateUserParams{
+		UserUuid: user.UserUuid,
+		Email:    pgtype.Text{String: util.RandomEmail(), Valid: true},
+	})
+	require.NoError(t, err)
+	unverified, err := testStore.GetUserByUserUUID(context.Background(), user.UserUuid)
+	require.NoError(t, err)
+	require.True(t, unverified.VerifiedEmailAt.IsZero())
+}
+
 func GenerateUser(t *testing.T) CreateUserRow {
 	password, err := util.MakePasswordBcrypt("P4ssw0rd!")
 	require.NoError(t, err)
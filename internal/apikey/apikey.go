@@ -0,0 +1,196 @@
+// Package apikey implements API keys: long-lived credentials machine clients send instead of a
+// bearer token. A key acts as the user it belongs to, limited to the scopes it was created with.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Scopes a key can hold besides permissions. ScopeRead allows the calls that only read, ScopeWrite
+// the calls that change something. Calls requiring a permission also need it among the scopes.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+const (
+	// keyType starts every key so leaked keys are easy to recognise.
+	keyType = "sbk"
+	// prefixBytes is the length of the public part of a key, which looks the key up.
+	prefixBytes = 8
+	// secretBytes is the length of the secret part of a key. Only its hash is stored.
+	secretBytes = 32
+)
+
+var (
+	// ErrInvalidKey is returned for a key that is malformed, unknown, revoked or expired, or whose
+	// user is blocked. The cases are not told apart.
+	ErrInvalidKey = errors.New("invalid API key")
+	// ErrInvalidScope is returned when creating a key with a scope that is unknown or not held by
+	// the role of its user.
+	ErrInvalidScope = errors.New("invalid API key scope")
+)
+
+// Store is the data API keys are kept in. db.Store satisfies it.
+type Store interface {
+	CreateAPIKey(ctx context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (db.GetAPIKeyByPrefixRow, error)
+	TouchAPIKey(ctx context.Context, arg db.TouchAPIKeyParams) (int64, error)
+}
+
+// CreateParams describes a new key.
+type CreateParams struct {
+	UserUUID  uuid.UUID
+	CreatedBy uuid.UUID
+	Name      string
+	Scopes    []string
+	// ExpiresAt is when the key stops working. The zero time never expires.
+	ExpiresAt time.Time
+}
+
+// ValidateScopes checks that every scope is ScopeRead, ScopeWrite or a permission the role of the
+// user holds, as told by can.
+func ValidateScopes(scopes []string, can func(permission string) bool) error {
+	for _, scope := range scopes {
+		if scope == ScopeRead || scope == ScopeWrite {
+			continue
+		}
+		if !can(scope) {
+			return fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+
+	return nil
+}
+
+// Create stores a new key and returns it together with the key itself, which is shown to the user
+// once and cannot be recovered afterwards. The scopes have to be checked with ValidateScopes first.
+func Create(ctx context.Context, store Store, params CreateParams) (db.ApiKey, string, error) {
+	prefix, err := randomHex(prefixBytes)
+	if err != nil {
+		return db.ApiKey{}, "", err
+	}
+	secret, err := randomHex(secretBytes)
+	if err != nil {
+		return db.ApiKey{}, "", err
+	}
+
+	scopes := slices.Clone(params.Scopes)
+	slices.Sort(scopes)
+
+	arg := db.CreateAPIKeyParams{
+		UserUuid:   params.UserUUID,
+		Name:       params.Name,
+		Prefix:     prefix,
+		SecretHash: hashSecret(secret),
+		Scopes:     slices.Compact(scopes),
+		CreatedBy:  params.CreatedBy,
+	}
+	if !params.ExpiresAt.IsZero() {
+		arg.ExpiresAt = pgtype.Timestamptz{Time: params.ExpiresAt, Valid: true}
+	}
+
+	key, err := store.CreateAPIKey(ctx, arg)
+	if err != nil {
+		return db.ApiKey{}, "", err
+	}
+
+	return key, strings.Join([]string{keyType, prefix, secret}, "_"), nil
+}
+
+// Caller is the user a request was authenticated as with an API key.
+type Caller struct {
+	KeyUUID  uuid.UUID
+	UserUUID uuid.UUID
+	Role     string
+	Scopes   []string
+	// ExpiresAt is when the key expires, the zero time if it never does.
+	ExpiresAt time.Time
+}
+
+// Allows reports whether the key holds scope.
+func (c Caller) Allows(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// Authenticate returns the caller key belongs to and records that the key was used from clientIP.
+func Authenticate(ctx context.Context, store Store, key, clientIP string) (Caller, error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != keyType || parts[1] == "" || parts[2] == "" {
+		return Caller{}, ErrInvalidKey
+	}
+
+	row, err := store.GetAPIKeyByPrefix(ctx, parts[1])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Caller{}, ErrInvalidKey
+		}
+		return Caller{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(parts[2])), []byte(row.SecretHash)) != 1 {
+		return Caller{}, ErrInvalidKey
+	}
+	if row.RevokedAt.Valid || row.IsBlocked {
+		return Caller{}, ErrInvalidKey
+	}
+	if row.ExpiresAt.Valid && !time.Now().Before(row.ExpiresAt.Time) {
+		return Caller{}, ErrInvalidKey
+	}
+
+	// only the last use is kept, losing it does not warrant failing the request
+	if _, err := store.TouchAPIKey(ctx, db.TouchAPIKeyParams{KeyUuid: row.KeyUuid, LastUsedIp: clientIP}); err != nil {
+		log.Printf("Error: cannot record use of API key %s: %s", row.KeyUuid, err.Error())
+	}
+
+	caller := Caller{
+		KeyUUID:  row.KeyUuid,
+		UserUUID: row.UserUuid,
+		Role:     row.Role,
+		Scopes:   row.Scopes,
+	}
+	if row.ExpiresAt.Valid {
+		caller.ExpiresAt = row.ExpiresAt.Time
+	}
+
+	return caller, nil
+}
+
+// MethodScope returns the scope an HTTP request with method needs.
+func MethodScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ScopeRead
+	default:
+		return ScopeWrite
+	}
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,170 @@
+package apikey_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/apikey"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// createKey creates a key through the mock store and returns it with the row it stored.
+func createKey(t *testing.T, store *mockdb.MockStore, params apikey.CreateParams) (string, db.GetAPIKeyByPrefixRow) {
+	var stored db.CreateAPIKeyParams
+	store.EXPECT().
+		CreateAPIKey(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+			stored = arg
+			return db.ApiKey{KeyUuid: uuid.New(), UserUuid: arg.UserUuid, Prefix: arg.Prefix, SecretHash: arg.SecretHash, Scopes: arg.Scopes, ExpiresAt: arg.ExpiresAt}, nil
+		})
+
+	created, key, err := apikey.Create(context.Background(), store, params)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, "sbk_"+stored.Prefix+"_"))
+	require.NotContains(t, stored.SecretHash, strings.Split(key, "_")[2])
+
+	return key, db.GetAPIKeyByPrefixRow{
+		KeyUuid:    created.KeyUuid,
+		UserUuid:   created.UserUuid,
+		Prefix:     created.Prefix,
+		SecretHash: created.SecretHash,
+		Scopes:     created.Scopes,
+		ExpiresAt:  created.ExpiresAt,
+		Role:       "customer",
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	userUUID := uuid.New()
+	key, row := createKey(t, store, apikey.CreateParams{
+		UserUUID:  userUUID,
+		CreatedBy: userUUID,
+		Name:      "ci",
+		Scopes:    []string{apikey.ScopeWrite, apikey.ScopeRead, apikey.ScopeRead},
+	})
+	require.Equal(t, []string{apikey.ScopeRead, apikey.ScopeWrite}, row.Scopes)
+
+	store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), row.Prefix).Times(1).Return(row, nil)
+	store.EXPECT().
+		TouchAPIKey(gomock.Any(), db.TouchAPIKeyParams{KeyUuid: row.KeyUuid, LastUsedIp: "10.0.0.1"}).
+		Times(1).
+		Return(int64(1), nil)
+
+	caller, err := apikey.Authenticate(context.Background(), store, key, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, userUUID, caller.UserUUID)
+	require.Equal(t, "customer", caller.Role)
+	require.True(t, caller.Allows(apikey.ScopeRead))
+	require.False(t, caller.Allows(authz.UsersRead))
+	require.True(t, caller.ExpiresAt.IsZero())
+}
+
+func TestAuthenticateRejects(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mutate func(key string, row *db.GetAPIKeyByPrefixRow) string
+	}{
+		{
+			name: "WrongSecret",
+			mutate: func(key string, row *db.GetAPIKeyByPrefixRow) string {
+				return key[:len(key)-1] + "x"
+			},
+		},
+		{
+			name: "Revoked",
+			mutate: func(key string, row *db.GetAPIKeyByPrefixRow) string {
+				row.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+				return key
+			},
+		},
+		{
+			name: "Expired",
+			mutate: func(key string, row *db.GetAPIKeyByPrefixRow) string {
+				row.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+				return key
+			},
+		},
+		{
+			name: "UserBlocked",
+			mutate: func(key string, row *db.GetAPIKeyByPrefixRow) string {
+				row.IsBlocked = true
+				return key
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			userUUID := uuid.New()
+			key, row := createKey(t, store, apikey.CreateParams{UserUUID: userUUID, CreatedBy: userUUID, Name: "ci", Scopes: []string{apikey.ScopeRead}})
+			key = tc.mutate(key, &row)
+
+			store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), row.Prefix).Times(1).Return(row, nil)
+			store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+
+			_, err := apikey.Authenticate(context.Background(), store, key, "10.0.0.1")
+			require.ErrorIs(t, err, apikey.ErrInvalidKey)
+		})
+	}
+}
+
+func TestAuthenticateUnknownKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), "abcd").Times(1).Return(db.GetAPIKeyByPrefixRow{}, pgx.ErrNoRows)
+
+	_, err := apikey.Authenticate(context.Background(), store, "sbk_abcd_secret", "")
+	require.ErrorIs(t, err, apikey.ErrInvalidKey)
+
+	_, err = apikey.Authenticate(context.Background(), store, "not-a-key", "")
+	require.ErrorIs(t, err, apikey.ErrInvalidKey)
+}
+
+func TestAuthenticateIgnoresTouchFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	userUUID := uuid.New()
+	key, row := createKey(t, store, apikey.CreateParams{UserUUID: userUUID, CreatedBy: userUUID, Name: "ci", Scopes: []string{apikey.ScopeRead}})
+
+	store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), row.Prefix).Times(1).Return(row, nil)
+	store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), errors.New("connection refused"))
+
+	_, err := apikey.Authenticate(context.Background(), store, key, "10.0.0.1")
+	require.NoError(t, err)
+}
+
+func TestValidateScopes(t *testing.T) {
+	authorizer := authz.NewAuthorizer(authz.DefaultRolePermissions, 0)
+	canAdmin := func(permission string) bool {
+		return authorizer.Can(context.Background(), "admin", permission)
+	}
+
+	require.NoError(t, apikey.ValidateScopes([]string{apikey.ScopeRead, authz.UsersRead}, canAdmin))
+	require.ErrorIs(t, apikey.ValidateScopes([]string{authz.UsersManagePrivileged}, canAdmin), apikey.ErrInvalidScope)
+	require.ErrorIs(t, apikey.ValidateScopes([]string{"everything"}, canAdmin), apikey.ErrInvalidScope)
+}
+
+func TestMethodScope(t *testing.T) {
+	require.Equal(t, apikey.ScopeRead, apikey.MethodScope("GET"))
+	require.Equal(t, apikey.ScopeWrite, apikey.MethodScope("POST"))
+	require.Equal(t, apikey.ScopeWrite, apikey.MethodScope("DELETE"))
+}
@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionAccountCreate           = "account.create"
+	ActionAccountUpdate           = "account.update"
+	ActionAccountMemberSet        = "account.member_set"
+	ActionAccountMemberRemove     = "account.member_remove"
+	ActionAccountPocketCreate     = "account.pocket_create"
+	ActionAccountPocketMove       = "account.pocket_move"
+	ActionUserCreate              = "user.create"
+	ActionUserUpdate              = "user.update"
+	ActionUserBlock               = "user.block"
+	ActionUserUnblock             = "user.unblock"
+	ActionUserRoleChange          = "user.role_change"
+	ActionUserPasswordResetForce  = "user.password_reset_force"
+	ActionTransferReviewApprove   = "transfer_review.approve"
+	ActionTransferReviewReject    = "transfer_review.reject"
+	ActionComplianceReviewClear   = "compliance_review.clear"
+	ActionComplianceReviewConfirm = "compliance_review.confirm"
+	ActionWebhookCreate           = "webhook.create"
+	ActionWebhookDelete           = "webhook.delete"
+	ActionSessionRefreshReuse     = "session.refresh_reuse"
+	ActionUserMFAEnable           = "user.mfa_enable"
+	ActionUserMFADisable          = "user.mfa_disable"
+	ActionRoleMFARequirementSet   = "role.mfa_requirement_set"
+	ActionUserPasswordReset       = "user.password_reset"
+	ActionAPIKeyCreate            = "api_key.create"
+	ActionAPIKeyRevoke            = "api_key.revoke"
+)
+
+// Entity types recorded in the audit log.
+const (
+	EntityAccount          = "account"
+	EntityUser             = "user"
+	EntityRiskDecision     = "risk_decision"
+	EntityComplianceReview = "compliance_review"
+	EntityWebhook          = "webhook_subscription"
+	EntitySession          = "session"
+	EntityRole             = "role"
+	EntityAPIKey           = "api_key"
+)
+
+// Actor is who performed an operation and from where.
+type Actor struct {
+	UserUUID  uuid.UUID
+	Role      string
+	ClientIP  string
+	UserAgent string
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying actor. Both the Gin and the gRPC stacks set it once the
+// request is authenticated, so services can record audit entries without passing it around.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// Entry is one state change. Before is nil for creations.
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     any
+	After      any
+}
+
+// Store is the data the audit log writes to. db.Store satisfies it.
+type Store interface {
+	CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error)
+}
+
+// Write appends entry to the audit log on behalf of the actor in ctx. store is the transaction of
+// the change entry records, so the change is not committed without its audit entry.
+func Write(ctx context.Context, store Store, entry Entry) error {
+	arg := db.CreateAuditLogParams{
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+	}
+
+	if actor, ok := ActorFromContext(ctx); ok {
+		arg.ActorUuid = pgtype.UUID{Bytes: actor.UserUUID, Valid: true}
+		arg.ActorRole = actor.Role
+		arg.ClientIp = actor.ClientIP
+		arg.UserAgent = actor.UserAgent
+	}
+
+	var err error
+	if arg.Before, err = marshal(entry.Before); err != nil {
+		return err
+	}
+	if arg.After, err = marshal(entry.After); err != nil {
+		return err
+	}
+
+	_, err = store.CreateAuditLog(ctx, arg)
+	return err
+}
+
+func marshal(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
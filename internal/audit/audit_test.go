@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	arg db.CreateAuditLogParams
+	err error
+}
+
+func (f *fakeStore) CreateAuditLog(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+	f.arg = arg
+	return db.AuditLog{}, f.err
+}
+
+func TestWriteWithActor(t *testing.T) {
+	actor := Actor{UserUUID: uuid.New(), Role: "admin", ClientIP: "10.0.0.1", UserAgent: "test-agent"}
+	ctx := WithActor(context.Background(), actor)
+	store := &fakeStore{}
+
+	err := Write(ctx, store, Entry{
+		Action:     ActionAccountUpdate,
+		EntityType: EntityAccount,
+		EntityID:   "account-1",
+		Before:     map[string]string{"currency": "USD"},
+		After:      map[string]string{"currency": "IDR"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, pgtype.UUID{Bytes: actor.UserUUID, Valid: true}, store.arg.ActorUuid)
+	require.Equal(t, "admin", store.arg.ActorRole)
+	require.Equal(t, "10.0.0.1", store.arg.ClientIp)
+	require.Equal(t, "test-agent", store.arg.UserAgent)
+	require.Equal(t, ActionAccountUpdate, store.arg.Action)
+	require.Equal(t, EntityAccount, store.arg.EntityType)
+	require.Equal(t, "account-1", store.arg.EntityID)
+	require.JSONEq(t, `{"currency":"USD"}`, string(store.arg.Before))
+	require.JSONEq(t, `{"currency":"IDR"}`, string(store.arg.After))
+}
+
+func TestWriteWithoutActor(t *testing.T) {
+	store := &fakeStore{}
+
+	err := Write(context.Background(), store, Entry{
+		Action:     ActionUserCreate,
+		EntityType: EntityUser,
+		EntityID:   "user-1",
+		After:      map[string]string{"username": "jdoe"},
+	})
+	require.NoError(t, err)
+
+	require.False(t, store.arg.ActorUuid.Valid)
+	require.Empty(t, store.arg.ActorRole)
+	require.Nil(t, store.arg.Before)
+	require.JSONEq(t, `{"username":"jdoe"}`, string(store.arg.After))
+}
+
+func TestWriteError(t *testing.T) {
+	store := &fakeStore{err: errors.New("connection refused")}
+
+	err := Write(context.Background(), store, Entry{Action: ActionWebhookDelete})
+	require.Error(t, err)
+
+	err = Write(context.Background(), &fakeStore{}, Entry{After: make(chan int)})
+	require.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package authz
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/rs/zerolog/log"
+)
+
+// Permissions checked by the APIs. Which role holds which permission is stored in the
+// role_permissions table.
+const (
+	AccountsReadAny       = "accounts:read:any"
+	AccountsUpdateAny     = "accounts:update:any"
+	AuditRead             = "audit:read"
+	ComplianceReview      = "compliance:review"
+	TransfersApprove      = "transfers:approve"
+	UsersManage           = "users:manage"
+	UsersManagePrivileged = "users:manage:privileged"
+	UsersRead             = "users:read"
+	UsersUpdateSelf       = "users:update:self"
+	WebhooksManageAny     = "webhooks:manage:any"
+)
+
+// DefaultCacheTTL is how long the role permissions are cached before they are loaded again.
+const DefaultCacheTTL = time.Minute
+
+// Source loads the permissions granted to every role. db.Store is a Source.
+type Source interface {
+	ListRolePermissions(ctx context.Context) ([]db.ListRolePermissionsRow, error)
+}
+
+// StaticSource is a Source with fixed permissions per role.
+type StaticSource map[string][]string
+
+func (s StaticSource) ListRolePermissions(ctx context.Context) ([]db.ListRolePermissionsRow, error) {
+	rows := []db.ListRolePermissionsRow{}
+	for role, permissions := range s {
+		for _, permission := range permissions {
+			rows = append(rows, db.ListRolePermissionsRow{Role: role, Permission: permission})
+		}
+	}
+	return rows, nil
+}
+
+// DefaultRolePermissions are the permissions the migrations grant to the built-in roles.
+var DefaultRolePermissions = StaticSource{
+	"customer": {},
+	"admin": {
+		AccountsReadAny, AccountsUpdateAny, AuditRead, ComplianceReview, TransfersApprove,
+		UsersManage, UsersRead, UsersUpdateSelf, WebhooksManageAny,
+	},
+	"superadmin": {
+		AccountsReadAny, AccountsUpdateAny, AuditRead, ComplianceReview, TransfersApprove,
+		UsersManage, UsersManagePrivileged, UsersRead, UsersUpdateSelf, WebhooksManageAny,
+	},
+}
+
+// Authorizer decides whether a role holds a permission. It is shared by the HTTP and gRPC APIs
+// and caches the role permissions of its source for the configured TTL.
+type Authorizer struct {
+	source Source
+	ttl    time.Duration
+
+	mu       sync.RWMutex
+	grants   map[string]map[string]bool
+	loadedAt time.Time
+}
+
+// NewAuthorizer creates an authorizer reading from source. A ttl of 0 uses DefaultCacheTTL.
+func NewAuthorizer(source Source, ttl time.Duration) *Authorizer {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Authorizer{source: source, ttl: ttl}
+}
+
+// Can reports whether role holds permission. When the permissions cannot be loaded the last
+// loaded ones are used, and every permission is denied if none were ever loaded.
+// When ctx was limited with WithScopes only the permissions among the scopes are granted.
+func (a *Authorizer) Can(ctx context.Context, role, permission string) bool {
+	if scopes, ok := ctx.Value(scopesContextKey{}).([]string); ok && !slices.Contains(scopes, permission) {
+		return false
+	}
+
+	grants := a.load(ctx)
+	return grants[role][permission]
+}
+
+type scopesContextKey struct{}
+
+// WithScopes limits the permissions granted to calls made with ctx to scopes, whatever the role
+// holds. Callers authenticated with an API key only act with the scopes of the key.
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesContextKey{}, scopes)
+}
+
+func (a *Authorizer) load(ctx context.Context) map[string]map[string]bool {
+	a.mu.RLock()
+	grants, fresh := a.grants, time.Since(a.loadedAt) < a.ttl
+	a.mu.RUnlock()
+	if grants != nil && fresh {
+		return grants
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// another caller may have loaded them while this one waited for the lock
+	if a.grants != nil && time.Since(a.loadedAt) < a.ttl {
+		return a.grants
+	}
+
+	rows, err := a.source.ListRolePermissions(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load role permissions")
+		return a.grants
+	}
+
+	grants = make(map[string]map[string]bool)
+	for _, row := range rows {
+		if grants[row.Role] == nil {
+			grants[row.Role] = make(map[string]bool)
+		}
+		grants[row.Role][row.Permission] = true
+	}
+	a.grants = grants
+	a.loadedAt = time.Now()
+
+	return grants
+}
@@ -0,0 +1,105 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuthorizerDefaultRolePermissions(t *testing.T) {
+	authorizer := NewAuthorizer(DefaultRolePermissions, 0)
+	ctx := context.Background()
+
+	require.True(t, authorizer.Can(ctx, "admin", AuditRead))
+	require.False(t, authorizer.Can(ctx, "admin", UsersManagePrivileged))
+	require.True(t, authorizer.Can(ctx, "superadmin", UsersManagePrivileged))
+	require.False(t, authorizer.Can(ctx, "customer", AccountsReadAny))
+	require.False(t, authorizer.Can(ctx, "unknown", AccountsReadAny))
+}
+
+func TestAuthorizerCachesPermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ListRolePermissions(gomock.Any()).
+		Times(1).
+		Return([]db.ListRolePermissionsRow{{Role: "auditor", Permission: AuditRead}}, nil)
+
+	authorizer := NewAuthorizer(store, time.Hour)
+	ctx := context.Background()
+
+	require.True(t, authorizer.Can(ctx, "auditor", AuditRead))
+	require.False(t, authorizer.Can(ctx, "auditor", UsersRead))
+}
+
+func TestAuthorizerReloadsAfterTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			ListRolePermissions(gomock.Any()).
+			Return([]db.ListRolePermissionsRow{{Role: "auditor", Permission: AuditRead}}, nil),
+		store.EXPECT().
+			ListRolePermissions(gomock.Any()).
+			Return([]db.ListRolePermissionsRow{}, nil),
+	)
+
+	authorizer := NewAuthorizer(store, time.Millisecond)
+	ctx := context.Background()
+
+	require.True(t, authorizer.Can(ctx, "auditor", AuditRead))
+	time.Sleep(5 * time.Millisecond)
+	require.False(t, authorizer.Can(ctx, "auditor", AuditRead))
+}
+
+func TestAuthorizerKeepsPermissionsOnLoadError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			ListRolePermissions(gomock.Any()).
+			Return([]db.ListRolePermissionsRow{{Role: "auditor", Permission: AuditRead}}, nil),
+		store.EXPECT().
+			ListRolePermissions(gomock.Any()).
+			Return(nil, errors.New("connection refused")),
+	)
+
+	authorizer := NewAuthorizer(store, time.Millisecond)
+	ctx := context.Background()
+
+	require.True(t, authorizer.Can(ctx, "auditor", AuditRead))
+	time.Sleep(5 * time.Millisecond)
+	require.True(t, authorizer.Can(ctx, "auditor", AuditRead))
+}
+
+func TestAuthorizerDeniesWhenNeverLoaded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ListRolePermissions(gomock.Any()).
+		AnyTimes().
+		Return(nil, errors.New("connection refused"))
+
+	authorizer := NewAuthorizer(store, 0)
+	require.False(t, authorizer.Can(context.Background(), "superadmin", AuditRead))
+}
+
+func TestAuthorizerLimitsToScopes(t *testing.T) {
+	authorizer := NewAuthorizer(DefaultRolePermissions, 0)
+	ctx := WithScopes(context.Background(), []string{UsersRead, AccountsReadAny})
+
+	require.True(t, authorizer.Can(ctx, "admin", UsersRead))
+	require.False(t, authorizer.Can(ctx, "admin", UsersManage))
+	// a scope does not grant what the role does not hold
+	require.False(t, authorizer.Can(ctx, "customer", AccountsReadAny))
+}
@@ -0,0 +1,314 @@
+// Package device keeps the devices users sign in from, identified by a device token the server
+// issues at the first login from the device, and the client IPs they sign in from. A login from a device or an IP never seen before is
+// alerted to the user by email. Optionally a login from a device the user does not trust has to be
+// confirmed with a code emailed to the user before the session starts. Users review their trusted
+// devices and remove the ones they no longer use.
+package device
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/email"
+	"github.com/fajaramaulana/simple_bank_project/internal/securityevent"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	confirmationKeyPrefix = "device_confirmation:"
+
+	// confirmationCodeSize is the length of a confirmation code in random bytes.
+	confirmationCodeSize = 16
+	// deviceTokenSize is the length of a device token in random bytes.
+	deviceTokenSize = 32
+)
+
+var (
+	// ErrConfirmationRequired is returned when a login from a new device has to be confirmed with
+	// the code emailed to the user.
+	ErrConfirmationRequired = errors.New("new device must be confirmed with the code sent by email")
+	// ErrInvalidConfirmationCode is returned when a confirmation code is unknown, used up, expired
+	// or used from another device than the one it was issued for.
+	ErrInvalidConfirmationCode = errors.New("invalid or expired device confirmation code")
+	// ErrDeviceNotFound is returned when removing a device that is not a trusted device of the user.
+	ErrDeviceNotFound = errors.New("device not found")
+)
+
+// Config tunes the registry. Zero values fall back to DefaultConfig, except RequireConfirmation.
+type Config struct {
+	// RequireConfirmation makes a login from a device the user does not trust wait until the device
+	// is confirmed with a code emailed to the user. Without it such devices are trusted right away.
+	RequireConfirmation bool
+	// ConfirmationCodeTTL is how long the emailed confirmation code is valid.
+	ConfirmationCodeTTL time.Duration
+}
+
+// DefaultConfig returns the configuration used unless configured otherwise.
+func DefaultConfig() Config {
+	return Config{
+		ConfirmationCodeTTL: 15 * time.Minute,
+	}
+}
+
+// Store is the data the registry reads and writes. db.Store satisfies it.
+type Store interface {
+	securityevent.Store
+	CountUserDevices(ctx context.Context, userUuid uuid.UUID) (int64, error)
+	GetUserDevice(ctx context.Context, arg db.GetUserDeviceParams) (db.UserDevice, error)
+	ListTrustedUserDevices(ctx context.Context, userUuid uuid.UUID) ([]db.UserDevice, error)
+	RecordUserLoginIP(ctx context.Context, arg db.RecordUserLoginIPParams) (bool, error)
+	TrustUserDevice(ctx context.Context, arg db.TrustUserDeviceParams) (db.UserDevice, error)
+	UntrustUserDevice(ctx context.Context, arg db.UntrustUserDeviceParams) (int64, error)
+	UpsertUserDevice(ctx context.Context, arg db.UpsertUserDeviceParams) (db.UserDevice, error)
+}
+
+// Login is a user who authenticated and is about to get a session.
+type Login struct {
+	UserUUID uuid.UUID
+	Email    string
+	// DeviceToken is the token SignIn issued to the device before, empty when the device has none.
+	DeviceToken string
+	UserAgent   string
+	ClientIP    string
+}
+
+// Registry records the devices of logins and decides whether they may start a session.
+type Registry struct {
+	rdb    *redis.Client
+	store  Store
+	emails email.Queue
+	config Config
+}
+
+// NewRegistry creates a registry that keeps the pending confirmation codes in rdb.
+func NewRegistry(rdb *redis.Client, store Store, emails email.Queue, config Config) *Registry {
+	if config.ConfirmationCodeTTL <= 0 {
+		config.ConfirmationCodeTTL = DefaultConfig().ConfirmationCodeTTL
+	}
+
+	return &Registry{rdb: rdb, store: store, emails: emails, config: config}
+}
+
+// Fingerprint identifies the device holding deviceToken. It is empty for a token SignIn cannot
+// have issued, which identifies no device.
+func Fingerprint(deviceToken string) string {
+	raw, err := hex.DecodeString(deviceToken)
+	if err != nil || len(raw) != deviceTokenSize {
+		return ""
+	}
+
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// SignIn records the device and the client IP of login and returns the device token the device
+// has to send from now on: the one of login, or a new one when the device has none. The device
+// is identified by that token only, never by headers the client chooses freely.
+//
+// A device the user does not trust yet is trusted right away, unless new devices must be
+// confirmed: then the user is emailed a confirmation code and ErrConfirmationRequired is returned
+// along with the device token the code has to be confirmed with. A login from a new device or an
+// IP not seen before is alerted to the user, except the first login of the user.
+func (r *Registry) SignIn(ctx context.Context, login Login) (string, error) {
+	deviceToken := login.DeviceToken
+	fingerprint := Fingerprint(deviceToken)
+	if fingerprint == "" {
+		raw := make([]byte, deviceTokenSize)
+		if _, err := rand.Read(raw); err != nil {
+			return "", err
+		}
+		deviceToken = hex.EncodeToString(raw)
+		fingerprint = Fingerprint(deviceToken)
+	}
+
+	known, err := r.store.GetUserDevice(ctx, db.GetUserDeviceParams{UserUuid: login.UserUUID, Fingerprint: fingerprint})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+	found := err == nil
+	trusted := found && known.TrustedAt.Valid
+
+	if !trusted && r.config.RequireConfirmation {
+		return deviceToken, r.requestConfirmation(ctx, login, fingerprint)
+	}
+
+	// nobody is to be alerted of the device the account was just created from
+	firstDevice := false
+	if !found {
+		devices, err := r.store.CountUserDevices(ctx, login.UserUUID)
+		if err != nil {
+			return "", err
+		}
+		firstDevice = devices == 0
+	}
+
+	now := time.Now()
+	_, err = r.store.UpsertUserDevice(ctx, db.UpsertUserDeviceParams{
+		UserUuid:     login.UserUUID,
+		Fingerprint:  fingerprint,
+		UserAgent:    login.UserAgent,
+		LastClientIp: login.ClientIP,
+		TrustedAt:    pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	newIP, err := r.store.RecordUserLoginIP(ctx, db.RecordUserLoginIPParams{UserUuid: login.UserUUID, ClientIp: login.ClientIP})
+	if err != nil {
+		return "", err
+	}
+
+	if !firstDevice && (!trusted || newIP) {
+		r.alert(ctx, login, now)
+	}
+	return deviceToken, nil
+}
+
+// Confirm trusts the device the emailed code was issued for and returns its user. The code works
+// once, and only from the device holding the device token SignIn returned with it.
+func (r *Registry) Confirm(ctx context.Context, code, deviceToken, userAgent, clientIP string) (uuid.UUID, error) {
+	data, err := r.rdb.GetDel(ctx, confirmationKey(code)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return uuid.Nil, ErrInvalidConfirmationCode
+		}
+		return uuid.Nil, err
+	}
+
+	var confirmation confirmationData
+	if err := json.Unmarshal([]byte(data), &confirmation); err != nil {
+		return uuid.Nil, err
+	}
+	if fingerprint := Fingerprint(deviceToken); fingerprint == "" || confirmation.Fingerprint != fingerprint {
+		return uuid.Nil, ErrInvalidConfirmationCode
+	}
+
+	_, err = r.store.TrustUserDevice(ctx, db.TrustUserDeviceParams{DeviceUuid: confirmation.DeviceUUID, UserUuid: confirmation.UserUUID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrInvalidConfirmationCode
+		}
+		return uuid.Nil, err
+	}
+
+	_, err = r.store.RecordUserLoginIP(ctx, db.RecordUserLoginIPParams{UserUuid: confirmation.UserUUID, ClientIp: clientIP})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	securityevent.Record(ctx, r.store, securityevent.Event{
+		UserUUID:  confirmation.UserUUID,
+		Type:      securityevent.TypeDeviceConfirmed,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+	})
+
+	return confirmation.UserUUID, nil
+}
+
+// List returns the trusted devices of the user, most recently seen first.
+func (r *Registry) List(ctx context.Context, userUUID uuid.UUID) ([]db.UserDevice, error) {
+	return r.store.ListTrustedUserDevices(ctx, userUUID)
+}
+
+// Remove takes a device off the trusted devices of the user. The next login from it counts as a
+// login from a new device. Its sessions are not revoked, they are managed on their own.
+func (r *Registry) Remove(ctx context.Context, userUUID, deviceUUID uuid.UUID, clientIP, userAgent string) error {
+	rows, err := r.store.UntrustUserDevice(ctx, db.UntrustUserDeviceParams{DeviceUuid: deviceUUID, UserUuid: userUUID})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrDeviceNotFound
+	}
+
+	securityevent.Record(ctx, r.store, securityevent.Event{
+		UserUUID:  userUUID,
+		Type:      securityevent.TypeDeviceRemoved,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+	})
+
+	return nil
+}
+
+type confirmationData struct {
+	UserUUID    uuid.UUID `json:"user_uuid"`
+	DeviceUUID  uuid.UUID `json:"device_uuid"`
+	Fingerprint string    `json:"fingerprint"`
+}
+
+// requestConfirmation records the untrusted device of login and emails the code confirming it.
+func (r *Registry) requestConfirmation(ctx context.Context, login Login, fingerprint string) error {
+	pending, err := r.store.UpsertUserDevice(ctx, db.UpsertUserDeviceParams{
+		UserUuid:     login.UserUUID,
+		Fingerprint:  fingerprint,
+		UserAgent:    login.UserAgent,
+		LastClientIp: login.ClientIP,
+	})
+	if err != nil {
+		return err
+	}
+
+	raw := make([]byte, confirmationCodeSize)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	code := hex.EncodeToString(raw)
+
+	data, err := json.Marshal(confirmationData{UserUUID: login.UserUUID, DeviceUUID: pending.DeviceUuid, Fingerprint: fingerprint})
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(r.config.ConfirmationCodeTTL)
+	if err := r.rdb.Set(ctx, confirmationKey(code), data, r.config.ConfirmationCodeTTL).Err(); err != nil {
+		return err
+	}
+
+	securityevent.Record(ctx, r.store, securityevent.Event{
+		UserUUID:  login.UserUUID,
+		Type:      securityevent.TypeLoginNewDevice,
+		ClientIP:  login.ClientIP,
+		UserAgent: login.UserAgent,
+	})
+
+	if err := r.emails.QueueDeviceConfirmation(ctx, login.UserUUID, login.Email, code, expiresAt); err != nil {
+		return err
+	}
+	return ErrConfirmationRequired
+}
+
+// alert records the login from a new device or IP and emails the user about it. The login succeeds
+// anyway, so a failure is only logged.
+func (r *Registry) alert(ctx context.Context, login Login, signedInAt time.Time) {
+	securityevent.Record(ctx, r.store, securityevent.Event{
+		UserUUID:  login.UserUUID,
+		Type:      securityevent.TypeLoginNewDevice,
+		ClientIP:  login.ClientIP,
+		UserAgent: login.UserAgent,
+	})
+
+	device := fmt.Sprintf("%s from %s", login.UserAgent, login.ClientIP)
+	if err := r.emails.QueueNewDeviceAlert(ctx, login.UserUUID, login.Email, device, signedInAt); err != nil {
+		log.Printf("Error: cannot queue new device alert for user %s: %s", login.UserUUID, err.Error())
+	}
+}
+
+func confirmationKey(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return confirmationKeyPrefix + hex.EncodeToString(sum[:])
+}
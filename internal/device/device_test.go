@@ -0,0 +1,275 @@
+package device_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	"github.com/fajaramaulana/simple_bank_project/internal/securityevent"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// emailQueue records the queued confirmation codes and alerts instead of sending them.
+type emailQueue struct {
+	codes  map[uuid.UUID]string
+	alerts []string
+}
+
+func (q *emailQueue) QueueVerification(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueuePasswordReset(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueueAccountUnlock(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueueDeviceConfirmation(_ context.Context, userUUID uuid.UUID, _, code string, _ time.Time) error {
+	if q.codes == nil {
+		q.codes = make(map[uuid.UUID]string)
+	}
+	q.codes[userUUID] = code
+	return nil
+}
+
+func (q *emailQueue) QueueNewDeviceAlert(_ context.Context, _ uuid.UUID, _, device string, _ time.Time) error {
+	q.alerts = append(q.alerts, device)
+	return nil
+}
+
+func newRegistry(t *testing.T, store device.Store, queue *emailQueue, config device.Config) *device.Registry {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	return device.NewRegistry(rdb, store, queue, config)
+}
+
+// deviceToken is a device token as SignIn issues them.
+var deviceToken = strings.Repeat("5e", 32)
+
+func TestSignIn(t *testing.T) {
+	login := device.Login{
+		UserUUID:    util.RandomUUID(),
+		Email:       util.RandomEmail(),
+		DeviceToken: deviceToken,
+		UserAgent:   "Mozilla/5.0 (X11; Linux x86_64)",
+		ClientIP:    "10.0.0.1",
+	}
+	trusted := db.UserDevice{
+		DeviceUuid:  util.RandomUUID(),
+		UserUuid:    login.UserUUID,
+		Fingerprint: device.Fingerprint(login.DeviceToken),
+		TrustedAt:   pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+	}
+
+	testCases := []struct {
+		name       string
+		config     device.Config
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, deviceToken string, err error, queue *emailQueue)
+	}{
+		{
+			name: "KnownDeviceAndIP",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserDevice(gomock.Any(), db.GetUserDeviceParams{UserUuid: login.UserUUID, Fingerprint: trusted.Fingerprint}).Times(1).Return(trusted, nil)
+				store.EXPECT().CountUserDevices(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(trusted, nil)
+				store.EXPECT().RecordUserLoginIP(gomock.Any(), db.RecordUserLoginIPParams{UserUuid: login.UserUUID, ClientIp: login.ClientIP}).Times(1).Return(false, nil)
+				store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, deviceToken string, err error, queue *emailQueue) {
+				require.NoError(t, err)
+				require.Equal(t, login.DeviceToken, deviceToken)
+				require.Empty(t, queue.alerts)
+			},
+		},
+		{
+			name: "KnownDeviceNewIP",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(trusted, nil)
+				store.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(trusted, nil)
+				store.EXPECT().RecordUserLoginIP(gomock.Any(), gomock.Any()).Times(1).Return(true, nil)
+				store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSecurityEventParams) (db.SecurityEvent, error) {
+						require.Equal(t, securityevent.TypeLoginNewDevice, arg.EventType)
+						return db.SecurityEvent{}, nil
+					})
+			},
+			check: func(t *testing.T, deviceToken string, err error, queue *emailQueue) {
+				require.NoError(t, err)
+				require.Equal(t, login.DeviceToken, deviceToken)
+				require.Len(t, queue.alerts, 1)
+				require.Contains(t, queue.alerts[0], login.ClientIP)
+			},
+		},
+		{
+			name: "NewDevice",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(db.UserDevice{}, pgx.ErrNoRows)
+				store.EXPECT().CountUserDevices(gomock.Any(), login.UserUUID).Times(1).Return(int64(1), nil)
+				store.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpsertUserDeviceParams) (db.UserDevice, error) {
+						require.True(t, arg.TrustedAt.Valid)
+						return trusted, nil
+					})
+				store.EXPECT().RecordUserLoginIP(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+				store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(1).Return(db.SecurityEvent{}, nil)
+			},
+			check: func(t *testing.T, deviceToken string, err error, queue *emailQueue) {
+				require.NoError(t, err)
+				require.Equal(t, login.DeviceToken, deviceToken)
+				require.Len(t, queue.alerts, 1)
+				require.Contains(t, queue.alerts[0], login.UserAgent)
+			},
+		},
+		{
+			name: "FirstDevice",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(db.UserDevice{}, pgx.ErrNoRows)
+				store.EXPECT().CountUserDevices(gomock.Any(), login.UserUUID).Times(1).Return(int64(0), nil)
+				store.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(trusted, nil)
+				store.EXPECT().RecordUserLoginIP(gomock.Any(), gomock.Any()).Times(1).Return(true, nil)
+				store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, deviceToken string, err error, queue *emailQueue) {
+				require.NoError(t, err)
+				require.Equal(t, login.DeviceToken, deviceToken)
+				require.Empty(t, queue.alerts)
+			},
+		},
+		{
+			name:   "ConfirmationRequired",
+			config: device.Config{RequireConfirmation: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(db.UserDevice{}, pgx.ErrNoRows)
+				store.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpsertUserDeviceParams) (db.UserDevice, error) {
+						require.False(t, arg.TrustedAt.Valid)
+						return db.UserDevice{DeviceUuid: trusted.DeviceUuid, UserUuid: login.UserUUID}, nil
+					})
+				store.EXPECT().RecordUserLoginIP(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(1).Return(db.SecurityEvent{}, nil)
+			},
+			check: func(t *testing.T, deviceToken string, err error, queue *emailQueue) {
+				require.ErrorIs(t, err, device.ErrConfirmationRequired)
+				require.Equal(t, login.DeviceToken, deviceToken)
+				require.NotEmpty(t, queue.codes[login.UserUUID])
+				require.Empty(t, queue.alerts)
+			},
+		},
+		{
+			name:   "TrustedDeviceWithConfirmationRequired",
+			config: device.Config{RequireConfirmation: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(trusted, nil)
+				store.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(trusted, nil)
+				store.EXPECT().RecordUserLoginIP(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+			},
+			check: func(t *testing.T, deviceToken string, err error, queue *emailQueue) {
+				require.NoError(t, err)
+				require.Equal(t, login.DeviceToken, deviceToken)
+				require.Empty(t, queue.codes)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			queue := &emailQueue{}
+			registry := newRegistry(t, store, queue, tc.config)
+			deviceToken, err := registry.SignIn(context.Background(), login)
+			tc.check(t, deviceToken, err, queue)
+		})
+	}
+}
+
+func TestSignInIssuesDeviceToken(t *testing.T) {
+	userAgent := "Mozilla/5.0 (X11; Linux x86_64)"
+
+	// a device without a token, or with one the server cannot have issued, gets a new token. A
+	// header the client chooses freely never identifies a device.
+	for _, sent := range []string{"", userAgent, strings.Repeat("5e", 16)} {
+		login := device.Login{
+			UserUUID:    util.RandomUUID(),
+			Email:       util.RandomEmail(),
+			DeviceToken: sent,
+			UserAgent:   userAgent,
+			ClientIP:    "10.0.0.1",
+		}
+
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+
+		var fingerprint string
+		store.EXPECT().GetUserDevice(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, arg db.GetUserDeviceParams) (db.UserDevice, error) {
+				fingerprint = arg.Fingerprint
+				return db.UserDevice{}, pgx.ErrNoRows
+			})
+		store.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(db.UserDevice{DeviceUuid: util.RandomUUID()}, nil)
+		store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(1).Return(db.SecurityEvent{}, nil)
+
+		registry := newRegistry(t, store, &emailQueue{}, device.Config{RequireConfirmation: true})
+		issued, err := registry.SignIn(context.Background(), login)
+		require.ErrorIs(t, err, device.ErrConfirmationRequired)
+		require.NotEqual(t, sent, issued)
+		require.NotEmpty(t, device.Fingerprint(issued))
+		require.Equal(t, device.Fingerprint(issued), fingerprint)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	login := device.Login{
+		UserUUID:  util.RandomUUID(),
+		Email:     util.RandomEmail(),
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64)",
+		ClientIP:  "10.0.0.1",
+	}
+	pending := db.UserDevice{DeviceUuid: util.RandomUUID(), UserUuid: login.UserUUID}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUserDevice(gomock.Any(), gomock.Any()).AnyTimes().Return(db.UserDevice{}, pgx.ErrNoRows)
+	store.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).AnyTimes().Return(pending, nil)
+	store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).AnyTimes().Return(db.SecurityEvent{}, nil)
+
+	queue := &emailQueue{}
+	registry := newRegistry(t, store, queue, device.Config{RequireConfirmation: true})
+
+	// a code used from another device is spent, even one sending the same user agent
+	_, err := registry.SignIn(context.Background(), login)
+	require.ErrorIs(t, err, device.ErrConfirmationRequired)
+	_, err = registry.Confirm(context.Background(), queue.codes[login.UserUUID], deviceToken, login.UserAgent, login.ClientIP)
+	require.ErrorIs(t, err, device.ErrInvalidConfirmationCode)
+
+	issued, err := registry.SignIn(context.Background(), login)
+	require.ErrorIs(t, err, device.ErrConfirmationRequired)
+	code := queue.codes[login.UserUUID]
+
+	store.EXPECT().TrustUserDevice(gomock.Any(), db.TrustUserDeviceParams{DeviceUuid: pending.DeviceUuid, UserUuid: login.UserUUID}).Times(1).Return(pending, nil)
+	store.EXPECT().RecordUserLoginIP(gomock.Any(), db.RecordUserLoginIPParams{UserUuid: login.UserUUID, ClientIp: login.ClientIP}).Times(1).Return(true, nil)
+
+	userUUID, err := registry.Confirm(context.Background(), code, issued, login.UserAgent, login.ClientIP)
+	require.NoError(t, err)
+	require.Equal(t, login.UserUUID, userUUID)
+
+	// the code works once
+	_, err = registry.Confirm(context.Background(), code, issued, login.UserAgent, login.ClientIP)
+	require.ErrorIs(t, err, device.ErrInvalidConfirmationCode)
+}
@@ -0,0 +1,110 @@
+// Package email queues the emails the email runner sends. Every email is a Redis hash whose key
+// prefix tells the runner what kind of email it is.
+package email
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// VerificationKeyPrefix prefixes the keys of email address verification emails.
+	VerificationKeyPrefix = "verification_email:"
+	// PasswordResetKeyPrefix prefixes the keys of password reset emails.
+	PasswordResetKeyPrefix = "password_reset_email:"
+	// AccountUnlockKeyPrefix prefixes the keys of the emails sent when a login is locked.
+	AccountUnlockKeyPrefix = "account_unlock_email:"
+	// DeviceConfirmationKeyPrefix prefixes the keys of the emails confirming a login from a new
+	// device.
+	DeviceConfirmationKeyPrefix = "device_confirmation_email:"
+	// NewDeviceAlertKeyPrefix prefixes the keys of the emails alerting a user of a login from a
+	// device or IP not seen before.
+	NewDeviceAlertKeyPrefix = "new_device_alert_email:"
+)
+
+// newDeviceAlertTTL is how long an alert of a login is worth sending.
+const newDeviceAlertTTL = 24 * time.Hour
+
+// Queue queues emails for the email runner.
+type Queue interface {
+	// QueueVerification queues the email carrying the code that verifies the email address of a
+	// user. A newer code for the same user replaces one that has not been sent yet.
+	QueueVerification(ctx context.Context, userUUID uuid.UUID, address, code string, expiresAt time.Time) error
+	// QueuePasswordReset queues the email carrying a password reset code. A newer code for the
+	// same user replaces one that has not been sent yet.
+	QueuePasswordReset(ctx context.Context, userUUID uuid.UUID, address, code string, expiresAt time.Time) error
+	// QueueAccountUnlock queues the email telling the user the login was locked after failed
+	// attempts, carrying the code that unlocks it.
+	QueueAccountUnlock(ctx context.Context, userUUID uuid.UUID, address, code string, expiresAt time.Time) error
+	// QueueDeviceConfirmation queues the email carrying the code that confirms a login from a new
+	// device. A newer code for the same user replaces one that has not been sent yet.
+	QueueDeviceConfirmation(ctx context.Context, userUUID uuid.UUID, address, code string, expiresAt time.Time) error
+	// QueueNewDeviceAlert queues the email telling the user about a login from a device or IP not
+	// seen before. device describes the device and where it signed in from.
+	QueueNewDeviceAlert(ctx context.Context, userUUID uuid.UUID, address, device string, signedInAt time.Time) error
+}
+
+// RedisQueue queues emails in Redis.
+type RedisQueue struct {
+	rdb *redis.Client
+}
+
+func NewRedisQueue(rdb *redis.Client) *RedisQueue {
+	return &RedisQueue{rdb: rdb}
+}
+
+func (q *RedisQueue) QueueVerification(ctx context.Context, userUUID uuid.UUID, address, code string, expiresAt time.Time) error {
+	return q.queue(ctx, VerificationKeyPrefix+"users:"+userUUID.String(), map[string]interface{}{
+		"email":             address,
+		"verification_code": code,
+		"expired_at":        expiresAt,
+	}, expiresAt)
+}
+
+func (q *RedisQueue) QueuePasswordReset(ctx context.Context, userUUID uuid.UUID, address, code string, expiresAt time.Time) error {
+	return q.queue(ctx, PasswordResetKeyPrefix+"users:"+userUUID.String(), map[string]interface{}{
+		"email":      address,
+		"reset_code": code,
+		"expired_at": expiresAt,
+	}, expiresAt)
+}
+
+func (q *RedisQueue) QueueAccountUnlock(ctx context.Context, userUUID uuid.UUID, address, code string, expiresAt time.Time) error {
+	return q.queue(ctx, AccountUnlockKeyPrefix+"users:"+userUUID.String(), map[string]interface{}{
+		"email":       address,
+		"unlock_code": code,
+		"expired_at":  expiresAt,
+	}, expiresAt)
+}
+
+func (q *RedisQueue) QueueDeviceConfirmation(ctx context.Context, userUUID uuid.UUID, address, code string, expiresAt time.Time) error {
+	return q.queue(ctx, DeviceConfirmationKeyPrefix+"users:"+userUUID.String(), map[string]interface{}{
+		"email":             address,
+		"confirmation_code": code,
+		"expired_at":        expiresAt,
+	}, expiresAt)
+}
+
+func (q *RedisQueue) QueueNewDeviceAlert(ctx context.Context, userUUID uuid.UUID, address, device string, signedInAt time.Time) error {
+	// every login gets its own alert, a later one must not replace it
+	key := NewDeviceAlertKeyPrefix + "users:" + userUUID.String() + ":" + strconv.FormatInt(signedInAt.UnixNano(), 10)
+	return q.queue(ctx, key, map[string]interface{}{
+		"email":        address,
+		"device":       device,
+		"signed_in_at": signedInAt,
+	}, signedInAt.Add(newDeviceAlertTTL))
+}
+
+func (q *RedisQueue) queue(ctx context.Context, key string, fields map[string]interface{}, expiresAt time.Time) error {
+	_, err := q.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, fields)
+		// a code nobody sent before it expired is useless
+		pipe.ExpireAt(ctx, key, expiresAt)
+		return nil
+	})
+	return err
+}
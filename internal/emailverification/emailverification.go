@@ -0,0 +1,154 @@
+// Package emailverification implements verifying the email address of users: a code is emailed to
+// the user and sent back to confirm the address. Creating accounts and transferring money can be
+// restricted to users who verified their address.
+package emailverification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/email"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CodeTTL is how long a verification code is valid.
+const CodeTTL = 15 * time.Minute
+
+var (
+	// ErrNotVerified is returned when the policy requires a verified email address the user does
+	// not have.
+	ErrNotVerified = errors.New("email address is not verified")
+	// ErrAlreadyVerified is returned when verifying an address, or asking for a new code, after the
+	// address was verified.
+	ErrAlreadyVerified = errors.New("email already verified")
+	// ErrInvalidCode is returned for an unknown verification code.
+	ErrInvalidCode = errors.New("verification code not found")
+	// ErrCodeExpired is returned for a verification code that expired.
+	ErrCodeExpired = errors.New("verification code is expired")
+	// ErrResendTooSoon is returned when asking for a new code shortly after the last one was sent.
+	ErrResendTooSoon = errors.New("a verification email was sent recently, try again later")
+)
+
+// Store is the data email verification reads and writes. db.Store satisfies it.
+type Store interface {
+	GetUserByUserUUID(ctx context.Context, userUuid uuid.UUID) (db.GetUserByUserUUIDRow, error)
+	GetUserByVerificationEmailCode(ctx context.Context, verificationEmailCode pgtype.Text) (db.GetUserByVerificationEmailCodeRow, error)
+	RenewVerificationEmailCode(ctx context.Context, arg db.RenewVerificationEmailCodeParams) (db.RenewVerificationEmailCodeRow, error)
+	UpdateUserVerificationEmail(ctx context.Context, arg db.UpdateUserVerificationEmailParams) (db.UpdateUserVerificationEmailRow, error)
+}
+
+// Config tunes email verification. Zero values fall back to DefaultConfig, except Required.
+type Config struct {
+	// Required restricts creating accounts and transferring money to users with a verified email
+	// address.
+	Required bool
+	// ResendInterval is the least time between two verification emails to a user.
+	ResendInterval time.Duration
+}
+
+// DefaultConfig returns the configuration used unless configured otherwise.
+func DefaultConfig() Config {
+	return Config{
+		ResendInterval: time.Minute,
+	}
+}
+
+// Verifier issues and checks verification codes and enforces the verified email policy.
+type Verifier struct {
+	store  Store
+	emails email.Queue
+	config Config
+}
+
+// NewVerifier creates a verifier queueing its emails in emails.
+func NewVerifier(store Store, emails email.Queue, config Config) *Verifier {
+	if config.ResendInterval <= 0 {
+		config.ResendInterval = DefaultConfig().ResendInterval
+	}
+
+	return &Verifier{store: store, emails: emails, config: config}
+}
+
+// Require returns ErrNotVerified when the policy requires a verified email address and the user
+// has not verified theirs.
+func (v *Verifier) Require(ctx context.Context, userUUID uuid.UUID) error {
+	if !v.config.Required {
+		return nil
+	}
+
+	user, err := v.store.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+	if user.VerifiedEmailAt.IsZero() {
+		return ErrNotVerified
+	}
+
+	return nil
+}
+
+// Resend issues a new verification code for the user, replacing the previous one, and queues the
+// email carrying it. Codes are issued at most once per ResendInterval.
+func (v *Verifier) Resend(ctx context.Context, userUUID uuid.UUID) error {
+	code := uuid.New().String()
+	expiresAt := time.Now().Add(CodeTTL)
+
+	// the current code was issued before the interval when it expires before the new one would,
+	// less the interval
+	row, err := v.store.RenewVerificationEmailCode(ctx, db.RenewVerificationEmailCodeParams{
+		VerificationEmailCode:      pgtype.Text{String: code, Valid: true},
+		VerificationEmailExpiredAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		UserUuid:                   userUUID,
+		IssuedCodeExpiresBefore:    pgtype.Timestamptz{Time: expiresAt.Add(-v.config.ResendInterval), Valid: true},
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		user, err := v.store.GetUserByUserUUID(ctx, userUUID)
+		if err != nil {
+			return err
+		}
+		if !user.VerifiedEmailAt.IsZero() {
+			return ErrAlreadyVerified
+		}
+		return ErrResendTooSoon
+	}
+
+	return v.emails.QueueVerification(ctx, row.UserUuid, row.Email, code, expiresAt)
+}
+
+// Verify marks the email address the code was sent to as verified and returns its user.
+func (v *Verifier) Verify(ctx context.Context, code string) (uuid.UUID, error) {
+	user, err := v.store.GetUserByVerificationEmailCode(ctx, pgtype.Text{String: code, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrInvalidCode
+		}
+		return uuid.Nil, err
+	}
+
+	if !user.VerifiedEmailAt.IsZero() {
+		return uuid.Nil, ErrAlreadyVerified
+	}
+	if user.VerificationEmailExpiredAt.Time.Before(time.Now()) {
+		return uuid.Nil, ErrCodeExpired
+	}
+
+	_, err = v.store.UpdateUserVerificationEmail(ctx, db.UpdateUserVerificationEmailParams{
+		VerificationEmailCode:      user.VerificationEmailCode,
+		VerificationEmailExpiredAt: user.VerificationEmailExpiredAt,
+		VerifiedEmailAt:            time.Now(),
+		UserUuid:                   user.UserUuid,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return user.UserUuid, nil
+}
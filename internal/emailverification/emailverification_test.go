@@ -0,0 +1,242 @@
+package emailverification_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// emailQueue records the queued verification codes instead of sending them.
+type emailQueue struct {
+	codes map[uuid.UUID]string
+}
+
+func (q *emailQueue) QueueVerification(_ context.Context, userUUID uuid.UUID, _, code string, _ time.Time) error {
+	if q.codes == nil {
+		q.codes = make(map[uuid.UUID]string)
+	}
+	q.codes[userUUID] = code
+	return nil
+}
+
+func (q *emailQueue) QueuePasswordReset(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueueAccountUnlock(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueueDeviceConfirmation(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueueNewDeviceAlert(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func TestRequire(t *testing.T) {
+	userUUID := util.RandomUUID()
+
+	testCases := []struct {
+		name       string
+		config     emailverification.Config
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name:   "NotRequired",
+			config: emailverification.Config{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "Verified",
+			config: emailverification.Config{Required: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), userUUID).Times(1).
+					Return(db.GetUserByUserUUIDRow{UserUuid: userUUID, VerifiedEmailAt: time.Now()}, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "NotVerified",
+			config: emailverification.Config{Required: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), userUUID).Times(1).
+					Return(db.GetUserByUserUUIDRow{UserUuid: userUUID}, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, emailverification.ErrNotVerified)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			verifier := emailverification.NewVerifier(store, &emailQueue{}, tc.config)
+			tc.checkError(t, verifier.Require(context.Background(), userUUID))
+		})
+	}
+}
+
+func TestResend(t *testing.T) {
+	userUUID := util.RandomUUID()
+	address := util.RandomEmail()
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error, queue *emailQueue)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RenewVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RenewVerificationEmailCodeParams) (db.RenewVerificationEmailCodeRow, error) {
+						require.Equal(t, userUUID, arg.UserUuid)
+						require.WithinDuration(t, time.Now().Add(emailverification.CodeTTL), arg.VerificationEmailExpiredAt.Time, time.Second)
+						require.Equal(t, 5*time.Minute, arg.VerificationEmailExpiredAt.Time.Sub(arg.IssuedCodeExpiresBefore.Time))
+						return db.RenewVerificationEmailCodeRow{UserUuid: userUUID, Email: address, VerificationEmailExpiredAt: arg.VerificationEmailExpiredAt}, nil
+					})
+			},
+			checkError: func(t *testing.T, err error, queue *emailQueue) {
+				require.NoError(t, err)
+				require.NotEmpty(t, queue.codes[userUUID])
+			},
+		},
+		{
+			name: "TooSoon",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RenewVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RenewVerificationEmailCodeRow{}, pgx.ErrNoRows)
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), userUUID).Times(1).Return(db.GetUserByUserUUIDRow{UserUuid: userUUID}, nil)
+			},
+			checkError: func(t *testing.T, err error, queue *emailQueue) {
+				require.ErrorIs(t, err, emailverification.ErrResendTooSoon)
+				require.Empty(t, queue.codes)
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RenewVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RenewVerificationEmailCodeRow{}, pgx.ErrNoRows)
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), userUUID).Times(1).
+					Return(db.GetUserByUserUUIDRow{UserUuid: userUUID, VerifiedEmailAt: time.Now()}, nil)
+			},
+			checkError: func(t *testing.T, err error, queue *emailQueue) {
+				require.ErrorIs(t, err, emailverification.ErrAlreadyVerified)
+				require.Empty(t, queue.codes)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			queue := &emailQueue{}
+			verifier := emailverification.NewVerifier(store, queue, emailverification.Config{ResendInterval: 5 * time.Minute})
+			tc.checkError(t, verifier.Resend(context.Background(), userUUID), queue)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	userUUID := util.RandomUUID()
+	code := uuid.New().String()
+	pending := db.GetUserByVerificationEmailCodeRow{
+		UserUuid:                   userUUID,
+		VerificationEmailCode:      pgtype.Text{String: code, Valid: true},
+		VerificationEmailExpiredAt: pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByVerificationEmailCode(gomock.Any(), pgtype.Text{String: code, Valid: true}).Times(1).Return(pending, nil)
+				store.EXPECT().UpdateUserVerificationEmail(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserVerificationEmailParams) (db.UpdateUserVerificationEmailRow, error) {
+						require.Equal(t, userUUID, arg.UserUuid)
+						require.WithinDuration(t, time.Now(), arg.VerifiedEmailAt, time.Second)
+						return db.UpdateUserVerificationEmailRow{UserUuid: userUUID}, nil
+					})
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "UnknownCode",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).Return(db.GetUserByVerificationEmailCodeRow{}, pgx.ErrNoRows)
+				store.EXPECT().UpdateUserVerificationEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, emailverification.ErrInvalidCode)
+			},
+		},
+		{
+			name: "Expired",
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := pending
+				expired.VerificationEmailExpiredAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+				store.EXPECT().GetUserByVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).Return(expired, nil)
+				store.EXPECT().UpdateUserVerificationEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, emailverification.ErrCodeExpired)
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := pending
+				verified.VerifiedEmailAt = time.Now()
+				store.EXPECT().GetUserByVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).Return(verified, nil)
+				store.EXPECT().UpdateUserVerificationEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, emailverification.ErrAlreadyVerified)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			verifier := emailverification.NewVerifier(store, &emailQueue{}, emailverification.DefaultConfig())
+			_, err := verifier.Verify(context.Background(), code)
+			tc.checkError(t, err)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stream is the Redis stream that outbox events are published to.
+const Stream = "simple_bank:events"
+
+// Type identifies the kind of a domain event.
+type Type string
+
+const (
+	TypeTransferCompleted Type = "TransferCompleted"
+	TypeAccountCreated    Type = "AccountCreated"
+	TypeUserRegistered    Type = "UserRegistered"
+)
+
+// Event is implemented by every domain event payload that can be written to the outbox.
+type Event interface {
+	EventType() Type
+	AggregateType() string
+	AggregateID() string
+}
+
+// Envelope wraps an event payload with the metadata consumers need to route and deduplicate it.
+// Delivery is at-least-once, so consumers must treat ID as an idempotency key.
+type Envelope struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          Type            `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// NewEnvelope builds an envelope for the given event with a fresh event ID.
+func NewEnvelope(e Event, occurredAt time.Time) (Envelope, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{
+		ID:            uuid.New(),
+		Type:          e.EventType(),
+		AggregateType: e.AggregateType(),
+		AggregateID:   e.AggregateID(),
+		OccurredAt:    occurredAt,
+		Payload:       payload,
+	}, nil
+}
+
+type TransferCompleted struct {
+	TransactionUUID uuid.UUID `json:"transaction_uuid"`
+	FromAccountUUID uuid.UUID `json:"from_account_uuid"`
+	ToAccountUUID   uuid.UUID `json:"to_account_uuid"`
+	Amount          string    `json:"amount"`
+	Currency        string    `json:"currency"`
+}
+
+func (e TransferCompleted) EventType() Type       { return TypeTransferCompleted }
+func (e TransferCompleted) AggregateType() string { return "transaction" }
+func (e TransferCompleted) AggregateID() string   { return e.TransactionUUID.String() }
+
+type AccountCreated struct {
+	AccountUUID uuid.UUID `json:"account_uuid"`
+	UserUUID    uuid.UUID `json:"user_uuid"`
+	Owner       string    `json:"owner"`
+	Currency    string    `json:"currency"`
+}
+
+func (e AccountCreated) EventType() Type       { return TypeAccountCreated }
+func (e AccountCreated) AggregateType() string { return "account" }
+func (e AccountCreated) AggregateID() string   { return e.AccountUUID.String() }
+
+type UserRegistered struct {
+	UserUUID uuid.UUID `json:"user_uuid"`
+	Username string    `json:"username"`
+	FullName string    `json:"full_name"`
+	Email    string    `json:"email"`
+}
+
+func (e UserRegistered) EventType() Type       { return TypeUserRegistered }
+func (e UserRegistered) AggregateType() string { return "user" }
+func (e UserRegistered) AggregateID() string   { return e.UserUUID.String() }
@@ -0,0 +1,50 @@
+package event
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEnvelope(t *testing.T) {
+	transfer := TransferCompleted{
+		TransactionUUID: uuid.New(),
+		FromAccountUUID: uuid.New(),
+		ToAccountUUID:   uuid.New(),
+		Amount:          "100",
+		Currency:        "IDR",
+	}
+	occurredAt := time.Now()
+
+	envelope, err := NewEnvelope(transfer, occurredAt)
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, envelope.ID)
+	require.Equal(t, TypeTransferCompleted, envelope.Type)
+	require.Equal(t, "transaction", envelope.AggregateType)
+	require.Equal(t, transfer.TransactionUUID.String(), envelope.AggregateID)
+	require.Equal(t, occurredAt, envelope.OccurredAt)
+
+	var payload TransferCompleted
+	err = json.Unmarshal(envelope.Payload, &payload)
+	require.NoError(t, err)
+	require.Equal(t, transfer, payload)
+
+	other, err := NewEnvelope(transfer, occurredAt)
+	require.NoError(t, err)
+	require.NotEqual(t, envelope.ID, other.ID)
+}
+
+func TestEventAggregates(t *testing.T) {
+	account := AccountCreated{AccountUUID: uuid.New(), UserUUID: uuid.New()}
+	require.Equal(t, TypeAccountCreated, account.EventType())
+	require.Equal(t, "account", account.AggregateType())
+	require.Equal(t, account.AccountUUID.String(), account.AggregateID())
+
+	user := UserRegistered{UserUUID: uuid.New()}
+	require.Equal(t, TypeUserRegistered, user.EventType())
+	require.Equal(t, "user", user.AggregateType())
+	require.Equal(t, user.UserUUID.String(), user.AggregateID())
+}
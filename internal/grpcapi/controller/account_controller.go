@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/validate"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/service"
+	"github.com/fajaramaulana/simple_bank_project/pb"
+	"github.com/rs/zerolog/log"
+)
+
+type AccountController struct {
+	accountService *service.AccountService
+}
+
+func NewAccountController(accountService *service.AccountService) *AccountController {
+	return &AccountController{accountService: accountService}
+}
+
+func (c *AccountController) GetAccountBalance(ctx context.Context, req *pb.GetAccountBalanceRequest, payload *token.Payload) (*pb.GetAccountBalanceResponse, error) {
+	violations := validate.ValidateGetAccountBalanceRequest(req)
+	if violations != nil {
+		log.Error().Err(helper.InvalidArgumentError(violations)).Msg("GetAccountBalanceRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	res, err := c.accountService.GetAccountBalance(ctx, req, payload)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get account balance")
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/validate"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/service"
@@ -35,6 +36,87 @@ func (c *AuthController) LoginUser(ctx context.Context, req *pb.LoginUserRequest
 	return res, nil
 }
 
+func (c *AuthController) VerifyLoginMFA(ctx context.Context, req *pb.VerifyLoginMFARequest) (*pb.LoginUserResponse, error) {
+	violations := validate.ValidateVerifyLoginMFARequest(req)
+	if violations != nil {
+		log.Err(helper.InvalidArgumentError(violations)).Msg("VerifyLoginMFARequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+	metaData := shared.ExtractMetadata(ctx)
+	res, err := c.authService.VerifyLoginMFA(ctx, req, metaData)
+	if err != nil {
+		log.Err(err).Msg("Failed to verify login two-factor code")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) ConfirmDevice(ctx context.Context, req *pb.ConfirmDeviceRequest) (*pb.LoginUserResponse, error) {
+	violations := validate.ValidateConfirmDeviceRequest(req)
+	if violations != nil {
+		log.Err(helper.InvalidArgumentError(violations)).Msg("ConfirmDeviceRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+	metaData := shared.ExtractMetadata(ctx)
+	res, err := c.authService.ConfirmDevice(ctx, req, metaData)
+	if err != nil {
+		log.Err(err).Msg("Failed to confirm device")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	violations := validate.ValidateRequestPasswordResetRequest(req)
+	if violations != nil {
+		log.Err(helper.InvalidArgumentError(violations)).Msg("RequestPasswordResetRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	metaData := shared.ExtractMetadata(ctx)
+	res, err := c.authService.RequestPasswordReset(ctx, req, metaData)
+	if err != nil {
+		log.Err(err).Msg("Failed to request password reset")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	violations := validate.ValidateResetPasswordRequest(req)
+	if violations != nil {
+		log.Err(helper.InvalidArgumentError(violations)).Msg("ResetPasswordRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	res, err := c.authService.ResetPassword(ctx, req)
+	if err != nil {
+		log.Err(err).Msg("Failed to reset password")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) UnlockLogin(ctx context.Context, req *pb.UnlockLoginRequest) (*pb.UnlockLoginResponse, error) {
+	violations := validate.ValidateUnlockLoginRequest(req)
+	if violations != nil {
+		log.Err(helper.InvalidArgumentError(violations)).Msg("UnlockLoginRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+	metaData := shared.ExtractMetadata(ctx)
+	res, err := c.authService.UnlockLogin(ctx, req, metaData)
+	if err != nil {
+		log.Err(err).Msg("Failed to unlock login")
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func (c *AuthController) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
 	violdations := validate.ValidateVerifyEmailUserRequest(req)
 	if violdations != nil {
@@ -50,3 +132,104 @@ func (c *AuthController) VerifyEmail(ctx context.Context, req *pb.VerifyEmailReq
 
 	return res, nil
 }
+
+func (c *AuthController) ResendVerificationEmail(ctx context.Context, payload *token.Payload) (*pb.ResendVerificationEmailResponse, error) {
+	res, err := c.authService.ResendVerificationEmail(ctx, payload)
+	if err != nil {
+		log.Err(err).Msg("Failed to resend verification email")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) StepUp(ctx context.Context, req *pb.StepUpRequest, payload *token.Payload) (*pb.StepUpResponse, error) {
+	violations := validate.ValidateStepUpRequest(req)
+	if violations != nil {
+		log.Err(helper.InvalidArgumentError(violations)).Msg("StepUpRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	metaData := shared.ExtractMetadata(ctx)
+	res, err := c.authService.StepUp(ctx, req, payload, metaData)
+	if err != nil {
+		log.Err(err).Msg("Failed to step up authentication")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) Logout(ctx context.Context, payload *token.Payload) (*pb.LogoutResponse, error) {
+	res, err := c.authService.Logout(ctx, payload)
+	if err != nil {
+		log.Err(err).Msg("Failed to logout")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) ListSessions(ctx context.Context, payload *token.Payload) (*pb.ListSessionsResponse, error) {
+	res, err := c.authService.ListSessions(ctx, payload)
+	if err != nil {
+		log.Err(err).Msg("Failed to list sessions")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest, payload *token.Payload) (*pb.RevokeSessionsResponse, error) {
+	violations := validate.ValidateRevokeSessionRequest(req)
+	if violations != nil {
+		log.Err(helper.InvalidArgumentError(violations)).Msg("RevokeSessionRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	res, err := c.authService.RevokeSession(ctx, req, payload)
+	if err != nil {
+		log.Err(err).Msg("Failed to revoke session")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) RevokeOtherSessions(ctx context.Context, payload *token.Payload) (*pb.RevokeSessionsResponse, error) {
+	res, err := c.authService.RevokeOtherSessions(ctx, payload)
+	if err != nil {
+		log.Err(err).Msg("Failed to revoke other sessions")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) ListDevices(ctx context.Context, payload *token.Payload) (*pb.ListDevicesResponse, error) {
+	metaData := shared.ExtractMetadata(ctx)
+	res, err := c.authService.ListDevices(ctx, payload, metaData)
+	if err != nil {
+		log.Err(err).Msg("Failed to list devices")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *AuthController) RemoveDevice(ctx context.Context, req *pb.RemoveDeviceRequest, payload *token.Payload) (*pb.RemoveDeviceResponse, error) {
+	violations := validate.ValidateRemoveDeviceRequest(req)
+	if violations != nil {
+		log.Err(helper.InvalidArgumentError(violations)).Msg("RemoveDeviceRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	metaData := shared.ExtractMetadata(ctx)
+	res, err := c.authService.RemoveDevice(ctx, req, payload, metaData)
+	if err != nil {
+		log.Err(err).Msg("Failed to remove device")
+		return nil, err
+	}
+
+	return res, nil
+}
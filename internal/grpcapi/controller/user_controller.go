@@ -7,7 +7,6 @@ import (
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/validate"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
-	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/middleware"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/service"
 	"github.com/fajaramaulana/simple_bank_project/pb"
 	"github.com/rs/zerolog/log"
@@ -47,13 +46,6 @@ func (c *UserController) UpdateUser(ctx context.Context, req *pb.UpdateUserReque
 		return nil, err
 	}
 
-	allowedRole := []string{"admin", "superadmin"}
-	err = middleware.CheckRole(payload, allowedRole)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to check role")
-		return nil, helper.UnauthenticatedError(err)
-	}
-
 	if payload.UserUUID != uuidUserReq {
 		log.Error().Msg("access denied: user uuid is not match")
 		return nil, helper.UnauthenticatedError(errors.New("access denied: user uuid is not match"))
@@ -64,7 +56,7 @@ func (c *UserController) UpdateUser(ctx context.Context, req *pb.UpdateUserReque
 		log.Error().Err(helper.InvalidArgumentError(violations)).Msg("UpdateUserRequest is invalid")
 		return nil, helper.InvalidArgumentError(violations)
 	}
-	res, err := c.userService.UpdateUser(ctx, req)
+	res, err := c.userService.UpdateUser(ctx, req, payload)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to update user")
 		return nil, err
@@ -72,3 +64,99 @@ func (c *UserController) UpdateUser(ctx context.Context, req *pb.UpdateUserReque
 
 	return res, nil
 }
+
+func (c *UserController) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	violations := validate.ValidateListUsersRequest(req)
+	if violations != nil {
+		log.Error().Err(helper.InvalidArgumentError(violations)).Msg("ListUsersRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	res, err := c.userService.ListUsers(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list users")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *UserController) GetUser(ctx context.Context, req *pb.AdminUserRequest) (*pb.GetUserResponse, error) {
+	violations := validate.ValidateAdminUserRequest(req)
+	if violations != nil {
+		log.Error().Err(helper.InvalidArgumentError(violations)).Msg("AdminUserRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	res, err := c.userService.GetUser(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *UserController) BlockUser(ctx context.Context, req *pb.AdminUserRequest, payload *token.Payload) (*pb.AdminUserResponse, error) {
+	violations := validate.ValidateAdminUserRequest(req)
+	if violations != nil {
+		log.Error().Err(helper.InvalidArgumentError(violations)).Msg("AdminUserRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	res, err := c.userService.BlockUser(ctx, req, payload)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to block user")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *UserController) UnblockUser(ctx context.Context, req *pb.AdminUserRequest, payload *token.Payload) (*pb.AdminUserResponse, error) {
+	violations := validate.ValidateAdminUserRequest(req)
+	if violations != nil {
+		log.Error().Err(helper.InvalidArgumentError(violations)).Msg("AdminUserRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	res, err := c.userService.UnblockUser(ctx, req, payload)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to unblock user")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *UserController) ChangeUserRole(ctx context.Context, req *pb.ChangeUserRoleRequest, payload *token.Payload) (*pb.AdminUserResponse, error) {
+	violations := validate.ValidateChangeUserRoleRequest(req)
+	if violations != nil {
+		log.Error().Err(helper.InvalidArgumentError(violations)).Msg("ChangeUserRoleRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	res, err := c.userService.ChangeUserRole(ctx, req, payload)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to change user role")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *UserController) ForcePasswordReset(ctx context.Context, req *pb.AdminUserRequest, payload *token.Payload) (*pb.AdminUserResponse, error) {
+	violations := validate.ValidateAdminUserRequest(req)
+	if violations != nil {
+		log.Error().Err(helper.InvalidArgumentError(violations)).Msg("AdminUserRequest is invalid")
+		return nil, helper.InvalidArgumentError(violations)
+	}
+
+	res, err := c.userService.ForcePasswordReset(ctx, req, payload)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to force password reset")
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,89 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// KeySet provides the keys an EdDSAMaker signs and verifies with. *signingkey.KeySet implements it.
+type KeySet interface {
+	// SigningKey returns the ID and the private key of the key that signs new tokens.
+	SigningKey() (string, ed25519.PrivateKey, error)
+	// PublicKey returns the public key of kid, if tokens signed with it are still accepted.
+	PublicKey(kid string) (ed25519.PublicKey, error)
+}
+
+// EdDSAMaker signs tokens as JWTs with the current key of a rotating key set. Every token names its
+// key in the kid header, so tokens signed with a retired key verify until the key expires. Services
+// that only verify tokens need nothing but the published public keys.
+type EdDSAMaker struct {
+	keys KeySet
+}
+
+// NewEdDSAMaker creates a new instance of EdDSAMaker signing with keys.
+func NewEdDSAMaker(keys KeySet) (Maker, error) {
+	if keys == nil {
+		return nil, fmt.Errorf("invalid key set, must not be nil")
+	}
+
+	return &EdDSAMaker{keys: keys}, nil
+}
+
+func (maker *EdDSAMaker) CreateToken(userUuid string, sessionID uuid.UUID, kind Kind, duration time.Duration, role string, authTime time.Time) (string, *Payload, error) {
+	payload, err := NewPayload(userUuid, sessionID, kind, duration, role, authTime)
+	if err != nil {
+		return "", payload, err
+	}
+
+	kid, privateKey, err := maker.keys.SigningKey()
+	if err != nil {
+		return "", payload, err
+	}
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, payload)
+	jwtToken.Header["kid"] = kid
+	token, err := jwtToken.SignedString(privateKey)
+	if err != nil {
+		return "", payload, err
+	}
+	return token, payload, nil
+}
+
+func (maker *EdDSAMaker) VerifyToken(token string, kind Kind, audience string) (*Payload, error) {
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, func(token *jwt.Token) (interface{}, error) {
+		_, ok := token.Method.(*jwt.SigningMethodEd25519)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		return maker.keys.PublicKey(kid)
+	})
+	if err != nil {
+		if errors.Is(err, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	err = payload.Expect(kind, audience)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 const secretKeyMinimumLength = 32
@@ -24,10 +25,10 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 	return &JWTMaker{secretKey}, nil
 }
 
-// CreateToken generates a new token for the given user UUID and duration.
+// CreateToken generates a new token of kind for the given user UUID and duration.
 // It returns the generated token as a string and any error encountered.
-func (maker *JWTMaker) CreateToken(userUuid string, duration time.Duration, role string) (string, *Payload, error) {
-	payload, err := NewPayload(userUuid, duration, role)
+func (maker *JWTMaker) CreateToken(userUuid string, sessionID uuid.UUID, kind Kind, duration time.Duration, role string, authTime time.Time) (string, *Payload, error) {
+	payload, err := NewPayload(userUuid, sessionID, kind, duration, role, authTime)
 	if err != nil {
 		return "", payload, err
 	}
@@ -45,7 +46,7 @@ func (maker *JWTMaker) CreateToken(userUuid string, duration time.Duration, role
 // VerifyToken verifies the authenticity of a JWT token and returns the payload if the token is valid.
 // It takes a token string as input and returns a pointer to the Payload struct and an error.
 // If the token is invalid or an error occurs during verification, it returns nil and the corresponding error.
-func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
+func (maker *JWTMaker) VerifyToken(token string, kind Kind, audience string) (*Payload, error) {
 	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
@@ -63,5 +64,10 @@ func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 		return nil, ErrInvalidToken
 	}
 
+	err = payload.Expect(kind, audience)
+	if err != nil {
+		return nil, err
+	}
+
 	return payload, nil
 }
@@ -0,0 +1,28 @@
+package validate
+
+import (
+	"errors"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
+	"github.com/fajaramaulana/simple_bank_project/pb"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func ValidateGetAccountBalanceRequest(req *pb.GetAccountBalanceRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := ValidateUserUUID(req.GetAccountUuid()); err != nil {
+		log.Error().Err(err).Msg("Invalid account uuid")
+		violations = append(violations, helper.FieldViolation("account_uuid", err))
+	}
+
+	if req.GetAsOf() == nil {
+		err := errors.New("is required")
+		log.Error().Err(err).Msg("Invalid as of")
+		violations = append(violations, helper.FieldViolation("as_of", err))
+	} else if err := req.GetAsOf().CheckValid(); err != nil {
+		log.Error().Err(err).Msg("Invalid as of")
+		violations = append(violations, helper.FieldViolation("as_of", err))
+	}
+
+	return violations
+}
@@ -1,6 +1,8 @@
 package validate
 
 import (
+	"fmt"
+
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
 	"github.com/fajaramaulana/simple_bank_project/pb"
 	"github.com/rs/zerolog/log"
@@ -21,6 +23,66 @@ func ValidateLoginUserRequest(req *pb.LoginUserRequest) (violations []*errdetail
 	return violations
 }
 
+func ValidateVerifyLoginMFARequest(req *pb.VerifyLoginMFARequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := helper.ValidateRequired(req.GetMfaToken()); err != nil {
+		log.Error().Err(err).Msg("Invalid mfa token")
+		violations = append(violations, helper.FieldViolation("mfa_token", err))
+	}
+
+	if err := helper.ValidateRequired(req.GetCode()); err != nil {
+		log.Error().Err(err).Msg("Invalid code")
+		violations = append(violations, helper.FieldViolation("code", err))
+	}
+
+	return violations
+}
+
+func ValidateRequestPasswordResetRequest(req *pb.RequestPasswordResetRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := helper.ValidateEmail(req.GetEmail()); err != nil {
+		log.Error().Err(err).Msg("Invalid email")
+		violations = append(violations, helper.FieldViolation("email", err))
+	}
+
+	return violations
+}
+
+func ValidateResetPasswordRequest(req *pb.ResetPasswordRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := helper.ValidateEmail(req.GetEmail()); err != nil {
+		log.Error().Err(err).Msg("Invalid email")
+		violations = append(violations, helper.FieldViolation("email", err))
+	}
+
+	if err := helper.ValidateRequired(req.GetCode()); err != nil {
+		log.Error().Err(err).Msg("Invalid code")
+		violations = append(violations, helper.FieldViolation("code", err))
+	}
+
+	if err := ValidatePassword(req.GetNewPassword()); err != nil {
+		log.Error().Err(err).Msg("Invalid new password")
+		violations = append(violations, helper.FieldViolation("new_password", err))
+	}
+
+	return violations
+}
+
+func ValidateUnlockLoginRequest(req *pb.UnlockLoginRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := helper.ValidateRequired(req.GetCode()); err != nil {
+		log.Error().Err(err).Msg("Invalid code")
+		violations = append(violations, helper.FieldViolation("code", err))
+	}
+
+	return violations
+}
+
+func ValidateConfirmDeviceRequest(req *pb.ConfirmDeviceRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := helper.ValidateRequired(req.GetCode()); err != nil {
+		log.Error().Err(err).Msg("Invalid code")
+		violations = append(violations, helper.FieldViolation("code", err))
+	}
+
+	return violations
+}
+
 func ValidateVerifyEmailUserRequest(req *pb.VerifyEmailRequest) (violations []*errdetails.BadRequest_FieldViolation) {
 	if err := helper.ValidateRequired(req.GetVerificationCode()); err != nil {
 		log.Error().Err(err).Msg("Invalid verification code")
@@ -34,3 +96,44 @@ func ValidateVerifyEmailUserRequest(req *pb.VerifyEmailRequest) (violations []*e
 
 	return violations
 }
+
+func ValidateRevokeSessionRequest(req *pb.RevokeSessionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := helper.ValidateRequired(req.GetSessionId()); err != nil {
+		log.Error().Err(err).Msg("Invalid session id")
+		violations = append(violations, helper.FieldViolation("session_id", err))
+	}
+
+	if err := helper.ValidateUUID(req.GetSessionId()); err != nil {
+		log.Error().Err(err).Msg("Invalid session id")
+		violations = append(violations, helper.FieldViolation("session_id", err))
+	}
+
+	return violations
+}
+
+func ValidateRemoveDeviceRequest(req *pb.RemoveDeviceRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := helper.ValidateRequired(req.GetDeviceUuid()); err != nil {
+		log.Error().Err(err).Msg("Invalid device uuid")
+		violations = append(violations, helper.FieldViolation("device_uuid", err))
+	}
+
+	if err := helper.ValidateUUID(req.GetDeviceUuid()); err != nil {
+		log.Error().Err(err).Msg("Invalid device uuid")
+		violations = append(violations, helper.FieldViolation("device_uuid", err))
+	}
+
+	return violations
+}
+
+func ValidateStepUpRequest(req *pb.StepUpRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetCode() != "" {
+		return violations
+	}
+
+	if err := helper.ValidateRequired(req.GetPassword()); err != nil {
+		log.Error().Err(err).Msg("Invalid password")
+		violations = append(violations, helper.FieldViolation("password", fmt.Errorf("password or code is required")))
+	}
+
+	return violations
+}
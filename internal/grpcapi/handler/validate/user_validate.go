@@ -1,6 +1,8 @@
 package validate
 
 import (
+	"errors"
+
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
 	"github.com/fajaramaulana/simple_bank_project/pb"
 	"github.com/rs/zerolog/log"
@@ -66,3 +68,51 @@ func ValidateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdeta
 
 	return violations
 }
+
+var roles = []string{"customer", "admin", "superadmin"}
+
+func ValidateListUsersRequest(req *pb.ListUsersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetPage() < 1 {
+		err := errors.New("must be at least 1")
+		log.Error().Err(err).Msg("Invalid page")
+		violations = append(violations, helper.FieldViolation("page", err))
+	}
+
+	if req.GetLimit() < 5 || req.GetLimit() > 100 {
+		err := errors.New("must be between 5 and 100")
+		log.Error().Err(err).Msg("Invalid limit")
+		violations = append(violations, helper.FieldViolation("limit", err))
+	}
+
+	if req.Role != nil {
+		if err := helper.ValidateOneOf(req.GetRole(), roles); err != nil {
+			log.Error().Err(err).Msg("Invalid role")
+			violations = append(violations, helper.FieldViolation("role", err))
+		}
+	}
+
+	return violations
+}
+
+func ValidateAdminUserRequest(req *pb.AdminUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := ValidateUserUUID(req.GetUserUuid()); err != nil {
+		log.Error().Err(err).Msg("Invalid user uuid")
+		violations = append(violations, helper.FieldViolation("user_uuid", err))
+	}
+
+	return violations
+}
+
+func ValidateChangeUserRoleRequest(req *pb.ChangeUserRoleRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := ValidateUserUUID(req.GetUserUuid()); err != nil {
+		log.Error().Err(err).Msg("Invalid user uuid")
+		violations = append(violations, helper.FieldViolation("user_uuid", err))
+	}
+
+	if err := helper.ValidateOneOf(req.GetRole(), roles); err != nil {
+		log.Error().Err(err).Msg("Invalid role")
+		violations = append(violations, helper.FieldViolation("role", err))
+	}
+
+	return violations
+}
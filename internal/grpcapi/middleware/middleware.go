@@ -2,56 +2,218 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/fajaramaulana/simple_bank_project/internal/apikey"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/shared"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/servertls"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 const (
 	AuthorizationHeaderKey  = "authorization"
 	AuthorizationTypeBearer = "bearer"
+	AuthorizationTypeAPIKey = "apikey"
 	AuthorizationPayloadKey = "authorization_payload"
 )
 
-func AuthMiddleware(ctx context.Context, tokenMaker token.Maker) (*token.Payload, error) {
+// AuthMiddleware authenticates the caller with a bearer access token or an API key. The caller is
+// only returned for API keys.
+func AuthMiddleware(ctx context.Context, tokenMaker token.Maker, apiKeys apikey.Store) (*token.Payload, *apikey.Caller, error) {
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
 
 		authorizationHeader := md.Get(AuthorizationHeaderKey)
 
 		if len(authorizationHeader) == 0 {
-			return nil, fmt.Errorf("authorization header is not provided")
+			return nil, nil, fmt.Errorf("authorization header is not provided")
 		}
 
 		authHeader := authorizationHeader[0]
 		fields := strings.Fields(authHeader)
 		if len(fields) < 2 {
-			return nil, fmt.Errorf("authorization header is not provided")
+			return nil, nil, fmt.Errorf("authorization header is not provided")
 		}
 
 		authType := strings.ToLower(fields[0])
+		if authType == AuthorizationTypeAPIKey {
+			caller, err := apikey.Authenticate(ctx, apiKeys, fields[1], shared.ExtractMetadata(ctx).ClientIP)
+			if err != nil {
+				if err != apikey.ErrInvalidKey {
+					log.Err(err).Msg("Failed to authenticate API key")
+				}
+				return nil, nil, apikey.ErrInvalidKey
+			}
+
+			// the key stands in for an access token of its user, without a login session
+			payload := &token.Payload{
+				ID:        caller.KeyUUID,
+				UserUUID:  caller.UserUUID,
+				Kind:      token.KindAccess,
+				Issuer:    token.Issuer,
+				Audience:  token.AudienceAPI,
+				ExpiredAt: caller.ExpiresAt,
+				Role:      caller.Role,
+			}
+			return payload, &caller, nil
+		}
+
 		if authType != AuthorizationTypeBearer {
-			return nil, fmt.Errorf("unsupported authorization type: %s", authType)
+			return nil, nil, fmt.Errorf("unsupported authorization type: %s", authType)
 		}
 
 		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		payload, err := tokenMaker.VerifyToken(accessToken, token.KindAccess, token.AudienceAPI)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		return payload, nil
+		return payload, nil, nil
+	}
+
+	return nil, nil, nil
+}
+
+// ServicePrincipal authenticates a caller sending no authorization header by the client
+// certificate it connected with, when principals maps the certificate to a service principal.
+// The principal acts with its role and no user.
+func ServicePrincipal(ctx context.Context, principals servertls.Principals) (*token.Payload, bool) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(AuthorizationHeaderKey)) > 0 {
+		return nil, false
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := principals.Lookup(tlsInfo.State)
+	if !ok {
+		return nil, false
 	}
 
-	return nil, nil
+	log.Debug().Str("principal", principal.Identity).Msg("Authenticated service principal")
+	return &token.Payload{
+		Kind:      token.KindAccess,
+		Issuer:    token.Issuer,
+		Audience:  token.AudienceAPI,
+		ExpiredAt: principal.ExpiresAt,
+		Role:      principal.Role,
+	}, true
 }
 
-func CheckRole(payload *token.Payload, role []string) error {
-	for _, r := range role {
-		if payload.Role == r {
-			return nil
+type payloadContextKey struct{}
+
+// Authorize authenticates the caller and checks that its role holds permission. An empty
+// permission only requires a valid access token, which must not be on the revocation list. API
+// keys need scope, ScopeRead or ScopeWrite, and permission among their scopes; an empty scope
+// refuses them. The returned context limits the permissions checked with it to the scopes of the key.
+// Service principals authenticated by their client certificate need permission, an empty
+// permission refuses them as they act for no user.
+func Authorize(ctx context.Context, tokenMaker token.Maker, apiKeys apikey.Store, revocations *revocation.List, principals servertls.Principals, authorizer *authz.Authorizer, permission, scope string) (context.Context, *token.Payload, error) {
+	if payload, ok := ServicePrincipal(ctx, principals); ok {
+		if permission == "" {
+			return nil, nil, status.Errorf(codes.PermissionDenied, "service principals cannot call this method")
+		}
+		if !authorizer.Can(ctx, payload.Role, permission) {
+			return nil, nil, status.Errorf(codes.PermissionDenied, "permission %s is required", permission)
 		}
+		return ctx, payload, nil
+	}
+
+	payload, caller, err := AuthMiddleware(ctx, tokenMaker, apiKeys)
+	if err != nil {
+		return nil, nil, helper.UnauthenticatedError(err)
+	}
+	if payload == nil {
+		return nil, nil, helper.UnauthenticatedError(fmt.Errorf("metadata is not provided"))
 	}
-	return fmt.Errorf("role is not allowed")
+
+	if caller == nil {
+		err := revocations.Check(ctx, revocation.Token{
+			ID:        payload.ID,
+			SessionID: payload.SessionID,
+			UserUUID:  payload.UserUUID,
+			IssuedAt:  payload.IssuedAt,
+			ExpiredAt: payload.ExpiredAt,
+		})
+		if err != nil {
+			if errors.Is(err, revocation.ErrRevoked) {
+				return nil, nil, helper.UnauthenticatedError(err)
+			}
+			log.Err(err).Msg("Failed to check token revocation")
+			return nil, nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	if caller != nil {
+		if scope == "" {
+			return nil, nil, status.Errorf(codes.PermissionDenied, "API keys cannot call this method")
+		}
+		if !caller.Allows(scope) {
+			return nil, nil, status.Errorf(codes.PermissionDenied, "API key does not have the %s scope", scope)
+		}
+		ctx = authz.WithScopes(ctx, caller.Scopes)
+	}
+
+	if permission != "" && !authorizer.Can(ctx, payload.Role, permission) {
+		return nil, nil, status.Errorf(codes.PermissionDenied, "permission %s is required", permission)
+	}
+
+	return ctx, payload, nil
+}
+
+// AuthInterceptor authorizes calls to the methods declared in permissions, keyed by full method
+// name, and stores the caller in the context for PayloadFromContext. Other methods are public.
+// scopes declares the scope API keys need to call a method, as for Authorize.
+func AuthInterceptor(tokenMaker token.Maker, apiKeys apikey.Store, revocations *revocation.List, principals servertls.Principals, authorizer *authz.Authorizer, permissions, scopes map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		permission, ok := permissions[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		ctx, payload, err := Authorize(ctx, tokenMaker, apiKeys, revocations, principals, authorizer, permission, scopes[info.FullMethod])
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(context.WithValue(ctx, payloadContextKey{}, payload), req)
+	}
+}
+
+// PayloadFromContext returns the caller authorized by AuthInterceptor.
+func PayloadFromContext(ctx context.Context) (*token.Payload, bool) {
+	payload, ok := ctx.Value(payloadContextKey{}).(*token.Payload)
+	return payload, ok
+}
+
+// WithAuditActor attaches the authenticated caller and its client metadata to ctx for the audit log.
+func WithAuditActor(ctx context.Context, payload *token.Payload) context.Context {
+	if payload == nil {
+		return ctx
+	}
+
+	mtdt := shared.ExtractMetadata(ctx)
+	return audit.WithActor(ctx, audit.Actor{
+		UserUUID:  payload.UserUUID,
+		Role:      payload.Role,
+		ClientIP:  mtdt.ClientIP,
+		UserAgent: mtdt.UserAgent,
+	})
 }
@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/rs/zerolog/log"
+)
+
+const snapshotCheckInterval = time.Hour
+
+// SnapshotDailyBalances keeps the daily balance snapshots up to date.
+// Every account gets one snapshot per day holding its balance at midnight UTC,
+// which GetBalanceAsOf uses as a starting point for point-in-time balance queries.
+func SnapshotDailyBalances(ctx context.Context, store db.Store) {
+	ticker := time.NewTicker(snapshotCheckInterval)
+	defer ticker.Stop()
+
+	var lastSnapshotAt time.Time
+	for {
+		// the end of the last completed day
+		snapshotAt := time.Now().UTC().Truncate(24 * time.Hour)
+		if !snapshotAt.Equal(lastSnapshotAt) {
+			rows, err := store.CreateDailyBalanceSnapshots(ctx, snapshotAt)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to create balance snapshots at %s", snapshotAt)
+			} else {
+				lastSnapshotAt = snapshotAt
+				log.Info().Msgf("Created %d balance snapshots at %s", rows, snapshotAt)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Info().Msg("Context canceled, stopping balance snapshot runner")
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/event"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	outboxPollInterval = time.Second
+	outboxBatchSize    = 100
+)
+
+// DispatchOutboxEvents publishes pending outbox events to the Redis stream and marks them sent.
+// An event is only marked sent after Redis accepted it, so a crash in between publishes it again:
+// delivery is at-least-once and consumers deduplicate on the envelope ID. Each batch is claimed in a
+// transaction, so instances running side by side publish different events.
+func DispatchOutboxEvents(ctx context.Context, store db.Store, rdb *redis.Client) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			dispatchOutboxBatch(ctx, store, rdb)
+		case <-ctx.Done():
+			log.Info().Msg("Context canceled, stopping outbox dispatcher")
+			return
+		}
+	}
+}
+
+func dispatchOutboxBatch(ctx context.Context, store db.Store, rdb *redis.Client) {
+	// publishing stops at the first failure so events keep their order, the rest is retried on the
+	// next tick
+	err := store.DispatchOutboxEventsTx(ctx, db.DispatchOutboxEventsTxParam{
+		Limit: outboxBatchSize,
+		Publish: func(e db.Outbox) error {
+			return publishOutboxEvent(ctx, rdb, e)
+		},
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to dispatch outbox events")
+	}
+}
+
+func publishOutboxEvent(ctx context.Context, rdb *redis.Client, e db.Outbox) error {
+	envelope := event.Envelope{
+		ID:            e.EventUuid,
+		Type:          event.Type(e.EventType),
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		OccurredAt:    e.CreatedAt,
+		Payload:       e.Payload,
+	}
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	return rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: event.Stream,
+		Values: map[string]interface{}{
+			"event_id":   envelope.ID.String(),
+			"event_type": string(envelope.Type),
+			"envelope":   string(data),
+		},
+	}).Err()
+}
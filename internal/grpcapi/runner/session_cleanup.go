@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	sessionCleanupInterval  = time.Hour
+	defaultSessionRetention = 30 * 24 * time.Hour
+)
+
+// PurgeExpiredSessions deletes sessions that expired longer than retention ago. The sessions a
+// refresh replaced are kept as long as their family is, so the login time of a session stays known.
+// Recently expired sessions are kept for the session history shown with the user details.
+func PurgeExpiredSessions(ctx context.Context, store db.Store, retention time.Duration) {
+	if retention <= 0 {
+		retention = defaultSessionRetention
+	}
+
+	ticker := time.NewTicker(sessionCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		expiredBefore := time.Now().Add(-retention)
+		rows, err := store.PurgeExpiredSessions(ctx, expiredBefore)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to purge sessions expired before %s", expiredBefore)
+		} else if rows > 0 {
+			log.Info().Msgf("Purged %d sessions expired before %s", rows, expiredBefore)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Info().Msg("Context canceled, stopping session cleanup runner")
+			return
+		}
+	}
+}
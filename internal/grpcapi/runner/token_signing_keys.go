@@ -0,0 +1,29 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/signingkey"
+	"github.com/rs/zerolog/log"
+)
+
+// RefreshTokenSigningKeys reloads the token signing keys and rotates them when they are due.
+// Keys rotated by another instance are picked up on the next refresh, before they start to sign.
+func RefreshTokenSigningKeys(ctx context.Context, rotator *signingkey.Rotator) {
+	ticker := time.NewTicker(signingkey.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Info().Msg("Context canceled, stopping token signing key runner")
+			return
+		}
+
+		if err := rotator.Refresh(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to refresh token signing keys")
+		}
+	}
+}
@@ -3,9 +3,11 @@ package runner
 import (
 	"context"
 	"errors"
-
+	"fmt"
+	"html"
 	"time"
 
+	"github.com/fajaramaulana/simple_bank_project/internal/email"
 	"github.com/fajaramaulana/simple_bank_project/util"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
@@ -43,15 +45,65 @@ func sendEmailWithRetry(m *gomail.Message, d *gomail.Dialer) error {
 	return errors.New("failed to send email after maximum retries")
 }
 
-// SendVerificationEmails scans for verification email keys and sends emails at a controlled rate.
-func SendVerificationEmails(ctx context.Context, rdb *redis.Client, config util.Config) {
+// mailTemplate describes a kind of email queued by the email package. The value of codeField is
+// filled into body.
+type mailTemplate struct {
+	name      string
+	prefix    string
+	codeField string
+	subject   string
+	body      string
+}
+
+var mailTemplates = []mailTemplate{
+	{
+		name:      "verification",
+		prefix:    email.VerificationKeyPrefix,
+		codeField: "verification_code",
+		subject:   "Verification Code Simplebank",
+		body:      "Hello, this is your verification code: %s",
+	},
+	{
+		name:      "password reset",
+		prefix:    email.PasswordResetKeyPrefix,
+		codeField: "reset_code",
+		subject:   "Password Reset Code Simplebank",
+		body:      "Hello, this is your password reset code: %s<br>If you did not ask to reset your password, you can ignore this email.",
+	},
+	{
+		name:      "account unlock",
+		prefix:    email.AccountUnlockKeyPrefix,
+		codeField: "unlock_code",
+		subject:   "Sign-in Locked Simplebank",
+		body:      "Hello, sign-in to your account was locked after too many failed attempts. It unlocks by itself after a while, or right away with this unlock code: %s<br>If these attempts were not yours, change your password after unlocking.",
+	},
+	{
+		name:      "device confirmation",
+		prefix:    email.DeviceConfirmationKeyPrefix,
+		codeField: "confirmation_code",
+		subject:   "Confirm New Device Simplebank",
+		body:      "Hello, someone signed in to your account from a device it was not used from before. If it was you, confirm the device with this code: %s<br>If it was not you, change your password.",
+	},
+	{
+		name:      "new device alert",
+		prefix:    email.NewDeviceAlertKeyPrefix,
+		codeField: "device",
+		subject:   "New Sign-in Simplebank",
+		body:      "Hello, your account was signed in to from a device or network it was not used from before: %s<br>If it was not you, change your password and remove the device from your trusted devices.",
+	},
+}
+
+// SendEmails scans for queued email keys and sends the emails at a controlled rate.
+func SendEmails(ctx context.Context, rdb *redis.Client, config util.Config) {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			processEmails(ctx, rdb, config)
+			for _, template := range mailTemplates {
+				processEmails(ctx, rdb, config, template)
+			}
 		case <-ctx.Done():
 			log.Info().Msg("Context canceled, stopping email sender")
 			return
@@ -59,15 +111,15 @@ func SendVerificationEmails(ctx context.Context, rdb *redis.Client, config util.
 	}
 }
 
-func processEmails(ctx context.Context, rdb *redis.Client, config util.Config) {
+func processEmails(ctx context.Context, rdb *redis.Client, config util.Config, template mailTemplate) {
 	cursor := uint64(0)
-	prefix := "verification_email:"
 	limiter := rate.NewLimiter(rate.Every(rateLimitInterval/emailsPerMinute), emailsPerMinute) // Rate limiter
 	for {
 		// Scan for keys with the specified prefix
-		keys, nextCursor, err := rdb.Scan(ctx, cursor, prefix+"*", 0).Result()
+		keys, nextCursor, err := rdb.Scan(ctx, cursor, template.prefix+"*", 0).Result()
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to scan keys")
+			log.Error().Err(err).Msg("Failed to scan keys")
+			return
 		}
 
 		// Process each key
@@ -76,7 +128,7 @@ func processEmails(ctx context.Context, rdb *redis.Client, config util.Config) {
 				log.Error().Err(err).Msg("Rate limiter error")
 				continue
 			}
-			processKey(ctx, key, rdb, config)
+			processKey(ctx, key, rdb, config, template)
 		}
 
 		// If nextCursor is 0, we have finished scanning
@@ -87,7 +139,7 @@ func processEmails(ctx context.Context, rdb *redis.Client, config util.Config) {
 	}
 }
 
-func processKey(ctx context.Context, key string, rdb *redis.Client, config util.Config) {
+func processKey(ctx context.Context, key string, rdb *redis.Client, config util.Config, template mailTemplate) {
 	// Retrieve the hash data
 	fields, err := rdb.HGetAll(ctx, key).Result()
 	if err != nil {
@@ -95,27 +147,27 @@ func processKey(ctx context.Context, key string, rdb *redis.Client, config util.
 		return
 	}
 
-	// Log the retrieved fields
-
 	// Extract specific fields
-	email, emailExists := fields["email"]
-	verificationCode, codeExists := fields["verification_code"]
+	address, emailExists := fields["email"]
+	code, codeExists := fields[template.codeField]
 
+	// the key can expire between the scan and reading it
 	if !emailExists {
 		log.Error().Msgf("Email for key %s not found", key)
+		return
 	}
 
 	if !codeExists {
-		log.Error().Msgf("Verification code for key %s not found", key)
+		log.Error().Msgf("Code for key %s not found", key)
+		return
 	}
 
-	// todo sendemail
 	mailer := gomail.NewMessage()
 	mailer.SetHeader("From", "simplebank@fajaramaulanadev.com")
-	mailer.SetHeader("To", email)
-	// mailer.SetAddressHeader("Cc", "tralalala@gmail.com", "Tra Lala La")
-	mailer.SetHeader("Subject", "Verification Code Simplebank")
-	mailer.SetBody("text/html", "Hello, this is your verification code: "+verificationCode)
+	mailer.SetHeader("To", address)
+	mailer.SetHeader("Subject", template.subject)
+	// the alerts carry the user agent of the client, which must not inject html
+	mailer.SetBody("text/html", fmt.Sprintf(template.body, html.EscapeString(code)))
 
 	dialer := gomail.NewDialer(
 		config.MailHost, config.MailPort, config.MailUser, config.MailPassword,
@@ -124,7 +176,8 @@ func processKey(ctx context.Context, key string, rdb *redis.Client, config util.
 		log.Error().Err(err).Msg("Failed to send email")
 	}
 
-	log.Info().Msgf("Sending email to %s with verification code %s", email, verificationCode)
+	// the code itself is a credential and is never logged
+	log.Info().Msgf("Sending %s email to %s", template.name, address)
 
 	// Delete the key after processing
 	if err := rdb.Del(ctx, key).Err(); err != nil {
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/webhook"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	webhookPollInterval = 5 * time.Second
+	webhookBatchSize    = 50
+)
+
+// DeliverWebhooks sends due webhook deliveries to their subscribers.
+// A failed delivery is retried with exponential backoff and moved to the dead-letter state
+// after webhook.MaxAttempts failed attempts; it can then only be retried by a manual redeliver.
+func DeliverWebhooks(ctx context.Context, store db.Store, sender *webhook.Sender) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deliverWebhookBatch(ctx, store, sender)
+		case <-ctx.Done():
+			log.Info().Msg("Context canceled, stopping webhook delivery")
+			return
+		}
+	}
+}
+
+func deliverWebhookBatch(ctx context.Context, store db.Store, sender *webhook.Sender) {
+	deliveries, err := store.ListDueWebhookDeliveries(ctx, webhookBatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list due webhook deliveries")
+		return
+	}
+
+	for _, d := range deliveries {
+		statusCode, err := sender.Send(ctx, webhook.Delivery{
+			ID:        d.DeliveryUuid,
+			EventType: d.EventType,
+			URL:       d.Url,
+			Secret:    d.Secret,
+			Payload:   d.Payload,
+		})
+
+		lastStatusCode := pgtype.Int4{Int32: int32(statusCode), Valid: statusCode != 0}
+
+		if err == nil {
+			_, err = store.MarkWebhookDeliverySucceeded(ctx, db.MarkWebhookDeliverySucceededParams{
+				ID:             d.ID,
+				LastStatusCode: lastStatusCode,
+			})
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to mark webhook delivery %s as succeeded", d.DeliveryUuid)
+			}
+			continue
+		}
+
+		attempts := int(d.Attempts) + 1
+		status := webhook.StatusPending
+		if attempts >= webhook.MaxAttempts {
+			status = webhook.StatusDead
+		}
+
+		log.Error().Err(err).Msgf("Webhook delivery %s failed (attempt %d, status %s)", d.DeliveryUuid, attempts, status)
+
+		_, err = store.MarkWebhookDeliveryFailed(ctx, db.MarkWebhookDeliveryFailedParams{
+			ID:             d.ID,
+			Status:         status,
+			NextAttemptAt:  time.Now().Add(webhook.NextRetryDelay(attempts)),
+			LastStatusCode: lastStatusCode,
+			LastError:      pgtype.Text{String: err.Error(), Valid: true},
+		})
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to record webhook delivery %s failure", d.DeliveryUuid)
+		}
+	}
+}
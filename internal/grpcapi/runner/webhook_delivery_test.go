@@ -0,0 +1,100 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/webhook"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeliverWebhookBatch(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statusCode int
+		attempts   int32
+		buildStubs func(store *mockdb.MockStore, delivery db.ListDueWebhookDeliveriesRow)
+	}{
+		{
+			name:       "Succeeded",
+			statusCode: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore, delivery db.ListDueWebhookDeliveriesRow) {
+				store.EXPECT().MarkWebhookDeliverySucceeded(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.MarkWebhookDeliverySucceededParams) (int64, error) {
+						require.Equal(t, delivery.ID, arg.ID)
+						require.Equal(t, int32(http.StatusOK), arg.LastStatusCode.Int32)
+						return 1, nil
+					})
+				store.EXPECT().MarkWebhookDeliveryFailed(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:       "Retry",
+			statusCode: http.StatusInternalServerError,
+			attempts:   1,
+			buildStubs: func(store *mockdb.MockStore, delivery db.ListDueWebhookDeliveriesRow) {
+				store.EXPECT().MarkWebhookDeliveryFailed(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.MarkWebhookDeliveryFailedParams) (int64, error) {
+						require.Equal(t, delivery.ID, arg.ID)
+						require.Equal(t, webhook.StatusPending, arg.Status)
+						require.WithinDuration(t, time.Now().Add(webhook.NextRetryDelay(2)), arg.NextAttemptAt, time.Second)
+						require.Equal(t, int32(http.StatusInternalServerError), arg.LastStatusCode.Int32)
+						require.True(t, arg.LastError.Valid)
+						return 1, nil
+					})
+				store.EXPECT().MarkWebhookDeliverySucceeded(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:       "DeadLetter",
+			statusCode: http.StatusGone,
+			attempts:   webhook.MaxAttempts - 1,
+			buildStubs: func(store *mockdb.MockStore, delivery db.ListDueWebhookDeliveriesRow) {
+				store.EXPECT().MarkWebhookDeliveryFailed(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.MarkWebhookDeliveryFailedParams) (int64, error) {
+						require.Equal(t, webhook.StatusDead, arg.Status)
+						return 1, nil
+					})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			received := 0
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received++
+				require.NotEmpty(t, r.Header.Get(webhook.HeaderSignature))
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer receiver.Close()
+
+			delivery := db.ListDueWebhookDeliveriesRow{
+				ID:           1,
+				DeliveryUuid: uuid.New(),
+				EventType:    "TransferCompleted",
+				Payload:      []byte(`{"type":"TransferCompleted"}`),
+				Attempts:     tc.attempts,
+				Url:          receiver.URL,
+				Secret:       "whsec_test",
+			}
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ListDueWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.ListDueWebhookDeliveriesRow{delivery}, nil)
+			tc.buildStubs(store, delivery)
+
+			deliverWebhookBatch(context.Background(), store, webhook.NewSender(receiver.Client()))
+			require.Equal(t, 1, received)
+		})
+	}
+}
@@ -112,6 +112,11 @@ func (s *Seeder) Seed() {
 			if err != nil {
 				log.Fatal().Err(err).Msg("Cannot insert accounts")
 			}
+
+			_, err = s.conn.Exec(context.Background(), "INSERT INTO account_members (account_id, user_uuid, role, permission) SELECT id, user_uuid, 'owner', 'transfer' FROM accounts WHERE account_uuid = $1", v["account_uuid"])
+			if err != nil {
+				log.Fatal().Err(err).Msg("Cannot insert account owners")
+			}
 		}
 	}
 }
@@ -10,21 +10,23 @@ import (
 
 // Implement gRPC methods using the controllers
 func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserRespose, error) {
-	// s.tokenMaker
-	payload, err := middleware.AuthMiddleware(ctx, s.tokenMaker)
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_CreateUser_FullMethodName)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to authenticate")
+		log.Error().Err(err).Msg("Failed to authorize")
 		return nil, err
 	}
+
+	ctx = middleware.WithAuditActor(ctx, payload)
 	return s.userController.CreateUser(ctx, req, payload)
 }
 func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	payload, err := middleware.AuthMiddleware(ctx, s.tokenMaker)
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_UpdateUser_FullMethodName)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to authenticate")
+		log.Error().Err(err).Msg("Failed to authorize")
 		return nil, err
 	}
 
+	ctx = middleware.WithAuditActor(ctx, payload)
 	return s.userController.UpdateUser(ctx, req, payload)
 }
 
@@ -32,6 +34,180 @@ func (s *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.L
 	return s.authController.LoginUser(ctx, req)
 }
 
+func (s *Server) VerifyLoginMFA(ctx context.Context, req *pb.VerifyLoginMFARequest) (*pb.LoginUserResponse, error) {
+	return s.authController.VerifyLoginMFA(ctx, req)
+}
+
+func (s *Server) ConfirmDevice(ctx context.Context, req *pb.ConfirmDeviceRequest) (*pb.LoginUserResponse, error) {
+	return s.authController.ConfirmDevice(ctx, req)
+}
+
+func (s *Server) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	return s.authController.RequestPasswordReset(ctx, req)
+}
+
+func (s *Server) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	return s.authController.ResetPassword(ctx, req)
+}
+
+func (s *Server) UnlockLogin(ctx context.Context, req *pb.UnlockLoginRequest) (*pb.UnlockLoginResponse, error) {
+	return s.authController.UnlockLogin(ctx, req)
+}
+
 func (s *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
 	return s.authController.VerifyEmail(ctx, req)
 }
+
+func (s *Server) ResendVerificationEmail(ctx context.Context, req *pb.ResendVerificationEmailRequest) (*pb.ResendVerificationEmailResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_ResendVerificationEmail_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.authController.ResendVerificationEmail(ctx, payload)
+}
+
+func (s *Server) GetAccountBalance(ctx context.Context, req *pb.GetAccountBalanceRequest) (*pb.GetAccountBalanceResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_GetAccountBalance_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.accountController.GetAccountBalance(ctx, req, payload)
+}
+
+func (s *Server) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	ctx, _, err := s.authorize(ctx, pb.SimpleBank_ListUsers_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.userController.ListUsers(ctx, req)
+}
+
+func (s *Server) GetUser(ctx context.Context, req *pb.AdminUserRequest) (*pb.GetUserResponse, error) {
+	ctx, _, err := s.authorize(ctx, pb.SimpleBank_GetUser_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.userController.GetUser(ctx, req)
+}
+
+func (s *Server) BlockUser(ctx context.Context, req *pb.AdminUserRequest) (*pb.AdminUserResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_BlockUser_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	ctx = middleware.WithAuditActor(ctx, payload)
+	return s.userController.BlockUser(ctx, req, payload)
+}
+
+func (s *Server) UnblockUser(ctx context.Context, req *pb.AdminUserRequest) (*pb.AdminUserResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_UnblockUser_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	ctx = middleware.WithAuditActor(ctx, payload)
+	return s.userController.UnblockUser(ctx, req, payload)
+}
+
+func (s *Server) ChangeUserRole(ctx context.Context, req *pb.ChangeUserRoleRequest) (*pb.AdminUserResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_ChangeUserRole_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	ctx = middleware.WithAuditActor(ctx, payload)
+	return s.userController.ChangeUserRole(ctx, req, payload)
+}
+
+func (s *Server) ForcePasswordReset(ctx context.Context, req *pb.AdminUserRequest) (*pb.AdminUserResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_ForcePasswordReset_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	ctx = middleware.WithAuditActor(ctx, payload)
+	return s.userController.ForcePasswordReset(ctx, req, payload)
+}
+
+func (s *Server) StepUp(ctx context.Context, req *pb.StepUpRequest) (*pb.StepUpResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_StepUp_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.authController.StepUp(ctx, req, payload)
+}
+
+func (s *Server) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_Logout_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.authController.Logout(ctx, payload)
+}
+
+func (s *Server) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_ListSessions_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.authController.ListSessions(ctx, payload)
+}
+
+func (s *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionsResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_RevokeSession_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.authController.RevokeSession(ctx, req, payload)
+}
+
+func (s *Server) RevokeOtherSessions(ctx context.Context, req *pb.RevokeOtherSessionsRequest) (*pb.RevokeSessionsResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_RevokeOtherSessions_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.authController.RevokeOtherSessions(ctx, payload)
+}
+
+func (s *Server) ListDevices(ctx context.Context, req *pb.ListDevicesRequest) (*pb.ListDevicesResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_ListDevices_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.authController.ListDevices(ctx, payload)
+}
+
+func (s *Server) RemoveDevice(ctx context.Context, req *pb.RemoveDeviceRequest) (*pb.RemoveDeviceResponse, error) {
+	ctx, payload, err := s.authorize(ctx, pb.SimpleBank_RemoveDevice_FullMethodName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authorize")
+		return nil, err
+	}
+
+	return s.authController.RemoveDevice(ctx, req, payload)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/apikey"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/middleware"
+	"github.com/fajaramaulana/simple_bank_project/pb"
+)
+
+// methodPermissions declares the permission each authenticated method requires. An empty
+// permission only requires a valid access token. Methods that are not listed are public.
+var methodPermissions = map[string]string{
+	pb.SimpleBank_CreateUser_FullMethodName:              "",
+	pb.SimpleBank_UpdateUser_FullMethodName:              authz.UsersUpdateSelf,
+	pb.SimpleBank_GetAccountBalance_FullMethodName:       "",
+	pb.SimpleBank_ListUsers_FullMethodName:               authz.UsersRead,
+	pb.SimpleBank_GetUser_FullMethodName:                 authz.UsersRead,
+	pb.SimpleBank_BlockUser_FullMethodName:               authz.UsersManage,
+	pb.SimpleBank_UnblockUser_FullMethodName:             authz.UsersManage,
+	pb.SimpleBank_ChangeUserRole_FullMethodName:          authz.UsersManage,
+	pb.SimpleBank_ForcePasswordReset_FullMethodName:      authz.UsersManage,
+	pb.SimpleBank_ResendVerificationEmail_FullMethodName: "",
+	pb.SimpleBank_StepUp_FullMethodName:                  "",
+	pb.SimpleBank_Logout_FullMethodName:                  "",
+	pb.SimpleBank_ListSessions_FullMethodName:            "",
+	pb.SimpleBank_RevokeSession_FullMethodName:           "",
+	pb.SimpleBank_RevokeOtherSessions_FullMethodName:     "",
+	pb.SimpleBank_ListDevices_FullMethodName:             "",
+	pb.SimpleBank_RemoveDevice_FullMethodName:            "",
+}
+
+// methodScopes declares the scope an API key needs to call each method. Methods that are not listed
+// cannot be called with an API key, like the ones managing login sessions.
+var methodScopes = map[string]string{
+	pb.SimpleBank_CreateUser_FullMethodName:         apikey.ScopeWrite,
+	pb.SimpleBank_UpdateUser_FullMethodName:         apikey.ScopeWrite,
+	pb.SimpleBank_GetAccountBalance_FullMethodName:  apikey.ScopeRead,
+	pb.SimpleBank_ListUsers_FullMethodName:          apikey.ScopeRead,
+	pb.SimpleBank_GetUser_FullMethodName:            apikey.ScopeRead,
+	pb.SimpleBank_BlockUser_FullMethodName:          apikey.ScopeWrite,
+	pb.SimpleBank_UnblockUser_FullMethodName:        apikey.ScopeWrite,
+	pb.SimpleBank_ChangeUserRole_FullMethodName:     apikey.ScopeWrite,
+	pb.SimpleBank_ForcePasswordReset_FullMethodName: apikey.ScopeWrite,
+}
+
+// authorize returns the caller of method and the context to call it with. Calls through the gRPC server were already authorized
+// by the interceptor; calls from the in-process gateway bypass interceptors and are authorized here.
+func (s *Server) authorize(ctx context.Context, method string) (context.Context, *token.Payload, error) {
+	if payload, ok := middleware.PayloadFromContext(ctx); ok {
+		return ctx, payload, nil
+	}
+
+	return middleware.Authorize(ctx, s.tokenMaker, s.apiKeys, s.revocations, s.principals, s.authorizer, methodPermissions[method], methodScopes[method])
+}
@@ -5,43 +5,65 @@ import (
 	"net"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/apikey"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/controller"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/logger"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/middleware"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/servertls"
+	"github.com/fajaramaulana/simple_bank_project/internal/signingkey"
 	"github.com/fajaramaulana/simple_bank_project/pb"
 	"github.com/fajaramaulana/simple_bank_project/util"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
 type Server struct {
 	pb.UnimplementedSimpleBankServer
 	// grpcServer     *grpc.Server
-	config         util.Config
-	authController *controller.AuthController
-	userController *controller.UserController
-	tokenMaker     token.Maker
+	config            util.Config
+	authController    *controller.AuthController
+	userController    *controller.UserController
+	accountController *controller.AccountController
+	tokenMaker        token.Maker
+	authorizer        *authz.Authorizer
+	apiKeys           apikey.Store
+	revocations       *revocation.List
+	principals        servertls.Principals
 }
 
-func NewServer(store db.Store, authController *controller.AuthController, userController *controller.UserController, config util.Config) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+func NewServer(store db.Store, authController *controller.AuthController, userController *controller.UserController, accountController *controller.AccountController, authorizer *authz.Authorizer, config util.Config, signingKeys *signingkey.KeySet, revocations *revocation.List) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config.TokenSigningAlgorithm, config.TokenSymmetricKey, signingKeys)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create token maker")
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	principals, err := servertls.ParsePrincipals(config.GRPCClientPrincipals)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse client principals: %w", err)
+	}
+
 	server := &Server{
 		// grpcServer:     grpcServer,
-		config:         config,
-		authController: authController,
-		userController: userController,
-		tokenMaker:     tokenMaker,
+		config:            config,
+		authController:    authController,
+		userController:    userController,
+		accountController: accountController,
+		tokenMaker:        tokenMaker,
+		authorizer:        authorizer,
+		apiKeys:           store,
+		revocations:       revocations,
+		principals:        principals,
 	}
 	return server, nil
 }
 
-// Start runs the gRPC server on the specified port.
+// Start runs the gRPC server on the specified port, over TLS when a certificate is configured.
 func (s *Server) Start(port string) {
 	listener, err := net.Listen("tcp", ":"+port)
 	if err != nil {
@@ -49,8 +71,27 @@ func (s *Server) Start(port string) {
 	}
 	log.Info().Msgf("Start gRPC server at port: %s", port)
 
-	grpcLogger := grpc.UnaryInterceptor(logger.GrpcLogger)
-	grpcServer := grpc.NewServer(grpcLogger)
+	interceptors := grpc.ChainUnaryInterceptor(
+		logger.GrpcLogger,
+		middleware.AuthInterceptor(s.tokenMaker, s.apiKeys, s.revocations, s.principals, s.authorizer, methodPermissions, methodScopes),
+	)
+	options := []grpc.ServerOption{interceptors}
+	if s.config.GRPCTLSCertFile != "" {
+		certs, err := servertls.New(servertls.Config{
+			CertFile:          s.config.GRPCTLSCertFile,
+			KeyFile:           s.config.GRPCTLSKeyFile,
+			ClientCAFile:      s.config.GRPCClientCAFile,
+			RequireClientCert: s.config.GRPCClientCertRequired,
+			ReloadInterval:    s.config.TLSReloadInterval,
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("Cannot load TLS certificate")
+		}
+		options = append(options, grpc.Creds(credentials.NewTLS(certs.ServerConfig())))
+	} else if s.config.GRPCClientCAFile != "" {
+		log.Fatal().Msg("Client certificates need a TLS certificate")
+	}
+	grpcServer := grpc.NewServer(options...)
 	pb.RegisterSimpleBankServer(grpcServer, s)
 	reflection.Register(grpcServer)
 
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
+	"github.com/fajaramaulana/simple_bank_project/pb"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type AccountService struct {
+	db         db.Store
+	config     util.Config
+	authorizer *authz.Authorizer
+}
+
+func NewAccountService(db db.Store, config util.Config, authorizer *authz.Authorizer) *AccountService {
+	return &AccountService{db: db, config: config, authorizer: authorizer}
+}
+
+// GetAccountBalance returns the balance an account had at the requested point in time.
+// Only the members of the account or a role with accounts:read:any can query it.
+func (s *AccountService) GetAccountBalance(ctx context.Context, req *pb.GetAccountBalanceRequest, payload *token.Payload) (*pb.GetAccountBalanceResponse, error) {
+	accountUUID, err := helper.ConvertStringToUUID(req.GetAccountUuid())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account_uuid: %v", err)
+	}
+
+	asOf := req.GetAsOf().AsTime()
+	if asOf.After(time.Now()) {
+		return nil, status.Error(codes.InvalidArgument, "as_of must not be in the future")
+	}
+
+	account, err := s.db.GetAccountByUUID(ctx, accountUUID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account by account_uuid: %v", err)
+	}
+
+	if !s.authorizer.Can(ctx, payload.Role, authz.AccountsReadAny) {
+		// pockets share the members of their parent account
+		accountID := account.ID
+		if account.ParentAccountID.Valid {
+			accountID = account.ParentAccountID.Int64
+		}
+
+		_, err := s.db.GetAccountMember(ctx, db.GetAccountMemberParams{
+			AccountID: accountID,
+			UserUuid:  payload.UserUUID,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return nil, status.Errorf(codes.PermissionDenied, "account does not belong to the user")
+			}
+			return nil, status.Errorf(codes.Internal, "failed to get account member: %v", err)
+		}
+	}
+
+	balance, err := s.db.GetBalanceAsOf(ctx, account.ID, asOf)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get balance as of %s: %v", asOf, err)
+	}
+
+	res := &pb.GetAccountBalanceResponse{
+		AccountUuid: account.AccountUuid.String(),
+		Currency:    account.Currency,
+		Balance:     helper.NumericToString(balance.Balance),
+		AsOf:        timestamppb.New(balance.AsOf),
+	}
+
+	return res, nil
+}
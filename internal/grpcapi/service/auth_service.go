@@ -2,33 +2,68 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"time"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	"github.com/fajaramaulana/simple_bank_project/internal/email"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
+	grpctoken "github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/shared"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/mfa"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordreset"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/stepup"
 	"github.com/fajaramaulana/simple_bank_project/pb"
 	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// mfaChallengeDuration is how long the user has to enter the two-factor code after the password was checked.
+const mfaChallengeDuration = 5 * time.Minute
+
 type AuthService struct {
-	db     db.Store
-	config util.Config
+	db          db.Store
+	config      util.Config
+	maker       token.Maker
+	emails      email.Queue
+	guard       *lockout.Guard
+	policy      *passwordpolicy.Policy
+	hasher      *passwordhash.Hasher
+	verifier    *emailverification.Verifier
+	stepUp      *stepup.Authenticator
+	devices     *device.Registry
+	revocations *revocation.List
+	resets      *passwordreset.Limiter
 }
 
-func NewAuthService(db db.Store, config util.Config) *AuthService {
-	return &AuthService{db: db, config: config}
+func NewAuthService(db db.Store, config util.Config, maker token.Maker, emails email.Queue, guard *lockout.Guard, policy *passwordpolicy.Policy, hasher *passwordhash.Hasher, verifier *emailverification.Verifier, stepUp *stepup.Authenticator, devices *device.Registry, revocations *revocation.List, resets *passwordreset.Limiter) *AuthService {
+	return &AuthService{db: db, config: config, maker: maker, emails: emails, guard: guard, policy: policy, hasher: hasher, verifier: verifier, stepUp: stepUp, devices: devices, revocations: revocations, resets: resets}
 }
 
 func (s *AuthService) LoginUser(ctx context.Context, req *pb.LoginUserRequest, metaData *shared.Metadata) (*pb.LoginUserResponse, error) {
+	attempt := lockout.Attempt{Username: req.GetUsername(), ClientIP: metaData.ClientIP, UserAgent: metaData.UserAgent}
+	if err := s.guard.Check(ctx, attempt); err != nil {
+		return nil, lockedStatus(err)
+	}
+
 	detailLogin, err := s.db.GetDetailLoginByUsername(ctx, req.GetUsername())
 	if err != nil {
 		if err == pgx.ErrNoRows {
+			s.loginFailed(ctx, attempt, nil)
 			// return nil, ErrUserNotFound
 			return nil, status.Errorf(codes.NotFound, "user not found")
 		}
@@ -36,24 +71,182 @@ func (s *AuthService) LoginUser(ctx context.Context, req *pb.LoginUserRequest, m
 	}
 
 	// check password
-	err = util.CheckPasswordBcrypt(req.GetPassword(), detailLogin.HashedPassword)
+	rehash, err := s.hasher.Verify(req.GetPassword(), detailLogin.HashedPassword)
 	if err != nil {
+		s.loginFailed(ctx, attempt, &lockout.User{UUID: detailLogin.UserUuid, Email: detailLogin.Email})
 		return nil, status.Error(codes.InvalidArgument, "invalid password")
 	}
 
-	// generate token with paseto
-	maker, err := token.NewPasetoMaker(s.config.TokenSymmetricKey)
+	if detailLogin.IsBlocked {
+		return nil, status.Error(codes.PermissionDenied, "user is blocked")
+	}
+	if detailLogin.PasswordResetRequired {
+		return nil, status.Error(codes.PermissionDenied, "password reset required")
+	}
+	if rehash {
+		s.rehashPassword(ctx, detailLogin.UserUuid, req.GetPassword(), detailLogin.HashedPassword)
+	}
+
+	maker := s.maker
+
+	challenge, err := s.mfaChallenge(ctx, maker, detailLogin.UserUuid, detailLogin.Role)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		// the failed logins are only cleared once the code is verified too, or a new login
+		// would reset the count of wrong codes
+		return challenge, nil
+	}
+
+	if err := s.guard.Succeed(ctx, attempt); err != nil {
+		log.Err(err).Msg("Cannot clear failed logins")
+	}
+
+	return s.completeLogin(ctx, maker, detailLogin.UserUuid, detailLogin.Role, &pb.User{
+		UserUuid:      detailLogin.UserUuid.String(),
+		Username:      detailLogin.Username,
+		FullName:      detailLogin.FullName,
+		Email:         detailLogin.Email,
+		CreatedAt:     &timestamppb.Timestamp{Seconds: time.Now().Unix()},
+		EmailVerified: !detailLogin.VerifiedEmailAt.IsZero(),
+	}, metaData)
+}
+
+// rehashPassword replaces an outdated hash of the password the user just signed in with. The
+// login succeeds with the old hash anyway, so an error is only logged.
+func (s *AuthService) rehashPassword(ctx context.Context, userUUID uuid.UUID, password, oldHash string) {
+	newHash, err := s.hasher.Hash(password)
+	if err == nil {
+		_, err = s.db.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+			NewHashedPassword: newHash,
+			UserUuid:          userUUID,
+			OldHashedPassword: oldHash,
+		})
+	}
+	if err != nil {
+		log.Err(err).Msg("Cannot rehash password")
+	}
+}
+
+// VerifyLoginMFA completes a login that requires two-factor authentication. Enrolling is only
+// possible through the HTTP API, which returns the secret and the recovery codes. Wrong codes count
+// as failed logins of the user, and the MFA token is revoked once they lock the user out.
+func (s *AuthService) VerifyLoginMFA(ctx context.Context, req *pb.VerifyLoginMFARequest, metaData *shared.Metadata) (*pb.LoginUserResponse, error) {
+	maker := s.maker
+
+	payload, err := maker.VerifyToken(req.GetMfaToken(), token.KindMFA, token.AudienceMFA)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid mfa token")
+	}
+	err = s.revocations.Check(ctx, revocation.Token{
+		ID:        payload.ID,
+		SessionID: payload.SessionID,
+		UserUUID:  payload.UserUUID,
+		IssuedAt:  payload.IssuedAt,
+		ExpiredAt: payload.ExpiredAt,
+	})
+	if errors.Is(err, revocation.ErrRevoked) {
+		return nil, status.Error(codes.Unauthenticated, "invalid mfa token")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	user, err := s.db.GetUserByUserUUID(ctx, payload.UserUUID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if user.IsBlocked {
+		return nil, status.Error(codes.PermissionDenied, "user is blocked")
+	}
+	if user.PasswordResetRequired {
+		return nil, status.Error(codes.PermissionDenied, "password reset required")
+	}
+
+	attempt := lockout.Attempt{Username: user.Username, ClientIP: metaData.ClientIP, UserAgent: metaData.UserAgent}
+	if err := s.guard.Check(ctx, attempt); err != nil {
+		return nil, lockedStatus(err)
+	}
+
+	totp, err := s.db.GetUserTOTP(ctx, user.UserUuid)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if err == pgx.ErrNoRows || !totp.ConfirmedAt.Valid {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is not enrolled, enroll through the HTTP API")
+	}
+
+	err = mfa.VerifyCode(ctx, s.db, totp, req.GetCode())
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "cannot create token maker: %v", err)
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			s.mfaFailed(ctx, attempt, user, payload)
+			return nil, status.Error(codes.Unauthenticated, "invalid two-factor code")
+		}
+		return nil, status.Error(codes.Internal, "internal error")
 	}
+
+	if err := s.guard.Succeed(ctx, attempt); err != nil {
+		log.Err(err).Msg("Cannot clear failed logins")
+	}
+
+	return s.completeLogin(ctx, maker, user.UserUuid, user.Role, &pb.User{
+		UserUuid:      user.UserUuid.String(),
+		Username:      user.Username,
+		FullName:      user.FullName,
+		Email:         user.Email,
+		CreatedAt:     timestamppb.New(user.CreatedAt),
+		EmailVerified: !user.VerifiedEmailAt.IsZero(),
+	}, metaData)
+}
+
+// mfaChallenge returns the response asking for a two-factor code, or nil when the password is enough.
+func (s *AuthService) mfaChallenge(ctx context.Context, maker token.Maker, userUUID uuid.UUID, role string) (*pb.LoginUserResponse, error) {
+	enabled, err := mfa.Enabled(ctx, s.db, userUUID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if !enabled {
+		setting, err := s.db.GetRoleSetting(ctx, role)
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if !setting.MfaRequired {
+			return nil, nil
+		}
+	}
+
+	mfaToken, _, err := maker.CreateToken(userUUID.String(), uuid.Nil, token.KindMFA, mfaChallengeDuration, role, time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create mfa token: %v", err)
+	}
+
+	return &pb.LoginUserResponse{
+		MfaRequired:           true,
+		MfaToken:              mfaToken,
+		MfaEnrollmentRequired: !enabled,
+	}, nil
+}
+
+// startSession creates a new session family and its first access and refresh tokens.
+func (s *AuthService) startSession(ctx context.Context, maker token.Maker, userUUID uuid.UUID, role string, user *pb.User, metaData *shared.Metadata) (*pb.LoginUserResponse, error) {
 	accessTokenDuration, err := time.ParseDuration(s.config.AccessTokenDuration.String())
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "cannot parse access token duration: %v", err)
 	}
 
-	role := detailLogin.Role
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create session id: %v", err)
+	}
+	// the session starts right after the user authenticated
+	authTime := time.Now()
 
-	accessToken, _, err := maker.CreateToken(detailLogin.UserUuid.String(), accessTokenDuration, role)
+	accessToken, _, err := maker.CreateToken(userUUID.String(), sessionID, token.KindAccess, accessTokenDuration, role, authTime)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "cannot create access token: %v", err)
 	}
@@ -63,14 +256,15 @@ func (s *AuthService) LoginUser(ctx context.Context, req *pb.LoginUserRequest, m
 		return nil, status.Errorf(codes.Internal, "cannot parse refresh token duration: %v", err)
 	}
 
-	refreshToken, payloadRefresh, err := maker.CreateToken(detailLogin.UserUuid.String(), refreshTokenDuration, role)
+	refreshToken, payloadRefresh, err := maker.CreateToken(userUUID.String(), sessionID, token.KindRefresh, refreshTokenDuration, role, authTime)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "cannot create refresh token: %v", err)
 	}
 
 	arg := db.CreateSessionParams{
 		ID:           payloadRefresh.ID,
-		UserUuid:     detailLogin.UserUuid,
+		FamilyID:     sessionID,
+		UserUuid:     userUUID,
 		RefreshToken: refreshToken,
 		UserAgent:    metaData.UserAgent,
 		ClientIp:     metaData.ClientIP,
@@ -86,46 +280,167 @@ func (s *AuthService) LoginUser(ctx context.Context, req *pb.LoginUserRequest, m
 	}
 
 	res := &pb.LoginUserResponse{
-		SessionId:    session.ID.String(),
+		SessionId:    session.FamilyID.String(),
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		User: &pb.User{
-			UserUuid:  detailLogin.UserUuid.String(),
-			Username:  detailLogin.Username,
-			FullName:  detailLogin.FullName,
-			Email:     detailLogin.Email,
-			CreatedAt: &timestamppb.Timestamp{Seconds: time.Now().Unix()},
-		},
+		User:         user,
 	}
 
 	return res, nil
 }
 
-func (s *AuthService) VerifyUserEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
-	checkVerCode, err := s.db.GetUserByVerificationEmailCode(ctx, pgtype.Text{String: req.GetVerificationCode(), Valid: true})
+// loginFailed counts a failed login. The login fails anyway, so an error is only logged.
+func (s *AuthService) loginFailed(ctx context.Context, attempt lockout.Attempt, user *lockout.User) {
+	if err := s.guard.Fail(ctx, attempt, user); err != nil {
+		log.Err(err).Msg("Cannot count failed login")
+	}
+}
+
+// mfaFailed counts a wrong two-factor code as a failed login. Once the user is locked out, the MFA
+// token is revoked as well, so the login has to start over with the password after the lockout.
+func (s *AuthService) mfaFailed(ctx context.Context, attempt lockout.Attempt, user db.GetUserByUserUUIDRow, payload *token.Payload) {
+	s.loginFailed(ctx, attempt, &lockout.User{UUID: user.UserUuid, Email: user.Email})
+
+	var locked *lockout.LockedError
+	if err := s.guard.Check(ctx, attempt); !errors.As(err, &locked) || !locked.Locked {
+		return
+	}
+	if err := s.revocations.Revoke(ctx, payload.ID, payload.ExpiredAt); err != nil {
+		log.Err(err).Msg("Cannot revoke mfa token")
+	}
+}
+
+// lockedStatus turns a *lockout.LockedError into a ResourceExhausted status that tells the client
+// when to retry.
+func lockedStatus(err error) error {
+	var lockedErr *lockout.LockedError
+	if !errors.As(err, &lockedErr) {
+		return status.Errorf(codes.Internal, "failed to check login attempts: %v", err)
+	}
+
+	return retryStatus(lockedErr.Error(), lockedErr.RetryAfter)
+}
+
+// retryStatus returns a ResourceExhausted status that tells the client to retry after retryAfter.
+func retryStatus(message string, retryAfter time.Duration) error {
+	st, err := status.New(codes.ResourceExhausted, message).WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter),
+	})
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, status.Errorf(codes.NotFound, "verification code not found")
+		return status.Error(codes.ResourceExhausted, message)
+	}
+	return st.Err()
+}
+
+// stepUpStatus turns a *stepup.RequiredError into an Unauthenticated status whose STEP_UP_REQUIRED
+// reason tells the client to step up and retry.
+func stepUpStatus(err error) error {
+	var requiredErr *stepup.RequiredError
+	if !errors.As(err, &requiredErr) {
+		return status.Errorf(codes.Internal, "failed to check step-up authentication: %v", err)
+	}
+
+	st, detailErr := status.New(codes.Unauthenticated, requiredErr.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason: "STEP_UP_REQUIRED",
+		Domain: grpctoken.Issuer,
+		Metadata: map[string]string{
+			"operation": requiredErr.Operation,
+			"max_age":   strconv.FormatInt(int64(requiredErr.MaxAge.Seconds()), 10),
+		},
+	})
+	if detailErr != nil {
+		return status.Error(codes.Unauthenticated, requiredErr.Error())
+	}
+	return st.Err()
+}
+
+// StepUp authenticates the caller again with the password, or a two-factor code when one is set,
+// and issues an access token of the same session for the operations that require a recent
+// authentication.
+func (s *AuthService) StepUp(ctx context.Context, req *pb.StepUpRequest, payload *grpctoken.Payload, metaData *shared.Metadata) (*pb.StepUpResponse, error) {
+	err := s.stepUp.Authenticate(ctx, payload.UserUUID, req.GetPassword(), req.GetCode(), metaData.ClientIP, metaData.UserAgent)
+	if err != nil {
+		var lockedErr *lockout.LockedError
+		switch {
+		case errors.As(err, &lockedErr):
+			return nil, lockedStatus(err)
+		case errors.Is(err, stepup.ErrInvalidCredentials):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
 		}
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, status.Errorf(codes.Internal, "cannot authenticate: %v", err)
 	}
 
-	if checkVerCode.VerificationEmailExpiredAt.Time.Before(time.Now()) {
-		return nil, status.Error(codes.InvalidArgument, "verification code is expired")
+	accessToken, accessPayload, err := s.maker.CreateToken(payload.UserUUID.String(), payload.SessionID, token.KindAccess, s.config.AccessTokenDuration, payload.Role, time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create access token: %v", err)
 	}
 
-	if checkVerCode.VerifiedEmailAt.String() != "0001-01-01 07:00:00 +0700 +07" {
-		return nil, status.Error(codes.InvalidArgument, "email already verified")
+	return &pb.StepUpResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: timestamppb.New(accessPayload.ExpiredAt),
+		AuthTime:             timestamppb.New(accessPayload.AuthTime),
+	}, nil
+}
+
+// UnlockLogin lifts a login lockout with the code emailed when the login was locked.
+func (s *AuthService) UnlockLogin(ctx context.Context, req *pb.UnlockLoginRequest, metaData *shared.Metadata) (*pb.UnlockLoginResponse, error) {
+	err := s.guard.Unlock(ctx, req.GetCode(), metaData.ClientIP, metaData.UserAgent)
+	if err != nil {
+		if errors.Is(err, lockout.ErrInvalidUnlockCode) {
+			return nil, status.Error(codes.InvalidArgument, "invalid or expired unlock code")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to unlock login: %v", err)
 	}
 
-	_, err = s.db.UpdateUserVerificationEmail(ctx, db.UpdateUserVerificationEmailParams{
-		VerificationEmailCode:      pgtype.Text{String: req.GetVerificationCode(), Valid: true},
-		UserUuid:                   checkVerCode.UserUuid,
-		VerificationEmailExpiredAt: pgtype.Timestamptz{Time: checkVerCode.VerificationEmailExpiredAt.Time, Valid: true},
-		VerifiedEmailAt:            time.Now(),
-	})
+	return &pb.UnlockLoginResponse{Message: "Login unlocked"}, nil
+}
+
+// RequestPasswordReset emails a password reset code. It answers the same whether or not the email
+// address is registered, but refuses codes requested too often for the address or the client IP.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest, metaData *shared.Metadata) (*pb.RequestPasswordResetResponse, error) {
+	_, _, err := passwordreset.Request(ctx, s.db, s.emails, s.resets, req.GetEmail(), metaData.ClientIP)
+	if err != nil {
+		var limitedErr *passwordreset.LimitedError
+		if errors.As(err, &limitedErr) {
+			return nil, retryStatus(limitedErr.Error(), limitedErr.RetryAfter)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to request password reset: %v", err)
+	}
+
+	return &pb.RequestPasswordResetResponse{Message: "If the email is registered, a password reset code has been sent"}, nil
+}
 
+// ResetPassword sets a new password with an emailed password reset code and signs the user out of
+// every session, revoking the access tokens issued before the password changed. The password has
+// to satisfy the password policy.
+func (s *AuthService) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	user, err := passwordreset.Reset(ctx, s.db, s.policy, s.hasher, req.GetEmail(), req.GetCode(), req.GetNewPassword())
 	if err != nil {
+		if errors.Is(err, passwordreset.ErrInvalidCode) {
+			return nil, status.Error(codes.InvalidArgument, "invalid or expired password reset code")
+		}
+		if errors.Is(err, passwordpolicy.ErrWeakPassword) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reset password: %v", err)
+	}
+
+	if err := s.revocations.RevokeIssuedBefore(ctx, user.UserUuid, user.PasswordChangedAt); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke access tokens: %v", err)
+	}
+
+	return &pb.ResetPasswordResponse{Message: "Password reset success"}, nil
+}
+
+func (s *AuthService) VerifyUserEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	_, err := s.verifier.Verify(ctx, req.GetVerificationCode())
+	if err != nil {
+		switch {
+		case errors.Is(err, emailverification.ErrInvalidCode):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, emailverification.ErrCodeExpired), errors.Is(err, emailverification.ErrAlreadyVerified):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, status.Errorf(codes.Internal, "cannot update user verification email: %v", err)
 	}
 
@@ -134,5 +449,119 @@ func (s *AuthService) VerifyUserEmail(ctx context.Context, req *pb.VerifyEmailRe
 	}
 
 	return res, nil
+}
+
+// ResendVerificationEmail sends the caller a new verification code, replacing the previous one.
+func (s *AuthService) ResendVerificationEmail(ctx context.Context, payload *grpctoken.Payload) (*pb.ResendVerificationEmailResponse, error) {
+	err := s.verifier.Resend(ctx, payload.UserUUID)
+	if err != nil {
+		switch {
+		case errors.Is(err, emailverification.ErrResendTooSoon):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		case errors.Is(err, emailverification.ErrAlreadyVerified):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "cannot resend verification email: %v", err)
+	}
+
+	return &pb.ResendVerificationEmailResponse{Message: "Verification email sent"}, nil
+}
+
+// Logout revokes the session the access token was issued for, so neither its refresh token nor
+// its access tokens can be used again.
+func (s *AuthService) Logout(ctx context.Context, payload *grpctoken.Payload) (*pb.LogoutResponse, error) {
+	if err := s.revokeSession(ctx, payload, sessionIDOf(payload)); err != nil {
+		return nil, err
+	}
+
+	return &pb.LogoutResponse{Message: "Logout success"}, nil
+}
+
+// ListSessions returns the active sessions of the caller, marking the session of the access token as current.
+func (s *AuthService) ListSessions(ctx context.Context, payload *grpctoken.Payload) (*pb.ListSessionsResponse, error) {
+	sessions, err := s.db.ListActiveSessionsByUser(ctx, payload.UserUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot list sessions: %v", err)
+	}
+
+	res := &pb.ListSessionsResponse{Sessions: make([]*pb.ActiveSession, 0, len(sessions))}
+	for _, session := range sessions {
+		res.Sessions = append(res.Sessions, &pb.ActiveSession{
+			SessionId: session.FamilyID.String(),
+			UserAgent: session.UserAgent,
+			ClientIp:  session.ClientIp,
+			Current:   session.FamilyID == sessionIDOf(payload),
+			ExpiresAt: timestamppb.New(session.ExpiresAt),
+			CreatedAt: timestamppb.New(session.CreatedAt),
+		})
+	}
+
+	return res, nil
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest, payload *grpctoken.Payload) (*pb.RevokeSessionsResponse, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session id: %v", err)
+	}
+
+	if err := s.revokeSession(ctx, payload, sessionID); err != nil {
+		return nil, err
+	}
+
+	return &pb.RevokeSessionsResponse{Revoked: 1}, nil
+}
+
+// RevokeOtherSessions revokes every session of the caller except the current one.
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, payload *grpctoken.Payload) (*pb.RevokeSessionsResponse, error) {
+	familyIDs, err := s.db.BlockOtherUserSessions(ctx, db.BlockOtherUserSessionsParams{
+		UserUuid: payload.UserUUID,
+		FamilyID: sessionIDOf(payload),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot revoke sessions: %v", err)
+	}
+
+	// the IDs repeat once per refresh token of a session
+	revoked := make(map[uuid.UUID]bool)
+	for _, familyID := range familyIDs {
+		if revoked[familyID] {
+			continue
+		}
+		if err := s.revocations.RevokeSession(ctx, familyID); err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot revoke access tokens: %v", err)
+		}
+		revoked[familyID] = true
+	}
+
+	return &pb.RevokeSessionsResponse{Revoked: int64(len(revoked))}, nil
+}
+
+func (s *AuthService) revokeSession(ctx context.Context, payload *grpctoken.Payload, sessionID uuid.UUID) error {
+	rows, err := s.db.BlockSessionFamily(ctx, db.BlockSessionFamilyParams{
+		FamilyID: sessionID,
+		UserUuid: payload.UserUUID,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot revoke session: %v", err)
+	}
+	if rows == 0 {
+		return status.Error(codes.NotFound, "session not found")
+	}
+
+	if err := s.revocations.RevokeSession(ctx, sessionID); err != nil {
+		return status.Errorf(codes.Internal, "cannot revoke access tokens: %v", err)
+	}
+
+	return nil
+}
+
+// sessionIDOf returns the session family a token belongs to. Tokens issued before tokens carried
+// the session ID started their own family, keyed by their own ID.
+func sessionIDOf(payload *grpctoken.Payload) uuid.UUID {
+	if payload.SessionID == uuid.Nil {
+		return payload.ID
+	}
 
+	return payload.SessionID
 }
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	grpctoken "github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/shared"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/pb"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// completeLogin records the device of a user who authenticated and starts the session, unless the
+// device has to be confirmed with the emailed code first. The response carries the device token the
+// client has to send with its next logins.
+func (s *AuthService) completeLogin(ctx context.Context, maker token.Maker, userUUID uuid.UUID, role string, user *pb.User, metaData *shared.Metadata) (*pb.LoginUserResponse, error) {
+	deviceToken, err := s.devices.SignIn(ctx, device.Login{
+		UserUUID:    userUUID,
+		Email:       user.GetEmail(),
+		DeviceToken: metaData.DeviceToken,
+		UserAgent:   metaData.UserAgent,
+		ClientIP:    metaData.ClientIP,
+	})
+	if errors.Is(err, device.ErrConfirmationRequired) {
+		return &pb.LoginUserResponse{DeviceConfirmationRequired: true, DeviceToken: deviceToken, User: user}, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot record device: %v", err)
+	}
+
+	res, err := s.startSession(ctx, maker, userUUID, role, user, metaData)
+	if err != nil {
+		return nil, err
+	}
+	res.DeviceToken = deviceToken
+	return res, nil
+}
+
+// ConfirmDevice completes a login from a new device with the code emailed to the user. The code
+// only works from the device holding the device token the login returned.
+func (s *AuthService) ConfirmDevice(ctx context.Context, req *pb.ConfirmDeviceRequest, metaData *shared.Metadata) (*pb.LoginUserResponse, error) {
+	userUUID, err := s.devices.Confirm(ctx, req.GetCode(), metaData.DeviceToken, metaData.UserAgent, metaData.ClientIP)
+	if err != nil {
+		if errors.Is(err, device.ErrInvalidConfirmationCode) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	user, err := s.db.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if user.IsBlocked {
+		return nil, status.Error(codes.PermissionDenied, "user is blocked")
+	}
+	if user.PasswordResetRequired {
+		return nil, status.Error(codes.PermissionDenied, "password reset required")
+	}
+
+	res, err := s.startSession(ctx, s.maker, user.UserUuid, user.Role, &pb.User{
+		UserUuid:      user.UserUuid.String(),
+		Username:      user.Username,
+		FullName:      user.FullName,
+		Email:         user.Email,
+		CreatedAt:     timestamppb.New(user.CreatedAt),
+		EmailVerified: !user.VerifiedEmailAt.IsZero(),
+	}, metaData)
+	if err != nil {
+		return nil, err
+	}
+	res.DeviceToken = metaData.DeviceToken
+	return res, nil
+}
+
+// ListDevices returns the trusted devices of the caller, marking the device of the request as current.
+func (s *AuthService) ListDevices(ctx context.Context, payload *grpctoken.Payload, metaData *shared.Metadata) (*pb.ListDevicesResponse, error) {
+	devices, err := s.devices.List(ctx, payload.UserUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot list devices: %v", err)
+	}
+
+	current := device.Fingerprint(metaData.DeviceToken)
+	res := &pb.ListDevicesResponse{Devices: make([]*pb.TrustedDevice, 0, len(devices))}
+	for _, d := range devices {
+		res.Devices = append(res.Devices, &pb.TrustedDevice{
+			DeviceUuid:   d.DeviceUuid.String(),
+			UserAgent:    d.UserAgent,
+			LastClientIp: d.LastClientIp,
+			Current:      current != "" && d.Fingerprint == current,
+			TrustedAt:    timestamppb.New(d.TrustedAt.Time),
+			LastSeenAt:   timestamppb.New(d.LastSeenAt),
+			CreatedAt:    timestamppb.New(d.CreatedAt),
+		})
+	}
+
+	return res, nil
+}
+
+// RemoveDevice takes a device off the trusted devices of the caller.
+func (s *AuthService) RemoveDevice(ctx context.Context, req *pb.RemoveDeviceRequest, payload *grpctoken.Payload, metaData *shared.Metadata) (*pb.RemoveDeviceResponse, error) {
+	deviceUUID, err := uuid.Parse(req.GetDeviceUuid())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device uuid: %v", err)
+	}
+
+	err = s.devices.Remove(ctx, payload.UserUUID, deviceUUID, metaData.ClientIP, metaData.UserAgent)
+	if err != nil {
+		if errors.Is(err, device.ErrDeviceNotFound) {
+			return nil, status.Error(codes.NotFound, "device not found")
+		}
+		return nil, status.Errorf(codes.Internal, "cannot remove device: %v", err)
+	}
+
+	return &pb.RemoveDeviceResponse{Message: "Device removed"}, nil
+}
@@ -2,17 +2,30 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/email"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/helper"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/screening"
+	"github.com/fajaramaulana/simple_bank_project/internal/stepup"
 	"github.com/fajaramaulana/simple_bank_project/pb"
 	"github.com/fajaramaulana/simple_bank_project/util"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -22,13 +35,24 @@ type UserService struct {
 	db          db.Store
 	config      util.Config
 	redisClient *redis.Client
+	screener    *screening.Screener
+	authorizer  *authz.Authorizer
+	policy      *passwordpolicy.Policy
+	hasher      *passwordhash.Hasher
+	stepUp      *stepup.Policy
+	revocations *revocation.List
+	verifier    *emailverification.Verifier
 }
 
-func NewUserService(db db.Store, config util.Config, redisClient *redis.Client) *UserService {
-	return &UserService{db: db, config: config, redisClient: redisClient}
+func NewUserService(db db.Store, config util.Config, redisClient *redis.Client, screener *screening.Screener, authorizer *authz.Authorizer, policy *passwordpolicy.Policy, hasher *passwordhash.Hasher, stepUp *stepup.Policy, revocations *revocation.List, verifier *emailverification.Verifier) *UserService {
+	return &UserService{db: db, config: config, redisClient: redisClient, screener: screener, authorizer: authorizer, policy: policy, hasher: hasher, stepUp: stepUp, revocations: revocations, verifier: verifier}
 }
 
 func (s *UserService) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserRespose, error) {
+	if err := s.policy.Validate(req.GetPassword(), req.GetUsername(), req.GetEmail()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	user, err := s.db.GetUserByEmail(ctx, req.GetEmail())
 	if err != nil {
 		fmt.Printf("%# v\n", err.Error())
@@ -54,7 +78,10 @@ func (s *UserService) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 		return nil, status.Errorf(codes.AlreadyExists, "username already exists")
 	}
 
-	hashPass, err := util.MakePasswordBcrypt(req.GetPassword())
+	// screen the full name against the sanctions list
+	match, matched := s.screener.Screen(req.GetFullName())
+
+	hashPass, err := s.hasher.Hash(req.GetPassword())
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to hash password: %v", err)
 	}
@@ -66,7 +93,25 @@ func (s *UserService) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 		FullName: req.GetFullName(),
 		Currency: req.GetCurrency(),
 	}
-	userCreate, err := s.db.CreateUserWithAccountTx(ctx, arg)
+	// a matching user is created but held until compliance clears the match
+	param := db.CreateUserWithAccountTxParam{User: arg}
+	if matched {
+		param.SanctionsMatch = &match
+	}
+	var userCreate db.CreateUserWithAccountResult
+	err = s.db.ExecTx(ctx, func(store db.Store) error {
+		userCreate, err = store.CreateUserWithAccountTx(ctx, param)
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionUserCreate,
+			EntityType: audit.EntityUser,
+			EntityID:   userCreate.User.UserUUID,
+			After:      userCreate,
+		})
+	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create user: %v", err)
 	}
@@ -98,7 +143,7 @@ func (s *UserService) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 	}
 
 	// insert to redis
-	keyRedis := "verification_email:users:" + userCreate.User.UserUUID
+	keyRedis := email.VerificationKeyPrefix + "users:" + userCreate.User.UserUUID
 	valueRedis := map[string]interface{}{
 		"verification_code": verificationCode,
 		"expired_at":        result.VerificationEmailExpiredAt.Time,
@@ -111,11 +156,12 @@ func (s *UserService) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 
 	res := &pb.CreateUserRespose{
 		User: &pb.User{
-			UserUuid:  userCreate.User.UserUUID,
-			Username:  userCreate.User.Username,
-			FullName:  userCreate.User.FullName,
-			Email:     userCreate.User.Email,
-			CreatedAt: timestamppb.New(time.Now()),
+			UserUuid:      userCreate.User.UserUUID,
+			Username:      userCreate.User.Username,
+			FullName:      userCreate.User.FullName,
+			Email:         userCreate.User.Email,
+			CreatedAt:     timestamppb.New(time.Now()),
+			EmailVerified: false,
 		},
 		Account: &pb.Account{
 			AccountUuid: userCreate.Account.AccountUUID.String(),
@@ -125,21 +171,42 @@ func (s *UserService) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 			CreatedAt:   timestamppb.New(time.Now()),
 		},
 	}
+	if userCreate.ComplianceReview != nil {
+		res.ComplianceStatus = "pending_review"
+	}
 
 	return res, nil
 }
 
-func (s *UserService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+// UpdateUser updates the profile of the caller. Changing the password or the email address needs a
+// recent authentication. A new email address has to be verified again, a code is emailed to it.
+func (s *UserService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest, payload *token.Payload) (*pb.UpdateUserResponse, error) {
 	uuidUser, err := helper.ConvertStringToUUID(req.GetUserUuid())
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user_uuid: %v", err)
 	}
 
-	_, err = s.db.GetUserByUserUUID(ctx, uuidUser)
+	// users:update:self only covers the profile of the caller, other users are managed through the admin methods
+	if uuidUser != payload.UserUUID {
+		return nil, status.Error(codes.PermissionDenied, "users can only update their own profile")
+	}
+
+	before, err := s.db.GetUserByUserUUID(ctx, uuidUser)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get user by user_uuid: %v", err)
 	}
 
+	if req.Password != nil {
+		if err := s.stepUp.Require(stepup.OperationPasswordChange, 0, payload.AuthTime); err != nil {
+			return nil, stepUpStatus(err)
+		}
+	}
+	if req.Email != nil && req.GetEmail() != before.Email {
+		if err := s.stepUp.Require(stepup.OperationEmailChange, 0, payload.AuthTime); err != nil {
+			return nil, stepUpStatus(err)
+		}
+	}
+
 	checkEmail, err := s.db.GetUserByEmail(ctx, req.GetEmail())
 	if err != nil {
 		if err.Error() != "no rows in result set" {
@@ -150,6 +217,15 @@ func (s *UserService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 	if len(checkEmail.Email) > 0 && checkEmail.UserUuid != uuidUser {
 		return nil, status.Errorf(codes.AlreadyExists, "email already exists")
 	}
+	// a new full name is screened like the name at signup, a match holds the user until compliance
+	// clears it
+	param := db.UpdateUserTxParam{SessionFamilyID: payload.SessionID}
+	if req.FullName != nil && req.GetFullName() != before.FullName {
+		if match, matched := s.screener.Screen(req.GetFullName()); matched {
+			param.SanctionsMatch = &match
+		}
+	}
+
 	arg := db.UpdateUserParams{
 		UserUuid: uuidUser,
 		Email: pgtype.Text{
@@ -163,7 +239,18 @@ func (s *UserService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 	}
 
 	if req.Password != nil {
-		hashPass, err := util.MakePasswordBcrypt(req.GetPassword())
+		email := before.Email
+		if req.Email != nil {
+			email = req.GetEmail()
+		}
+		if err := s.policy.Check(ctx, s.db, uuidUser, req.GetPassword(), before.Username, email); err != nil {
+			if errors.Is(err, passwordpolicy.ErrWeakPassword) {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			return nil, status.Errorf(codes.Internal, "failed to check password history: %v", err)
+		}
+
+		hashPass, err := s.hasher.Hash(req.GetPassword())
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to hash password: %v", err)
 		}
@@ -178,11 +265,49 @@ func (s *UserService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		}
 	}
 
-	userUpdate, err := s.db.UpdateUser(ctx, arg)
+	param.User = arg
+	var userUpdate db.UpdateUserRow
+	err = s.db.ExecTx(ctx, func(store db.Store) error {
+		userUpdate, err = store.UpdateUserTx(ctx, param)
+		if err != nil {
+			return err
+		}
+
+		// the password hash is never written to the audit log
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionUserUpdate,
+			EntityType: audit.EntityUser,
+			EntityID:   userUpdate.UserUuid.String(),
+			Before:     before,
+			After: map[string]any{
+				"user_uuid":        userUpdate.UserUuid,
+				"username":         userUpdate.Username,
+				"full_name":        userUpdate.FullName,
+				"email":            userUpdate.Email,
+				"password_changed": arg.HashedPassword.Valid,
+			},
+		})
+	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
 	}
 
+	// access tokens issued with the old password stop working, the caller refreshes its session, the
+	// other sessions were blocked
+	if arg.HashedPassword.Valid {
+		if err := s.revocations.RevokeIssuedBefore(ctx, userUpdate.UserUuid, userUpdate.PasswordChangedAt); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to revoke access tokens: %v", err)
+		}
+	}
+
+	// the update reset the verification of a new address, the user is updated anyway when the
+	// code cannot be sent and asks for another one
+	if userUpdate.Email != before.Email {
+		if err := s.verifier.Resend(ctx, userUpdate.UserUuid); err != nil {
+			log.Err(err).Msg("Cannot send verification email")
+		}
+	}
+
 	// list acocunt by user_uuid
 	accounts, err := s.db.GetAccountByUserUUIDMany(ctx, userUpdate.UserUuid)
 	if err != nil {
@@ -215,3 +340,285 @@ func (s *UserService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 
 	return res, nil
 }
+
+// ListUsers returns the users matching the search and filters of req with the total number of
+// matching users. The search matches part of the username, email or full name.
+func (s *UserService) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	filter := db.CountUsersParams{
+		Search:    pgtype.Text{String: req.GetSearch(), Valid: req.Search != nil},
+		Role:      pgtype.Text{String: req.GetRole(), Valid: req.Role != nil},
+		IsBlocked: pgtype.Bool{Bool: req.GetBlocked(), Valid: req.Blocked != nil},
+	}
+
+	users, err := s.db.ListUsers(ctx, db.ListUsersParams{
+		Search:     filter.Search,
+		Role:       filter.Role,
+		IsBlocked:  filter.IsBlocked,
+		PageLimit:  req.GetLimit(),
+		PageOffset: (req.GetPage() - 1) * req.GetLimit(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list users: %v", err)
+	}
+
+	total, err := s.db.CountUsers(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count users: %v", err)
+	}
+
+	res := &pb.ListUsersResponse{
+		Users: make([]*pb.AdminUser, 0, len(users)),
+		Total: total,
+	}
+	for _, user := range users {
+		res.Users = append(res.Users, &pb.AdminUser{
+			UserUuid:              user.UserUuid.String(),
+			Username:              user.Username,
+			FullName:              user.FullName,
+			Email:                 user.Email,
+			Role:                  user.Role,
+			IsBlocked:             user.IsBlocked,
+			PasswordResetRequired: user.PasswordResetRequired,
+			EmailVerified:         !user.VerifiedEmailAt.IsZero(),
+			CreatedAt:             timestamppb.New(user.CreatedAt),
+		})
+	}
+
+	return res, nil
+}
+
+// GetUser returns a user with all of the user's accounts and sessions.
+func (s *UserService) GetUser(ctx context.Context, req *pb.AdminUserRequest) (*pb.GetUserResponse, error) {
+	userUUID, err := helper.ConvertStringToUUID(req.GetUserUuid())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user_uuid: %v", err)
+	}
+
+	user, err := s.db.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user by user_uuid: %v", err)
+	}
+
+	accounts, err := s.db.GetAccountByUserUUIDMany(ctx, userUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get account by user_uuid: %v", err)
+	}
+
+	sessions, err := s.db.ListSessionsByUser(ctx, userUUID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list sessions: %v", err)
+	}
+
+	res := &pb.GetUserResponse{
+		User: &pb.AdminUser{
+			UserUuid:              user.UserUuid.String(),
+			Username:              user.Username,
+			FullName:              user.FullName,
+			Email:                 user.Email,
+			Role:                  user.Role,
+			IsBlocked:             user.IsBlocked,
+			PasswordResetRequired: user.PasswordResetRequired,
+			CreatedAt:             timestamppb.New(user.CreatedAt),
+		},
+		Accounts: make([]*pb.Account, 0, len(accounts)),
+		Sessions: make([]*pb.UserSession, 0, len(sessions)),
+	}
+	for _, account := range accounts {
+		res.Accounts = append(res.Accounts, &pb.Account{
+			AccountUuid: account.AccountUuid.String(),
+			Owner:       account.Owner,
+			Currency:    account.Currency,
+			Balance:     account.Balance.Int.String(),
+			CreatedAt:   timestamppb.New(account.CreatedAt),
+		})
+	}
+	for _, session := range sessions {
+		res.Sessions = append(res.Sessions, &pb.UserSession{
+			SessionId: session.ID.String(),
+			UserAgent: session.UserAgent,
+			ClientIp:  session.ClientIp,
+			IsBlocked: session.IsBlocked,
+			ExpiresAt: timestamppb.New(session.ExpiresAt),
+			CreatedAt: timestamppb.New(session.CreatedAt),
+		})
+	}
+
+	return res, nil
+}
+
+// BlockUser blocks a user from signing in, blocks all of the user's sessions and revokes the access
+// tokens issued so far.
+func (s *UserService) BlockUser(ctx context.Context, req *pb.AdminUserRequest, payload *token.Payload) (*pb.AdminUserResponse, error) {
+	before, err := s.getManagedUser(ctx, req.GetUserUuid(), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var user db.UpdateUserBlockedRow
+	err = s.db.ExecTx(ctx, func(store db.Store) error {
+		user, err = store.BlockUserTx(ctx, before.UserUuid)
+		if err != nil {
+			return err
+		}
+
+		return writeUserChange(ctx, store, audit.ActionUserBlock, before, user)
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block user: %v", err)
+	}
+
+	if err := s.revocations.RevokeIssuedBefore(ctx, before.UserUuid, time.Now()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke access tokens: %v", err)
+	}
+
+	return adminUserResponse(user), nil
+}
+
+// UnblockUser lets a blocked user sign in again. Sessions blocked along with the user stay
+// blocked.
+func (s *UserService) UnblockUser(ctx context.Context, req *pb.AdminUserRequest, payload *token.Payload) (*pb.AdminUserResponse, error) {
+	before, err := s.getManagedUser(ctx, req.GetUserUuid(), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var user db.UpdateUserBlockedRow
+	err = s.db.ExecTx(ctx, func(store db.Store) error {
+		user, err = store.UpdateUserBlocked(ctx, db.UpdateUserBlockedParams{
+			UserUuid:  before.UserUuid,
+			IsBlocked: false,
+		})
+		if err != nil {
+			return err
+		}
+
+		return writeUserChange(ctx, store, audit.ActionUserUnblock, before, user)
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to unblock user: %v", err)
+	}
+
+	return adminUserResponse(user), nil
+}
+
+// ChangeUserRole changes the role of a user. Granting a role that holds users:manage:privileged
+// requires that permission too. The new role takes effect from the user's next token.
+func (s *UserService) ChangeUserRole(ctx context.Context, req *pb.ChangeUserRoleRequest, payload *token.Payload) (*pb.AdminUserResponse, error) {
+	before, err := s.getManagedUser(ctx, req.GetUserUuid(), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.canManageRole(ctx, payload.Role, req.GetRole()) {
+		return nil, status.Errorf(codes.PermissionDenied, "permission %s is required to grant role %s", authz.UsersManagePrivileged, req.GetRole())
+	}
+
+	var user db.UpdateUserBlockedRow
+	err = s.db.ExecTx(ctx, func(store db.Store) error {
+		updated, err := store.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+			UserUuid: before.UserUuid,
+			Role:     req.GetRole(),
+		})
+		if err != nil {
+			return err
+		}
+
+		user = db.UpdateUserBlockedRow(updated)
+		return writeUserChange(ctx, store, audit.ActionUserRoleChange, before, user)
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to change user role: %v", err)
+	}
+
+	return adminUserResponse(user), nil
+}
+
+// ForcePasswordReset makes a user reset the password before signing in again, blocks all of the
+// user's sessions and revokes the access tokens issued so far.
+func (s *UserService) ForcePasswordReset(ctx context.Context, req *pb.AdminUserRequest, payload *token.Payload) (*pb.AdminUserResponse, error) {
+	before, err := s.getManagedUser(ctx, req.GetUserUuid(), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var user db.UpdateUserBlockedRow
+	err = s.db.ExecTx(ctx, func(store db.Store) error {
+		updated, err := store.RequirePasswordResetTx(ctx, before.UserUuid)
+		if err != nil {
+			return err
+		}
+
+		user = db.UpdateUserBlockedRow(updated)
+		return writeUserChange(ctx, store, audit.ActionUserPasswordResetForce, before, user)
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to force password reset: %v", err)
+	}
+
+	if err := s.revocations.RevokeIssuedBefore(ctx, before.UserUuid, time.Now()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke access tokens: %v", err)
+	}
+
+	return adminUserResponse(user), nil
+}
+
+// getManagedUser returns the user an admin is about to change. Admins cannot change themselves,
+// and users holding users:manage:privileged can only be changed by admins holding it too.
+func (s *UserService) getManagedUser(ctx context.Context, userUUIDString string, payload *token.Payload) (db.GetUserByUserUUIDRow, error) {
+	userUUID, err := helper.ConvertStringToUUID(userUUIDString)
+	if err != nil {
+		return db.GetUserByUserUUIDRow{}, status.Errorf(codes.InvalidArgument, "invalid user_uuid: %v", err)
+	}
+
+	if userUUID == payload.UserUUID {
+		return db.GetUserByUserUUIDRow{}, status.Error(codes.PermissionDenied, "cannot manage own user")
+	}
+
+	user, err := s.db.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return db.GetUserByUserUUIDRow{}, status.Error(codes.NotFound, "user not found")
+		}
+		return db.GetUserByUserUUIDRow{}, status.Errorf(codes.Internal, "failed to get user by user_uuid: %v", err)
+	}
+
+	if !s.canManageRole(ctx, payload.Role, user.Role) {
+		return db.GetUserByUserUUIDRow{}, status.Errorf(codes.PermissionDenied, "permission %s is required to manage role %s", authz.UsersManagePrivileged, user.Role)
+	}
+
+	return user, nil
+}
+
+// canManageRole reports whether an admin with adminRole can manage users with role.
+func (s *UserService) canManageRole(ctx context.Context, adminRole, role string) bool {
+	return !s.authorizer.Can(ctx, role, authz.UsersManagePrivileged) || s.authorizer.Can(ctx, adminRole, authz.UsersManagePrivileged)
+}
+
+// writeUserChange writes the audit entry of an admin change to a user with store, which runs in
+// the transaction of the change.
+func writeUserChange(ctx context.Context, store db.Store, action string, before db.GetUserByUserUUIDRow, user db.UpdateUserBlockedRow) error {
+	return audit.Write(ctx, store, audit.Entry{
+		Action:     action,
+		EntityType: audit.EntityUser,
+		EntityID:   user.UserUuid.String(),
+		Before:     before,
+		After:      user,
+	})
+}
+
+func adminUserResponse(user db.UpdateUserBlockedRow) *pb.AdminUserResponse {
+	return &pb.AdminUserResponse{
+		User: &pb.AdminUser{
+			UserUuid:              user.UserUuid.String(),
+			Username:              user.Username,
+			FullName:              user.FullName,
+			Email:                 user.Email,
+			Role:                  user.Role,
+			IsBlocked:             user.IsBlocked,
+			PasswordResetRequired: user.PasswordResetRequired,
+		},
+	}
+}
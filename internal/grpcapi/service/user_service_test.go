@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/service"
+	"github.com/fajaramaulana/simple_bank_project/internal/screening"
+	"github.com/fajaramaulana/simple_bank_project/pb"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateUserScreensNewFullName(t *testing.T) {
+	screener := screening.NewScreener(&screening.List{Entries: []screening.Entry{
+		{Reference: "QDi.001", Name: "John Alexander Doe"},
+	}}, 0)
+	before := db.GetUserByUserUUIDRow{
+		UserUuid: uuid.New(),
+		Username: util.RandomUsername(),
+		FullName: "Jane Roe",
+		Email:    util.RandomEmail(),
+	}
+
+	testCases := []struct {
+		name      string
+		fullName  string
+		checkHold func(t *testing.T, match *screening.Match)
+	}{
+		{
+			name:     "listed name",
+			fullName: "John Alexander Doe",
+			checkHold: func(t *testing.T, match *screening.Match) {
+				require.NotNil(t, match)
+				require.Equal(t, "QDi.001", match.Reference)
+			},
+		},
+		{
+			name:     "clean name",
+			fullName: "Jane Doe Roe",
+			checkHold: func(t *testing.T, match *screening.Match) {
+				require.Nil(t, match)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().ExecTx(gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(_ context.Context, fn func(db.Store) error) error { return fn(store) })
+			store.EXPECT().GetUserByUserUUID(gomock.Any(), before.UserUuid).Times(1).Return(before, nil)
+			store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(1).Return(db.GetUserByEmailRow{}, pgx.ErrNoRows)
+			store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(_ context.Context, param db.UpdateUserTxParam) (db.UpdateUserRow, error) {
+					require.Equal(t, tc.fullName, param.User.FullName.String)
+					tc.checkHold(t, param.SanctionsMatch)
+					return db.UpdateUserRow{
+						UserUuid: before.UserUuid,
+						Username: before.Username,
+						FullName: tc.fullName,
+						Email:    before.Email,
+					}, nil
+				})
+			store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+			store.EXPECT().GetAccountByUserUUIDMany(gomock.Any(), before.UserUuid).Times(1).
+				Return([]db.GetAccountByUserUUIDManyRow{{AccountUuid: uuid.New(), Owner: before.FullName, Currency: util.RandomCurrency()}}, nil)
+
+			userService := service.NewUserService(store, util.Config{}, nil, screener, nil, nil, nil, nil, nil, nil)
+			fullName := tc.fullName
+			res, err := userService.UpdateUser(context.Background(), &pb.UpdateUserRequest{
+				UserUuid: before.UserUuid.String(),
+				FullName: &fullName,
+			}, &token.Payload{UserUUID: before.UserUuid, SessionID: uuid.New(), AuthTime: time.Now()})
+			require.NoError(t, err)
+			require.Equal(t, tc.fullName, res.GetFullName())
+		})
+	}
+}
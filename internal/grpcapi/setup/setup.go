@@ -2,20 +2,37 @@ package setup
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	"github.com/fajaramaulana/simple_bank_project/internal/email"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/controller"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/logger"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/seed"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/server"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/service"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/shared"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordreset"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/screening"
+	"github.com/fajaramaulana/simple_bank_project/internal/servertls"
+	"github.com/fajaramaulana/simple_bank_project/internal/signingkey"
+	"github.com/fajaramaulana/simple_bank_project/internal/stepup"
 	"github.com/fajaramaulana/simple_bank_project/pb"
 	"github.com/fajaramaulana/simple_bank_project/util"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -81,15 +98,77 @@ func GetDbStore(config util.Config, conn *pgxpool.Pool) db.Store {
 // It creates instances of the required services and controllers,
 // and then creates a gRPC server with the provided store, controllers, and configuration.
 // Finally, it starts the gRPC server on the specified port from the configuration.
-func InitializeAndStartAppGRPCApi(config util.Config, store db.Store, redisClient *redis.Client) {
+func InitializeAndStartAppGRPCApi(config util.Config, store db.Store, redisClient *redis.Client, signingKeys *signingkey.KeySet) {
 
-	authService := service.NewAuthService(store, config)
+	// permissions of every role are read from the role_permissions table
+	authorizer := authz.NewAuthorizer(store, config.PermissionCacheTTL)
+
+	passwordHasher, err := passwordhash.NewHasher(passwordhash.Config{
+		Algorithm: config.PasswordHashAlgorithm,
+		Argon2id: passwordhash.Argon2idParams{
+			Memory:      config.PasswordArgon2Memory,
+			Iterations:  config.PasswordArgon2Iterations,
+			Parallelism: config.PasswordArgon2Parallelism,
+		},
+		BcryptCost: config.PasswordBcryptCost,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot create password hasher")
+	}
+	passwordPolicy := passwordpolicy.NewPolicy(passwordpolicy.Config{
+		MinLength:           config.PasswordMinLength,
+		MinCharacterClasses: config.PasswordMinCharacterClasses,
+		HistorySize:         config.PasswordHistorySize,
+	}, passwordHasher)
+	emails := email.NewRedisQueue(redisClient)
+	loginGuard := lockout.NewGuard(redisClient, store, emails, lockout.Config{
+		MaxFailures:  config.LoginMaxFailures,
+		LockDuration: config.LoginLockoutDuration,
+	})
+	tokenMaker, err := token.NewMaker(config.TokenSigningAlgorithm, config.TokenSymmetricKey, signingKeys)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot create token maker")
+	}
+	emailVerifier := emailverification.NewVerifier(store, emails, emailverification.Config{
+		Required:       config.EmailVerificationRequired,
+		ResendInterval: config.EmailVerificationResendInterval,
+	})
+	deviceRegistry := device.NewRegistry(redisClient, store, emails, device.Config{
+		RequireConfirmation: config.DeviceConfirmationRequired,
+		ConfirmationCodeTTL: config.DeviceConfirmationCodeTTL,
+	})
+	// access tokens revoked before they expire
+	revocations := revocation.NewList(redisClient, revocation.Config{
+		TokenLifetime: config.AccessTokenDuration,
+		CacheTTL:      config.TokenRevocationCacheTTL,
+	})
+	resetLimiter := passwordreset.NewLimiter(redisClient, passwordreset.LimitConfig{
+		AddressInterval: config.PasswordResetRequestInterval,
+		MaxPerIP:        config.PasswordResetMaxRequestsPerIP,
+	})
+	authService := service.NewAuthService(store, config, tokenMaker, emails, loginGuard, passwordPolicy, passwordHasher, emailVerifier, stepup.NewAuthenticator(store, passwordHasher, loginGuard), deviceRegistry, revocations, resetLimiter)
 	authController := controller.NewAuthController(authService)
 
-	userService := service.NewUserService(store, config, redisClient)
+	// credential changes need a recent authentication
+	stepUpPolicy := stepup.NewPolicy(stepup.Config{
+		Rules: map[string]stepup.Rule{
+			stepup.OperationPasswordChange: {MaxAge: config.StepUpPasswordChangeMaxAge},
+			stepup.OperationEmailChange:    {MaxAge: config.StepUpEmailChangeMaxAge},
+		},
+	})
+	// sanctions screening
+	sanctionsList, err := screening.LoadList(config.SanctionsListFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot load sanctions list")
+	}
+	screener := screening.NewScreener(sanctionsList, config.SanctionsThreshold)
+	userService := service.NewUserService(store, config, redisClient, screener, authorizer, passwordPolicy, passwordHasher, stepUpPolicy, revocations, emailVerifier)
 	userController := controller.NewUserController(userService)
 
-	server, err := server.NewServer(store, authController, userController, config)
+	accountService := service.NewAccountService(store, config, authorizer)
+	accountController := controller.NewAccountController(accountService)
+
+	server, err := server.NewServer(store, authController, userController, accountController, authorizer, config, signingKeys, revocations)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Cannot create gRPC server")
 	}
@@ -106,15 +185,77 @@ func InitializeAndStartAppGRPCApi(config util.Config, store db.Store, redisClien
 // The function also serves the Swagger UI using the provided statik file system.
 // It listens on the configured port and logs the server startup message.
 // If any error occurs during the initialization or serving, the function logs the error and exits.
-func InitializeAndStartGatewayServer(config util.Config, store db.Store, redisClient *redis.Client) {
+func InitializeAndStartGatewayServer(config util.Config, store db.Store, redisClient *redis.Client, signingKeys *signingkey.KeySet) {
 
-	authService := service.NewAuthService(store, config)
+	// permissions of every role are read from the role_permissions table
+	authorizer := authz.NewAuthorizer(store, config.PermissionCacheTTL)
+
+	passwordHasher, err := passwordhash.NewHasher(passwordhash.Config{
+		Algorithm: config.PasswordHashAlgorithm,
+		Argon2id: passwordhash.Argon2idParams{
+			Memory:      config.PasswordArgon2Memory,
+			Iterations:  config.PasswordArgon2Iterations,
+			Parallelism: config.PasswordArgon2Parallelism,
+		},
+		BcryptCost: config.PasswordBcryptCost,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot create password hasher")
+	}
+	passwordPolicy := passwordpolicy.NewPolicy(passwordpolicy.Config{
+		MinLength:           config.PasswordMinLength,
+		MinCharacterClasses: config.PasswordMinCharacterClasses,
+		HistorySize:         config.PasswordHistorySize,
+	}, passwordHasher)
+	emails := email.NewRedisQueue(redisClient)
+	loginGuard := lockout.NewGuard(redisClient, store, emails, lockout.Config{
+		MaxFailures:  config.LoginMaxFailures,
+		LockDuration: config.LoginLockoutDuration,
+	})
+	tokenMaker, err := token.NewMaker(config.TokenSigningAlgorithm, config.TokenSymmetricKey, signingKeys)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot create token maker")
+	}
+	emailVerifier := emailverification.NewVerifier(store, emails, emailverification.Config{
+		Required:       config.EmailVerificationRequired,
+		ResendInterval: config.EmailVerificationResendInterval,
+	})
+	deviceRegistry := device.NewRegistry(redisClient, store, emails, device.Config{
+		RequireConfirmation: config.DeviceConfirmationRequired,
+		ConfirmationCodeTTL: config.DeviceConfirmationCodeTTL,
+	})
+	// access tokens revoked before they expire
+	revocations := revocation.NewList(redisClient, revocation.Config{
+		TokenLifetime: config.AccessTokenDuration,
+		CacheTTL:      config.TokenRevocationCacheTTL,
+	})
+	resetLimiter := passwordreset.NewLimiter(redisClient, passwordreset.LimitConfig{
+		AddressInterval: config.PasswordResetRequestInterval,
+		MaxPerIP:        config.PasswordResetMaxRequestsPerIP,
+	})
+	authService := service.NewAuthService(store, config, tokenMaker, emails, loginGuard, passwordPolicy, passwordHasher, emailVerifier, stepup.NewAuthenticator(store, passwordHasher, loginGuard), deviceRegistry, revocations, resetLimiter)
 	authController := controller.NewAuthController(authService)
 
-	userService := service.NewUserService(store, config, redisClient)
+	// credential changes need a recent authentication
+	stepUpPolicy := stepup.NewPolicy(stepup.Config{
+		Rules: map[string]stepup.Rule{
+			stepup.OperationPasswordChange: {MaxAge: config.StepUpPasswordChangeMaxAge},
+			stepup.OperationEmailChange:    {MaxAge: config.StepUpEmailChangeMaxAge},
+		},
+	})
+	// sanctions screening
+	sanctionsList, err := screening.LoadList(config.SanctionsListFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot load sanctions list")
+	}
+	screener := screening.NewScreener(sanctionsList, config.SanctionsThreshold)
+	userService := service.NewUserService(store, config, redisClient, screener, authorizer, passwordPolicy, passwordHasher, stepUpPolicy, revocations, emailVerifier)
 	userController := controller.NewUserController(userService)
 
-	server, err := server.NewServer(store, authController, userController, config)
+	accountService := service.NewAccountService(store, config, authorizer)
+	accountController := controller.NewAccountController(accountService)
+
+	server, err := server.NewServer(store, authController, userController, accountController, authorizer, config, signingKeys, revocations)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Cannot create gRPC server")
 	}
@@ -128,7 +269,15 @@ func InitializeAndStartGatewayServer(config util.Config, store db.Store, redisCl
 		},
 	})
 
-	grpcMux := runtime.NewServeMux(jsonOpt)
+	// the device token header is passed on to the gRPC server, which identifies the device of a login by it
+	headerOpt := runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		if strings.EqualFold(key, shared.DeviceTokenHeader) {
+			return shared.DeviceTokenHeader, true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	})
+
+	grpcMux := runtime.NewServeMux(jsonOpt, headerOpt)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -139,6 +288,7 @@ func InitializeAndStartGatewayServer(config util.Config, store db.Store, redisCl
 
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
+	mux.Handle("/.well-known/jwks.json", signingkey.Handler(signingKeys))
 
 	statikFS, err := fs.New()
 	if err != nil {
@@ -153,6 +303,18 @@ func InitializeAndStartGatewayServer(config util.Config, store db.Store, redisCl
 		log.Fatal().Err(err).Msg("Cannot listen to the port")
 	}
 
+	if config.GatewayTLSCertFile != "" {
+		certs, err := servertls.New(servertls.Config{
+			CertFile:       config.GatewayTLSCertFile,
+			KeyFile:        config.GatewayTLSKeyFile,
+			ReloadInterval: config.TLSReloadInterval,
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("Cannot load TLS certificate")
+		}
+		listener = tls.NewListener(listener, certs.ServerConfig())
+	}
+
 	log.Printf("Starting gRPC gateway server on %s", config.PortGatewayGrpc)
 	handler := logger.HttpLogger(mux)
 	err = http.Serve(listener, handler)
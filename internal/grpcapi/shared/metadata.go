@@ -13,9 +13,13 @@ const (
 	xForwardedForHeader        = "x-forwarded-for"
 )
 
+// DeviceTokenHeader carries the device token a login returned, identifying the device of the client.
+const DeviceTokenHeader = "x-device-token"
+
 type Metadata struct {
-	UserAgent string
-	ClientIP  string
+	UserAgent   string
+	ClientIP    string
+	DeviceToken string
 }
 
 func ExtractMetadata(ctx context.Context) *Metadata {
@@ -31,6 +35,10 @@ func ExtractMetadata(ctx context.Context) *Metadata {
 			mtdt.UserAgent = userAgents[0]
 		}
 
+		if deviceTokens := md.Get(DeviceTokenHeader); len(deviceTokens) > 0 {
+			mtdt.DeviceToken = deviceTokens[0]
+		}
+
 		if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
 			mtdt.ClientIP = clientIPs[0]
 		}
@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"time"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
@@ -120,6 +122,62 @@ func (a *AccountController) GetAccount(ctx *gin.Context) {
 	helper.ReturnJSON(ctx, http.StatusOK, "Account found", account)
 }
 
+// GetAccountBalance retrieves the balance an account had at the point in time given by the
+// as_of query parameter (RFC 3339). It is used to answer audit questions such as the
+// balance of an account at the end of a reporting period.
+// Only the members of the account or a role with accounts:read:any can query the balance.
+func (a *AccountController) GetAccountBalance(ctx *gin.Context) {
+	var req request.GetAccountRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	// covert uuid to uuid.UUID
+	uuidAcc, err := helper.ConvertStringToUUID(req.UUIDAcc)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	var reqBalance request.GetAccountBalanceRequest
+	if err := ctx.ShouldBindQuery(&reqBalance); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), reqBalance)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	if reqBalance.AsOf.After(time.Now()) {
+		log.Println("Error: as_of is in the future")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "as_of must not be in the future", nil, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	balance, err := a.accountService.GetBalanceAsOf(ctx.Request.Context(), uuidAcc, reqBalance.AsOf, authPayload)
+	if err != nil {
+		if err.Error() == "unauthorized" {
+			log.Println("Error: Unauthorized")
+			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Unauthorized", nil, nil)
+			return
+		} else if err.Error() == "sql: no rows in result set" || err.Error() == "no rows in result set" {
+			log.Println("Error: Data not found")
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
+			return
+		}
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Account balance found", balance)
+}
+
 // GetAccounts retrieves a list of accounts based on the provided query parameters.
 // It binds the query parameters from the request context, validates them, and then
 // calls the accountService to fetch the accounts from the database. The function
@@ -224,6 +282,11 @@ func (a *AccountController) UpdateAccount(ctx *gin.Context) {
 	account, err := a.accountService.UpdateAccount(ctx.Request.Context(), arg, authPayload)
 
 	if err != nil {
+		if errors.Is(err, service.ErrPocketAccount) || errors.Is(err, service.ErrAccountHasPockets) {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+			return
+		}
+
 		if err.Error() == "no rows in result set" {
 			log.Println("Error: Data not found")
 			helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
@@ -249,3 +312,333 @@ func (a *AccountController) UpdateAccount(ctx *gin.Context) {
 
 	helper.ReturnJSON(ctx, http.StatusOK, "Account updated", account)
 }
+
+// GetAccountMembers lists the owners and signatories of the account.
+// Members of the account and roles with accounts:read:any can list them.
+func (a *AccountController) GetAccountMembers(ctx *gin.Context) {
+	var req request.GetAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	uuidAcc, err := helper.ConvertStringToUUID(req.UUIDAcc)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	members, err := a.accountService.ListAccountMembers(ctx.Request.Context(), uuidAcc, authPayload)
+	if err != nil {
+		if errors.Is(err, service.ErrPocketAccount) {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+			return
+		} else if err.Error() == "unauthorized" {
+			log.Println("Error: Unauthorized")
+			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Unauthorized", nil, nil)
+			return
+		} else if err.Error() == "no rows in result set" {
+			log.Println("Error: Data not found")
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
+			return
+		}
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Account members found", members)
+}
+
+// SetAccountMember adds a user to the account or changes the role and permission of an existing member.
+// Only owners of the account can manage its members. An owner always holds the transfer permission and
+// the last owner of the account cannot be demoted.
+func (a *AccountController) SetAccountMember(ctx *gin.Context) {
+	var req request.GetAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	uuidAcc, err := helper.ConvertStringToUUID(req.UUIDAcc)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	var request request.AccountMemberRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), request)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	res := helper.DoValidation(&request)
+	if len(res) > 0 {
+		log.Println("Error: Validation error")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Validation error", nil, res)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	member, err := a.accountService.SetAccountMember(ctx.Request.Context(), uuidAcc, request, authPayload)
+	if err != nil {
+		if errors.Is(err, service.ErrOwnerPermission) || errors.Is(err, service.ErrPocketAccount) {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+			return
+		} else if errors.Is(err, service.ErrLastAccountOwner) {
+			helper.ReturnJSONError(ctx, http.StatusConflict, err.Error(), nil, nil)
+			return
+		} else if err.Error() == "unauthorized" {
+			log.Println("Error: Unauthorized")
+			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Unauthorized", nil, nil)
+			return
+		} else if err.Error() == "no rows in result set" {
+			log.Println("Error: Data not found")
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
+			return
+		}
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Account member saved", member)
+}
+
+// RemoveAccountMember removes a member from the account. Owners can remove any member and
+// every member can remove themselves, but the last owner of the account cannot be removed.
+func (a *AccountController) RemoveAccountMember(ctx *gin.Context) {
+	var req request.AccountMemberUserRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	uuidAcc, err := helper.ConvertStringToUUID(req.UUIDAcc)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	uuidUser, err := helper.ConvertStringToUUID(req.UUIDUser)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	err = a.accountService.RemoveAccountMember(ctx.Request.Context(), uuidAcc, uuidUser, authPayload)
+	if err != nil {
+		if errors.Is(err, service.ErrPocketAccount) {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+			return
+		} else if errors.Is(err, service.ErrLastAccountOwner) {
+			helper.ReturnJSONError(ctx, http.StatusConflict, err.Error(), nil, nil)
+			return
+		} else if err.Error() == "unauthorized" {
+			log.Println("Error: Unauthorized")
+			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Unauthorized", nil, nil)
+			return
+		} else if err.Error() == "no rows in result set" {
+			log.Println("Error: Data not found")
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
+			return
+		}
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Account member removed", nil)
+}
+
+// GetPockets lists the pockets of the account.
+// Members of the account and roles with accounts:read:any can list them.
+func (a *AccountController) GetPockets(ctx *gin.Context) {
+	var req request.GetAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	uuidAcc, err := helper.ConvertStringToUUID(req.UUIDAcc)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	pockets, err := a.accountService.ListPockets(ctx.Request.Context(), uuidAcc, authPayload)
+	if err != nil {
+		if errors.Is(err, service.ErrPocketAccount) {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+			return
+		} else if err.Error() == "unauthorized" {
+			log.Println("Error: Unauthorized")
+			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Unauthorized", nil, nil)
+			return
+		} else if err.Error() == "no rows in result set" {
+			log.Println("Error: Data not found")
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
+			return
+		}
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Pockets found", pockets)
+}
+
+// CreatePocket opens a named pocket under the account. The pocket shares the currency and the
+// members of the account. Only owners of the account can create pockets.
+func (a *AccountController) CreatePocket(ctx *gin.Context) {
+	var req request.GetAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	uuidAcc, err := helper.ConvertStringToUUID(req.UUIDAcc)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	var request request.CreatePocketRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), request)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	res := helper.DoValidation(&request)
+	if len(res) > 0 {
+		log.Println("Error: Validation error")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Validation error", nil, res)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	pocket, err := a.accountService.CreatePocket(ctx.Request.Context(), uuidAcc, request, authPayload)
+	if err != nil {
+		if errors.Is(err, service.ErrPocketAccount) {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+			return
+		} else if errors.Is(err, service.ErrPocketNameTaken) {
+			helper.ReturnJSONError(ctx, http.StatusConflict, err.Error(), nil, nil)
+			return
+		} else if err.Error() == "unauthorized" {
+			log.Println("Error: Unauthorized")
+			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Unauthorized", nil, nil)
+			return
+		} else if err.Error() == "no rows in result set" {
+			log.Println("Error: Data not found")
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
+			return
+		}
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusCreated, "Pocket created", pocket)
+}
+
+// MovePocketFunds moves funds between the account and one of its pockets, in the direction given
+// by the request. The move is booked instantly and is not counted as a transfer.
+func (a *AccountController) MovePocketFunds(ctx *gin.Context) {
+	var req request.AccountPocketRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	uuidAcc, err := helper.ConvertStringToUUID(req.UUIDAcc)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	uuidPocket, err := helper.ConvertStringToUUID(req.UUIDPocket)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	var request request.PocketMoveRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), request)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	res := helper.DoValidation(&request)
+	if len(res) > 0 {
+		log.Println("Error: Validation error")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Validation error", nil, res)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	moved, err := a.accountService.MovePocketFunds(ctx.Request.Context(), uuidAcc, uuidPocket, request, authPayload)
+	if err != nil {
+		if errors.Is(err, service.ErrPocketAccount) || errors.Is(err, service.ErrPocketCurrency) || errors.Is(err, db.ErrBalanceNotEnough) {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+			return
+		} else if errors.Is(err, service.ErrMemberTransferLimit) || errors.Is(err, service.ErrAccountMemberForbidden) {
+			helper.ReturnJSONError(ctx, http.StatusForbidden, err.Error(), nil, nil)
+			return
+		} else if err.Error() == "unauthorized" {
+			log.Println("Error: Unauthorized")
+			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Unauthorized", nil, nil)
+			return
+		} else if err.Error() == "no rows in result set" {
+			log.Println("Error: Data not found")
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
+			return
+		}
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Pocket funds moved", moved)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/fajaramaulana/simple_bank_project/util"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -31,6 +32,7 @@ func TestMockGetAccountByUUIDController(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	user := randomUser3()
 	account := randomAccount(t, user.UserUuid)
+	pocket := randomPocket(t, account)
 
 	testCases := []struct {
 		name          string
@@ -49,11 +51,46 @@ func TestMockGetAccountByUUIDController(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: account.ID, UserUuid: user.UserUuid}).Times(1).Return(accountOwner(account), nil)
 				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().ListPocketsByParentID(gomock.Any(), account.ID).Times(1).Return([]db.ListPocketsByParentIDRow{{
+					ID:              pocket.ID,
+					AccountUuid:     pocket.AccountUuid,
+					Currency:        pocket.Currency,
+					Balance:         pocket.Balance,
+					ParentAccountID: pocket.ParentAccountID,
+					PocketName:      pocket.PocketName,
+				}}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccount(t, recorder.Body, account)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				consolidated := new(big.Int).Add(helper.NumericToBigInt(account.Balance), helper.NumericToBigInt(pocket.Balance))
+				require.Equal(t, consolidated.String(), data["consolidated_balance"])
+				require.Len(t, data["pockets"], 1)
+
+				requireBodyMatchAccount(t, bytes.NewBuffer(recorder.Body.Bytes()), account)
+			},
+		},
+		{
+			name:        "Unauthorized-not member",
+			AccountUuid: account.AccountUuid,
+			paramUuid:   account.AccountUuid.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTest(t, request, tokenMaker, middleware.AuthorizationTypeBearer, time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, pgx.ErrNoRows)
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
 		{
@@ -412,6 +449,614 @@ func TestGetAccountsController(t *testing.T) {
 	}
 }
 
+func TestGetAccountBalanceController(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+	user := randomUser3()
+	account := randomAccount(t, user.UserUuid)
+	asOf := time.Date(2024, time.March, 31, 23, 59, 0, 0, time.UTC)
+
+	balance := db.BalanceAsOfResult{
+		AccountID: account.ID,
+		Balance:   pgtype.Numeric{Int: big.NewInt(1500), Exp: 0, Valid: true},
+		AsOf:      asOf,
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "as_of=" + asOf.Format(time.RFC3339),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, user.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: account.ID, UserUuid: user.UserUuid}).Times(1).Return(accountOwner(account), nil)
+				store.EXPECT().GetBalanceAsOf(gomock.Any(), account.ID, asOf).Times(1).Return(balance, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				require.Equal(t, account.AccountUuid.String(), data["account_uuid"])
+				require.Equal(t, "1500", data["balance"])
+			},
+		},
+		{
+			name:  "OK-admin",
+			query: "as_of=" + asOf.Format(time.RFC3339),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTest(t, request, tokenMaker, middleware.AuthorizationTypeBearer, time.Minute, "admin")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetBalanceAsOf(gomock.Any(), account.ID, asOf).Times(1).Return(balance, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "Unauthorized",
+			query: "as_of=" + asOf.Format(time.RFC3339),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTest(t, request, tokenMaker, middleware.AuthorizationTypeBearer, time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, pgx.ErrNoRows)
+				store.EXPECT().GetBalanceAsOf(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "BadRequest-missing as_of",
+			query: "",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, user.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "BadRequest-future as_of",
+			query: "as_of=" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, user.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotFound",
+			query: "as_of=" + asOf.Format(time.RFC3339),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, user.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(db.GetAccountByUUIDRow{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			// Initialize the server with the mock store
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			// Define the URL path for the request
+			urlPath := fmt.Sprintf("/api/v1/account/%s/balance?%s", account.AccountUuid.String(), tc.query)
+
+			// Initialize the recorder and request
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, urlPath, nil)
+			require.NoError(t, err)
+
+			// Set up authentication for the request
+			tc.setupAuth(t, request, server.TokenMaker)
+
+			// Serve the request
+			server.Engine.ServeHTTP(recorder, request)
+
+			// Check the response
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetAccountMemberController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	owner := randomUser3()
+	target := randomUser3()
+	account := randomAccount(t, owner.UserUuid)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"user_uuid": target.UserUuid.String(), "role": "signatory", "permission": "transfer_limit", "transfer_limit": 500},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, owner.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: account.ID, UserUuid: owner.UserUuid}).Times(1).Return(accountOwner(account), nil)
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), target.UserUuid).Times(1).Return(target, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: account.ID, UserUuid: target.UserUuid}).Times(1).Return(db.AccountMember{}, pgx.ErrNoRows)
+				store.EXPECT().SetAccountMemberTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.AddAccountMemberParams) (db.AccountMember, error) {
+						require.Equal(t, "signatory", arg.Role)
+						require.Equal(t, "transfer_limit", arg.Permission)
+						require.Equal(t, int64(500), arg.TransferLimit.Int.Int64())
+						return db.AccountMember{
+							AccountID:     arg.AccountID,
+							UserUuid:      arg.UserUuid,
+							Role:          arg.Role,
+							Permission:    arg.Permission,
+							TransferLimit: arg.TransferLimit,
+						}, nil
+					})
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				require.Equal(t, target.UserUuid.String(), data["user_uuid"])
+				require.Equal(t, "500", data["transfer_limit"])
+			},
+		},
+		{
+			name: "Unauthorized-signatory",
+			body: gin.H{"user_uuid": target.UserUuid.String(), "role": "signatory", "permission": "view"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, owner.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				signatory := accountOwner(account)
+				signatory.Role = "signatory"
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(signatory, nil)
+				store.EXPECT().SetAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Conflict-last owner",
+			body: gin.H{"user_uuid": owner.UserUuid.String(), "role": "signatory", "permission": "view"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, owner.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(2).Return(accountOwner(account), nil)
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), owner.UserUuid).Times(1).Return(owner, nil)
+				store.EXPECT().SetAccountMemberTx(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, db.ErrLastAccountOwner)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest-owner without transfer",
+			body: gin.H{"user_uuid": target.UserUuid.String(), "role": "owner", "permission": "view"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, owner.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest-missing transfer limit",
+			body: gin.H{"user_uuid": target.UserUuid.String(), "role": "signatory", "permission": "transfer_limit"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, owner.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			urlPath := fmt.Sprintf("/api/v1/account/%s/members", account.AccountUuid.String())
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, urlPath, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRemoveAccountMemberController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	owner := randomUser3()
+	signatory := randomUser3()
+	account := randomAccount(t, owner.UserUuid)
+
+	signatoryMember := accountOwner(account)
+	signatoryMember.UserUuid = signatory.UserUuid
+	signatoryMember.Role = "signatory"
+
+	testCases := []struct {
+		name          string
+		userUUID      uuid.UUID
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK-self",
+			userUUID: signatory.UserUuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, signatory.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: account.ID, UserUuid: signatory.UserUuid}).Times(1).Return(signatoryMember, nil)
+				store.EXPECT().RemoveAccountMemberTx(gomock.Any(), db.RemoveAccountMemberParams{AccountID: account.ID, UserUuid: signatory.UserUuid}).Times(1).Return(signatoryMember, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Unauthorized-signatory removes other",
+			userUUID: owner.UserUuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, signatory.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: account.ID, UserUuid: signatory.UserUuid}).Times(1).Return(signatoryMember, nil)
+				store.EXPECT().RemoveAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "Conflict-last owner",
+			userUUID: owner.UserUuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, owner.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(accountOwner(account), nil)
+				store.EXPECT().RemoveAccountMemberTx(gomock.Any(), db.RemoveAccountMemberParams{AccountID: account.ID, UserUuid: owner.UserUuid}).Times(1).Return(db.AccountMember{}, db.ErrLastAccountOwner)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			urlPath := fmt.Sprintf("/api/v1/account/%s/members/%s", account.AccountUuid.String(), tc.userUUID.String())
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodDelete, urlPath, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreatePocketController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := randomUser3()
+	account := randomAccount(t, user.UserUuid)
+	pocket := randomPocket(t, account)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": "holiday"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(accountOwner(account), nil)
+				store.EXPECT().ListPocketsByParentID(gomock.Any(), account.ID).Times(1).Return([]db.ListPocketsByParentIDRow{}, nil)
+				store.EXPECT().CreatePocket(gomock.Any(), db.CreatePocketParams{PocketName: "holiday", ParentAccountID: account.ID}).Times(1).
+					Return(db.CreatePocketRow{
+						AccountUuid:     uuid.New(),
+						Currency:        account.Currency,
+						Balance:         pgtype.Numeric{Int: big.NewInt(0), Valid: true},
+						ParentAccountID: pgtype.Int8{Int64: account.ID, Valid: true},
+						PocketName:      pgtype.Text{String: "holiday", Valid: true},
+					}, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "Conflict-name taken",
+			body: gin.H{"name": pocket.PocketName.String},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(accountOwner(account), nil)
+				store.EXPECT().ListPocketsByParentID(gomock.Any(), account.ID).Times(1).Return([]db.ListPocketsByParentIDRow{{PocketName: pocket.PocketName}}, nil)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest-pocket of pocket",
+			body: gin.H{"name": "holiday"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(pocket, nil)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			urlPath := fmt.Sprintf("/api/v1/account/%s/pockets", account.AccountUuid.String())
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, urlPath, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTestAPI(t, request, server.TokenMaker, middleware.AuthorizationTypeBearer, user.UserUuid.String(), time.Minute, "customer")
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestMovePocketFundsController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := randomUser3()
+	account := randomAccount(t, user.UserUuid)
+	pocket := randomPocket(t, account)
+	otherPocket := randomPocket(t, account)
+	otherPocket.ParentAccountID = pgtype.Int8{Int64: account.ID + 1, Valid: true}
+	otherCurrencyPocket := randomPocket(t, account)
+	otherCurrencyPocket.Currency = account.Currency + "X"
+
+	testCases := []struct {
+		name          string
+		pocket        db.GetAccountByUUIDRow
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK-to pocket",
+			pocket: pocket,
+			body:   gin.H{"direction": "to_pocket", "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountByUUID(gomock.Any(), pocket.AccountUuid).Times(1).Return(pocket, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(accountOwner(account), nil)
+				store.EXPECT().PocketMoveTx(gomock.Any(), db.PocketMoveTxParam{FromAccountID: account.ID, ToAccountID: pocket.ID, Amount: 10}).Times(1).
+					Return(db.PocketMoveTxResult{
+						FromAccount: db.SubtractAccountBalanceRow{Balance: pgtype.Numeric{Int: big.NewInt(90), Valid: true}},
+						ToAccount:   db.AddAccountBalanceRow{Balance: pgtype.Numeric{Int: big.NewInt(10), Valid: true}},
+					}, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				require.Equal(t, "90", data["account_balance"])
+				require.Equal(t, "10", data["pocket"].(map[string]interface{})["balance"])
+			},
+		},
+		{
+			name:   "OK-to parent",
+			pocket: pocket,
+			body:   gin.H{"direction": "to_parent", "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountByUUID(gomock.Any(), pocket.AccountUuid).Times(1).Return(pocket, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(accountOwner(account), nil)
+				store.EXPECT().PocketMoveTx(gomock.Any(), db.PocketMoveTxParam{FromAccountID: pocket.ID, ToAccountID: account.ID, Amount: 10}).Times(1).
+					Return(db.PocketMoveTxResult{}, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound-pocket of another account",
+			pocket: otherPocket,
+			body:   gin.H{"direction": "to_pocket", "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountByUUID(gomock.Any(), otherPocket.AccountUuid).Times(1).Return(otherPocket, nil)
+				store.EXPECT().PocketMoveTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest-pocket in another currency",
+			pocket: otherCurrencyPocket,
+			body:   gin.H{"direction": "to_pocket", "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountByUUID(gomock.Any(), otherCurrencyPocket.AccountUuid).Times(1).Return(otherCurrencyPocket, nil)
+				store.EXPECT().PocketMoveTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "Forbidden-view member",
+			pocket: pocket,
+			body:   gin.H{"direction": "to_pocket", "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				viewer := accountOwner(account)
+				viewer.Role = "signatory"
+				viewer.Permission = "view"
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountByUUID(gomock.Any(), pocket.AccountUuid).Times(1).Return(pocket, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(viewer, nil)
+				store.EXPECT().PocketMoveTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest-balance not enough",
+			pocket: pocket,
+			body:   gin.H{"direction": "to_parent", "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), account.AccountUuid).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountByUUID(gomock.Any(), pocket.AccountUuid).Times(1).Return(pocket, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(accountOwner(account), nil)
+				store.EXPECT().PocketMoveTx(gomock.Any(), gomock.Any()).Times(1).Return(db.PocketMoveTxResult{}, db.ErrBalanceNotEnough)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest-invalid direction",
+			pocket: pocket,
+			body:   gin.H{"direction": "sideways", "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByUUID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			urlPath := fmt.Sprintf("/api/v1/account/%s/pockets/%s/move", account.AccountUuid.String(), tc.pocket.AccountUuid.String())
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, urlPath, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTestAPI(t, request, server.TokenMaker, middleware.AuthorizationTypeBearer, user.UserUuid.String(), time.Minute, "customer")
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.GetAccountByUUIDRow) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
@@ -445,3 +1090,20 @@ func randomAccount(t *testing.T, Useruuid uuid.UUID) db.GetAccountByUUIDRow {
 		UserUuid:    Useruuid,
 	}
 }
+
+func accountOwner(account db.GetAccountByUUIDRow) db.AccountMember {
+	return db.AccountMember{
+		AccountID:  account.ID,
+		UserUuid:   account.UserUuid,
+		Role:       "owner",
+		Permission: "transfer",
+	}
+}
+
+func randomPocket(t *testing.T, parent db.GetAccountByUUIDRow) db.GetAccountByUUIDRow {
+	pocket := randomAccount(t, parent.UserUuid)
+	pocket.Currency = parent.Currency
+	pocket.ParentAccountID = pgtype.Int8{Int64: parent.ID, Valid: true}
+	pocket.PocketName = pgtype.Text{String: util.RandomName(), Valid: true}
+	return pocket
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditController handles HTTP requests related to the audit log.
+type AuditController struct {
+	auditService *service.AuditService
+}
+
+func NewAuditController(auditService *service.AuditService) *AuditController {
+	return &AuditController{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLogs lists the audit log, newest first. It can be filtered by actor_uuid, action,
+// entity_type, entity_id and a from/to time range (RFC 3339). Requires audit:read.
+func (ac *AuditController) ListAuditLogs(ctx *gin.Context) {
+	var req request.ListAuditLogRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	logs, totalData, err := ac.auditService.ListAuditLogs(ctx.Request.Context(), req)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSONWithMetaPage(ctx, http.StatusOK, "Audit log found", logs, int(totalData), len(logs), int(req.Page), int(req.Limit))
+}
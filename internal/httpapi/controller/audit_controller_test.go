@@ -0,0 +1,127 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/setup"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListAuditLogsController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin := randomUser3()
+	actorUUID := uuid.New()
+
+	entry := db.AuditLog{
+		ID:         1,
+		AuditUuid:  uuid.New(),
+		ActorUuid:  pgtype.UUID{Bytes: actorUUID, Valid: true},
+		ActorRole:  "customer",
+		Action:     audit.ActionAccountUpdate,
+		EntityType: audit.EntityAccount,
+		EntityID:   uuid.NewString(),
+		Before:     []byte(`{"currency":"USD"}`),
+		After:      []byte(`{"currency":"IDR"}`),
+		ClientIp:   "10.0.0.1",
+		UserAgent:  "test-agent",
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			role:  "admin",
+			query: "?page=1&limit=10&actor_uuid=" + actorUUID.String() + "&action=account.update&from=2024-01-01T00:00:00Z",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.ListAuditLogsParams) ([]db.AuditLog, error) {
+						require.Equal(t, pgtype.UUID{Bytes: actorUUID, Valid: true}, arg.ActorUuid)
+						require.Equal(t, pgtype.Text{String: audit.ActionAccountUpdate, Valid: true}, arg.Action)
+						require.False(t, arg.EntityType.Valid)
+						require.True(t, arg.FromTime.Valid)
+						require.False(t, arg.ToTime.Valid)
+						require.Equal(t, int32(10), arg.PageLimit)
+						require.Equal(t, int32(0), arg.PageOffset)
+						return []db.AuditLog{entry}, nil
+					})
+				store.EXPECT().CountAuditLogs(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].([]interface{})
+				require.Len(t, data, 1)
+				item := data[0].(map[string]interface{})
+				require.Equal(t, entry.AuditUuid.String(), item["audit_uuid"])
+				require.Equal(t, actorUUID.String(), item["actor_uuid"])
+				require.Equal(t, "USD", item["before"].(map[string]interface{})["currency"])
+				require.Equal(t, "IDR", item["after"].(map[string]interface{})["currency"])
+			},
+		},
+		{
+			name:  "Unauthorized-customer",
+			role:  "customer",
+			query: "?page=1&limit=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "BadRequest-invalid actor uuid",
+			role:  "admin",
+			query: "?page=1&limit=10&actor_uuid=not-a-uuid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/api/v1/audit-logs"+tc.query, nil)
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTestAPI(t, request, server.TokenMaker, middleware.AuthorizationTypeBearer, admin.UserUuid.String(), time.Minute, tc.role)
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,15 +1,30 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/service"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordreset"
+	"github.com/fajaramaulana/simple_bank_project/internal/stepup"
 	"github.com/gin-gonic/gin"
 )
 
+// deviceTokenHeader carries the device token a login returned, identifying the device of the client.
+const deviceTokenHeader = "X-Device-Token"
+
 type AuthController struct {
 	authService *service.AuthService
 }
@@ -45,25 +60,162 @@ func (a *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	responseService, err := a.authService.Login(ctx, req.Username, req.Password, userAgent, clientIp)
+	responseService, err := a.authService.Login(ctx, req.Username, req.Password, ctx.GetHeader(deviceTokenHeader), userAgent, clientIp)
 	if err != nil {
 		log.Printf("Error: %s", err.Error())
 
+		var lockedErr *lockout.LockedError
+		if errors.As(err, &lockedErr) {
+			ctx.Header("Retry-After", strconv.Itoa(int(lockedErr.RetryAfter.Seconds())))
+			if lockedErr.Locked {
+				helper.ReturnJSONError(ctx, http.StatusTooManyRequests, "Too many failed login attempts, login is locked", nil, nil)
+				return
+			}
+			helper.ReturnJSONError(ctx, http.StatusTooManyRequests, "Too many failed login attempts, please retry later", nil, nil)
+			return
+		}
+
 		if err == service.ErrUserNotFound {
 			helper.ReturnJSONError(ctx, http.StatusNotFound, "User not found", nil, nil)
 			return
 		} else if err == service.ErrorInvalidPassword {
 			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Invalid password", nil, nil)
 			return
+		} else if err == service.ErrUserBlocked {
+			helper.ReturnJSONError(ctx, http.StatusForbidden, "User is blocked", nil, nil)
+			return
+		} else if err == service.ErrPasswordResetRequired {
+			helper.ReturnJSONError(ctx, http.StatusForbidden, "Password reset required", nil, nil)
+			return
 		} else {
 			helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, err.Error())
 			return
 		}
 	}
 
+	if responseService.MFARequired {
+		helper.ReturnJSON(ctx, http.StatusOK, "Two-factor authentication required", responseService)
+		return
+	}
+	if responseService.DeviceConfirmationRequired {
+		helper.ReturnJSON(ctx, http.StatusOK, "Confirm the new device with the code sent to your email", responseService)
+		return
+	}
+
 	helper.ReturnJSON(ctx, http.StatusOK, "Login success", responseService)
 }
 
+// VerifyLoginMFA completes a login that requires two-factor authentication.
+func (a *AuthController) VerifyLoginMFA(ctx *gin.Context) {
+	var req request.MFALoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		massage, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", massage)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, massage, nil, data)
+		return
+	}
+
+	// get user agent
+	userAgent := ctx.GetHeader("User-Agent")
+	if userAgent == "" {
+		log.Printf("User-Agent is required")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "User-Agent is required", nil, nil)
+		return
+	}
+
+	// get client ip
+	clientIp := ctx.ClientIP()
+	if clientIp == "" {
+		log.Printf("Client IP is required")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Client IP is required", nil, nil)
+		return
+	}
+
+	responseService, err := a.authService.VerifyLoginMFA(ctx, req.MFAToken, req.Code, ctx.GetHeader(deviceTokenHeader), userAgent, clientIp)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			log.Printf("Error: %s", err.Error())
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "User not found", nil, nil)
+			return
+		} else if err == service.ErrUserBlocked {
+			log.Printf("Error: %s", err.Error())
+			helper.ReturnJSONError(ctx, http.StatusForbidden, "User is blocked", nil, nil)
+			return
+		} else if err == service.ErrPasswordResetRequired {
+			log.Printf("Error: %s", err.Error())
+			helper.ReturnJSONError(ctx, http.StatusForbidden, "Password reset required", nil, nil)
+			return
+		}
+		returnMFAError(ctx, err)
+		return
+	}
+
+	if responseService.DeviceConfirmationRequired {
+		helper.ReturnJSON(ctx, http.StatusOK, "Confirm the new device with the code sent to your email", responseService)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Login success", responseService)
+}
+
+// ConfirmDevice completes a login from a new device with the code emailed to the user.
+func (a *AuthController) ConfirmDevice(ctx *gin.Context) {
+	var req request.ConfirmDeviceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		massage, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", massage)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, massage, nil, data)
+		return
+	}
+
+	// get user agent
+	userAgent := ctx.GetHeader("User-Agent")
+	if userAgent == "" {
+		log.Printf("User-Agent is required")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "User-Agent is required", nil, nil)
+		return
+	}
+
+	responseService, err := a.authService.ConfirmDevice(ctx.Request.Context(), req.Code, ctx.GetHeader(deviceTokenHeader), userAgent, ctx.ClientIP())
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		switch {
+		case errors.Is(err, device.ErrInvalidConfirmationCode):
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid or expired device confirmation code", nil, nil)
+		case err == service.ErrUserNotFound:
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "User not found", nil, nil)
+		case err == service.ErrUserBlocked:
+			helper.ReturnJSONError(ctx, http.StatusForbidden, "User is blocked", nil, nil)
+		case err == service.ErrPasswordResetRequired:
+			helper.ReturnJSONError(ctx, http.StatusForbidden, "Password reset required", nil, nil)
+		default:
+			helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		}
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Login success", responseService)
+}
+
+// EnrollLoginMFA starts the two-factor enrollment a role requires, using the MFA token of the login.
+func (a *AuthController) EnrollLoginMFA(ctx *gin.Context) {
+	var req request.MFAEnrollLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		massage, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", massage)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, massage, nil, data)
+		return
+	}
+
+	enrollment, err := a.authService.EnrollLoginMFA(ctx.Request.Context(), req.MFAToken)
+	if err != nil {
+		returnMFAError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Two-factor enrollment started", enrollment)
+}
+
 func (a *AuthController) RefreshToken(ctx *gin.Context) {
 	var req request.RefreshRequest
 
@@ -101,6 +253,18 @@ func (a *AuthController) RefreshToken(ctx *gin.Context) {
 		} else if err == service.ErrorInvalidPassword {
 			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Invalid password", nil, nil)
 			return
+		} else if err == service.ErrUserBlocked {
+			helper.ReturnJSONError(ctx, http.StatusForbidden, "User is blocked", nil, nil)
+			return
+		} else if err == service.ErrPasswordResetRequired {
+			helper.ReturnJSONError(ctx, http.StatusForbidden, "Password reset required", nil, nil)
+			return
+		} else if err == service.ErrRefreshTokenReused {
+			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Refresh token reused, please login again", nil, nil)
+			return
+		} else if err.Error() == "mismatched session token" {
+			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Mismatched session token", nil, nil)
+			return
 		} else if err.Error() == "session is blocked" {
 			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Session is blocked", nil, nil)
 			return
@@ -115,3 +279,418 @@ func (a *AuthController) RefreshToken(ctx *gin.Context) {
 
 	helper.ReturnJSON(ctx, http.StatusOK, "Refresh token success", responseService)
 }
+
+// ForgotPassword emails a password reset code. The response is the same whether or not the email
+// address is registered.
+func (a *AuthController) ForgotPassword(ctx *gin.Context) {
+	var req request.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		massage, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", massage)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, massage, nil, data)
+		return
+	}
+
+	err := a.authService.RequestPasswordReset(ctx.Request.Context(), req.Email, ctx.ClientIP())
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+
+		var limitedErr *passwordreset.LimitedError
+		if errors.As(err, &limitedErr) {
+			ctx.Header("Retry-After", strconv.Itoa(int(limitedErr.RetryAfter.Seconds())))
+			helper.ReturnJSONError(ctx, http.StatusTooManyRequests, "Too many password reset requests, please retry later", nil, nil)
+			return
+		}
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "If the email is registered, a password reset code has been sent", nil)
+}
+
+// ResetPassword sets a new password with an emailed password reset code.
+func (a *AuthController) ResetPassword(ctx *gin.Context) {
+	var req request.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		massage, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", massage)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, massage, nil, data)
+		return
+	}
+
+	err := a.authService.ResetPassword(ctx.Request.Context(), req.Email, req.Code, req.NewPassword)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		if err == service.ErrInvalidResetCode {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid or expired password reset code", nil, nil)
+			return
+		}
+		if errors.Is(err, passwordpolicy.ErrWeakPassword) {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+			return
+		}
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Password reset success", nil)
+}
+
+// UnlockLogin lifts a login lockout with the code emailed when the login was locked.
+func (a *AuthController) UnlockLogin(ctx *gin.Context) {
+	var req request.UnlockLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		massage, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", massage)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, massage, nil, data)
+		return
+	}
+
+	err := a.authService.UnlockLogin(ctx.Request.Context(), req.Code, ctx.GetHeader("User-Agent"), ctx.ClientIP())
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		if err == service.ErrInvalidUnlockCode {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid or expired unlock code", nil, nil)
+			return
+		}
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Login unlocked", nil)
+}
+
+// VerifyEmail verifies the email address of a user with the emailed verification code.
+func (a *AuthController) VerifyEmail(ctx *gin.Context) {
+	var req request.VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		massage, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", massage)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, massage, nil, data)
+		return
+	}
+
+	err := a.authService.VerifyEmail(ctx.Request.Context(), req.Code)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		switch {
+		case errors.Is(err, emailverification.ErrInvalidCode), errors.Is(err, emailverification.ErrCodeExpired):
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid or expired verification code", nil, nil)
+		case errors.Is(err, emailverification.ErrAlreadyVerified):
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, "Email already verified", nil, nil)
+		default:
+			helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		}
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Email verified successfully", nil)
+}
+
+// ResendVerificationEmail emails a new verification code to the authenticated user.
+func (a *AuthController) ResendVerificationEmail(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	err := a.authService.ResendVerificationEmail(ctx.Request.Context(), authPayload)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		switch {
+		case errors.Is(err, emailverification.ErrResendTooSoon):
+			helper.ReturnJSONError(ctx, http.StatusTooManyRequests, err.Error(), nil, nil)
+		case errors.Is(err, emailverification.ErrAlreadyVerified):
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, "Email already verified", nil, nil)
+		default:
+			helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		}
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Verification email sent", nil)
+}
+
+// StepUp authenticates the user again with the password or a two-factor code and returns an access
+// token for the operations that require a recent authentication.
+func (a *AuthController) StepUp(ctx *gin.Context) {
+	var req request.StepUpRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		massage, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", massage)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, massage, nil, data)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	res, err := a.authService.StepUp(ctx.Request.Context(), authPayload, req.Password, req.Code, ctx.GetHeader("User-Agent"), ctx.ClientIP())
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+
+		var lockedErr *lockout.LockedError
+		if errors.As(err, &lockedErr) {
+			ctx.Header("Retry-After", strconv.Itoa(int(lockedErr.RetryAfter.Seconds())))
+			helper.ReturnJSONError(ctx, http.StatusTooManyRequests, "Too many failed login attempts, please retry later", nil, nil)
+			return
+		}
+		if errors.Is(err, stepup.ErrInvalidCredentials) {
+			helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Invalid password or two-factor code", nil, nil)
+			return
+		}
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Step-up authentication success", res)
+}
+
+// returnStepUpRequired answers a request that needs a more recent authentication with the challenge
+// of RFC 9470, so the client knows to step up and retry.
+func returnStepUpRequired(ctx *gin.Context, requiredErr *stepup.RequiredError) {
+	maxAge := int64(requiredErr.MaxAge.Seconds())
+	ctx.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_user_authentication", error_description="%s", max_age=%d`, requiredErr.Error(), maxAge))
+	helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Step-up authentication required", response.StepUpRequiredResponse{
+		Operation: requiredErr.Operation,
+		MaxAge:    maxAge,
+	}, nil)
+}
+
+// Logout revokes the session of the access token, so neither its refresh token nor its access
+// tokens can be used again.
+func (a *AuthController) Logout(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	err := a.authService.Logout(ctx.Request.Context(), authPayload)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		if err == service.ErrSessionNotFound {
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "Session not found", nil, nil)
+			return
+		}
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Logout success", nil)
+}
+
+func (a *AuthController) ListSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	sessions, err := a.authService.ListSessions(ctx.Request.Context(), authPayload)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Session found", sessions)
+}
+
+// ListDevices lists the trusted devices of the user.
+func (a *AuthController) ListDevices(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	devices, err := a.authService.ListDevices(ctx.Request.Context(), authPayload, ctx.GetHeader(deviceTokenHeader))
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Device found", devices)
+}
+
+// RemoveDevice takes a device off the trusted devices of the user.
+func (a *AuthController) RemoveDevice(ctx *gin.Context) {
+	var req request.DeviceRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	deviceUUID, err := helper.ConvertStringToUUID(req.DeviceUUID)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	err = a.authService.RemoveDevice(ctx.Request.Context(), authPayload, deviceUUID, ctx.GetHeader("User-Agent"), ctx.ClientIP())
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		if errors.Is(err, device.ErrDeviceNotFound) {
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "Device not found", nil, nil)
+			return
+		}
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Device removed", nil)
+}
+
+func (a *AuthController) ListSecurityEvents(ctx *gin.Context) {
+	var req request.ListSecurityEventRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	events, err := a.authService.ListSecurityEvents(ctx.Request.Context(), authPayload, req)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Security event found", events)
+}
+
+func (a *AuthController) RevokeSession(ctx *gin.Context) {
+	var req request.SessionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	uuidSession, err := helper.ConvertStringToUUID(req.UUIDSession)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	err = a.authService.RevokeSession(ctx.Request.Context(), authPayload, uuidSession)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		if err == service.ErrSessionNotFound {
+			helper.ReturnJSONError(ctx, http.StatusNotFound, "Session not found", nil, nil)
+			return
+		}
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Session revoked", nil)
+}
+
+// RevokeOtherSessions signs the user out everywhere except the current session.
+func (a *AuthController) RevokeOtherSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	revoked, err := a.authService.RevokeOtherSessions(ctx.Request.Context(), authPayload)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Sessions revoked", response.RevokeSessionsResponse{Revoked: revoked})
+}
+
+func (a *AuthController) EnrollTOTP(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	enrollment, err := a.authService.EnrollTOTP(ctx.Request.Context(), authPayload)
+	if err != nil {
+		returnMFAError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Two-factor enrollment started", enrollment)
+}
+
+// ConfirmTOTP enables two-factor authentication and returns the recovery codes.
+func (a *AuthController) ConfirmTOTP(ctx *gin.Context) {
+	code, ok := bindMFACode(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	recoveryCodes, err := a.authService.ConfirmTOTP(ctx.Request.Context(), authPayload, code)
+	if err != nil {
+		returnMFAError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Two-factor authentication enabled", recoveryCodes)
+}
+
+func (a *AuthController) DisableTOTP(ctx *gin.Context) {
+	code, ok := bindMFACode(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	err := a.authService.DisableTOTP(ctx.Request.Context(), authPayload, code)
+	if err != nil {
+		returnMFAError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+func (a *AuthController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	code, ok := bindMFACode(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	recoveryCodes, err := a.authService.RegenerateRecoveryCodes(ctx.Request.Context(), authPayload, code)
+	if err != nil {
+		returnMFAError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Recovery codes regenerated", recoveryCodes)
+}
+
+func bindMFACode(ctx *gin.Context) (string, bool) {
+	var req request.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		massage, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", massage)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, massage, nil, data)
+		return "", false
+	}
+
+	return req.Code, true
+}
+
+func returnMFAError(ctx *gin.Context, err error) {
+	log.Printf("Error: %s", err.Error())
+	switch err {
+	case service.ErrInvalidMFAToken:
+		helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Invalid or expired mfa token", nil, nil)
+	case service.ErrInvalidMFACode:
+		helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Invalid two-factor code", nil, nil)
+	case service.ErrMFANotEnrolled:
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Two-factor authentication is not enrolled", nil, nil)
+	case service.ErrMFAAlreadyEnabled:
+		helper.ReturnJSONError(ctx, http.StatusConflict, "Two-factor authentication is already enabled", nil, nil)
+	case service.ErrMFARequiredByRole:
+		helper.ReturnJSONError(ctx, http.StatusForbidden, "Two-factor authentication is required for your role", nil, nil)
+	case service.ErrUserNotFound:
+		helper.ReturnJSONError(ctx, http.StatusNotFound, "User not found", nil, nil)
+	default:
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+	}
+}
@@ -2,19 +2,38 @@ package controller_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/controller"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/service"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/setup"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/mfa"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordreset"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/securityevent"
 	"github.com/fajaramaulana/simple_bank_project/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -44,6 +63,9 @@ func TestAuthController_Login(t *testing.T) {
 			clientIp:  "1.1.1.1",
 			mockSetup: func(store *mockdb.MockStore) {
 				store.EXPECT().GetDetailLoginByUsername(gomock.Any(), user.Username).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(db.UserTotp{}, pgx.ErrNoRows)
+				store.EXPECT().GetRoleSetting(gomock.Any(), user.Role).Times(1).Return(db.RoleSetting{}, pgx.ErrNoRows)
+				expectKnownDevice(store)
 				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -62,6 +84,48 @@ func TestAuthController_Login(t *testing.T) {
 				require.Equal(t, "Login success", message)
 			},
 		},
+		{
+			name: "MFA Enabled",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			userAgent: "test",
+			clientIp:  "1.1.1.1",
+			mockSetup: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDetailLoginByUsername(gomock.Any(), user.Username).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(db.UserTotp{
+					UserUuid:    user.UserUuid,
+					ConfirmedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+				}, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				data := requireMFAChallenge(t, w)
+				require.Nil(t, data["mfa_enrollment_required"])
+			},
+		},
+		{
+			name: "MFA Required By Role",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			userAgent: "test",
+			clientIp:  "1.1.1.1",
+			mockSetup: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDetailLoginByUsername(gomock.Any(), user.Username).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(db.UserTotp{}, pgx.ErrNoRows)
+				store.EXPECT().GetRoleSetting(gomock.Any(), user.Role).Times(1).Return(db.RoleSetting{Role: user.Role, MfaRequired: true}, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				data := requireMFAChallenge(t, w)
+				require.Equal(t, true, data["mfa_enrollment_required"])
+			},
+		},
 		{
 			name: "Invalid Password",
 			body: gin.H{
@@ -89,6 +153,40 @@ func TestAuthController_Login(t *testing.T) {
 				require.Equal(t, "Invalid password", message)
 			},
 		},
+		{
+			name: "User Blocked",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			userAgent: "test",
+			clientIp:  "1.1.1.1",
+			mockSetup: func(store *mockdb.MockStore) {
+				blocked := user
+				blocked.IsBlocked = true
+				store.EXPECT().GetDetailLoginByUsername(gomock.Any(), user.Username).Times(1).Return(blocked, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "User is blocked",
+		},
+		{
+			name: "Password Reset Required",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			userAgent: "test",
+			clientIp:  "1.1.1.1",
+			mockSetup: func(store *mockdb.MockStore) {
+				resetRequired := user
+				resetRequired.PasswordResetRequired = true
+				store.EXPECT().GetDetailLoginByUsername(gomock.Any(), user.Username).Times(1).Return(resetRequired, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "Password reset required",
+		},
 		{
 			name: "Password Too Short",
 			body: gin.H{
@@ -199,7 +297,7 @@ func TestAuthController_Login(t *testing.T) {
 				"refresh_token_duration": (15 * time.Minute).String(),
 			}
 
-			service := service.NewAuthService(store, configToken)
+			service := service.NewAuthService(store, configToken, newTokenMaker(t, configToken), nil, newLoginGuard(t, store), newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), newRevocationList(t), newResetLimiter(t))
 			controller := controller.NewAuthController(service)
 
 			bodyJSON, err := json.Marshal(tt.body)
@@ -223,3 +321,1206 @@ func TestAuthController_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthController_LoginRehashesPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// the fixture is hashed with bcrypt, the hasher prefers argon2id
+	user, password := randomUser2(t)
+	hasher, err := passwordhash.NewHasher(passwordhash.Config{
+		Argon2id: passwordhash.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1},
+	})
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetDetailLoginByUsername(gomock.Any(), user.Username).Times(1).Return(user, nil)
+	store.EXPECT().RehashUserPassword(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RehashUserPasswordParams) (int64, error) {
+			require.Equal(t, user.UserUuid, arg.UserUuid)
+			require.Equal(t, user.HashedPassword, arg.OldHashedPassword)
+			rehash, err := hasher.Verify(password, arg.NewHashedPassword)
+			require.NoError(t, err)
+			require.False(t, rehash)
+			return 1, nil
+		})
+	store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(db.UserTotp{}, pgx.ErrNoRows)
+	store.EXPECT().GetRoleSetting(gomock.Any(), user.Role).Times(1).Return(db.RoleSetting{}, pgx.ErrNoRows)
+	expectKnownDevice(store)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, nil)
+
+	configToken := map[string]string{
+		"token_secret":           util.RandomString(32),
+		"access_token_duration":  time.Minute.String(),
+		"refresh_token_duration": (15 * time.Minute).String(),
+	}
+	controller := controller.NewAuthController(service.NewAuthService(store, configToken, newTokenMaker(t, configToken), nil, newLoginGuard(t, store), newPasswordPolicy(), hasher, newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), newRevocationList(t), newResetLimiter(t)))
+
+	bodyJSON, err := json.Marshal(gin.H{"username": user.Username, "password": password})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(bodyJSON))
+	ctx.Request.Header.Set("User-Agent", "test")
+	controller.Login(ctx)
+
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthController_ConfirmDevice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user, password := randomUser2(t)
+	pending := db.UserDevice{DeviceUuid: util.RandomUUID(), UserUuid: user.UserUuid}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetDetailLoginByUsername(gomock.Any(), user.Username).Times(1).Return(user, nil)
+	store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(db.UserTotp{}, pgx.ErrNoRows)
+	store.EXPECT().GetRoleSetting(gomock.Any(), user.Role).Times(1).Return(db.RoleSetting{}, pgx.ErrNoRows)
+	store.EXPECT().GetUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(db.UserDevice{}, pgx.ErrNoRows)
+	store.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(pending, nil)
+	store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(2).Return(db.SecurityEvent{}, nil)
+	store.EXPECT().TrustUserDevice(gomock.Any(), db.TrustUserDeviceParams{DeviceUuid: pending.DeviceUuid, UserUuid: user.UserUuid}).Times(1).Return(pending, nil)
+	store.EXPECT().RecordUserLoginIP(gomock.Any(), gomock.Any()).Times(1).Return(true, nil)
+	store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(db.GetUserByUserUUIDRow{
+		UserUuid: user.UserUuid,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     user.Role,
+	}, nil)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+			return db.Session{ID: arg.ID, FamilyID: arg.FamilyID, UserUuid: arg.UserUuid}, nil
+		})
+
+	configToken := map[string]string{
+		"token_secret":           util.RandomString(32),
+		"access_token_duration":  time.Minute.String(),
+		"refresh_token_duration": (15 * time.Minute).String(),
+	}
+	queue := &emailQueue{}
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	registry := device.NewRegistry(rdb, store, queue, device.Config{RequireConfirmation: true})
+	controller := controller.NewAuthController(service.NewAuthService(store, configToken, newTokenMaker(t, configToken), nil, newLoginGuard(t, store), newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), registry, newRevocationList(t), newResetLimiter(t)))
+
+	// the login from the new device gets no tokens
+	bodyJSON, err := json.Marshal(gin.H{"username": user.Username, "password": password})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(bodyJSON))
+	ctx.Request.Header.Set("User-Agent", "test")
+	controller.Login(ctx)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var login struct {
+		Data response.AuthLoginResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	require.True(t, login.Data.DeviceConfirmationRequired)
+	require.Empty(t, login.Data.AcessToken)
+	deviceToken := login.Data.DeviceToken
+	require.NotEmpty(t, deviceToken)
+
+	code := queue.codes[user.UserUuid]
+	require.NotEmpty(t, code)
+
+	// the emailed code confirms the device and starts the session
+	bodyJSON, err = json.Marshal(gin.H{"code": code})
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/login/device/confirm", bytes.NewReader(bodyJSON))
+	ctx.Request.Header.Set("User-Agent", "test")
+	ctx.Request.Header.Set("X-Device-Token", deviceToken)
+	controller.ConfirmDevice(ctx)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	require.NotEmpty(t, login.Data.AcessToken)
+	require.Equal(t, deviceToken, login.Data.DeviceToken)
+
+	// the code works once
+	w = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/login/device/confirm", bytes.NewReader(bodyJSON))
+	ctx.Request.Header.Set("User-Agent", "test")
+	ctx.Request.Header.Set("X-Device-Token", deviceToken)
+	controller.ConfirmDevice(ctx)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAuthController_LoginSlowsDownFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user, _ := randomUser2(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	// the third attempt is refused before the user is looked up
+	store.EXPECT().GetDetailLoginByUsername(gomock.Any(), user.Username).Times(2).Return(user, nil)
+
+	configToken := map[string]string{
+		"token_secret":           util.RandomString(32),
+		"access_token_duration":  time.Minute.String(),
+		"refresh_token_duration": (15 * time.Minute).String(),
+	}
+	controller := controller.NewAuthController(service.NewAuthService(store, configToken, newTokenMaker(t, configToken), nil, newLoginGuard(t, store), newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), newRevocationList(t), newResetLimiter(t)))
+
+	login := func() *httptest.ResponseRecorder {
+		bodyJSON, err := json.Marshal(gin.H{"username": user.Username, "password": "WrongPassword1!"})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(bodyJSON))
+		ctx.Request.Header.Set("User-Agent", "test")
+		controller.Login(ctx)
+		return w
+	}
+
+	require.Equal(t, http.StatusUnauthorized, login().Code)
+	require.Equal(t, http.StatusUnauthorized, login().Code)
+
+	w := login()
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+// requireMFAChallenge checks that the login asked for a two-factor code instead of issuing tokens.
+func requireMFAChallenge(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	require.Equal(t, http.StatusOK, w.Code)
+	var responseBody map[string]interface{}
+
+	err := json.Unmarshal(w.Body.Bytes(), &responseBody)
+	require.NoError(t, err)
+
+	meta, ok := responseBody["meta"].(map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, "Two-factor authentication required", meta["message"])
+
+	data, ok := responseBody["data"].(map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, true, data["mfa_required"])
+	require.NotEmpty(t, data["mfa_token"])
+	require.Empty(t, data["access_token"])
+	require.Empty(t, data["refresh_token"])
+
+	return data
+}
+
+func TestAuthController_VerifyLoginMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := randomUser3()
+	secret, err := mfa.GenerateSecret()
+	require.NoError(t, err)
+	code, err := mfa.Code(secret, mfa.Step(time.Now()))
+	require.NoError(t, err)
+
+	confirmed := db.UserTotp{
+		UserUuid:    user.UserUuid,
+		Secret:      secret,
+		ConfirmedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	pending := db.UserTotp{
+		UserUuid: user.UserUuid,
+		Secret:   secret,
+	}
+
+	testCases := []struct {
+		name          string
+		code          string
+		tokenKind     token.Kind
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			code:      code,
+			tokenKind: token.KindMFA,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(confirmed, nil)
+				store.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				expectKnownDevice(store)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, nil)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, w.Code)
+			},
+		},
+		{
+			name:      "OK-recovery code",
+			code:      "abcde-12345",
+			tokenKind: token.KindMFA,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(confirmed, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), db.UseRecoveryCodeParams{
+					UserUuid: user.UserUuid,
+					CodeHash: mfa.HashRecoveryCode("abcde-12345"),
+				}).Times(1).Return(int64(1), nil)
+				expectKnownDevice(store)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, nil)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, w.Code)
+			},
+		},
+		{
+			name:      "OK-enroll",
+			code:      code,
+			tokenKind: token.KindMFA,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(pending, nil)
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+				expectKnownDevice(store)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, nil)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, w.Code)
+
+				var responseBody struct {
+					Data response.AuthLoginResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+				require.Len(t, responseBody.Data.RecoveryCodes, mfa.RecoveryCodeCount)
+			},
+		},
+		{
+			name:      "Unauthorized-replayed code",
+			code:      code,
+			tokenKind: token.KindMFA,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(confirmed, nil)
+				store.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, w.Code)
+			},
+		},
+		{
+			name:      "Unauthorized-access token",
+			code:      code,
+			tokenKind: token.KindAccess,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, w.Code)
+			},
+		},
+		{
+			name:      "BadRequest-not enrolled",
+			code:      code,
+			tokenKind: token.KindMFA,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(db.UserTotp{}, pgx.ErrNoRows)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, w.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectTx(store)
+			tc.buildStubs(store)
+
+			configToken := map[string]string{
+				"token_secret":           util.RandomString(32),
+				"access_token_duration":  time.Minute.String(),
+				"refresh_token_duration": (15 * time.Minute).String(),
+			}
+
+			maker, err := token.NewPasetoMaker(configToken["token_secret"])
+			require.NoError(t, err)
+			mfaToken, _, err := maker.CreateToken(user.UserUuid.String(), uuid.Nil, tc.tokenKind, time.Minute, user.Role, time.Now())
+			require.NoError(t, err)
+
+			controller := controller.NewAuthController(service.NewAuthService(store, configToken, newTokenMaker(t, configToken), nil, newLoginGuard(t, store), newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), newRevocationList(t), newResetLimiter(t)))
+
+			bodyJSON, err := json.Marshal(gin.H{"mfa_token": mfaToken, "code": tc.code})
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/login/mfa", bytes.NewReader(bodyJSON))
+			ctx.Request.Header.Set("User-Agent", "test")
+
+			controller.VerifyLoginMFA(ctx)
+
+			tc.checkResponse(t, w)
+		})
+	}
+}
+
+func TestAuthController_VerifyLoginMFALocksOutWrongCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := randomUser3()
+	secret, err := mfa.GenerateSecret()
+	require.NoError(t, err)
+	code, err := mfa.Code(secret, mfa.Step(time.Now()))
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	// the second wrong code locks the user out and revokes the MFA token, so even the right code
+	// is refused afterwards
+	store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(2).Return(user, nil)
+	store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(2).Return(db.UserTotp{
+		UserUuid:    user.UserUuid,
+		Secret:      secret,
+		ConfirmedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}, nil)
+	store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(2).Return(int64(0), nil)
+	store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(1).Return(db.SecurityEvent{}, nil)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+
+	configToken := map[string]string{
+		"token_secret":           util.RandomString(32),
+		"access_token_duration":  time.Minute.String(),
+		"refresh_token_duration": (15 * time.Minute).String(),
+	}
+	maker := newTokenMaker(t, configToken)
+	mfaToken, payload, err := maker.CreateToken(user.UserUuid.String(), uuid.Nil, token.KindMFA, time.Minute, user.Role, time.Now())
+	require.NoError(t, err)
+
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	guard := lockout.NewGuard(rdb, store, &emailQueue{}, lockout.Config{MaxFailures: 2})
+	revocations := newRevocationList(t)
+	controller := controller.NewAuthController(service.NewAuthService(store, configToken, maker, nil, guard, newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), revocations, newResetLimiter(t)))
+
+	verify := func(code string) *httptest.ResponseRecorder {
+		bodyJSON, err := json.Marshal(gin.H{"mfa_token": mfaToken, "code": code})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/login/mfa", bytes.NewReader(bodyJSON))
+		ctx.Request.Header.Set("User-Agent", "test")
+		controller.VerifyLoginMFA(ctx)
+		return w
+	}
+
+	require.Equal(t, http.StatusUnauthorized, verify("abcde-12345").Code)
+	require.Equal(t, http.StatusUnauthorized, verify("abcde-12345").Code)
+	require.Equal(t, http.StatusUnauthorized, verify(code).Code)
+
+	err = revocations.Check(context.Background(), revocation.Token{
+		ID:        payload.ID,
+		UserUUID:  payload.UserUUID,
+		IssuedAt:  payload.IssuedAt,
+		ExpiredAt: payload.ExpiredAt,
+	})
+	require.ErrorIs(t, err, revocation.ErrRevoked)
+}
+
+// emailQueue records the queued codes and alerts instead of sending them.
+type emailQueue struct {
+	codes  map[uuid.UUID]string
+	alerts []string
+}
+
+func (q *emailQueue) QueueVerification(_ context.Context, userUUID uuid.UUID, _, code string, _ time.Time) error {
+	if q.codes == nil {
+		q.codes = make(map[uuid.UUID]string)
+	}
+	q.codes[userUUID] = code
+	return nil
+}
+
+func (q *emailQueue) QueuePasswordReset(_ context.Context, userUUID uuid.UUID, _, code string, _ time.Time) error {
+	if q.codes == nil {
+		q.codes = make(map[uuid.UUID]string)
+	}
+	q.codes[userUUID] = code
+	return nil
+}
+
+func (q *emailQueue) QueueAccountUnlock(_ context.Context, userUUID uuid.UUID, _, code string, _ time.Time) error {
+	if q.codes == nil {
+		q.codes = make(map[uuid.UUID]string)
+	}
+	q.codes[userUUID] = code
+	return nil
+}
+
+func (q *emailQueue) QueueDeviceConfirmation(_ context.Context, userUUID uuid.UUID, _, code string, _ time.Time) error {
+	if q.codes == nil {
+		q.codes = make(map[uuid.UUID]string)
+	}
+	q.codes[userUUID] = code
+	return nil
+}
+
+func (q *emailQueue) QueueNewDeviceAlert(_ context.Context, _ uuid.UUID, _, device string, _ time.Time) error {
+	q.alerts = append(q.alerts, device)
+	return nil
+}
+
+func TestAuthController_ForgotPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := db.GetUserByEmailRow{
+		UserUuid: util.RandomUUID(),
+		Username: util.RandomUsername(),
+		Email:    util.RandomEmail(),
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, w *httptest.ResponseRecorder, queue *emailQueue)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"email": user.Email},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().CreatePasswordResetTx(gomock.Any(), gomock.Any()).Times(1).Return(db.PasswordReset{}, nil)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder, queue *emailQueue) {
+				require.Equal(t, http.StatusOK, w.Code)
+				require.NotEmpty(t, queue.codes[user.UserUuid])
+			},
+		},
+		{
+			name: "OK-unknown email answers the same",
+			body: gin.H{"email": "unknown@example.com"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), "unknown@example.com").Times(1).Return(db.GetUserByEmailRow{}, pgx.ErrNoRows)
+				store.EXPECT().CreatePasswordResetTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder, queue *emailQueue) {
+				require.Equal(t, http.StatusOK, w.Code)
+				require.Empty(t, queue.codes)
+			},
+		},
+		{
+			name: "BadRequest-invalid email",
+			body: gin.H{"email": "not-an-email"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder, queue *emailQueue) {
+				require.Equal(t, http.StatusBadRequest, w.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			queue := &emailQueue{}
+			configToken := map[string]string{"token_secret": util.RandomString(32)}
+			controller := controller.NewAuthController(service.NewAuthService(store, configToken, newTokenMaker(t, configToken), queue, newLoginGuard(t, store), newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), newRevocationList(t), newResetLimiter(t)))
+
+			bodyJSON, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/password/forgot", bytes.NewReader(bodyJSON))
+
+			controller.ForgotPassword(ctx)
+
+			tc.checkResponse(t, w, queue)
+		})
+	}
+}
+
+func TestAuthController_ForgotPasswordThrottled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	// the second request for the address is refused before it is looked up
+	store.EXPECT().GetUserByEmail(gomock.Any(), "unknown@example.com").Times(1).Return(db.GetUserByEmailRow{}, pgx.ErrNoRows)
+
+	configToken := map[string]string{"token_secret": util.RandomString(32)}
+	controller := controller.NewAuthController(service.NewAuthService(store, configToken, newTokenMaker(t, configToken), &emailQueue{}, newLoginGuard(t, store), newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), newRevocationList(t), newResetLimiter(t)))
+
+	forgot := func(address string) *httptest.ResponseRecorder {
+		bodyJSON, err := json.Marshal(gin.H{"email": address})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/password/forgot", bytes.NewReader(bodyJSON))
+		controller.ForgotPassword(ctx)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, forgot("unknown@example.com").Code)
+
+	w := forgot("Unknown@Example.com")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestAuthController_ResetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := db.GetUserByEmailRow{
+		UserUuid: util.RandomUUID(),
+		Username: util.RandomUsername(),
+		Email:    util.RandomEmail(),
+	}
+	codeHash, err := util.MakePasswordBcrypt("123456")
+	require.NoError(t, err)
+	reset := db.PasswordReset{ID: 1, UserUuid: user.UserUuid, CodeHash: codeHash, ExpiresAt: time.Now().Add(time.Minute)}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"email": user.Email, "code": "123456", "new_password": "n3wPassword"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(1).Return(reset, nil)
+				store.EXPECT().IncrementPasswordResetAttempts(gomock.Any(), db.IncrementPasswordResetAttemptsParams{ID: reset.ID, MaxAttempts: passwordreset.MaxAttempts}).Times(1).Return(int64(1), nil)
+				store.EXPECT().ListRecentPasswordHashes(gomock.Any(), gomock.Any()).Times(1).Return([]string{}, nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, param db.ResetPasswordTxParam) (db.UpdateUserPasswordRow, error) {
+						require.Equal(t, reset.ID, param.ResetID)
+						require.NoError(t, util.CheckPasswordBcrypt("n3wPassword", param.HashedPassword))
+						return db.UpdateUserPasswordRow{UserUuid: user.UserUuid}, nil
+					})
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, w.Code)
+			},
+		},
+		{
+			name: "BadRequest-wrong code",
+			body: gin.H{"email": user.Email, "code": "000000", "new_password": "n3wPassword"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(1).Return(reset, nil)
+				store.EXPECT().IncrementPasswordResetAttempts(gomock.Any(), db.IncrementPasswordResetAttemptsParams{ID: reset.ID, MaxAttempts: passwordreset.MaxAttempts}).Times(1).Return(int64(1), nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, w.Code)
+			},
+		},
+		{
+			name: "BadRequest-breached password",
+			body: gin.H{"email": user.Email, "code": "123456", "new_password": "Password1!"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(1).Return(reset, nil)
+				store.EXPECT().IncrementPasswordResetAttempts(gomock.Any(), db.IncrementPasswordResetAttemptsParams{ID: reset.ID, MaxAttempts: passwordreset.MaxAttempts}).Times(1).Return(int64(1), nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				require.Contains(t, w.Body.String(), "breached")
+			},
+		},
+		{
+			name: "BadRequest-password too short",
+			body: gin.H{"email": user.Email, "code": "123456", "new_password": "short"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, w.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectTx(store)
+			tc.buildStubs(store)
+
+			configToken := map[string]string{"token_secret": util.RandomString(32)}
+			controller := controller.NewAuthController(service.NewAuthService(store, configToken, newTokenMaker(t, configToken), &emailQueue{}, newLoginGuard(t, store), newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), newRevocationList(t), newResetLimiter(t)))
+
+			bodyJSON, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/password/reset", bytes.NewReader(bodyJSON))
+
+			controller.ResetPassword(ctx)
+
+			tc.checkResponse(t, w)
+		})
+	}
+}
+
+// addSessionAuthorization signs the request with an access token issued for sessionID.
+func addSessionAuthorization(t *testing.T, request *http.Request, maker token.Maker, userUUID, sessionID uuid.UUID) {
+	accessToken, _, err := maker.CreateToken(userUUID.String(), sessionID, token.KindAccess, time.Minute, "customer", time.Now())
+	require.NoError(t, err)
+
+	request.Header.Set(middleware.AuthorizationHeaderKey, fmt.Sprintf("%s %s", middleware.AuthorizationTypeBearer, accessToken))
+}
+
+func TestLogoutController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userUUID := uuid.New()
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSessionFamily(gomock.Any(), db.BlockSessionFamilyParams{FamilyID: sessionID, UserUuid: userUUID}).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound-already revoked",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InternalServerError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+			require.NoError(t, err)
+
+			addSessionAuthorization(t, request, server.TokenMaker, userUUID, sessionID)
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userUUID := uuid.New()
+	sessionID := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().BlockSessionFamily(gomock.Any(), db.BlockSessionFamilyParams{FamilyID: sessionID, UserUuid: userUUID}).Times(1).Return(int64(1), nil)
+	store.EXPECT().ListActiveSessionsByUser(gomock.Any(), gomock.Any()).Times(0)
+
+	server := setup.InitializeAndStartAppTest(t, store)
+
+	accessToken, _, err := server.TokenMaker.CreateToken(userUUID.String(), sessionID, token.KindAccess, time.Minute, "customer", time.Now())
+	require.NoError(t, err)
+	authorization := fmt.Sprintf("%s %s", middleware.AuthorizationTypeBearer, accessToken)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	require.NoError(t, err)
+	request.Header.Set(middleware.AuthorizationHeaderKey, authorization)
+	server.Engine.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// the access token of the session is refused although it has not expired
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/api/v1/auth/sessions", nil)
+	require.NoError(t, err)
+	request.Header.Set(middleware.AuthorizationHeaderKey, authorization)
+	server.Engine.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestListSessionsController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userUUID := uuid.New()
+	sessionID := uuid.New()
+
+	sessions := []db.ListActiveSessionsByUserRow{
+		{FamilyID: uuid.New(), UserAgent: "firefox", ClientIp: "1.1.1.1", ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now()},
+		{FamilyID: sessionID, UserAgent: "curl", ClientIp: "2.2.2.2", ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now().Add(-time.Hour)},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListActiveSessionsByUser(gomock.Any(), userUUID).Times(1).Return(sessions, nil)
+
+	server := setup.InitializeAndStartAppTest(t, store)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/api/v1/auth/sessions", nil)
+	require.NoError(t, err)
+
+	addSessionAuthorization(t, request, server.TokenMaker, userUUID, sessionID)
+
+	server.Engine.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var responseBody struct {
+		Data []response.SessionResponse `json:"data"`
+	}
+	err = json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+	require.NoError(t, err)
+	require.Len(t, responseBody.Data, 2)
+	require.False(t, responseBody.Data[0].Current)
+	require.True(t, responseBody.Data[1].Current)
+	require.Equal(t, sessionID, responseBody.Data[1].SessionID)
+}
+
+func TestRevokeSessionController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userUUID := uuid.New()
+	sessionID := uuid.New()
+	otherSessionID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/api/v1/auth/sessions/%s", otherSessionID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSessionFamily(gomock.Any(), db.BlockSessionFamilyParams{FamilyID: otherSessionID, UserUuid: userUUID}).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound-other user session",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/api/v1/auth/sessions/%s", otherSessionID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest-invalid uuid",
+			method: http.MethodDelete,
+			url:    "/api/v1/auth/sessions/not-a-uuid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "OK-revoke other sessions",
+			method: http.MethodDelete,
+			url:    "/api/v1/auth/sessions",
+			buildStubs: func(store *mockdb.MockStore) {
+				// one of the sessions was refreshed, it has two refresh tokens
+				otherSessionID := uuid.New()
+				familyIDs := []uuid.UUID{otherSessionID, otherSessionID, uuid.New()}
+				store.EXPECT().BlockOtherUserSessions(gomock.Any(), db.BlockOtherUserSessionsParams{UserUuid: userUUID, FamilyID: sessionID}).Times(1).Return(familyIDs, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var responseBody struct {
+					Data response.RevokeSessionsResponse `json:"data"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+				require.Equal(t, int64(2), responseBody.Data.Revoked)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+
+			addSessionAuthorization(t, request, server.TokenMaker, userUUID, sessionID)
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthController_RefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := randomUser3()
+	familyID := uuid.New()
+
+	configToken := map[string]string{
+		"token_secret":           util.RandomString(32),
+		"access_token_duration":  time.Minute.String(),
+		"refresh_token_duration": (15 * time.Minute).String(),
+	}
+
+	maker, err := token.NewPasetoMaker(configToken["token_secret"])
+	require.NoError(t, err)
+
+	refreshToken, payload, err := maker.CreateToken(user.UserUuid.String(), familyID, token.KindRefresh, 15*time.Minute, user.Role, time.Now())
+	require.NoError(t, err)
+
+	session := db.Session{
+		ID:           payload.ID,
+		FamilyID:     familyID,
+		UserUuid:     user.UserUuid,
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiredAt,
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK-rotates the session",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), payload.ID).Times(1).Return(session, nil)
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().RotateSession(gomock.Any(), session.ID).Times(1).Return(int64(1), nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateSessionParams) (db.Session, error) {
+						require.Equal(t, familyID, arg.FamilyID)
+						require.NotEqual(t, session.ID, arg.ID)
+						require.NotEqual(t, refreshToken, arg.RefreshToken)
+						return db.Session{ID: arg.ID, FamilyID: arg.FamilyID, UserUuid: arg.UserUuid}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var responseBody struct {
+					Data response.AuthLoginResponse `json:"data"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+				require.Equal(t, familyID.String(), responseBody.Data.SessionId)
+			},
+		},
+		{
+			name: "Unauthorized-reused refresh token revokes the family",
+			buildStubs: func(store *mockdb.MockStore) {
+				rotated := session
+				rotated.RotatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+				store.EXPECT().GetSession(gomock.Any(), payload.ID).Times(1).Return(rotated, nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), db.BlockSessionFamilyParams{FamilyID: familyID, UserUuid: user.UserUuid}).Times(1).Return(int64(2), nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, audit.ActionSessionRefreshReuse, arg.Action)
+						require.Equal(t, familyID.String(), arg.EntityID)
+						require.Equal(t, user.UserUuid, uuid.UUID(arg.ActorUuid.Bytes))
+						return db.AuditLog{}, nil
+					})
+				store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateSecurityEventParams) (db.SecurityEvent, error) {
+						require.Equal(t, securityevent.TypeSessionRefreshReuse, arg.EventType)
+						require.Equal(t, user.UserUuid, arg.UserUuid)
+						return db.SecurityEvent{}, nil
+					})
+				store.EXPECT().RotateSession(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Unauthorized-concurrent rotation",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), payload.ID).Times(1).Return(session, nil)
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().RotateSession(gomock.Any(), session.ID).Times(1).Return(int64(0), nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Unauthorized-mismatched token",
+			buildStubs: func(store *mockdb.MockStore) {
+				other := session
+				other.RefreshToken = "other-token"
+
+				store.EXPECT().GetSession(gomock.Any(), payload.ID).Times(1).Return(other, nil)
+				store.EXPECT().RotateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectTx(store)
+			tc.buildStubs(store)
+
+			authController := controller.NewAuthController(service.NewAuthService(store, configToken, newTokenMaker(t, configToken), nil, newLoginGuard(t, store), newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), newRevocationList(t), newResetLimiter(t)))
+
+			bodyJSON, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/refresh/token", bytes.NewReader(bodyJSON))
+			ctx.Request.Header.Set("User-Agent", "test")
+
+			authController.RefreshToken(ctx)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthController_VerifyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	code := uuid.New().String()
+	pending := db.GetUserByVerificationEmailCodeRow{
+		UserUuid:                   util.RandomUUID(),
+		VerificationEmailCode:      pgtype.Text{String: code, Valid: true},
+		VerificationEmailExpiredAt: pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByVerificationEmailCode(gomock.Any(), pgtype.Text{String: code, Valid: true}).Times(1).Return(pending, nil)
+				store.EXPECT().UpdateUserVerificationEmail(gomock.Any(), gomock.Any()).Times(1).Return(db.UpdateUserVerificationEmailRow{UserUuid: pending.UserUuid}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest-unknown code",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).Return(db.GetUserByVerificationEmailCodeRow{}, pgx.ErrNoRows)
+				store.EXPECT().UpdateUserVerificationEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest-already verified",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := pending
+				verified.VerifiedEmailAt = time.Now()
+				store.EXPECT().GetUserByVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).Return(verified, nil)
+				store.EXPECT().UpdateUserVerificationEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest-missing code",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByVerificationEmailCode(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			bodyJSON, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/auth/email/verify", bytes.NewReader(bodyJSON))
+			require.NoError(t, err)
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthController_ResendVerificationEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userUUID := uuid.New()
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RenewVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RenewVerificationEmailCodeParams) (db.RenewVerificationEmailCodeRow, error) {
+						require.Equal(t, userUUID, arg.UserUuid)
+						return db.RenewVerificationEmailCodeRow{UserUuid: userUUID, Email: util.RandomEmail(), VerificationEmailExpiredAt: arg.VerificationEmailExpiredAt}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "TooManyRequests",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RenewVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RenewVerificationEmailCodeRow{}, pgx.ErrNoRows)
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), userUUID).Times(1).Return(db.GetUserByUserUUIDRow{UserUuid: userUUID}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest-already verified",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RenewVerificationEmailCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RenewVerificationEmailCodeRow{}, pgx.ErrNoRows)
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), userUUID).Times(1).
+					Return(db.GetUserByUserUUIDRow{UserUuid: userUUID, VerifiedEmailAt: time.Now()}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			configToken := map[string]string{"token_secret": util.RandomString(32)}
+			controller := controller.NewAuthController(service.NewAuthService(store, configToken, newTokenMaker(t, configToken), nil, newLoginGuard(t, store), newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), newRevocationList(t), newResetLimiter(t)))
+
+			payload, err := token.NewPayload(userUUID.String(), sessionID, token.KindAccess, time.Minute, "customer", time.Now())
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/auth/email/verify/resend", nil)
+			ctx.Set(middleware.AuthorizationPayloadKey, payload)
+
+			controller.ResendVerificationEmail(ctx)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ComplianceController handles HTTP requests related to the sanctions screening review queue.
+type ComplianceController struct {
+	complianceService *service.ComplianceService
+}
+
+func NewComplianceController(complianceService *service.ComplianceService) *ComplianceController {
+	return &ComplianceController{
+		complianceService: complianceService,
+	}
+}
+
+// ListComplianceReviews lists the signups, renames and transfers that matched the sanctions list. Requires compliance:review.
+func (cc *ComplianceController) ListComplianceReviews(ctx *gin.Context) {
+	var req request.ListComplianceReviewRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	param := db.ListComplianceReviewsParams{
+		Status: req.Status,
+		Limit:  req.Limit,
+		Offset: (req.Page - 1) * req.Limit,
+	}
+
+	reviews, totalData, err := cc.complianceService.ListComplianceReviews(ctx.Request.Context(), param)
+	if err != nil {
+		returnComplianceError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSONWithMetaPage(ctx, http.StatusOK, "Compliance review found", reviews, int(totalData), len(reviews), int(req.Page), int(req.Limit))
+}
+
+// ClearComplianceReview clears a false positive match. A held transfer is booked when the risk rules
+// allow it. Requires compliance:review.
+func (cc *ComplianceController) ClearComplianceReview(ctx *gin.Context) {
+	reviewUUID, ok := bindComplianceReviewUUID(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	review, err := cc.complianceService.ClearComplianceReview(ctx.Request.Context(), reviewUUID, authPayload)
+	if err != nil {
+		returnComplianceError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "compliance review cleared", review)
+}
+
+// ConfirmComplianceReview confirms a match. The held operation is never executed. Requires compliance:review.
+func (cc *ComplianceController) ConfirmComplianceReview(ctx *gin.Context) {
+	reviewUUID, ok := bindComplianceReviewUUID(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	review, err := cc.complianceService.ConfirmComplianceReview(ctx.Request.Context(), reviewUUID, authPayload)
+	if err != nil {
+		returnComplianceError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "compliance review confirmed", review)
+}
+
+func bindComplianceReviewUUID(ctx *gin.Context) (uuid.UUID, bool) {
+	var req request.ComplianceReviewRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return uuid.UUID{}, false
+	}
+
+	reviewUUID, err := helper.ConvertStringToUUID(req.UUIDReview)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return uuid.UUID{}, false
+	}
+
+	return reviewUUID, true
+}
+
+func returnComplianceError(ctx *gin.Context, err error) {
+	log.Printf("Error: %s", err.Error())
+	switch err.Error() {
+	case "unauthorized":
+		helper.ReturnJSONError(ctx, http.StatusUnauthorized, "unauthorized", nil, nil)
+	case "no rows in result set", "sql: no rows in result set":
+		helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
+	case "compliance review already decided":
+		helper.ReturnJSONError(ctx, http.StatusConflict, "compliance review already decided", nil, nil)
+	case "balance not enough":
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "balance not enough", nil, nil)
+	case db.ErrNotAccountMember.Error(), service.ErrMemberTransferLimit.Error(), service.ErrAccountMemberForbidden.Error():
+		helper.ReturnJSONError(ctx, http.StatusForbidden, err.Error(), nil, nil)
+	default:
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+	}
+}
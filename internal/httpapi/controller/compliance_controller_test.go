@@ -0,0 +1,183 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/setup"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestClearComplianceReviewController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin := randomUser3()
+	fromAccount := randomAccount(t, uuid.New())
+	toAccount := randomAccount(t, uuid.New())
+
+	review := db.ComplianceReview{
+		ID:            1,
+		ReviewUuid:    uuid.New(),
+		Kind:          "transfer",
+		UserUuid:      fromAccount.UserUuid,
+		ScreenedName:  toAccount.Owner,
+		ListReference: "QDi.001",
+		ListedName:    toAccount.Owner,
+		Score:         1,
+		FromAccountID: pgtype.Int8{Int64: fromAccount.ID, Valid: true},
+		ToAccountID:   pgtype.Int8{Int64: toAccount.ID, Valid: true},
+		Amount:        pgtype.Numeric{Int: big.NewInt(10), Valid: true},
+		Currency:      pgtype.Text{String: fromAccount.Currency, Valid: true},
+		Status:        "pending",
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: "admin",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetComplianceReviewByUUID(gomock.Any(), gomock.Eq(review.ReviewUuid)).Times(1).Return(review, nil)
+				store.EXPECT().GetAccountTransferVelocity(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetAccountTransferVelocityRow{TotalAmount: pgtype.Numeric{Int: big.NewInt(0), Valid: true}}, nil)
+
+				cleared := review
+				cleared.Status = "cleared"
+				store.EXPECT().ClearComplianceReviewTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, param db.ClearComplianceReviewTxParam) (db.ClearComplianceReviewTxResult, error) {
+						require.Equal(t, review.ReviewUuid, param.ReviewUUID)
+						require.Equal(t, admin.UserUuid, param.Reviewer)
+						require.Equal(t, "allow", param.RiskDecision.Decision)
+						require.NoError(t, param.CheckMember(db.AccountMember{Permission: "transfer"}))
+						require.Error(t, param.CheckMember(db.AccountMember{Permission: "view"}))
+
+						return db.ClearComplianceReviewTxResult{
+							Review:       cleared,
+							RiskDecision: db.RiskDecision{DecisionUuid: uuid.New(), Decision: param.RiskDecision.Decision},
+							Transfer: db.TransferTxResult{
+								Transaction: db.Transaction{TransactionUuid: uuid.New(), Amount: review.Amount},
+							},
+						}, nil
+					})
+
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, audit.ActionComplianceReviewClear, arg.Action)
+						require.Equal(t, review.ReviewUuid.String(), arg.EntityID)
+						require.Equal(t, pgtype.UUID{Bytes: admin.UserUuid, Valid: true}, arg.ActorUuid)
+						require.Equal(t, "admin", arg.ActorRole)
+						require.Contains(t, string(arg.Before), `"status":"pending"`)
+						require.Contains(t, string(arg.After), `"status":"cleared"`)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				require.Equal(t, "cleared", data["status"])
+				require.NotNil(t, data["transaction"])
+			},
+		},
+		{
+			name: "OK-held by risk rules",
+			role: "admin",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetComplianceReviewByUUID(gomock.Any(), gomock.Eq(review.ReviewUuid)).Times(1).Return(review, nil)
+				store.EXPECT().GetAccountTransferVelocity(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetAccountTransferVelocityRow{TransferCount: 100, TotalAmount: pgtype.Numeric{Int: big.NewInt(0), Valid: true}}, nil)
+
+				cleared := review
+				cleared.Status = "cleared"
+				store.EXPECT().ClearComplianceReviewTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, param db.ClearComplianceReviewTxParam) (db.ClearComplianceReviewTxResult, error) {
+						require.Equal(t, "review", param.RiskDecision.Decision)
+						return db.ClearComplianceReviewTxResult{
+							Review:       cleared,
+							RiskDecision: db.RiskDecision{DecisionUuid: uuid.New(), Decision: param.RiskDecision.Decision, Reasons: param.RiskDecision.Reasons},
+						}, nil
+					})
+
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				require.Equal(t, "cleared", data["status"])
+				require.Nil(t, data["transaction"])
+				require.Equal(t, "review", data["risk_decision"].(map[string]interface{})["decision"])
+			},
+		},
+		{
+			name: "Unauthorized-customer",
+			role: "customer",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetComplianceReviewByUUID(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ClearComplianceReviewTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Conflict-already decided",
+			role: "admin",
+			buildStubs: func(store *mockdb.MockStore) {
+				decided := review
+				decided.Status = "confirmed"
+				store.EXPECT().GetComplianceReviewByUUID(gomock.Any(), gomock.Eq(review.ReviewUuid)).Times(1).Return(decided, nil)
+				store.EXPECT().ClearComplianceReviewTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/compliance/reviews/%s/clear", review.ReviewUuid)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTestAPI(t, request, server.TokenMaker, middleware.AuthorizationTypeBearer, admin.UserUuid.String(), time.Minute, tc.role)
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,19 +1,38 @@
 package controller_test
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/controller"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/router"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/service"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/setup"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordreset"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/risk"
+	"github.com/fajaramaulana/simple_bank_project/internal/screening"
+	"github.com/fajaramaulana/simple_bank_project/internal/stepup"
 	"github.com/fajaramaulana/simple_bank_project/util"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func TestMain(m *testing.M) {
@@ -36,24 +55,111 @@ func NewTestServer(t *testing.T, store db.Store) *router.Router {
 		"refresh_token_duration": configEnv.RefreshTokenDuration.String(),
 	}
 	fmt.Printf("%# v\n", configToken)
+	screener := screening.NewScreener(&screening.List{}, 0)
+	authorizer := authz.NewAuthorizer(authz.DefaultRolePermissions, 0)
+
 	// account
-	accountService := service.NewAccountService(store)
+	accountService := service.NewAccountService(store, authorizer)
 	accountController := controller.NewAccountController(accountService)
 
 	// transfer
-	transferService := service.NewTransactionService(store)
+	riskEngine, err := risk.NewEngineFromConfig(store, risk.DefaultConfig())
+	require.NoError(t, err)
+	transferService := service.NewTransactionService(store, riskEngine, screener, stepup.NewPolicy(stepup.DefaultConfig()))
 	transferController := controller.NewTransactionController(transferService)
 
+	// revoked access tokens are shared by the services and the router
+	revocations := newRevocationList(t)
+
 	// user
-	userService := service.NewUserService(store)
+	userService := service.NewUserService(store, screener, authorizer, newPasswordPolicy(), newPasswordHasher(), revocations)
 	userController := controller.NewUserController(userService)
 
 	// auth
-	authService := service.NewAuthService(store, configToken)
+	authService := service.NewAuthService(store, configToken, newTokenMaker(t, configToken), nil, newLoginGuard(t, store), newPasswordPolicy(), newPasswordHasher(), newEmailVerifier(store), newStepUpAuthenticator(t, store), newDeviceRegistry(t, store), revocations, newResetLimiter(t))
 	authController := controller.NewAuthController(authService)
 
-	server, err := router.NewRouter(accountController, transferController, userController, authController, configToken)
+	// webhook
+	webhookService := service.NewWebhookService(store, authorizer)
+	webhookController := controller.NewWebhookController(webhookService)
+
+	// compliance
+	complianceService := service.NewComplianceService(store, riskEngine)
+	complianceController := controller.NewComplianceController(complianceService)
+
+	// audit
+	auditService := service.NewAuditService(store)
+	auditController := controller.NewAuditController(auditService)
+
+	server, err := router.NewRouter(accountController, transferController, userController, authController, webhookController, complianceController, auditController, authorizer, newTokenMaker(t, configToken), nil, store, newEmailVerifier(store), revocations)
 	require.NoError(t, err)
 
 	return server
 }
+
+// newPasswordPolicy returns the default password policy.
+func newPasswordPolicy() *passwordpolicy.Policy {
+	return passwordpolicy.NewPolicy(passwordpolicy.DefaultConfig(), newPasswordHasher())
+}
+
+// newPasswordHasher returns a hasher preferring bcrypt, which the fixtures are hashed with, so
+// logins do not rehash them.
+func newPasswordHasher() *passwordhash.Hasher {
+	hasher, _ := passwordhash.NewHasher(passwordhash.Config{Algorithm: passwordhash.AlgorithmBcrypt})
+	return hasher
+}
+
+// newEmailVerifier returns a verifier with the default configuration, which does not require a
+// verified email address.
+func newEmailVerifier(store db.Store) *emailverification.Verifier {
+	return emailverification.NewVerifier(store, &emailQueue{}, emailverification.DefaultConfig())
+}
+
+// newStepUpAuthenticator returns an authenticator checking passwords hashed with bcrypt.
+func newStepUpAuthenticator(t *testing.T, store db.Store) *stepup.Authenticator {
+	return stepup.NewAuthenticator(store, newPasswordHasher(), newLoginGuard(t, store))
+}
+
+// newDeviceRegistry returns a registry that does not require confirming new devices.
+func newDeviceRegistry(t *testing.T, store db.Store) *device.Registry {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	return device.NewRegistry(rdb, store, &emailQueue{}, device.DefaultConfig())
+}
+
+// newRevocationList returns a revocation list kept in an in-memory redis.
+func newRevocationList(t *testing.T) *revocation.List {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	return revocation.NewList(rdb, revocation.DefaultConfig())
+}
+
+func newResetLimiter(t *testing.T) *passwordreset.Limiter {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	return passwordreset.NewLimiter(rdb, passwordreset.DefaultLimitConfig())
+}
+
+// expectKnownDevice stubs a login from a trusted device and a known client IP.
+func expectKnownDevice(store *mockdb.MockStore) {
+	trusted := db.UserDevice{DeviceUuid: util.RandomUUID(), TrustedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true}}
+	store.EXPECT().GetUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(trusted, nil)
+	store.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Times(1).Return(trusted, nil)
+	store.EXPECT().RecordUserLoginIP(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+}
+
+// expectTx runs the transactions of the services on store itself.
+func expectTx(store *mockdb.MockStore) {
+	store.EXPECT().ExecTx(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, fn func(db.Store) error) error { return fn(store) })
+}
+
+// newLoginGuard returns a login guard that counts failed logins in an in-memory redis.
+func newLoginGuard(t *testing.T, store db.Store) *lockout.Guard {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	return lockout.NewGuard(rdb, store, nil, lockout.DefaultConfig())
+}
+
+// newTokenMaker returns the maker of the tokens the tests sign with the token secret of configToken.
+func newTokenMaker(t *testing.T, configToken map[string]string) token.Maker {
+	maker, err := token.NewPasetoMaker(configToken["token_secret"])
+	require.NoError(t, err)
+	return maker
+}
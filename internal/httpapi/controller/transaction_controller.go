@@ -1,15 +1,22 @@
 package controller
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/service"
+	"github.com/fajaramaulana/simple_bank_project/internal/risk"
+	"github.com/fajaramaulana/simple_bank_project/internal/screening"
+	"github.com/fajaramaulana/simple_bank_project/internal/stepup"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type TransactionController struct {
@@ -42,9 +49,47 @@ func (tf *TransactionController) CreateTransfer(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
 
-	transfer, err := tf.transactionService.CreateTransferTrans(ctx.Request.Context(), &req, authPayload)
+	transfer, err := tf.transactionService.CreateTransferTrans(ctx.Request.Context(), &req, authPayload, ctx.GetHeader(deviceTokenHeader))
 	if err != nil {
 		log.Printf("Error: %s", err.Error())
+		var requiredErr *stepup.RequiredError
+		if errors.As(err, &requiredErr) {
+			returnStepUpRequired(ctx, requiredErr)
+			return
+		}
+
+		var decisionErr *risk.DecisionError
+		if errors.As(err, &decisionErr) {
+			data := response.TransferDecisionResponse{
+				DecisionUUID: decisionErr.DecisionUUID,
+				Decision:     string(decisionErr.Decision),
+				Reasons:      decisionErr.Reasons,
+			}
+
+			if decisionErr.Decision == risk.DecisionDeny {
+				helper.ReturnJSONError(ctx, http.StatusForbidden, "transfer denied", data, nil)
+				return
+			}
+
+			helper.ReturnJSON(ctx, http.StatusAccepted, "transfer held for review", data)
+			return
+		}
+
+		// the match itself is not disclosed to the customer
+		var holdErr *screening.HoldError
+		if errors.As(err, &holdErr) {
+			helper.ReturnJSON(ctx, http.StatusAccepted, "transfer held for compliance review", response.ComplianceHoldResponse{
+				ReviewUUID: holdErr.ReviewUUID,
+				Status:     "pending",
+			})
+			return
+		}
+
+		if err.Error() == "account under compliance review" {
+			helper.ReturnJSONError(ctx, http.StatusForbidden, "account under compliance review", nil, nil)
+			return
+		}
+
 		if err.Error() == "no rows in result set: from account not found" {
 			helper.ReturnJSONError(ctx, http.StatusNotFound, "from account not found", nil, nil)
 			return
@@ -60,6 +105,16 @@ func (tf *TransactionController) CreateTransfer(ctx *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, service.ErrPocketTransfer) {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+			return
+		}
+
+		if errors.Is(err, service.ErrMemberTransferLimit) || errors.Is(err, service.ErrAccountMemberForbidden) {
+			helper.ReturnJSONError(ctx, http.StatusForbidden, err.Error(), nil, nil)
+			return
+		}
+
 		if err.Error() == "balance not enough" {
 			helper.ReturnJSONError(ctx, http.StatusBadRequest, "balance not enough", nil, nil)
 			return
@@ -71,3 +126,100 @@ func (tf *TransactionController) CreateTransfer(ctx *gin.Context) {
 
 	helper.ReturnJSON(ctx, http.StatusCreated, "success transaction", transfer)
 }
+
+// ListTransferReviews lists the transfers held for review by the risk engine. Requires transfers:approve.
+func (tf *TransactionController) ListTransferReviews(ctx *gin.Context) {
+	var req request.ListTransferReviewRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	param := db.ListRiskDecisionsPendingReviewParams{
+		Limit:  req.Limit,
+		Offset: (req.Page - 1) * req.Limit,
+	}
+
+	reviews, totalData, err := tf.transactionService.ListTransferReviews(ctx.Request.Context(), param)
+	if err != nil {
+		returnTransferReviewError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSONWithMetaPage(ctx, http.StatusOK, "Transfer review found", reviews, int(totalData), len(reviews), int(req.Page), int(req.Limit))
+}
+
+// ApproveTransferReview approves and books a transfer held for review. Requires transfers:approve.
+func (tf *TransactionController) ApproveTransferReview(ctx *gin.Context) {
+	decisionUUID, ok := bindTransferReviewUUID(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	transfer, err := tf.transactionService.ApproveTransferReview(ctx.Request.Context(), decisionUUID, authPayload)
+	if err != nil {
+		returnTransferReviewError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusCreated, "success transaction", transfer)
+}
+
+// RejectTransferReview rejects a transfer held for review, it is never booked. Requires transfers:approve.
+func (tf *TransactionController) RejectTransferReview(ctx *gin.Context) {
+	decisionUUID, ok := bindTransferReviewUUID(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	decision, err := tf.transactionService.RejectTransferReview(ctx.Request.Context(), decisionUUID, authPayload)
+	if err != nil {
+		returnTransferReviewError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "transfer rejected", decision)
+}
+
+func bindTransferReviewUUID(ctx *gin.Context) (uuid.UUID, bool) {
+	var req request.TransferReviewRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return uuid.UUID{}, false
+	}
+
+	decisionUUID, err := helper.ConvertStringToUUID(req.UUIDDecision)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return uuid.UUID{}, false
+	}
+
+	return decisionUUID, true
+}
+
+func returnTransferReviewError(ctx *gin.Context, err error) {
+	log.Printf("Error: %s", err.Error())
+	switch err.Error() {
+	case "unauthorized":
+		helper.ReturnJSONError(ctx, http.StatusUnauthorized, "unauthorized", nil, nil)
+	case "no rows in result set", "sql: no rows in result set":
+		helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
+	case "transfer review already decided":
+		helper.ReturnJSONError(ctx, http.StatusConflict, "transfer review already decided", nil, nil)
+	case "balance not enough":
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "balance not enough", nil, nil)
+	case db.ErrNotAccountMember.Error(), service.ErrMemberTransferLimit.Error(), service.ErrAccountMemberForbidden.Error():
+		helper.ReturnJSONError(ctx, http.StatusForbidden, err.Error(), nil, nil)
+	default:
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+	}
+}
@@ -1,13 +1,19 @@
 package controller
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
+	"github.com/fajaramaulana/simple_bank_project/internal/apikey"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/service"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // UserController handles HTTP requests related to accounts.
@@ -44,6 +50,11 @@ func (u *UserController) CreateUser(ctx *gin.Context) {
 
 	user, err := u.userService.CreateUser(ctx.Request.Context(), &request)
 	if err != nil {
+		if errors.Is(err, passwordpolicy.ErrWeakPassword) {
+			log.Printf("Error: %s", err.Error())
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+			return
+		}
 		if user.Email != "" {
 			log.Println("Error: User already exists")
 			helper.ReturnJSONError(ctx, http.StatusConflict, "User Already Exist", nil, nil)
@@ -55,6 +66,347 @@ func (u *UserController) CreateUser(ctx *gin.Context) {
 		}
 	}
 
+	if user.ComplianceStatus != "" {
+		helper.ReturnJSON(ctx, http.StatusCreated, "Account created, held for compliance review", user)
+		return
+	}
+
 	// checking account already exist
 	helper.ReturnJSON(ctx, http.StatusCreated, "Account created", user)
 }
+
+// ListUsers lists users, optionally searched by username, email or full name and filtered by
+// role and blocked status. Requires users:read.
+func (u *UserController) ListUsers(ctx *gin.Context) {
+	var req request.ListUserRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	users, totalData, err := u.userService.ListUsers(ctx.Request.Context(), req)
+	if err != nil {
+		returnUserAdminError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSONWithMetaPage(ctx, http.StatusOK, "Users found", users, int(totalData), len(users), int(req.Page), int(req.Limit))
+}
+
+// GetUser returns a user with all of the user's accounts and sessions. Requires users:read.
+func (u *UserController) GetUser(ctx *gin.Context) {
+	userUUID, ok := bindUserUUID(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := u.userService.GetUserDetail(ctx.Request.Context(), userUUID)
+	if err != nil {
+		returnUserAdminError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "User found", user)
+}
+
+// BlockUser blocks a user and all of the user's sessions. Requires users:manage.
+func (u *UserController) BlockUser(ctx *gin.Context) {
+	userUUID, ok := bindUserUUID(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	user, err := u.userService.BlockUser(ctx.Request.Context(), userUUID, authPayload)
+	if err != nil {
+		returnUserAdminError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "User blocked", user)
+}
+
+// UnblockUser lets a blocked user sign in again. Requires users:manage.
+func (u *UserController) UnblockUser(ctx *gin.Context) {
+	userUUID, ok := bindUserUUID(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	user, err := u.userService.UnblockUser(ctx.Request.Context(), userUUID, authPayload)
+	if err != nil {
+		returnUserAdminError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "User unblocked", user)
+}
+
+// ChangeUserRole changes the role of a user. Requires users:manage, and users:manage:privileged
+// to grant a privileged role.
+func (u *UserController) ChangeUserRole(ctx *gin.Context) {
+	userUUID, ok := bindUserUUID(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.ChangeUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	user, err := u.userService.ChangeUserRole(ctx.Request.Context(), userUUID, req.Role, authPayload)
+	if err != nil {
+		returnUserAdminError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "User role changed", user)
+}
+
+// ForcePasswordReset makes a user reset the password before signing in again. Requires users:manage.
+func (u *UserController) ForcePasswordReset(ctx *gin.Context) {
+	userUUID, ok := bindUserUUID(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	user, err := u.userService.ForcePasswordReset(ctx.Request.Context(), userUUID, authPayload)
+	if err != nil {
+		returnUserAdminError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "User must reset password", user)
+}
+
+// SetRoleMFARequirement makes two-factor authentication mandatory or optional for a role. Requires
+// users:manage:privileged.
+func (u *UserController) SetRoleMFARequirement(ctx *gin.Context) {
+	var uri request.RoleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), uri)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	var req request.RoleMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	setting, err := u.userService.SetRoleMFARequirement(ctx.Request.Context(), uri.Role, *req.MFARequired)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Role two-factor requirement updated", setting)
+}
+
+// CreateAPIKey creates an API key for the authenticated user. The key is only returned here.
+func (u *UserController) CreateAPIKey(ctx *gin.Context) {
+	req, ok := bindCreateAPIKey(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	key, err := u.userService.CreateAPIKey(ctx.Request.Context(), req, authPayload)
+	if err != nil {
+		returnAPIKeyError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusCreated, "API key created", key)
+}
+
+// ListAPIKeys lists the API keys of the authenticated user.
+func (u *UserController) ListAPIKeys(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	keys, err := u.userService.ListAPIKeys(ctx.Request.Context(), authPayload)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Success", keys)
+}
+
+// RevokeAPIKey revokes an API key of the authenticated user.
+func (u *UserController) RevokeAPIKey(ctx *gin.Context) {
+	var req request.APIKeyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	keyUUID, err := helper.ConvertStringToUUID(req.UUIDKey)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	if err := u.userService.RevokeAPIKey(ctx.Request.Context(), keyUUID, authPayload); err != nil {
+		returnAPIKeyError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "API key revoked", nil)
+}
+
+// CreateUserAPIKey creates an API key for a user. Requires users:manage.
+func (u *UserController) CreateUserAPIKey(ctx *gin.Context) {
+	userUUID, ok := bindUserUUID(ctx)
+	if !ok {
+		return
+	}
+
+	req, ok := bindCreateAPIKey(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	key, err := u.userService.CreateUserAPIKey(ctx.Request.Context(), userUUID, req, authPayload)
+	if err != nil {
+		returnAPIKeyError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusCreated, "API key created", key)
+}
+
+// ListUserAPIKeys lists the API keys of a user. Requires users:read.
+func (u *UserController) ListUserAPIKeys(ctx *gin.Context) {
+	userUUID, ok := bindUserUUID(ctx)
+	if !ok {
+		return
+	}
+
+	keys, err := u.userService.ListUserAPIKeys(ctx.Request.Context(), userUUID)
+	if err != nil {
+		returnUserAdminError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Success", keys)
+}
+
+// RevokeUserAPIKey revokes an API key of a user. Requires users:manage.
+func (u *UserController) RevokeUserAPIKey(ctx *gin.Context) {
+	var req request.UserAPIKeyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	userUUID, err := helper.ConvertStringToUUID(req.UUIDUser)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	keyUUID, err := helper.ConvertStringToUUID(req.UUIDKey)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	if err := u.userService.RevokeUserAPIKey(ctx.Request.Context(), userUUID, keyUUID, authPayload); err != nil {
+		returnAPIKeyError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "API key revoked", nil)
+}
+
+func bindCreateAPIKey(ctx *gin.Context) (request.CreateAPIKeyRequest, bool) {
+	var req request.CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return request.CreateAPIKeyRequest{}, false
+	}
+
+	return req, true
+}
+
+func returnAPIKeyError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, apikey.ErrInvalidScope), err == service.ErrInvalidAPIKeyExpiry:
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, err.Error(), nil, nil)
+	case err == service.ErrAPIKeyNotFound:
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusNotFound, "API key not found", nil, nil)
+	default:
+		returnUserAdminError(ctx, err)
+	}
+}
+
+func bindUserUUID(ctx *gin.Context) (uuid.UUID, bool) {
+	var req request.UserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return uuid.UUID{}, false
+	}
+
+	userUUID, err := helper.ConvertStringToUUID(req.UUIDUser)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return uuid.UUID{}, false
+	}
+
+	return userUUID, true
+}
+
+func returnUserAdminError(ctx *gin.Context, err error) {
+	log.Printf("Error: %s", err.Error())
+	switch err.Error() {
+	case "unauthorized":
+		helper.ReturnJSONError(ctx, http.StatusUnauthorized, "unauthorized", nil, nil)
+	case "no rows in result set", "sql: no rows in result set":
+		helper.ReturnJSONError(ctx, http.StatusNotFound, "User not found", nil, nil)
+	case "cannot manage own user":
+		helper.ReturnJSONError(ctx, http.StatusForbidden, "cannot manage own user", nil, nil)
+	default:
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+	}
+}
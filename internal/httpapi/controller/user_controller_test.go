@@ -4,16 +4,26 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/controller"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/service"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/setup"
+	"github.com/fajaramaulana/simple_bank_project/internal/screening"
 	"github.com/fajaramaulana/simple_bank_project/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -29,6 +39,7 @@ func TestUserController_CreateUser(t *testing.T) {
 		name           string
 		body           gin.H
 		mockSetup      func(store *mockdb.MockStore)
+		sanctions      []screening.Entry
 		expectedStatus int
 		expectedBody   string
 		checkResponse  func(t *testing.T, w *httptest.ResponseRecorder)
@@ -54,10 +65,44 @@ func TestUserController_CreateUser(t *testing.T) {
 					CreateUserWithAccountTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.CreateUserWithAccountResult{}, nil)
+
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
 			},
 			expectedStatus: http.StatusCreated,
 			expectedBody:   "Account created",
 		},
+		{
+			name: "Created - Held For Compliance Review",
+			body: gin.H{
+				"email":     user.Email,
+				"password":  password,
+				"username":  user.Username,
+				"full_name": user.FullName,
+				"currency":  "USD",
+			},
+			sanctions: []screening.Entry{{Reference: "QDi.001", Name: user.FullName}},
+			mockSetup: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(db.GetUserByEmailRow{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByUsername(gomock.Any(), user.Username).Times(1).Return(db.GetUserByUsernameRow{}, sql.ErrNoRows)
+
+				userUUID := uuid.New()
+				store.EXPECT().
+					CreateUserWithAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, param db.CreateUserWithAccountTxParam) (db.CreateUserWithAccountResult, error) {
+						require.NotNil(t, param.SanctionsMatch)
+						require.Equal(t, "QDi.001", param.SanctionsMatch.Reference)
+						return db.CreateUserWithAccountResult{
+							User:             response.UserGetSimple{UserUUID: userUUID.String(), FullName: user.FullName},
+							ComplianceReview: &db.ComplianceReview{ReviewUuid: uuid.New(), Kind: "signup", UserUuid: userUUID, Status: "pending"},
+						}, nil
+					})
+
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   "held for compliance review",
+		},
 		{
 			name: "Conflict - Email Already Exists",
 			body: gin.H{
@@ -239,9 +284,10 @@ func TestUserController_CreateUser(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			store := mockdb.NewMockStore(ctrl)
+			expectTx(store)
 			tt.mockSetup(store)
 
-			userService := service.NewUserService(store)
+			userService := service.NewUserService(store, screening.NewScreener(&screening.List{Entries: tt.sanctions}, 0), authz.NewAuthorizer(authz.DefaultRolePermissions, 0), newPasswordPolicy(), newPasswordHasher(), newRevocationList(t))
 			userController := controller.NewUserController(userService)
 
 			bodyJSON, err := json.Marshal(tt.body)
@@ -270,6 +316,272 @@ func TestUserController_CreateUser(t *testing.T) {
 	}
 }
 
+func TestGetUserController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin := randomUser3()
+	user := randomUser3()
+	user.Role = "customer"
+	account := randomAccount(t, user.UserUuid)
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: "admin",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccountByUserUUIDMany(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).
+					Return([]db.GetAccountByUserUUIDManyRow{{AccountUuid: account.AccountUuid, Owner: account.Owner, Currency: account.Currency}}, nil)
+				store.EXPECT().ListSessionsByUser(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).
+					Return([]db.ListSessionsByUserRow{{ID: uuid.New(), UserAgent: "test", ClientIp: "1.1.1.1", ExpiresAt: time.Now().Add(time.Hour)}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				require.Equal(t, user.UserUuid.String(), data["user_uuid"])
+				require.Len(t, data["accounts"], 1)
+				require.Len(t, data["sessions"], 1)
+			},
+		},
+		{
+			name: "Unauthorized-customer",
+			role: "customer",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			role: "admin",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).Return(db.GetUserByUserUUIDRow{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/admin/users/%s", user.UserUuid)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTestAPI(t, request, server.TokenMaker, middleware.AuthorizationTypeBearer, admin.UserUuid.String(), time.Minute, tc.role)
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestBlockUserController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin := randomUser3()
+	user := randomUser3()
+	user.Role = "customer"
+
+	testCases := []struct {
+		name          string
+		role          string
+		actorUUID     uuid.UUID
+		target        db.GetUserByUserUUIDRow
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			role:      "admin",
+			actorUUID: admin.UserUuid,
+			target:    user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).Return(user, nil)
+				store.EXPECT().BlockUserTx(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).
+					Return(db.UpdateUserBlockedRow{UserUuid: user.UserUuid, Username: user.Username, Role: user.Role, IsBlocked: true}, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, audit.ActionUserBlock, arg.Action)
+						require.Equal(t, user.UserUuid.String(), arg.EntityID)
+						require.Contains(t, string(arg.After), `"is_blocked":true`)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				require.Equal(t, true, data["is_blocked"])
+			},
+		},
+		{
+			name:      "Unauthorized-customer",
+			role:      "customer",
+			actorUUID: admin.UserUuid,
+			target:    user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BlockUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "Forbidden-own user",
+			role:      "admin",
+			actorUUID: user.UserUuid,
+			target:    user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "Unauthorized-admin blocking superadmin",
+			role:      "admin",
+			actorUUID: admin.UserUuid,
+			target:    user,
+			buildStubs: func(store *mockdb.MockStore) {
+				superadmin := user
+				superadmin.Role = "superadmin"
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).Return(superadmin, nil)
+				store.EXPECT().BlockUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/admin/users/%s/block", tc.target.UserUuid)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTestAPI(t, request, server.TokenMaker, middleware.AuthorizationTypeBearer, tc.actorUUID.String(), time.Minute, tc.role)
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestChangeUserRoleController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin := randomUser3()
+	user := randomUser3()
+	user.Role = "customer"
+
+	testCases := []struct {
+		name          string
+		role          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: "admin",
+			body: gin.H{"role": "admin"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).Return(user, nil)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Eq(db.UpdateUserRoleParams{UserUuid: user.UserUuid, Role: "admin"})).Times(1).
+					Return(db.UpdateUserRoleRow{UserUuid: user.UserUuid, Role: "admin"}, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Unauthorized-admin granting superadmin",
+			role: "admin",
+			body: gin.H{"role": "superadmin"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).Return(user, nil)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest-unknown role",
+			role: "superadmin",
+			body: gin.H{"role": "root"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			bodyJSON, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/admin/users/%s/role", user.UserUuid)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(bodyJSON))
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTestAPI(t, request, server.TokenMaker, middleware.AuthorizationTypeBearer, admin.UserUuid.String(), time.Minute, tc.role)
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func randomUser(t *testing.T) db.CreateUserParams {
 	passHash, err := util.MakePasswordBcrypt(util.RandomName())
 
@@ -310,3 +622,107 @@ func randomUser3() db.GetUserByUserUUIDRow {
 
 	return user
 }
+
+func TestCreateUserAPIKeyController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin := randomUser3()
+	user := randomUser3()
+	user.Role = "customer"
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": "reporting", "scopes": []string{"read"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).Return(user, nil)
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						require.Equal(t, user.UserUuid, arg.UserUuid)
+						require.Equal(t, admin.UserUuid, arg.CreatedBy)
+						require.Equal(t, []string{"read"}, arg.Scopes)
+						require.False(t, arg.ExpiresAt.Valid)
+						return db.ApiKey{KeyUuid: uuid.New(), UserUuid: arg.UserUuid, Name: arg.Name, Prefix: arg.Prefix, SecretHash: arg.SecretHash, Scopes: arg.Scopes, CreatedBy: arg.CreatedBy}, nil
+					})
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, audit.ActionAPIKeyCreate, arg.Action)
+						require.NotContains(t, string(arg.After), `"key"`)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				require.True(t, strings.HasPrefix(data["key"].(string), "sbk_"+data["prefix"].(string)+"_"))
+				require.Nil(t, data["expires_at"])
+			},
+		},
+		{
+			name: "ScopeNotHeldByUser",
+			body: gin.H{"name": "reporting", "scopes": []string{"read", "users:read"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).Return(user, nil)
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiryInPast",
+			body: gin.H{"name": "reporting", "scopes": []string{"read"}, "expires_at": time.Now().Add(-time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Eq(user.UserUuid)).Times(1).Return(user, nil)
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingScopes",
+			body: gin.H{"name": "reporting"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/admin/users/%s/api-keys", user.UserUuid)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTestAPI(t, request, server.TokenMaker, middleware.AuthorizationTypeBearer, admin.UserUuid.String(), time.Minute, "admin")
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/service"
+	"github.com/fajaramaulana/simple_bank_project/internal/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookController handles HTTP requests related to webhook subscriptions and their deliveries.
+type WebhookController struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookController(webhookService *service.WebhookService) *WebhookController {
+	return &WebhookController{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhook registers a webhook endpoint for the chosen event types.
+// The response contains the signing secret, which is not shown again.
+func (wc *WebhookController) CreateWebhook(ctx *gin.Context) {
+	var req request.CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error Bind: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	created, err := wc.webhookService.CreateWebhook(ctx.Request.Context(), &req, authPayload)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		if errors.Is(err, webhook.ErrForbiddenTarget) {
+			helper.ReturnJSONError(ctx, http.StatusBadRequest, "Webhook url must point at a public address", nil, nil)
+			return
+		}
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusCreated, "Webhook created", created)
+}
+
+func (wc *WebhookController) ListWebhooks(ctx *gin.Context) {
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	webhooks, err := wc.webhookService.ListWebhooks(ctx.Request.Context(), authPayload)
+	if err != nil {
+		log.Printf("Error: %s", err.Error())
+		helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Webhook found", webhooks)
+}
+
+func (wc *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	var req request.GetWebhookRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	uuidWebhook, err := helper.ConvertStringToUUID(req.UUIDWebhook)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	err = wc.webhookService.DeleteWebhook(ctx.Request.Context(), uuidWebhook, authPayload)
+	if err != nil {
+		returnWebhookError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusOK, "Webhook deleted", nil)
+}
+
+// ListWebhookDeliveries returns the paginated delivery log of a webhook, newest first.
+func (wc *WebhookController) ListWebhookDeliveries(ctx *gin.Context) {
+	var req request.GetWebhookRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	uuidWebhook, err := helper.ConvertStringToUUID(req.UUIDWebhook)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	var reqPage request.ListWebhookDeliveryRequest
+	if err := ctx.ShouldBindQuery(&reqPage); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), reqPage)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	deliveries, totalData, err := wc.webhookService.ListDeliveries(ctx.Request.Context(), uuidWebhook, reqPage, authPayload)
+	if err != nil {
+		returnWebhookError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSONWithMetaPage(ctx, http.StatusOK, "Webhook delivery found", deliveries, int(totalData), len(deliveries), int(reqPage.Page), int(reqPage.Limit))
+}
+
+// RedeliverWebhook queues a delivery to be sent again, resetting its retry schedule.
+func (wc *WebhookController) RedeliverWebhook(ctx *gin.Context) {
+	var req request.RedeliverWebhookRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		message, data := helper.GlobalCheckingErrorBindJson(err.Error(), req)
+		log.Printf("Error: %s", message)
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, message, nil, data)
+		return
+	}
+
+	uuidWebhook, err := helper.ConvertStringToUUID(req.UUIDWebhook)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	uuidDelivery, err := helper.ConvertStringToUUID(req.UUIDDelivery)
+	if err != nil {
+		log.Println("Error: Invalid UUID")
+		helper.ReturnJSONError(ctx, http.StatusBadRequest, "Invalid Parameter", nil, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+
+	delivery, err := wc.webhookService.Redeliver(ctx.Request.Context(), uuidWebhook, uuidDelivery, authPayload)
+	if err != nil {
+		returnWebhookError(ctx, err)
+		return
+	}
+
+	helper.ReturnJSON(ctx, http.StatusAccepted, "Webhook delivery queued", delivery)
+}
+
+func returnWebhookError(ctx *gin.Context, err error) {
+	if err.Error() == "unauthorized" {
+		log.Println("Error: Unauthorized")
+		helper.ReturnJSONError(ctx, http.StatusUnauthorized, "Unauthorized", nil, nil)
+		return
+	}
+
+	if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+		log.Println("Error: Data not found")
+		helper.ReturnJSONError(ctx, http.StatusNotFound, "Data not found", nil, nil)
+		return
+	}
+
+	log.Printf("Error: %s", err.Error())
+	helper.ReturnJSONError(ctx, http.StatusInternalServerError, "Internal server error", nil, nil)
+}
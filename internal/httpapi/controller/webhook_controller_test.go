@@ -0,0 +1,252 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/setup"
+	"github.com/fajaramaulana/simple_bank_project/internal/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateWebhookController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := randomUser3()
+	subscription := randomWebhookSubscription(user.UserUuid)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"url":         subscription.Url,
+				"event_types": subscription.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+						require.Equal(t, user.UserUuid, arg.UserUuid)
+						require.Equal(t, subscription.Url, arg.Url)
+						require.NotEmpty(t, arg.Secret)
+						subscription.Secret = arg.Secret
+						return subscription, nil
+					})
+
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, audit.ActionWebhookCreate, arg.Action)
+						require.Nil(t, arg.Before)
+						require.NotContains(t, string(arg.After), subscription.Secret)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				require.Equal(t, subscription.SubscriptionUuid.String(), data["webhook_uuid"])
+				require.NotEmpty(t, data["secret"])
+			},
+		},
+		{
+			name: "BadRequest-invalid event type",
+			body: gin.H{
+				"url":         subscription.Url,
+				"event_types": []string{"AccountDeleted"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest-invalid url",
+			body: gin.H{
+				"url":         "not a url",
+				"event_types": subscription.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest-internal url",
+			body: gin.H{
+				"url":         "http://169.254.169.254/latest/meta-data/",
+				"event_types": subscription.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTestAPI(t, request, server.TokenMaker, middleware.AuthorizationTypeBearer, user.UserUuid.String(), time.Minute, "customer")
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRedeliverWebhookController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := randomUser3()
+	subscription := randomWebhookSubscription(user.UserUuid)
+	delivery := db.WebhookDelivery{
+		ID:             1,
+		DeliveryUuid:   uuid.New(),
+		SubscriptionID: subscription.ID,
+		EventUuid:      uuid.New(),
+		EventType:      "TransferCompleted",
+		Payload:        []byte(`{}`),
+		Status:         webhook.StatusDead,
+		Attempts:       webhook.MaxAttempts,
+		NextAttemptAt:  time.Now(),
+		CreatedAt:      time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, user.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				redelivered := delivery
+				redelivered.Status = webhook.StatusPending
+				redelivered.Attempts = 0
+
+				store.EXPECT().GetWebhookSubscriptionByUUID(gomock.Any(), subscription.SubscriptionUuid).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookDeliveryByUUID(gomock.Any(), delivery.DeliveryUuid).Times(1).Return(delivery, nil)
+				store.EXPECT().RedeliverWebhookDelivery(gomock.Any(), delivery.DeliveryUuid).Times(1).Return(redelivered, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var responseBody map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				data := responseBody["data"].(map[string]interface{})
+				require.Equal(t, webhook.StatusPending, data["status"])
+			},
+		},
+		{
+			name: "Unauthorized",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTest(t, request, tokenMaker, middleware.AuthorizationTypeBearer, time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscriptionByUUID(gomock.Any(), subscription.SubscriptionUuid).Times(1).Return(subscription, nil)
+				store.EXPECT().RedeliverWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound-delivery of another webhook",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				middleware.AddAuthorizationTestAPI(t, request, tokenMaker, middleware.AuthorizationTypeBearer, user.UserUuid.String(), time.Minute, "customer")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := delivery
+				other.SubscriptionID = subscription.ID + 1
+
+				store.EXPECT().GetWebhookSubscriptionByUUID(gomock.Any(), subscription.SubscriptionUuid).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookDeliveryByUUID(gomock.Any(), delivery.DeliveryUuid).Times(1).Return(other, nil)
+				store.EXPECT().RedeliverWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := setup.InitializeAndStartAppTest(t, store)
+
+			urlPath := fmt.Sprintf("/api/v1/webhooks/%s/deliveries/%s/redeliver", subscription.SubscriptionUuid, delivery.DeliveryUuid)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, urlPath, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+
+			server.Engine.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomWebhookSubscription(userUUID uuid.UUID) db.WebhookSubscription {
+	return db.WebhookSubscription{
+		ID:               1,
+		SubscriptionUuid: uuid.New(),
+		UserUuid:         userUUID,
+		Url:              "https://partner.example.com/hooks/simplebank",
+		EventTypes:       []string{"TransferCompleted", "AccountCreated"},
+		Secret:           "whsec_test",
+		CreatedAt:        time.Now(),
+	}
+}
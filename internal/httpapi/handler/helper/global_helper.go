@@ -1,9 +1,11 @@
 package helper
 
 import (
+	"math/big"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func ConvertStringToUUID(s string) (uuid.UUID, error) {
@@ -22,3 +24,19 @@ func Implode(currencies map[string]bool, separator string) string {
 	}
 	return strings.Join(keys, separator)
 }
+
+// NumericToBigInt converts a NUMERIC(20,0) value to a big.Int, applying its exponent.
+func NumericToBigInt(n pgtype.Numeric) *big.Int {
+	if n.Int == nil {
+		return big.NewInt(0)
+	}
+
+	result := new(big.Int).Set(n.Int)
+	if n.Exp > 0 {
+		result.Mul(result, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.Exp)), nil))
+	} else if n.Exp < 0 {
+		result.Quo(result, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-n.Exp)), nil))
+	}
+
+	return result
+}
@@ -1,14 +1,21 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/fajaramaulana/simple_bank_project/internal/apikey"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -17,10 +24,16 @@ import (
 const (
 	AuthorizationHeaderKey  = "authorization"
 	AuthorizationTypeBearer = "bearer"
+	AuthorizationTypeAPIKey = "apikey"
 	AuthorizationPayloadKey = "authorization_payload"
+	// APIKeyCallerKey holds the apikey.Caller of requests authenticated with an API key.
+	APIKeyCallerKey = "api_key_caller"
 )
 
-func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// AuthMiddleware authenticates the request with a bearer access token or an API key. A request
+// made with an API key needs the read or write scope matching its method, and acts with the
+// scopes of the key only. Access tokens on the revocation list are refused.
+func AuthMiddleware(tokenMaker token.Maker, apiKeys apikey.Store, revocations *revocation.List) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authorizationHeader := c.GetHeader(AuthorizationHeaderKey)
 
@@ -39,6 +52,11 @@ func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 
 		authorizationType := strings.ToLower(fields[0])
 
+		if authorizationType == AuthorizationTypeAPIKey {
+			authenticateAPIKey(c, apiKeys, fields[1])
+			return
+		}
+
 		if authorizationType != AuthorizationTypeBearer {
 			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
 			helper.ReturnJSONAbort(c, 401, err.Error(), nil)
@@ -46,14 +64,125 @@ func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		}
 
 		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		payload, err := tokenMaker.VerifyToken(accessToken, token.KindAccess, token.AudienceAPI)
 
 		if err != nil {
 			helper.ReturnJSONAbort(c, 401, err.Error(), nil)
 			return
 		}
 
+		err = revocations.Check(c.Request.Context(), revocation.Token{
+			ID:        payload.ID,
+			SessionID: payload.SessionID,
+			UserUUID:  payload.UserUUID,
+			IssuedAt:  payload.IssuedAt,
+			ExpiredAt: payload.ExpiredAt,
+		})
+		if err != nil {
+			if errors.Is(err, revocation.ErrRevoked) {
+				helper.ReturnJSONAbort(c, 401, err.Error(), nil)
+				return
+			}
+			log.Printf("Error: %s", err.Error())
+			helper.ReturnJSONAbort(c, 500, "Internal server error", nil)
+			return
+		}
+
 		c.Set(AuthorizationPayloadKey, payload)
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{
+			UserUUID:  payload.UserUUID,
+			Role:      payload.Role,
+			ClientIP:  c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
+		c.Next()
+	}
+}
+
+func authenticateAPIKey(c *gin.Context, apiKeys apikey.Store, key string) {
+	caller, err := apikey.Authenticate(c.Request.Context(), apiKeys, key, c.ClientIP())
+	if err != nil {
+		if err != apikey.ErrInvalidKey {
+			log.Printf("Error: %s", err.Error())
+		}
+		helper.ReturnJSONAbort(c, 401, apikey.ErrInvalidKey.Error(), nil)
+		return
+	}
+
+	scope := apikey.MethodScope(c.Request.Method)
+	if !caller.Allows(scope) {
+		helper.ReturnJSONAbort(c, 401, fmt.Sprintf("API key does not have the %s scope", scope), nil)
+		return
+	}
+
+	// the key stands in for an access token of its user, without a login session
+	payload := &token.Payload{
+		ID:        caller.KeyUUID,
+		UserUUID:  caller.UserUUID,
+		Kind:      token.KindAccess,
+		Issuer:    token.Issuer,
+		Audience:  token.AudienceAPI,
+		ExpiredAt: caller.ExpiresAt,
+		Role:      caller.Role,
+	}
+
+	c.Set(AuthorizationPayloadKey, payload)
+	c.Set(APIKeyCallerKey, caller)
+	ctx := authz.WithScopes(c.Request.Context(), caller.Scopes)
+	c.Request = c.Request.WithContext(audit.WithActor(ctx, audit.Actor{
+		UserUUID:  caller.UserUUID,
+		Role:      caller.Role,
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}))
+	c.Next()
+}
+
+// RequireSession aborts requests authenticated with an API key. It guards the routes that manage
+// the login sessions and credentials of a user, which a key must not be able to take over. It must
+// run after AuthMiddleware.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(APIKeyCallerKey); ok {
+			helper.ReturnJSONAbort(c, 401, "API keys cannot be used for this request", nil)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission aborts the request unless the role of the authenticated user holds permission,
+// and for API keys unless the key has it among its scopes. It must run after AuthMiddleware.
+func RequirePermission(authorizer *authz.Authorizer, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+		if !authorizer.Can(c.Request.Context(), payload.Role, permission) {
+			helper.ReturnJSONAbort(c, 401, "unauthorized", nil)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail aborts the request when the email verification policy requires a verified
+// email address the authenticated user does not have. It must run after AuthMiddleware.
+func RequireVerifiedEmail(verifier *emailverification.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+		if err := verifier.Require(c.Request.Context(), payload.UserUUID); err != nil {
+			if errors.Is(err, emailverification.ErrNotVerified) {
+				helper.ReturnJSONAbort(c, 403, err.Error(), nil)
+				return
+			}
+			log.Printf("Error: %s", err.Error())
+			helper.ReturnJSONAbort(c, 500, "Internal server error", nil)
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -71,7 +200,7 @@ func AddAuthorizationTest(
 
 	uuidTokenString := uuidToken.String()
 
-	token, payload, err := tokenMaker.CreateToken(uuidTokenString, duration, role)
+	token, payload, err := tokenMaker.CreateToken(uuidTokenString, uuid.New(), token.KindAccess, duration, role, time.Now())
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -89,7 +218,7 @@ func AddAuthorizationTestAPI(
 	role string,
 ) {
 
-	token, payload, err := tokenMaker.CreateToken(uuidTokenString, duration, role)
+	token, payload, err := tokenMaker.CreateToken(uuidTokenString, uuid.New(), token.KindAccess, duration, role, time.Now())
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -1,16 +1,25 @@
 package middleware_test
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/apikey"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/setup"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func TestAuthMiddleware(t *testing.T) {
@@ -65,6 +74,18 @@ func TestAuthMiddleware(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "RefreshTokenAsAccessToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				refreshToken, _, err := tokenMaker.CreateToken(uuid.New().String(), uuid.New(), token.KindRefresh, time.Minute, "customer", time.Now())
+				require.NoError(t, err)
+
+				request.Header.Set(middleware.AuthorizationHeaderKey, fmt.Sprintf("%s %s", middleware.AuthorizationTypeBearer, refreshToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -74,7 +95,7 @@ func TestAuthMiddleware(t *testing.T) {
 
 			router.Engine.GET(
 				authPath,
-				middleware.AuthMiddleware(router.TokenMaker),
+				middleware.AuthMiddleware(router.TokenMaker, nil, router.Revocations),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -90,3 +111,219 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authorizer := authz.NewAuthorizer(authz.StaticSource{
+		"auditor": {authz.AuditRead},
+	}, 0)
+
+	testCases := []struct {
+		name         string
+		role         string
+		expectedCode int
+	}{
+		{
+			name:         "RoleHoldsPermission",
+			role:         "auditor",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "RoleLacksPermission",
+			role:         "customer",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setup.InitializeAndStartAppTest(t, nil)
+			authPath := "/audit"
+
+			router.Engine.GET(
+				authPath,
+				middleware.AuthMiddleware(router.TokenMaker, nil, router.Revocations),
+				middleware.RequirePermission(authorizer, authz.AuditRead),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTest(t, request, router.TokenMaker, middleware.AuthorizationTypeBearer, time.Minute, tc.role)
+			router.Engine.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name         string
+		config       emailverification.Config
+		buildStubs   func(store *mockdb.MockStore)
+		expectedCode int
+	}{
+		{
+			name:   "NotRequired",
+			config: emailverification.Config{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Verified",
+			config: emailverification.Config{Required: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Any()).Times(1).Return(db.GetUserByUserUUIDRow{VerifiedEmailAt: time.Now()}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "NotVerified",
+			config: emailverification.Config{Required: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), gomock.Any()).Times(1).Return(db.GetUserByUserUUIDRow{}, nil)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			router := setup.InitializeAndStartAppTest(t, nil)
+			authPath := "/transfer"
+
+			router.Engine.POST(
+				authPath,
+				middleware.AuthMiddleware(router.TokenMaker, nil, router.Revocations),
+				middleware.RequireVerifiedEmail(emailverification.NewVerifier(store, nil, tc.config)),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, authPath, nil)
+			require.NoError(t, err)
+
+			middleware.AddAuthorizationTest(t, request, router.TokenMaker, middleware.AuthorizationTypeBearer, time.Minute, "customer")
+			router.Engine.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authorizer := authz.NewAuthorizer(authz.StaticSource{
+		"auditor": {authz.AuditRead, authz.UsersRead},
+	}, 0)
+
+	testCases := []struct {
+		name         string
+		method       string
+		scopes       []string
+		handlers     []gin.HandlerFunc
+		key          func(key string) string
+		expectedCode int
+	}{
+		{
+			name:         "ReadScope",
+			method:       http.MethodGet,
+			scopes:       []string{apikey.ScopeRead},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "MissingWriteScope",
+			method:       http.MethodPost,
+			scopes:       []string{apikey.ScopeRead},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "PermissionScope",
+			method:       http.MethodGet,
+			scopes:       []string{apikey.ScopeRead, authz.AuditRead},
+			handlers:     []gin.HandlerFunc{middleware.RequirePermission(authorizer, authz.AuditRead)},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "MissingPermissionScope",
+			method:       http.MethodGet,
+			scopes:       []string{apikey.ScopeRead, authz.UsersRead},
+			handlers:     []gin.HandlerFunc{middleware.RequirePermission(authorizer, authz.AuditRead)},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "SessionOnlyRoute",
+			method:       http.MethodGet,
+			scopes:       []string{apikey.ScopeRead},
+			handlers:     []gin.HandlerFunc{middleware.RequireSession()},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "WrongSecret",
+			method: http.MethodGet,
+			scopes: []string{apikey.ScopeRead},
+			key: func(key string) string {
+				return key + "0"
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			var row db.GetAPIKeyByPrefixRow
+			store.EXPECT().
+				CreateAPIKey(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+					row = db.GetAPIKeyByPrefixRow{KeyUuid: uuid.New(), UserUuid: arg.UserUuid, Prefix: arg.Prefix, SecretHash: arg.SecretHash, Scopes: arg.Scopes, Role: "auditor"}
+					return db.ApiKey{KeyUuid: row.KeyUuid}, nil
+				})
+			userUUID := uuid.New()
+			_, key, err := apikey.Create(context.Background(), store, apikey.CreateParams{UserUUID: userUUID, CreatedBy: userUUID, Name: "ci", Scopes: tc.scopes})
+			require.NoError(t, err)
+			if tc.key != nil {
+				key = tc.key(key)
+			}
+
+			store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, _ string) (db.GetAPIKeyByPrefixRow, error) {
+				return row, nil
+			})
+			store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(1), nil)
+
+			router := setup.InitializeAndStartAppTest(t, nil)
+			path := "/api-key"
+			handlers := append([]gin.HandlerFunc{middleware.AuthMiddleware(router.TokenMaker, store, router.Revocations)}, tc.handlers...)
+			handlers = append(handlers, func(ctx *gin.Context) {
+				payload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*token.Payload)
+				require.Equal(t, userUUID, payload.UserUUID)
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+			router.Engine.Handle(tc.method, path, handlers...)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tc.method, path, nil)
+			require.NoError(t, err)
+
+			request.Header.Set(middleware.AuthorizationHeaderKey, fmt.Sprintf("ApiKey %s", key))
+			router.Engine.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
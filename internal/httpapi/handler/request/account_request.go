@@ -1,5 +1,7 @@
 package request
 
+import "time"
+
 type CreateAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
 }
@@ -18,3 +20,33 @@ type UpdateAccountRequest struct {
 	Currency string `json:"currency" binding:"required,oneof=USD EUR IDR"`
 	Status   int32  `json:"status" binding:"required"`
 }
+
+type GetAccountBalanceRequest struct {
+	AsOf time.Time `form:"as_of" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type AccountMemberRequest struct {
+	UserUUID      string `json:"user_uuid" binding:"required,uuid"`
+	Role          string `json:"role" binding:"required,oneof=owner signatory"`
+	Permission    string `json:"permission" binding:"required,oneof=view transfer transfer_limit"`
+	TransferLimit int64  `json:"transfer_limit" binding:"required_if=Permission transfer_limit,omitempty,min=1"`
+}
+
+type AccountMemberUserRequest struct {
+	UUIDAcc  string `uri:"uuid" binding:"required"`
+	UUIDUser string `uri:"user_uuid" binding:"required"`
+}
+
+type CreatePocketRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type AccountPocketRequest struct {
+	UUIDAcc    string `uri:"uuid" binding:"required"`
+	UUIDPocket string `uri:"pocket_uuid" binding:"required"`
+}
+
+type PocketMoveRequest struct {
+	Direction string `json:"direction" binding:"required,oneof=to_pocket to_parent"`
+	Amount    int64  `json:"amount" binding:"required,numeric,min=1"`
+}
@@ -0,0 +1,14 @@
+package request
+
+import "time"
+
+type ListAuditLogRequest struct {
+	Page       int32     `form:"page" binding:"required,min=1"`
+	Limit      int32     `form:"limit" binding:"required,min=5,max=100"`
+	ActorUUID  string    `form:"actor_uuid" binding:"omitempty,uuid"`
+	Action     string    `form:"action"`
+	EntityType string    `form:"entity_type"`
+	EntityID   string    `form:"entity_id"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -8,3 +8,57 @@ type AuthLoginRequest struct {
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
+
+type SessionRequest struct {
+	UUIDSession string `uri:"uuid" binding:"required"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFAEnrollLoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Email       string `json:"email" binding:"required,email"`
+	Code        string `json:"code" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type UnlockLoginRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// StepUpRequest authenticates again with the password or a two-factor code.
+type StepUpRequest struct {
+	Password string `json:"password" binding:"required_without=Code"`
+	Code     string `json:"code" binding:"required_without=Password"`
+}
+
+type ConfirmDeviceRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DeviceRequest struct {
+	DeviceUUID string `uri:"device_uuid" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type ListSecurityEventRequest struct {
+	Page  int32 `form:"page" binding:"required,min=1"`
+	Limit int32 `form:"limit" binding:"required,min=5,max=100"`
+}
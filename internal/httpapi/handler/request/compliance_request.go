@@ -0,0 +1,11 @@
+package request
+
+type ListComplianceReviewRequest struct {
+	Page   int32  `form:"page" binding:"required,min=1"`
+	Limit  int32  `form:"limit" binding:"required,min=5,max=50"`
+	Status string `form:"status" binding:"omitempty,oneof=pending cleared confirmed"`
+}
+
+type ComplianceReviewRequest struct {
+	UUIDReview string `uri:"uuid" binding:"required"`
+}
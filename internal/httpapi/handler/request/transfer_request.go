@@ -6,3 +6,12 @@ type CreateTransferRequest struct {
 	Amount          int64  `json:"amount" binding:"required,numeric,min=1"`
 	Currency        string `json:"currency" binding:"required,currency"`
 }
+
+type ListTransferReviewRequest struct {
+	Page  int32 `form:"page" binding:"required,min=1"`
+	Limit int32 `form:"limit" binding:"required,min=5,max=50"`
+}
+
+type TransferReviewRequest struct {
+	UUIDDecision string `uri:"uuid" binding:"required"`
+}
@@ -1,5 +1,7 @@
 package request
 
+import "time"
+
 type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum,min=6"`
 	Password string `json:"password" binding:"required,min=8"`
@@ -7,3 +9,42 @@ type CreateUserRequest struct {
 	FullName string `json:"full_name" binding:"required"`
 	Currency string `json:"currency" binding:"required"`
 }
+
+type ListUserRequest struct {
+	Page    int32  `form:"page" binding:"required,min=1"`
+	Limit   int32  `form:"limit" binding:"required,min=5,max=100"`
+	Search  string `form:"search"`
+	Role    string `form:"role" binding:"omitempty,oneof=customer admin superadmin"`
+	Blocked *bool  `form:"blocked"`
+}
+
+type UserRequest struct {
+	UUIDUser string `uri:"uuid" binding:"required"`
+}
+
+type ChangeUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=customer admin superadmin"`
+}
+
+type RoleRequest struct {
+	Role string `uri:"role" binding:"required,oneof=customer admin superadmin"`
+}
+
+type RoleMFARequest struct {
+	MFARequired *bool `json:"mfa_required" binding:"required"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyRequest struct {
+	UUIDKey string `uri:"key_uuid" binding:"required"`
+}
+
+type UserAPIKeyRequest struct {
+	UUIDUser string `uri:"uuid" binding:"required"`
+	UUIDKey  string `uri:"key_uuid" binding:"required"`
+}
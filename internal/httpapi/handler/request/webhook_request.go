@@ -0,0 +1,20 @@
+package request
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,oneof=TransferCompleted AccountCreated UserRegistered"`
+}
+
+type GetWebhookRequest struct {
+	UUIDWebhook string `uri:"uuid" binding:"required"`
+}
+
+type RedeliverWebhookRequest struct {
+	UUIDWebhook  string `uri:"uuid" binding:"required"`
+	UUIDDelivery string `uri:"delivery_uuid" binding:"required"`
+}
+
+type ListWebhookDeliveryRequest struct {
+	Page  int32 `form:"page" binding:"required,min=1"`
+	Limit int32 `form:"limit" binding:"required,min=5,max=50"`
+}
@@ -22,11 +22,46 @@ type AccountResponseSimple struct {
 }
 
 type AccountResponseGet struct {
-	AccountUUID uuid.UUID     `json:"account_uuid"`
-	Owner       string        `json:"owner"`
-	Currency    string        `json:"currency"`
-	Balance     string        `json:"balance"`
-	CreatedAt   time.Time     `json:"created_at"`
-	Status      int32         `json:"status"`
-	User        UserGetSimple `json:"user"`
+	AccountUUID         uuid.UUID               `json:"account_uuid"`
+	Owner               string                  `json:"owner"`
+	Currency            string                  `json:"currency"`
+	Balance             string                  `json:"balance"`
+	CreatedAt           time.Time               `json:"created_at"`
+	Status              int32                   `json:"status"`
+	User                UserGetSimple           `json:"user"`
+	PocketName          *string                 `json:"pocket_name,omitempty"`
+	ConsolidatedBalance string                  `json:"consolidated_balance,omitempty"`
+	Pockets             []AccountPocketResponse `json:"pockets,omitempty"`
+}
+
+type AccountPocketResponse struct {
+	AccountUUID uuid.UUID `json:"account_uuid"`
+	Name        string    `json:"name"`
+	Currency    string    `json:"currency"`
+	Balance     string    `json:"balance"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type PocketMoveResponse struct {
+	AccountUUID    uuid.UUID             `json:"account_uuid"`
+	AccountBalance string                `json:"account_balance"`
+	Pocket         AccountPocketResponse `json:"pocket"`
+}
+
+type AccountBalanceResponse struct {
+	AccountUUID uuid.UUID `json:"account_uuid"`
+	Currency    string    `json:"currency"`
+	Balance     string    `json:"balance"`
+	AsOf        time.Time `json:"as_of"`
+}
+
+type AccountMemberResponse struct {
+	UserUUID      uuid.UUID `json:"user_uuid"`
+	Username      string    `json:"username"`
+	FullName      string    `json:"full_name"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	Permission    string    `json:"permission"`
+	TransferLimit *string   `json:"transfer_limit"`
+	CreatedAt     time.Time `json:"created_at"`
 }
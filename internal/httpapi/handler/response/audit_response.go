@@ -0,0 +1,22 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditLogResponse struct {
+	AuditUUID  uuid.UUID       `json:"audit_uuid"`
+	ActorUUID  *uuid.UUID      `json:"actor_uuid"`
+	ActorRole  string          `json:"actor_role"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	ClientIP   string          `json:"client_ip"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -1,8 +1,78 @@
 package response
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type AuthLoginResponse struct {
 	SessionId    string        `json:"session_id"`
 	AcessToken   string        `json:"access_token"`
 	RefreshToken string        `json:"refresh_token"`
 	User         UserGetSimple `json:"user"`
+	// set instead of the tokens when the login has to be completed with a two-factor code
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAToken              string   `json:"mfa_token,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`
+	// set instead of the tokens when the login from a new device has to be confirmed with the
+	// emailed code
+	DeviceConfirmationRequired bool `json:"device_confirmation_required,omitempty"`
+	// DeviceToken identifies the device the login came from. The client sends it back in the
+	// X-Device-Token header, so the next logins are recognized as coming from this device.
+	DeviceToken string `json:"device_token,omitempty"`
+}
+
+type StepUpResponse struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	AuthTime             time.Time `json:"auth_time"`
+}
+
+// StepUpRequiredResponse tells the client an operation needs an authentication at most MaxAge
+// seconds old.
+type StepUpRequiredResponse struct {
+	Operation string `json:"operation"`
+	MaxAge    int64  `json:"max_age"`
+}
+
+type SessionResponse struct {
+	SessionID uuid.UUID `json:"session_id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIP  string    `json:"client_ip"`
+	// CreatedAt is when the user logged in, refreshing the session does not change it.
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Current   bool      `json:"current"`
+}
+
+type DeviceResponse struct {
+	DeviceUUID   uuid.UUID `json:"device_uuid"`
+	UserAgent    string    `json:"user_agent"`
+	LastClientIP string    `json:"last_client_ip"`
+	TrustedAt    time.Time `json:"trusted_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	Current      bool      `json:"current"`
+}
+
+type SecurityEventResponse struct {
+	EventType string    `json:"event_type"`
+	ClientIP  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
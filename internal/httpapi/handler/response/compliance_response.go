@@ -0,0 +1,41 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ComplianceHoldResponse is returned to the customer whose operation was held. It does not reveal the match.
+type ComplianceHoldResponse struct {
+	ReviewUUID uuid.UUID `json:"review_uuid"`
+	Status     string    `json:"status"`
+}
+
+type ComplianceReviewResponse struct {
+	ReviewUUID      uuid.UUID  `json:"review_uuid"`
+	Kind            string     `json:"kind"`
+	UserUUID        uuid.UUID  `json:"user_uuid"`
+	ScreenedName    string     `json:"screened_name"`
+	ListReference   string     `json:"list_reference"`
+	ListedName      string     `json:"listed_name"`
+	Score           float64    `json:"score"`
+	FromAccountUUID *uuid.UUID `json:"from_account_uuid"`
+	ToAccountUUID   *uuid.UUID `json:"to_account_uuid"`
+	Amount          *string    `json:"amount"`
+	Currency        *string    `json:"currency"`
+	Status          string     `json:"status"`
+	ReviewedBy      *uuid.UUID `json:"reviewed_by"`
+	ReviewedAt      *time.Time `json:"reviewed_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type ComplianceDecisionResponse struct {
+	ReviewUUID uuid.UUID `json:"review_uuid"`
+	Kind       string    `json:"kind"`
+	Status     string    `json:"status"`
+	// RiskDecision is the decision of the risk rules on a cleared transfer, which is only booked
+	// into Transaction when allowed.
+	RiskDecision *TransferDecisionResponse   `json:"risk_decision,omitempty"`
+	Transaction  *SuccessTransactionResponse `json:"transaction,omitempty"`
+}
@@ -1,5 +1,11 @@
 package response
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type SuccessTransactionResponse struct {
 	TransactionUUID string `json:"transaction_uuid"`
 	FromAccountUUID string `json:"from_account_uuid"`
@@ -9,3 +15,21 @@ type SuccessTransactionResponse struct {
 	LastedBalance   string `json:"lasted_balance"`
 	Type            string `json:"type"`
 }
+
+type TransferDecisionResponse struct {
+	DecisionUUID uuid.UUID `json:"decision_uuid"`
+	Decision     string    `json:"decision"`
+	Reasons      []string  `json:"reasons"`
+	ReviewStatus string    `json:"review_status,omitempty"`
+}
+
+type TransferReviewResponse struct {
+	DecisionUUID    uuid.UUID `json:"decision_uuid"`
+	UserUUID        uuid.UUID `json:"user_uuid"`
+	FromAccountUUID uuid.UUID `json:"from_account_uuid"`
+	ToAccountUUID   uuid.UUID `json:"to_account_uuid"`
+	Amount          string    `json:"amount"`
+	Currency        string    `json:"currency"`
+	Reasons         []string  `json:"reasons"`
+	CreatedAt       time.Time `json:"created_at"`
+}
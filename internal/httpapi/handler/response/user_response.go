@@ -1,16 +1,94 @@
 package response
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type UserGetSimple struct {
 	UserUUID string `json:"user_uuid"`
 	Username string `json:"username"`
 	FullName string `json:"full_name"`
 	Email    string `json:"email"`
+	// EmailVerified is left out of lists, which do not load it.
+	EmailVerified *bool `json:"email_verified,omitempty"`
 }
 
 type UserResponseCreate struct {
-	UserUUID string                `json:"user_uuid"`
-	FullName string                `json:"full_name"`
-	Email    string                `json:"email"`
-	Username string                `json:"username"`
-	Account  AccountResponseSimple `json:"account"`
+	UserUUID         string                `json:"user_uuid"`
+	FullName         string                `json:"full_name"`
+	Email            string                `json:"email"`
+	Username         string                `json:"username"`
+	EmailVerified    bool                  `json:"email_verified"`
+	Account          AccountResponseSimple `json:"account"`
+	ComplianceStatus string                `json:"compliance_status,omitempty"`
+}
+
+type UserAdminResponse struct {
+	UserUUID              uuid.UUID `json:"user_uuid"`
+	Username              string    `json:"username"`
+	FullName              string    `json:"full_name"`
+	Email                 string    `json:"email"`
+	Role                  string    `json:"role"`
+	IsBlocked             bool      `json:"is_blocked"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+	EmailVerified         bool      `json:"email_verified"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+type UserSessionResponse struct {
+	SessionID uuid.UUID `json:"session_id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIP  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserDetailResponse struct {
+	UserUUID              uuid.UUID               `json:"user_uuid"`
+	Username              string                  `json:"username"`
+	FullName              string                  `json:"full_name"`
+	Email                 string                  `json:"email"`
+	Role                  string                  `json:"role"`
+	IsBlocked             bool                    `json:"is_blocked"`
+	PasswordResetRequired bool                    `json:"password_reset_required"`
+	CreatedAt             time.Time               `json:"created_at"`
+	Accounts              []AccountResponseSimple `json:"accounts"`
+	Sessions              []UserSessionResponse   `json:"sessions"`
+}
+
+type UserStatusResponse struct {
+	UserUUID              uuid.UUID `json:"user_uuid"`
+	Username              string    `json:"username"`
+	Role                  string    `json:"role"`
+	IsBlocked             bool      `json:"is_blocked"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+}
+
+type RoleSettingResponse struct {
+	Role        string    `json:"role"`
+	MFARequired bool      `json:"mfa_required"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type APIKeyResponse struct {
+	KeyUUID    uuid.UUID  `json:"key_uuid"`
+	UserUUID   uuid.UUID  `json:"user_uuid"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  uuid.UUID  `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKeyResponse carries the key itself, which is only ever shown when it is created.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
 }
@@ -0,0 +1,33 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookResponse struct {
+	WebhookUUID uuid.UUID `json:"webhook_uuid"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WebhookResponseCreate includes the signing secret, which is only ever returned on creation.
+type WebhookResponseCreate struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	DeliveryUUID   uuid.UUID  `json:"delivery_uuid"`
+	EventUUID      uuid.UUID  `json:"event_uuid"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode *int32     `json:"last_status_code"`
+	LastError      *string    `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
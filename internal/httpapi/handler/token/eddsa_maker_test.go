@@ -0,0 +1,166 @@
+package token_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/signingkey"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func randomSigningKey(t *testing.T, activatesAt time.Time) signingkey.Key {
+	key, err := signingkey.GenerateKey(activatesAt)
+	require.NoError(t, err)
+	return key
+}
+
+func TestEdDSAMaker(t *testing.T) {
+	key := randomSigningKey(t, time.Now().Add(-time.Hour))
+	maker, err := token.NewEdDSAMaker(signingkey.NewKeySet(key))
+	require.NoError(t, err)
+
+	uuidUser := uuid.New().String()
+	sessionID := uuid.New()
+	duration := time.Minute
+	issuedAt := time.Now()
+
+	tokenString, payload, err := maker.CreateToken(uuidUser, sessionID, token.KindAccess, duration, util.RandomRole(), time.Now())
+	require.NoError(t, err)
+	require.NotEmpty(t, tokenString)
+	require.NotEmpty(t, payload)
+
+	parsed, _, err := new(jwt.Parser).ParseUnverified(tokenString, &token.Payload{})
+	require.NoError(t, err)
+	require.Equal(t, key.ID, parsed.Header["kid"])
+	require.Equal(t, signingkey.AlgorithmEdDSA, parsed.Header["alg"])
+
+	payload, err = maker.VerifyToken(tokenString, token.KindAccess, token.AudienceAPI)
+	require.NoError(t, err)
+	require.Equal(t, uuidUser, payload.UserUUID.String())
+	require.Equal(t, sessionID, payload.SessionID)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, issuedAt.Add(duration), payload.ExpiredAt, time.Second)
+}
+
+func TestEdDSAMakerKeyRotation(t *testing.T) {
+	oldKey := randomSigningKey(t, time.Now().Add(-time.Hour))
+	keys := signingkey.NewKeySet(oldKey)
+	maker, err := token.NewEdDSAMaker(keys)
+	require.NoError(t, err)
+
+	oldToken, _, err := maker.CreateToken(uuid.New().String(), uuid.New(), token.KindAccess, time.Minute, util.RandomRole(), time.Now())
+	require.NoError(t, err)
+
+	// a newer key takes over, the old one is still accepted during the grace period
+	newKey := randomSigningKey(t, time.Now().Add(-time.Minute))
+	oldKey.ExpiresAt = time.Now().Add(time.Hour)
+	keys.Set([]signingkey.Key{newKey, oldKey})
+
+	newToken, _, err := maker.CreateToken(uuid.New().String(), uuid.New(), token.KindAccess, time.Minute, util.RandomRole(), time.Now())
+	require.NoError(t, err)
+	parsed, _, err := new(jwt.Parser).ParseUnverified(newToken, &token.Payload{})
+	require.NoError(t, err)
+	require.Equal(t, newKey.ID, parsed.Header["kid"])
+
+	_, err = maker.VerifyToken(oldToken, token.KindAccess, token.AudienceAPI)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(newToken, token.KindAccess, token.AudienceAPI)
+	require.NoError(t, err)
+
+	// once the grace period is over, tokens of the old key are rejected
+	oldKey.ExpiresAt = time.Now().Add(-time.Second)
+	keys.Set([]signingkey.Key{newKey, oldKey})
+
+	payload, err := maker.VerifyToken(oldToken, token.KindAccess, token.AudienceAPI)
+	require.EqualError(t, err, token.ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestExpiredEdDSAToken(t *testing.T) {
+	maker, err := token.NewEdDSAMaker(signingkey.NewKeySet(randomSigningKey(t, time.Now().Add(-time.Hour))))
+	require.NoError(t, err)
+
+	tokenString, _, err := maker.CreateToken(uuid.New().String(), uuid.New(), token.KindAccess, -time.Minute, util.RandomRole(), time.Now())
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(tokenString, token.KindAccess, token.AudienceAPI)
+	require.EqualError(t, err, token.ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestEdDSATokenOfUnknownKey(t *testing.T) {
+	other, err := token.NewEdDSAMaker(signingkey.NewKeySet(randomSigningKey(t, time.Now().Add(-time.Hour))))
+	require.NoError(t, err)
+	maker, err := token.NewEdDSAMaker(signingkey.NewKeySet(randomSigningKey(t, time.Now().Add(-time.Hour))))
+	require.NoError(t, err)
+
+	tokenString, _, err := other.CreateToken(uuid.New().String(), uuid.New(), token.KindAccess, time.Minute, util.RandomRole(), time.Now())
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(tokenString, token.KindAccess, token.AudienceAPI)
+	require.EqualError(t, err, token.ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestEdDSAMakerRejectsSymmetricToken(t *testing.T) {
+	key := randomSigningKey(t, time.Now().Add(-time.Hour))
+	maker, err := token.NewEdDSAMaker(signingkey.NewKeySet(key))
+	require.NoError(t, err)
+
+	// an HMAC token keyed with the public key must not pass as signed by the private key
+	payload, err := token.NewPayload(uuid.New().String(), uuid.New(), token.KindAccess, time.Minute, util.RandomRole(), time.Now())
+	require.NoError(t, err)
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	jwtToken.Header["kid"] = key.ID
+	tokenString, err := jwtToken.SignedString([]byte(key.PublicKey))
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(tokenString, token.KindAccess, token.AudienceAPI)
+	require.EqualError(t, err, token.ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestEdDSAMakerWithoutActiveKey(t *testing.T) {
+	// a key that is published but does not sign yet
+	maker, err := token.NewEdDSAMaker(signingkey.NewKeySet(randomSigningKey(t, time.Now().Add(time.Hour))))
+	require.NoError(t, err)
+
+	_, _, err = maker.CreateToken(uuid.New().String(), uuid.New(), token.KindAccess, time.Minute, util.RandomRole(), time.Now())
+	require.ErrorIs(t, err, signingkey.ErrNoSigningKey)
+}
+
+func TestEdDSATokenRegisteredClaims(t *testing.T) {
+	key := randomSigningKey(t, time.Now().Add(-time.Hour))
+	maker, err := token.NewEdDSAMaker(signingkey.NewKeySet(key))
+	require.NoError(t, err)
+
+	tokenString, payload, err := maker.CreateToken(uuid.New().String(), uuid.New(), token.KindAccess, time.Minute, util.RandomRole(), time.Now())
+	require.NoError(t, err)
+
+	// a standard JWT library has to see the expiry, audience and issuer under their registered names
+	claims := &jwt.RegisteredClaims{}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return key.PublicKey, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, payload.ID.String(), claims.ID)
+	require.Equal(t, payload.UserUUID.String(), claims.Subject)
+	require.Equal(t, payload.Issuer, claims.Issuer)
+	require.True(t, claims.VerifyAudience(token.AudienceAPI, true))
+	require.NotNil(t, claims.ExpiresAt)
+	require.WithinDuration(t, payload.ExpiredAt, claims.ExpiresAt.Time, time.Second)
+	require.NotNil(t, claims.NotBefore)
+
+	expiredString, _, err := maker.CreateToken(uuid.New().String(), uuid.New(), token.KindAccess, -time.Minute, util.RandomRole(), time.Now())
+	require.NoError(t, err)
+	_, err = jwt.ParseWithClaims(expiredString, &jwt.RegisteredClaims{}, func(*jwt.Token) (interface{}, error) {
+		return key.PublicKey, nil
+	})
+	require.Error(t, err)
+	require.True(t, errors.Is(err, jwt.ErrTokenExpired))
+}
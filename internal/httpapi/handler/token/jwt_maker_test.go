@@ -28,17 +28,23 @@ func TestJWTMaker(t *testing.T) {
 
 	role := util.RandomRole()
 
-	token, payload, err := maker.CreateToken(uuidUser, duration, role)
+	sessionID := uuid.New()
+
+	tokenString, payload, err := maker.CreateToken(uuidUser, sessionID, token.KindAccess, duration, role, time.Now())
 	require.NoError(t, err)
-	require.NotEmpty(t, token)
+	require.NotEmpty(t, tokenString)
 	require.NotEmpty(t, payload)
 
-	payload, err = maker.VerifyToken(token)
+	payload, err = maker.VerifyToken(tokenString, token.KindAccess, token.AudienceAPI)
 	require.NoError(t, err)
-	require.NotEmpty(t, token)
+	require.NotEmpty(t, tokenString)
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, uuidUser, payload.UserUUID.String())
+	require.Equal(t, sessionID, payload.SessionID)
+	require.Equal(t, token.KindAccess, payload.Kind)
+	require.Equal(t, token.Issuer, payload.Issuer)
+	require.Equal(t, token.AudienceAPI, payload.Audience)
 	// require.Equal(t, role, payload.Role)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
@@ -56,17 +62,38 @@ func TestExpiredJWTToken(t *testing.T) {
 
 	role := util.RandomRole()
 
-	tokenString, payload, err := maker.CreateToken(uuidUser, -time.Minute, role)
+	tokenString, payload, err := maker.CreateToken(uuidUser, uuid.New(), token.KindAccess, -time.Minute, role, time.Now())
 	require.NoError(t, err)
 	require.NotEmpty(t, tokenString)
 	require.NotEmpty(t, payload)
 
-	payload, err = maker.VerifyToken(tokenString)
+	payload, err = maker.VerifyToken(tokenString, token.KindAccess, token.AudienceAPI)
 	require.Error(t, err)
 	require.EqualError(t, err, token.ErrExpiredToken.Error())
 	require.Nil(t, payload)
 }
 
+func TestWrongKindJWTToken(t *testing.T) {
+	maker, err := token.NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	refreshToken, _, err := maker.CreateToken(uuid.New().String(), uuid.New(), token.KindRefresh, time.Minute, util.RandomRole(), time.Now())
+	require.NoError(t, err)
+
+	// a refresh token cannot be used as an access token
+	payload, err := maker.VerifyToken(refreshToken, token.KindAccess, token.AudienceAPI)
+	require.EqualError(t, err, token.ErrInvalidTokenKind.Error())
+	require.Nil(t, payload)
+
+	payload, err = maker.VerifyToken(refreshToken, token.KindRefresh, token.AudienceAPI)
+	require.EqualError(t, err, token.ErrInvalidToken.Error())
+	require.Nil(t, payload)
+
+	payload, err = maker.VerifyToken(refreshToken, token.KindRefresh, token.AudienceRefresh)
+	require.NoError(t, err)
+	require.Equal(t, token.KindRefresh, payload.Kind)
+}
+
 func TestInvalidJWTTokenAlgNone(t *testing.T) {
 
 	newRandomUUID, err := uuid.NewRandom()
@@ -77,7 +104,7 @@ func TestInvalidJWTTokenAlgNone(t *testing.T) {
 
 	role := util.RandomRole()
 
-	payload, err := token.NewPayload(uuidUser, time.Minute, role)
+	payload, err := token.NewPayload(uuidUser, uuid.New(), token.KindAccess, time.Minute, role, time.Now())
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
@@ -87,7 +114,7 @@ func TestInvalidJWTTokenAlgNone(t *testing.T) {
 	maker, err := token.NewJWTMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	payload, err = maker.VerifyToken(tokenString)
+	payload, err = maker.VerifyToken(tokenString, token.KindAccess, token.AudienceAPI)
 	require.Error(t, err)
 	require.EqualError(t, err, token.ErrInvalidToken.Error())
 	require.Nil(t, payload)
@@ -103,7 +130,7 @@ func TestInvalidPayload(t *testing.T) {
 	_, err := token.NewJWTMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	_, err = token.NewPayload("wrong-uuid", time.Minute, "customer")
+	_, err = token.NewPayload("wrong-uuid", uuid.New(), token.KindAccess, time.Minute, "customer", time.Now())
 	require.Error(t, err)
 	require.EqualError(t, err, "invalid UUID length: 10")
 }
@@ -1,14 +1,32 @@
 package token
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Maker is an interface that defines methods for creating and verifying tokens.
 type Maker interface {
-	// CreateToken generates a new token for the given user UUID and duration.
+	// CreateToken generates a new token of kind for the given user UUID, login session and duration,
+	// recording authTime as the time the user authenticated.
 	// It returns the generated token as a string and any error encountered.
-	CreateToken(userUuid string, duration time.Duration, role string) (string, *Payload, error)
+	CreateToken(userUuid string, sessionID uuid.UUID, kind Kind, duration time.Duration, role string, authTime time.Time) (string, *Payload, error)
 
-	// VerifyToken verifies the authenticity of the provided token.
+	// VerifyToken verifies the authenticity of the provided token and that it is a token of kind for audience.
 	// It returns the payload of the token if it is valid, or an error if the token is invalid.
-	VerifyToken(token string) (*Payload, error)
+	VerifyToken(token string, kind Kind, audience string) (*Payload, error)
+}
+
+// AlgorithmEdDSA selects the EdDSAMaker in NewMaker.
+const AlgorithmEdDSA = "EdDSA"
+
+// NewMaker creates the maker of the configured signing algorithm. AlgorithmEdDSA signs with the
+// rotating asymmetric keys, anything else encrypts PASETO tokens with the symmetric key.
+func NewMaker(algorithm, symmetricKey string, keys KeySet) (Maker, error) {
+	if algorithm == AlgorithmEdDSA {
+		return NewEdDSAMaker(keys)
+	}
+
+	return NewPasetoMaker(symmetricKey)
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/aead/chacha20poly1305"
+	"github.com/google/uuid"
 	"github.com/o1egl/paseto"
 )
 
@@ -26,8 +27,8 @@ func NewPasetoMaker(symetricKey string) (Maker, error) {
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(userUuid string, duration time.Duration, role string) (string, *Payload, error) {
-	payload, err := NewPayload(userUuid, duration, role)
+func (maker *PasetoMaker) CreateToken(userUuid string, sessionID uuid.UUID, kind Kind, duration time.Duration, role string, authTime time.Time) (string, *Payload, error) {
+	payload, err := NewPayload(userUuid, sessionID, kind, duration, role, authTime)
 	if err != nil {
 		return "", payload, err
 	}
@@ -37,7 +38,7 @@ func (maker *PasetoMaker) CreateToken(userUuid string, duration time.Duration, r
 	return token, payload, err
 }
 
-func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
+func (maker *PasetoMaker) VerifyToken(token string, kind Kind, audience string) (*Payload, error) {
 	payload := &Payload{}
 
 	err := maker.paseto.Decrypt(token, maker.symetricKey, payload, nil)
@@ -48,7 +49,12 @@ func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 	err = payload.Valid()
 
 	if err != nil {
-		return nil, ErrExpiredToken
+		return nil, err
+	}
+
+	err = payload.Expect(kind, audience)
+	if err != nil {
+		return nil, err
 	}
 
 	return payload, nil
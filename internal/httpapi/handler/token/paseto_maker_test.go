@@ -27,17 +27,23 @@ func TestPasetoMaker(t *testing.T) {
 
 	role := util.RandomRole()
 
-	token, payload, err := maker.CreateToken(uuidUser, duration, role)
+	sessionID := uuid.New()
+
+	tokenString, payload, err := maker.CreateToken(uuidUser, sessionID, token.KindAccess, duration, role, time.Now())
 	require.NoError(t, err)
-	require.NotEmpty(t, token)
+	require.NotEmpty(t, tokenString)
 	require.NotEmpty(t, payload)
 
-	payload, err = maker.VerifyToken(token)
+	payload, err = maker.VerifyToken(tokenString, token.KindAccess, token.AudienceAPI)
 	require.NoError(t, err)
-	require.NotEmpty(t, token)
+	require.NotEmpty(t, tokenString)
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, uuidUser, payload.UserUUID.String())
+	require.Equal(t, sessionID, payload.SessionID)
+	require.Equal(t, token.KindAccess, payload.Kind)
+	require.Equal(t, token.Issuer, payload.Issuer)
+	require.Equal(t, token.AudienceAPI, payload.Audience)
 	// require.Equal(t, role, payload.Role)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
@@ -54,21 +60,42 @@ func TestExpiredPasetoToken(t *testing.T) {
 	uuidUser := newRandomUUID.String()
 	role := util.RandomRole()
 
-	tokenString, payload, err := maker.CreateToken(uuidUser, -time.Minute, role)
+	tokenString, payload, err := maker.CreateToken(uuidUser, uuid.New(), token.KindAccess, -time.Minute, role, time.Now())
 	require.NoError(t, err)
 	require.NotEmpty(t, tokenString)
 	require.NotEmpty(t, payload)
 
-	payload, err = maker.VerifyToken(tokenString)
+	payload, err = maker.VerifyToken(tokenString, token.KindAccess, token.AudienceAPI)
 	require.Error(t, err)
 	require.EqualError(t, err, token.ErrExpiredToken.Error())
 	require.Nil(t, payload)
 }
+func TestWrongKindPasetoToken(t *testing.T) {
+	maker, err := token.NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	refreshToken, _, err := maker.CreateToken(uuid.New().String(), uuid.New(), token.KindRefresh, time.Minute, util.RandomRole(), time.Now())
+	require.NoError(t, err)
+
+	// a refresh token cannot be used as an access token
+	payload, err := maker.VerifyToken(refreshToken, token.KindAccess, token.AudienceAPI)
+	require.EqualError(t, err, token.ErrInvalidTokenKind.Error())
+	require.Nil(t, payload)
+
+	payload, err = maker.VerifyToken(refreshToken, token.KindRefresh, token.AudienceAPI)
+	require.EqualError(t, err, token.ErrInvalidToken.Error())
+	require.Nil(t, payload)
+
+	payload, err = maker.VerifyToken(refreshToken, token.KindRefresh, token.AudienceRefresh)
+	require.NoError(t, err)
+	require.Equal(t, token.KindRefresh, payload.Kind)
+}
+
 func TestInvalidTokenPaseto(t *testing.T) {
 	maker, err := token.NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	_, err = maker.VerifyToken("invalid token")
+	_, err = maker.VerifyToken("invalid token", token.KindAccess, token.AudienceAPI)
 	require.EqualError(t, err, token.ErrInvalidToken.Error())
 }
 
@@ -78,7 +105,7 @@ func TestInvalidPayloadPaseto(t *testing.T) {
 
 	role := util.RandomRole()
 
-	_, _, err = maker.CreateToken("test", time.Minute, role)
+	_, _, err = maker.CreateToken("test", uuid.New(), token.KindAccess, time.Minute, role, time.Now())
 	require.Error(t, err)
 }
 
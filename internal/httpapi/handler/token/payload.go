@@ -1,7 +1,11 @@
 package token
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
@@ -9,19 +13,163 @@ import (
 )
 
 var (
-	ErrExpiredToken = errors.New("token has expired")
-	ErrInvalidToken = errors.New("token is invalid")
+	ErrExpiredToken     = errors.New("token has expired")
+	ErrInvalidToken     = errors.New("token is invalid")
+	ErrInvalidTokenKind = errors.New("token kind is invalid")
 )
 
+// Kind tells what a token may be used for.
+type Kind string
+
+const (
+	// KindAccess tokens authenticate API calls.
+	KindAccess Kind = "access"
+	// KindRefresh tokens can only be exchanged for new tokens.
+	KindRefresh Kind = "refresh"
+	// KindMFA tokens prove the password was checked and can only be used to complete a
+	// two-factor login.
+	KindMFA Kind = "mfa"
+)
+
+// Issuer is the issuer of every token this service creates.
+const Issuer = "simple_bank"
+
+// Audiences of the tokens. Access tokens are meant for the API, refresh tokens only for the
+// token refresh endpoint and MFA tokens only for the two-factor login endpoints.
+const (
+	AudienceAPI     = "simple_bank.api"
+	AudienceRefresh = "simple_bank.auth.refresh"
+	AudienceMFA     = "simple_bank.auth.mfa"
+)
+
+var kindAudiences = map[Kind]string{
+	KindAccess:  AudienceAPI,
+	KindRefresh: AudienceRefresh,
+	KindMFA:     AudienceMFA,
+}
+
+// Payload is the content of a token. It is serialized with the registered JWT claim names, see
+// claims.
 type Payload struct {
-	ID        uuid.UUID `json:"id"`
-	UserUUID  uuid.UUID `json:"user_uuid"`
-	IssuedAt  time.Time `json:"issued_at"`
-	ExpiredAt time.Time `json:"expired_at"`
-	Role      string    `json:"role"`
+	ID        uuid.UUID
+	UserUUID  uuid.UUID
+	SessionID uuid.UUID
+	Kind      Kind
+	Issuer    string
+	Audience  string
+	IssuedAt  time.Time
+	NotBefore time.Time
+	ExpiredAt time.Time
+	Role      string
+	// AuthTime is when the user last proved their identity with a password or a two-factor code.
+	// Tokens issued by refreshing a session keep the time of the login.
+	AuthTime time.Time
+}
+
+// claims is the JSON form of a Payload. It uses the registered claim names and NumericDate times,
+// so other services can verify the tokens with a standard JWT library, which enforces exp, nbf and
+// aud only under those names.
+type claims struct {
+	ID        uuid.UUID   `json:"jti"`
+	Subject   uuid.UUID   `json:"sub"`
+	SessionID uuid.UUID   `json:"session_id"`
+	Kind      Kind        `json:"kind"`
+	Issuer    string      `json:"iss"`
+	Audience  string      `json:"aud"`
+	IssuedAt  numericDate `json:"iat"`
+	NotBefore numericDate `json:"nbf"`
+	ExpiresAt numericDate `json:"exp"`
+	Role      string      `json:"role"`
+	AuthTime  numericDate `json:"auth_time"`
+}
+
+func (payload Payload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(claims{
+		ID:        payload.ID,
+		Subject:   payload.UserUUID,
+		SessionID: payload.SessionID,
+		Kind:      payload.Kind,
+		Issuer:    payload.Issuer,
+		Audience:  payload.Audience,
+		IssuedAt:  numericDate(payload.IssuedAt),
+		NotBefore: numericDate(payload.NotBefore),
+		ExpiresAt: numericDate(payload.ExpiredAt),
+		Role:      payload.Role,
+		AuthTime:  numericDate(payload.AuthTime),
+	})
+}
+
+func (payload *Payload) UnmarshalJSON(data []byte) error {
+	var c claims
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+
+	*payload = Payload{
+		ID:        c.ID,
+		UserUUID:  c.Subject,
+		SessionID: c.SessionID,
+		Kind:      c.Kind,
+		Issuer:    c.Issuer,
+		Audience:  c.Audience,
+		IssuedAt:  time.Time(c.IssuedAt),
+		NotBefore: time.Time(c.NotBefore),
+		ExpiredAt: time.Time(c.ExpiresAt),
+		Role:      c.Role,
+		AuthTime:  time.Time(c.AuthTime),
+	}
+	return nil
+}
+
+// numericDate is a JWT NumericDate: seconds since the epoch. The fraction keeps microseconds, which
+// revocation compares issue times at, and a zero time is null.
+type numericDate time.Time
+
+func (d numericDate) MarshalJSON() ([]byte, error) {
+	t := time.Time(d)
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+
+	micros := t.UnixMicro()
+	return []byte(fmt.Sprintf("%d.%06d", micros/1e6, micros%1e6)), nil
 }
 
-func NewPayload(userUUIDString string, duration time.Duration, role string) (*Payload, error) {
+func (d *numericDate) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = numericDate{}
+		return nil
+	}
+
+	seconds, fraction, _ := strings.Cut(string(data), ".")
+	micros, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	micros *= 1e6
+
+	if fraction != "" {
+		fraction = (fraction + "000000")[:6]
+		n, err := strconv.ParseInt(fraction, 10, 64)
+		if err != nil || n < 0 {
+			return ErrInvalidToken
+		}
+		micros += n
+	}
+
+	*d = numericDate(time.UnixMicro(micros))
+	return nil
+}
+
+// NewPayload creates the payload of a token of kind issued to a user. Every token of a login session,
+// access and refresh alike, carries the ID of that session so the session can be revoked, and the
+// time the user authenticated so sensitive operations can ask for a recent authentication.
+func NewPayload(userUUIDString string, sessionID uuid.UUID, kind Kind, duration time.Duration, role string, authTime time.Time) (*Payload, error) {
+	audience, ok := kindAudiences[kind]
+	if !ok {
+		return nil, ErrInvalidTokenKind
+	}
+
 	tokenUUID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -33,12 +181,19 @@ func NewPayload(userUUIDString string, duration time.Duration, role string) (*Pa
 		return nil, err
 	}
 
+	now := time.Now()
 	payload := &Payload{
 		ID:        tokenUUID,
 		UserUUID:  userUUID,
-		IssuedAt:  time.Now(),
-		ExpiredAt: time.Now().Add(duration),
+		SessionID: sessionID,
+		Kind:      kind,
+		Issuer:    Issuer,
+		Audience:  audience,
+		IssuedAt:  now,
+		NotBefore: now,
+		ExpiredAt: now.Add(duration),
 		Role:      role,
+		AuthTime:  authTime,
 	}
 
 	return payload, nil
@@ -48,6 +203,21 @@ func (payload *Payload) Valid() error {
 	if time.Now().After(payload.ExpiredAt) {
 		return ErrExpiredToken
 	}
+	if time.Now().Before(payload.NotBefore) {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// Expect checks that the token was issued by this service as a token of kind for audience.
+func (payload *Payload) Expect(kind Kind, audience string) error {
+	if payload.Kind != kind {
+		return ErrInvalidTokenKind
+	}
+	if payload.Issuer != Issuer || payload.Audience != audience {
+		return ErrInvalidToken
+	}
 
 	return nil
 }
@@ -1,12 +1,19 @@
 package router
 
 import (
-	"fmt"
+	"crypto/tls"
+	"log"
+	"net/http"
 
+	"github.com/fajaramaulana/simple_bank_project/internal/apikey"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/controller"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/middleware"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/signingkey"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
@@ -18,22 +25,34 @@ type Router struct {
 	transaction *controller.TransactionController
 	user        *controller.UserController
 	auth        *controller.AuthController
+	webhook     *controller.WebhookController
+	compliance  *controller.ComplianceController
+	audit       *controller.AuditController
+	authorizer  *authz.Authorizer
 	TokenMaker  token.Maker
+	signingKeys *signingkey.KeySet
+	apiKeys     apikey.Store
+	verifier    *emailverification.Verifier
+	Revocations *revocation.List
 }
 
 // NewRouter creates a new instance of the Router struct and initializes its dependencies.
-func NewRouter(account *controller.AccountController, transaction *controller.TransactionController, user *controller.UserController, auth *controller.AuthController, configToken map[string]string) (*Router, error) {
-	tokenMaker, err := token.NewPasetoMaker(configToken["token_secret"])
-	if err != nil {
-		return nil, fmt.Errorf("cannot create token maker: %w", err)
-	}
+func NewRouter(account *controller.AccountController, transaction *controller.TransactionController, user *controller.UserController, auth *controller.AuthController, webhook *controller.WebhookController, compliance *controller.ComplianceController, audit *controller.AuditController, authorizer *authz.Authorizer, tokenMaker token.Maker, signingKeys *signingkey.KeySet, apiKeys apikey.Store, verifier *emailverification.Verifier, revocations *revocation.List) (*Router, error) {
 	router := &Router{
 		Engine:      gin.Default(),
 		account:     account,
 		transaction: transaction,
 		user:        user,
 		auth:        auth,
+		webhook:     webhook,
+		compliance:  compliance,
+		audit:       audit,
+		authorizer:  authorizer,
 		TokenMaker:  tokenMaker,
+		signingKeys: signingKeys,
+		apiKeys:     apiKeys,
+		verifier:    verifier,
+		Revocations: revocations,
 	}
 
 	// Register custom validator
@@ -55,26 +74,109 @@ func (r *Router) SetupRouter() {
 		})
 	})
 
+	// public keys of the token signing keys, so other services can verify tokens
+	r.Engine.GET("/.well-known/jwks.json", gin.WrapH(signingkey.Handler(r.signingKeys)))
+
 	// auth
 	v1.POST("/auth/login", r.auth.Login)
 	v1.POST("auth/refresh/token", r.auth.RefreshToken)
+	v1.POST("/auth/login/mfa", r.auth.VerifyLoginMFA)
+	v1.POST("/auth/login/mfa/enroll", r.auth.EnrollLoginMFA)
+	v1.POST("/auth/login/device/confirm", r.auth.ConfirmDevice)
+	v1.POST("/auth/password/forgot", r.auth.ForgotPassword)
+	v1.POST("/auth/password/reset", r.auth.ResetPassword)
+	v1.POST("/auth/login/unlock", r.auth.UnlockLogin)
+	v1.POST("/auth/email/verify", r.auth.VerifyEmail)
 
-	authRoutesV1 := v1.Group("").Use(middleware.AuthMiddleware(r.TokenMaker))
+	authRoutesV1 := v1.Group("").Use(middleware.AuthMiddleware(r.TokenMaker, r.apiKeys, r.Revocations))
+
+	// can declares the permission a route requires on top of authentication
+	can := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(r.authorizer, permission)
+	}
+	// session keeps API keys away from the routes managing logins and credentials
+	session := middleware.RequireSession()
+	// verified keeps users without a verified email address from moving money when the policy requires it
+	verified := middleware.RequireVerifiedEmail(r.verifier)
+
+	// session
+	authRoutesV1.POST("/auth/logout", session, r.auth.Logout)
+	authRoutesV1.GET("/auth/sessions", session, r.auth.ListSessions)
+	authRoutesV1.DELETE("/auth/sessions/:uuid", session, r.auth.RevokeSession)
+	authRoutesV1.DELETE("/auth/sessions", session, r.auth.RevokeOtherSessions)
+	authRoutesV1.GET("/auth/devices", session, r.auth.ListDevices)
+	authRoutesV1.DELETE("/auth/devices/:device_uuid", session, r.auth.RemoveDevice)
+	authRoutesV1.GET("/auth/security-events", session, r.auth.ListSecurityEvents)
+	authRoutesV1.POST("/auth/email/verify/resend", session, r.auth.ResendVerificationEmail)
+	authRoutesV1.POST("/auth/step-up", session, r.auth.StepUp)
+	authRoutesV1.POST("/auth/mfa/totp", session, r.auth.EnrollTOTP)
+	authRoutesV1.POST("/auth/mfa/totp/confirm", session, r.auth.ConfirmTOTP)
+	authRoutesV1.POST("/auth/mfa/totp/disable", session, r.auth.DisableTOTP)
+	authRoutesV1.POST("/auth/mfa/recovery-codes", session, r.auth.RegenerateRecoveryCodes)
+	authRoutesV1.POST("/auth/api-keys", session, r.user.CreateAPIKey)
+	authRoutesV1.GET("/auth/api-keys", session, r.user.ListAPIKeys)
+	authRoutesV1.DELETE("/auth/api-keys/:key_uuid", session, r.user.RevokeAPIKey)
 
 	// account
-	authRoutesV1.POST("/account", r.account.CreateAccount)
+	authRoutesV1.POST("/account", verified, r.account.CreateAccount)
 	authRoutesV1.GET("/account/:uuid", r.account.GetAccount)
+	authRoutesV1.GET("/account/:uuid/balance", r.account.GetAccountBalance)
 	authRoutesV1.GET("/accounts", r.account.GetAccounts)
 	authRoutesV1.PUT("/account/:uuid", r.account.UpdateAccount)
+	authRoutesV1.GET("/account/:uuid/members", r.account.GetAccountMembers)
+	authRoutesV1.POST("/account/:uuid/members", r.account.SetAccountMember)
+	authRoutesV1.DELETE("/account/:uuid/members/:user_uuid", r.account.RemoveAccountMember)
+	authRoutesV1.GET("/account/:uuid/pockets", r.account.GetPockets)
+	authRoutesV1.POST("/account/:uuid/pockets", r.account.CreatePocket)
+	authRoutesV1.POST("/account/:uuid/pockets/:pocket_uuid/move", r.account.MovePocketFunds)
 
 	// transaction
-	authRoutesV1.POST("/transaction", r.transaction.CreateTransfer)
+	authRoutesV1.POST("/transaction", verified, r.transaction.CreateTransfer)
+	authRoutesV1.GET("/transaction/reviews", can(authz.TransfersApprove), r.transaction.ListTransferReviews)
+	authRoutesV1.POST("/transaction/reviews/:uuid/approve", can(authz.TransfersApprove), r.transaction.ApproveTransferReview)
+	authRoutesV1.POST("/transaction/reviews/:uuid/reject", can(authz.TransfersApprove), r.transaction.RejectTransferReview)
 
-	authRoutesV1.POST("/user", r.user.CreateUser)
 	// user
+	authRoutesV1.POST("/user", r.user.CreateUser)
+	authRoutesV1.GET("/admin/users", can(authz.UsersRead), r.user.ListUsers)
+	authRoutesV1.GET("/admin/users/:uuid", can(authz.UsersRead), r.user.GetUser)
+	authRoutesV1.POST("/admin/users/:uuid/block", can(authz.UsersManage), r.user.BlockUser)
+	authRoutesV1.POST("/admin/users/:uuid/unblock", can(authz.UsersManage), r.user.UnblockUser)
+	authRoutesV1.PUT("/admin/users/:uuid/role", can(authz.UsersManage), r.user.ChangeUserRole)
+	authRoutesV1.POST("/admin/users/:uuid/password-reset", can(authz.UsersManage), r.user.ForcePasswordReset)
+	authRoutesV1.POST("/admin/users/:uuid/api-keys", session, can(authz.UsersManage), r.user.CreateUserAPIKey)
+	authRoutesV1.GET("/admin/users/:uuid/api-keys", can(authz.UsersRead), r.user.ListUserAPIKeys)
+	authRoutesV1.DELETE("/admin/users/:uuid/api-keys/:key_uuid", can(authz.UsersManage), r.user.RevokeUserAPIKey)
+	authRoutesV1.PUT("/admin/roles/:role/mfa", can(authz.UsersManagePrivileged), r.user.SetRoleMFARequirement)
+
+	// webhook
+	authRoutesV1.POST("/webhooks", r.webhook.CreateWebhook)
+	authRoutesV1.GET("/webhooks", r.webhook.ListWebhooks)
+	authRoutesV1.DELETE("/webhooks/:uuid", r.webhook.DeleteWebhook)
+	authRoutesV1.GET("/webhooks/:uuid/deliveries", r.webhook.ListWebhookDeliveries)
+	authRoutesV1.POST("/webhooks/:uuid/deliveries/:delivery_uuid/redeliver", r.webhook.RedeliverWebhook)
+
+	// compliance
+	authRoutesV1.GET("/compliance/reviews", can(authz.ComplianceReview), r.compliance.ListComplianceReviews)
+	authRoutesV1.POST("/compliance/reviews/:uuid/clear", can(authz.ComplianceReview), r.compliance.ClearComplianceReview)
+	authRoutesV1.POST("/compliance/reviews/:uuid/confirm", can(authz.ComplianceReview), r.compliance.ConfirmComplianceReview)
+
+	// audit
+	authRoutesV1.GET("/audit-logs", can(authz.AuditRead), r.audit.ListAuditLogs)
 }
 
-// StartServer starts the HTTP server on the specified port.
-func (r *Router) StartServer(port string) {
-	r.Engine.Run(":" + port)
+// StartServer starts the HTTP server on the specified port, over TLS when tlsConfig is not nil. It
+// exits the process when the server cannot start or stops.
+func (r *Router) StartServer(port string, tlsConfig *tls.Config) {
+	if tlsConfig == nil {
+		if err := r.Engine.Run(":" + port); err != nil {
+			log.Fatal("Cannot start HTTP server: ", err)
+		}
+		return
+	}
+
+	server := &http.Server{Addr: ":" + port, Handler: r.Engine, TLSConfig: tlsConfig}
+	if err := server.ListenAndServeTLS("", ""); err != nil {
+		log.Fatal("Cannot start HTTP server: ", err)
+	}
 }
@@ -4,22 +4,58 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"time"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// Roles and permissions of the members of an account. Owners manage the members and always hold
+// the transfer permission, signatories act on the account within their permission.
+const (
+	memberRoleOwner     = "owner"
+	memberRoleSignatory = "signatory"
+
+	memberPermissionView          = "view"
+	memberPermissionTransfer      = "transfer"
+	memberPermissionTransferLimit = "transfer_limit"
+)
+
+var (
+	ErrLastAccountOwner       = db.ErrLastAccountOwner
+	ErrAccountHasPockets      = db.ErrAccountHasPockets
+	ErrOwnerPermission        = errors.New("an owner must hold the transfer permission")
+	ErrMemberTransferLimit    = errors.New("transfer exceeds member limit")
+	ErrAccountMemberForbidden = errors.New("member is not allowed to transfer")
+	ErrPocketAccount          = errors.New("operation is not available on a pocket")
+	ErrPocketNameTaken        = errors.New("pocket name already exists")
+	ErrPocketTransfer         = errors.New("pockets only move funds to and from their account")
+	ErrPocketCurrency         = errors.New("pocket currency does not match its account")
+)
+
+// Directions of a move between an account and one of its pockets.
+const (
+	pocketMoveToPocket = "to_pocket"
+	pocketMoveToParent = "to_parent"
+)
+
 type AccountService struct {
-	db db.Store
+	db         db.Store
+	authorizer *authz.Authorizer
 }
 
-func NewAccountService(db db.Store) *AccountService {
+func NewAccountService(db db.Store, authorizer *authz.Authorizer) *AccountService {
 	return &AccountService{
-		db: db,
+		db:         db,
+		authorizer: authorizer,
 	}
 }
 
@@ -54,31 +90,44 @@ func (a *AccountService) CreateAccount(ctx context.Context, request *request.Cre
 		// return error account already exists
 		return response.AccountResponseCreate{}, nil
 	}
-	// create account
-	account, err := a.db.CreateAccount(ctx, db.CreateAccountParams{
-		Owner:    user.FullName,
-		Currency: request.Currency,
-		Balance:  pgtype.Numeric{Int: big.NewInt(0), Valid: true},
-		UserUuid: useruuid,
-	})
+	// create account, audited in the same transaction
+	var result response.AccountResponseCreate
+	err = a.db.ExecTx(ctx, func(store db.Store) error {
+		account, err := store.CreateAccountTx(ctx, db.CreateAccountParams{
+			Owner:    user.FullName,
+			Currency: request.Currency,
+			Balance:  pgtype.Numeric{Int: big.NewInt(0), Valid: true},
+			UserUuid: useruuid,
+		})
+		if err != nil {
+			return err
+		}
 
+		result = response.AccountResponseCreate{
+			AccountUUID: account.AccountUuid,
+			Owner:       account.Owner,
+			Currency:    account.Currency,
+			Balance:     account.Balance.Int.String(),
+			User: response.UserGetSimple{
+				UserUUID:      user.UserUuid.String(),
+				Username:      user.Username,
+				FullName:      user.FullName,
+				Email:         user.Email,
+				EmailVerified: emailVerified(user.VerifiedEmailAt),
+			},
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionAccountCreate,
+			EntityType: audit.EntityAccount,
+			EntityID:   account.AccountUuid.String(),
+			After:      result,
+		})
+	})
 	if err != nil {
 		return response.AccountResponseCreate{}, err
 	}
 
-	result := response.AccountResponseCreate{
-		AccountUUID: account.AccountUuid,
-		Owner:       account.Owner,
-		Currency:    account.Currency,
-		Balance:     account.Balance.Int.String(),
-		User: response.UserGetSimple{
-			UserUUID: user.UserUuid.String(),
-			Username: user.Username,
-			FullName: user.FullName,
-			Email:    user.Email,
-		},
-	}
-
 	return result, nil
 }
 
@@ -91,8 +140,8 @@ func (a *AccountService) GetAccountByUUID(ctx context.Context, uuid uuid.UUID, a
 
 	}
 
-	if account.UserUuid != authPayload.UserUUID {
-		return response.AccountResponseGet{}, errors.New("unauthorized")
+	if _, err := getAccountMember(ctx, a.db, memberAccountID(account), authPayload.UserUUID); err != nil {
+		return response.AccountResponseGet{}, err
 	}
 
 	user, err := a.db.GetUserByUserUUID(ctx, account.UserUuid)
@@ -110,19 +159,68 @@ func (a *AccountService) GetAccountByUUID(ctx context.Context, uuid uuid.UUID, a
 		CreatedAt:   account.CreatedAt,
 		Status:      int32(account.Status),
 		User: response.UserGetSimple{
-			UserUUID: user.UserUuid.String(),
-			Username: user.Username,
-			FullName: user.FullName,
-			Email:    user.Email,
+			UserUUID:      user.UserUuid.String(),
+			Username:      user.Username,
+			FullName:      user.FullName,
+			Email:         user.Email,
+			EmailVerified: emailVerified(user.VerifiedEmailAt),
 		},
+		ConsolidatedBalance: account.Balance.Int.String(),
+	}
+
+	// a pocket has no pockets of its own
+	if account.ParentAccountID.Valid {
+		result.PocketName = &account.PocketName.String
+		return result, nil
+	}
+
+	pockets, err := a.db.ListPocketsByParentID(ctx, account.ID)
+	if err != nil {
+		return response.AccountResponseGet{}, err
+	}
+
+	consolidated := new(big.Int).Set(helper.NumericToBigInt(account.Balance))
+	for _, pocket := range pockets {
+		consolidated.Add(consolidated, helper.NumericToBigInt(pocket.Balance))
+		result.Pockets = append(result.Pockets, pocketResponse(pocket.AccountUuid, pocket.PocketName, pocket.Currency, pocket.Balance, pocket.CreatedAt))
+	}
+	result.ConsolidatedBalance = consolidated.String()
+
+	return result, nil
+}
+
+// GetBalanceAsOf returns the balance an account had at the given point in time.
+// Only the members of the account or a role with accounts:read:any can query it.
+func (a *AccountService) GetBalanceAsOf(ctx context.Context, uuid uuid.UUID, asOf time.Time, authPayload *token.Payload) (response.AccountBalanceResponse, error) {
+	account, err := a.db.GetAccountByUUID(ctx, uuid)
+	if err != nil {
+		return response.AccountBalanceResponse{}, err
+	}
+
+	if !a.authorizer.Can(ctx, authPayload.Role, authz.AccountsReadAny) {
+		if _, err := getAccountMember(ctx, a.db, memberAccountID(account), authPayload.UserUUID); err != nil {
+			return response.AccountBalanceResponse{}, err
+		}
+	}
+
+	balance, err := a.db.GetBalanceAsOf(ctx, account.ID, asOf)
+	if err != nil {
+		return response.AccountBalanceResponse{}, err
+	}
+
+	result := response.AccountBalanceResponse{
+		AccountUUID: account.AccountUuid,
+		Currency:    account.Currency,
+		Balance:     balance.Balance.Int.String(),
+		AsOf:        balance.AsOf,
 	}
 
 	return result, nil
 }
 
 func (a *AccountService) ListAccount(ctx context.Context, param db.ListAccountsParams, authPayload *token.Payload) ([]response.AccountResponseGet, int64, error) {
-	// if role is admin or superadmin return all account
-	if authPayload.Role == "admin" || authPayload.Role == "superadmin" {
+	// roles with accounts:read:any see every account
+	if a.authorizer.Can(ctx, authPayload.Role, authz.AccountsReadAny) {
 		accounts, err := a.db.ListAccounts(ctx, param)
 		if err != nil {
 			return nil, 0, err
@@ -198,20 +296,40 @@ func (a *AccountService) ListAccount(ctx context.Context, param db.ListAccountsP
 }
 
 func (a *AccountService) UpdateAccount(ctx context.Context, arg db.UpdateProfileAccountParams, authPayload *token.Payload) (response.AccountResponseGet, error) {
-	// check if role is not admin or superadmin
-	if authPayload.Role != "admin" && authPayload.Role != "superadmin" {
-		// check if account is not owned by the user
-		account, err := a.db.GetAccountByUUID(ctx, arg.AccountUuid)
-		if err != nil {
+	// the current state is kept for the audit log
+	before, err := a.db.GetAccountByUUID(ctx, arg.AccountUuid)
+	if err != nil {
+		return response.AccountResponseGet{}, err
+	}
+
+	// a pocket follows the profile of its account
+	if before.ParentAccountID.Valid {
+		return response.AccountResponseGet{}, ErrPocketAccount
+	}
+
+	// only an owner or a role with accounts:update:any can update the account
+	if !a.authorizer.Can(ctx, authPayload.Role, authz.AccountsUpdateAny) {
+		if _, err := getAccountOwner(ctx, a.db, before.ID, authPayload.UserUUID); err != nil {
 			return response.AccountResponseGet{}, err
 		}
+	}
 
-		if account.UserUuid != authPayload.UserUUID {
-			return response.AccountResponseGet{}, errors.New("unauthorized")
+	// the pockets keep their currency, so it cannot change while the account has any
+	var account db.UpdateProfileAccountRow
+	err = a.db.ExecTx(ctx, func(store db.Store) error {
+		account, err = store.UpdateProfileAccountTx(ctx, arg)
+		if err != nil {
+			return err
 		}
-	}
 
-	account, err := a.db.UpdateProfileAccount(ctx, arg)
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionAccountUpdate,
+			EntityType: audit.EntityAccount,
+			EntityID:   account.AccountUuid.String(),
+			Before:     before,
+			After:      account,
+		})
+	})
 	if err != nil {
 		return response.AccountResponseGet{}, err
 	}
@@ -233,3 +351,404 @@ func (a *AccountService) UpdateAccount(ctx context.Context, arg db.UpdateProfile
 
 	return result, nil
 }
+
+// ListAccountMembers returns the owners and signatories of an account. Every member of the account
+// and a role with accounts:read:any can list them.
+func (a *AccountService) ListAccountMembers(ctx context.Context, accountUUID uuid.UUID, authPayload *token.Payload) ([]response.AccountMemberResponse, error) {
+	account, err := a.getMainAccount(ctx, accountUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !a.authorizer.Can(ctx, authPayload.Role, authz.AccountsReadAny) {
+		if _, err := getAccountMember(ctx, a.db, account.ID, authPayload.UserUUID); err != nil {
+			return nil, err
+		}
+	}
+
+	members, err := a.db.ListAccountMembers(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []response.AccountMemberResponse{}
+	for _, member := range members {
+		result = append(result, response.AccountMemberResponse{
+			UserUUID:      member.UserUuid,
+			Username:      member.Username,
+			FullName:      member.FullName,
+			Email:         member.Email,
+			Role:          member.Role,
+			Permission:    member.Permission,
+			TransferLimit: transferLimitResponse(member.TransferLimit),
+			CreatedAt:     member.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// SetAccountMember adds a member to an account or changes the role and permission of an existing
+// member. Only owners of the account can manage its members, and the last owner cannot be demoted.
+func (a *AccountService) SetAccountMember(ctx context.Context, accountUUID uuid.UUID, req request.AccountMemberRequest, authPayload *token.Payload) (response.AccountMemberResponse, error) {
+	if req.Role == memberRoleOwner && req.Permission != memberPermissionTransfer {
+		return response.AccountMemberResponse{}, ErrOwnerPermission
+	}
+
+	userUUID, err := uuid.Parse(req.UserUUID)
+	if err != nil {
+		return response.AccountMemberResponse{}, err
+	}
+
+	account, err := a.getMainAccount(ctx, accountUUID)
+	if err != nil {
+		return response.AccountMemberResponse{}, err
+	}
+
+	if _, err := getAccountOwner(ctx, a.db, account.ID, authPayload.UserUUID); err != nil {
+		return response.AccountMemberResponse{}, err
+	}
+
+	user, err := a.db.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		return response.AccountMemberResponse{}, err
+	}
+
+	// the current membership, if any, is kept for the audit log
+	var before any
+	existing, err := a.db.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		UserUuid:  userUUID,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return response.AccountMemberResponse{}, err
+	}
+	if err == nil {
+		before = existing
+	}
+
+	var transferLimit pgtype.Numeric
+	if req.Permission == memberPermissionTransferLimit {
+		transferLimit = pgtype.Numeric{Int: big.NewInt(req.TransferLimit), Valid: true}
+	}
+
+	// an owner is only demoted while another owner remains
+	var member db.AccountMember
+	err = a.db.ExecTx(ctx, func(store db.Store) error {
+		member, err = store.SetAccountMemberTx(ctx, db.AddAccountMemberParams{
+			AccountID:     account.ID,
+			UserUuid:      userUUID,
+			Role:          req.Role,
+			Permission:    req.Permission,
+			TransferLimit: transferLimit,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionAccountMemberSet,
+			EntityType: audit.EntityAccount,
+			EntityID:   account.AccountUuid.String(),
+			Before:     before,
+			After:      member,
+		})
+	})
+	if err != nil {
+		return response.AccountMemberResponse{}, err
+	}
+
+	result := response.AccountMemberResponse{
+		UserUUID:      member.UserUuid,
+		Username:      user.Username,
+		FullName:      user.FullName,
+		Email:         user.Email,
+		Role:          member.Role,
+		Permission:    member.Permission,
+		TransferLimit: transferLimitResponse(member.TransferLimit),
+		CreatedAt:     member.CreatedAt,
+	}
+
+	return result, nil
+}
+
+// RemoveAccountMember removes a member from an account. Owners can remove any member and every
+// member can leave the account, but the last owner cannot be removed.
+func (a *AccountService) RemoveAccountMember(ctx context.Context, accountUUID uuid.UUID, userUUID uuid.UUID, authPayload *token.Payload) error {
+	account, err := a.getMainAccount(ctx, accountUUID)
+	if err != nil {
+		return err
+	}
+
+	if userUUID != authPayload.UserUUID {
+		if _, err := getAccountOwner(ctx, a.db, account.ID, authPayload.UserUUID); err != nil {
+			return err
+		}
+	}
+
+	member, err := a.db.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		UserUuid:  userUUID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the last owner is not removed
+	return a.db.ExecTx(ctx, func(store db.Store) error {
+		removed, err := store.RemoveAccountMemberTx(ctx, db.RemoveAccountMemberParams{
+			AccountID: account.ID,
+			UserUuid:  userUUID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionAccountMemberRemove,
+			EntityType: audit.EntityAccount,
+			EntityID:   account.AccountUuid.String(),
+			Before:     member,
+			After:      removed,
+		})
+	})
+}
+
+// ListPockets returns the pockets of an account. Every member of the account and a role with
+// accounts:read:any can list them.
+func (a *AccountService) ListPockets(ctx context.Context, accountUUID uuid.UUID, authPayload *token.Payload) ([]response.AccountPocketResponse, error) {
+	account, err := a.getMainAccount(ctx, accountUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !a.authorizer.Can(ctx, authPayload.Role, authz.AccountsReadAny) {
+		if _, err := getAccountMember(ctx, a.db, account.ID, authPayload.UserUUID); err != nil {
+			return nil, err
+		}
+	}
+
+	pockets, err := a.db.ListPocketsByParentID(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []response.AccountPocketResponse{}
+	for _, pocket := range pockets {
+		result = append(result, pocketResponse(pocket.AccountUuid, pocket.PocketName, pocket.Currency, pocket.Balance, pocket.CreatedAt))
+	}
+
+	return result, nil
+}
+
+// CreatePocket opens a named pocket under an account. The pocket shares the currency and members
+// of the account and starts with a zero balance. Only owners of the account can create pockets.
+func (a *AccountService) CreatePocket(ctx context.Context, accountUUID uuid.UUID, req request.CreatePocketRequest, authPayload *token.Payload) (response.AccountPocketResponse, error) {
+	account, err := a.getMainAccount(ctx, accountUUID)
+	if err != nil {
+		return response.AccountPocketResponse{}, err
+	}
+
+	if _, err := getAccountOwner(ctx, a.db, account.ID, authPayload.UserUUID); err != nil {
+		return response.AccountPocketResponse{}, err
+	}
+
+	pockets, err := a.db.ListPocketsByParentID(ctx, account.ID)
+	if err != nil {
+		return response.AccountPocketResponse{}, err
+	}
+	for _, pocket := range pockets {
+		if pocket.PocketName.String == req.Name {
+			return response.AccountPocketResponse{}, ErrPocketNameTaken
+		}
+	}
+
+	var result response.AccountPocketResponse
+	err = a.db.ExecTx(ctx, func(store db.Store) error {
+		pocket, err := store.CreatePocket(ctx, db.CreatePocketParams{
+			PocketName:      req.Name,
+			ParentAccountID: account.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = pocketResponse(pocket.AccountUuid, pocket.PocketName, pocket.Currency, pocket.Balance, pocket.CreatedAt)
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionAccountPocketCreate,
+			EntityType: audit.EntityAccount,
+			EntityID:   account.AccountUuid.String(),
+			After:      result,
+		})
+	})
+	if err != nil {
+		return response.AccountPocketResponse{}, err
+	}
+
+	return result, nil
+}
+
+// MovePocketFunds moves funds between an account and one of its pockets. The move is instant and is
+// not a transfer, so it is neither screened nor evaluated by the risk engine. Members need the
+// transfer permission of the account, within their limit.
+func (a *AccountService) MovePocketFunds(ctx context.Context, accountUUID uuid.UUID, pocketUUID uuid.UUID, req request.PocketMoveRequest, authPayload *token.Payload) (response.PocketMoveResponse, error) {
+	account, err := a.getMainAccount(ctx, accountUUID)
+	if err != nil {
+		return response.PocketMoveResponse{}, err
+	}
+
+	pocket, err := a.db.GetAccountByUUID(ctx, pocketUUID)
+	if err != nil {
+		return response.PocketMoveResponse{}, err
+	}
+	if !pocket.ParentAccountID.Valid || pocket.ParentAccountID.Int64 != account.ID {
+		return response.PocketMoveResponse{}, pgx.ErrNoRows
+	}
+	// funds move one to one, which is only right in the same currency
+	if pocket.Currency != account.Currency {
+		return response.PocketMoveResponse{}, ErrPocketCurrency
+	}
+
+	member, err := getAccountMember(ctx, a.db, account.ID, authPayload.UserUUID)
+	if err != nil {
+		return response.PocketMoveResponse{}, err
+	}
+	if err := checkMemberTransfer(member, req.Amount); err != nil {
+		return response.PocketMoveResponse{}, err
+	}
+
+	param := db.PocketMoveTxParam{
+		FromAccountID: account.ID,
+		ToAccountID:   pocket.ID,
+		Amount:        req.Amount,
+	}
+	if req.Direction == pocketMoveToParent {
+		param.FromAccountID, param.ToAccountID = pocket.ID, account.ID
+	}
+
+	var moved db.PocketMoveTxResult
+	err = a.db.ExecTx(ctx, func(store db.Store) error {
+		moved, err = store.PocketMoveTx(ctx, param)
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionAccountPocketMove,
+			EntityType: audit.EntityAccount,
+			EntityID:   account.AccountUuid.String(),
+			After: map[string]any{
+				"pocket_uuid": pocket.AccountUuid,
+				"direction":   req.Direction,
+				"amount":      req.Amount,
+			},
+		})
+	})
+	if err != nil {
+		return response.PocketMoveResponse{}, err
+	}
+
+	result := response.PocketMoveResponse{
+		AccountUUID: account.AccountUuid,
+		Pocket:      pocketResponse(pocket.AccountUuid, pocket.PocketName, pocket.Currency, pocket.Balance, pocket.CreatedAt),
+	}
+	if req.Direction == pocketMoveToPocket {
+		result.AccountBalance = helper.NumericToBigInt(moved.FromAccount.Balance).String()
+		result.Pocket.Balance = helper.NumericToBigInt(moved.ToAccount.Balance).String()
+	} else {
+		result.AccountBalance = helper.NumericToBigInt(moved.ToAccount.Balance).String()
+		result.Pocket.Balance = helper.NumericToBigInt(moved.FromAccount.Balance).String()
+	}
+
+	return result, nil
+}
+
+// getMainAccount returns an account that is not a pocket. Pockets are managed through their
+// account, so ErrPocketAccount is returned for them.
+func (a *AccountService) getMainAccount(ctx context.Context, accountUUID uuid.UUID) (db.GetAccountByUUIDRow, error) {
+	account, err := a.db.GetAccountByUUID(ctx, accountUUID)
+	if err != nil {
+		return db.GetAccountByUUIDRow{}, err
+	}
+	if account.ParentAccountID.Valid {
+		return db.GetAccountByUUIDRow{}, ErrPocketAccount
+	}
+
+	return account, nil
+}
+
+// getAccountMember returns the membership of a user in an account. It returns an "unauthorized"
+// error when the user is not a member.
+func getAccountMember(ctx context.Context, store db.Store, accountID int64, userUUID uuid.UUID) (db.AccountMember, error) {
+	member, err := store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: accountID,
+		UserUuid:  userUUID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.AccountMember{}, errors.New("unauthorized")
+		}
+		return db.AccountMember{}, err
+	}
+
+	return member, nil
+}
+
+// getAccountOwner returns the membership of a user in an account. It returns an "unauthorized"
+// error when the user is not an owner of the account.
+func getAccountOwner(ctx context.Context, store db.Store, accountID int64, userUUID uuid.UUID) (db.AccountMember, error) {
+	member, err := getAccountMember(ctx, store, accountID, userUUID)
+	if err != nil {
+		return db.AccountMember{}, err
+	}
+	if member.Role != memberRoleOwner {
+		return db.AccountMember{}, errors.New("unauthorized")
+	}
+
+	return member, nil
+}
+
+// checkMemberTransfer returns an error unless the member may transfer amount from the account.
+func checkMemberTransfer(member db.AccountMember, amount int64) error {
+	switch member.Permission {
+	case memberPermissionTransfer:
+		return nil
+	case memberPermissionTransferLimit:
+		if helper.NumericToBigInt(member.TransferLimit).Cmp(big.NewInt(amount)) < 0 {
+			return ErrMemberTransferLimit
+		}
+		return nil
+	default:
+		return ErrAccountMemberForbidden
+	}
+}
+
+// memberAccountID returns the account that holds the members of an account. Pockets share the
+// members of their parent account.
+func memberAccountID(account db.GetAccountByUUIDRow) int64 {
+	if account.ParentAccountID.Valid {
+		return account.ParentAccountID.Int64
+	}
+
+	return account.ID
+}
+
+func pocketResponse(accountUUID uuid.UUID, name pgtype.Text, currency string, balance pgtype.Numeric, createdAt time.Time) response.AccountPocketResponse {
+	return response.AccountPocketResponse{
+		AccountUUID: accountUUID,
+		Name:        name.String,
+		Currency:    currency,
+		Balance:     helper.NumericToBigInt(balance).String(),
+		CreatedAt:   createdAt,
+	}
+}
+
+func transferLimitResponse(limit pgtype.Numeric) *string {
+	if !limit.Valid {
+		return nil
+	}
+
+	result := helper.NumericToBigInt(limit).String()
+	return &result
+}
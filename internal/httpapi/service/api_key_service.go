@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/apikey"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAPIKeyNotFound is returned when revoking a key that does not exist, belongs to another user
+	// or was already revoked.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKeyExpiry is returned when a new key would already be expired.
+	ErrInvalidAPIKeyExpiry = errors.New("expires_at must be in the future")
+)
+
+// CreateAPIKey creates an API key for the authenticated user, limited to scopes the role of the
+// user holds.
+func (u *UserService) CreateAPIKey(ctx context.Context, req request.CreateAPIKeyRequest, authPayload *token.Payload) (response.CreatedAPIKeyResponse, error) {
+	return u.createAPIKey(ctx, authPayload.UserUUID, authPayload.Role, req, authPayload)
+}
+
+// ListAPIKeys returns the API keys of the authenticated user, revoked ones included.
+func (u *UserService) ListAPIKeys(ctx context.Context, authPayload *token.Payload) ([]response.APIKeyResponse, error) {
+	return u.listAPIKeys(ctx, authPayload.UserUUID)
+}
+
+// RevokeAPIKey revokes an API key of the authenticated user.
+func (u *UserService) RevokeAPIKey(ctx context.Context, keyUUID uuid.UUID, authPayload *token.Payload) error {
+	return u.revokeAPIKey(ctx, authPayload.UserUUID, keyUUID)
+}
+
+// CreateUserAPIKey creates an API key for a user on behalf of an admin, limited to scopes the role
+// of that user holds.
+func (u *UserService) CreateUserAPIKey(ctx context.Context, userUUID uuid.UUID, req request.CreateAPIKeyRequest, authPayload *token.Payload) (response.CreatedAPIKeyResponse, error) {
+	user, err := u.getManagedUser(ctx, userUUID, authPayload)
+	if err != nil {
+		return response.CreatedAPIKeyResponse{}, err
+	}
+
+	return u.createAPIKey(ctx, user.UserUuid, user.Role, req, authPayload)
+}
+
+// ListUserAPIKeys returns the API keys of a user.
+func (u *UserService) ListUserAPIKeys(ctx context.Context, userUUID uuid.UUID) ([]response.APIKeyResponse, error) {
+	if _, err := u.db.GetUserByUserUUID(ctx, userUUID); err != nil {
+		return nil, err
+	}
+
+	return u.listAPIKeys(ctx, userUUID)
+}
+
+// RevokeUserAPIKey revokes an API key of a user on behalf of an admin.
+func (u *UserService) RevokeUserAPIKey(ctx context.Context, userUUID, keyUUID uuid.UUID, authPayload *token.Payload) error {
+	if _, err := u.getManagedUser(ctx, userUUID, authPayload); err != nil {
+		return err
+	}
+
+	return u.revokeAPIKey(ctx, userUUID, keyUUID)
+}
+
+func (u *UserService) createAPIKey(ctx context.Context, userUUID uuid.UUID, role string, req request.CreateAPIKeyRequest, authPayload *token.Payload) (response.CreatedAPIKeyResponse, error) {
+	err := apikey.ValidateScopes(req.Scopes, func(permission string) bool {
+		return u.authorizer.Can(ctx, role, permission)
+	})
+	if err != nil {
+		return response.CreatedAPIKeyResponse{}, err
+	}
+
+	params := apikey.CreateParams{
+		UserUUID:  userUUID,
+		CreatedBy: authPayload.UserUUID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+	}
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return response.CreatedAPIKeyResponse{}, ErrInvalidAPIKeyExpiry
+		}
+		params.ExpiresAt = *req.ExpiresAt
+	}
+
+	var result response.APIKeyResponse
+	var secret string
+	err = u.db.ExecTx(ctx, func(store db.Store) error {
+		var key db.ApiKey
+		key, secret, err = apikey.Create(ctx, store, params)
+		if err != nil {
+			return err
+		}
+
+		result = apiKeyResponse(key)
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionAPIKeyCreate,
+			EntityType: audit.EntityAPIKey,
+			EntityID:   key.KeyUuid.String(),
+			After:      result,
+		})
+	})
+	if err != nil {
+		return response.CreatedAPIKeyResponse{}, err
+	}
+
+	return response.CreatedAPIKeyResponse{APIKeyResponse: result, Key: secret}, nil
+}
+
+func (u *UserService) listAPIKeys(ctx context.Context, userUUID uuid.UUID) ([]response.APIKeyResponse, error) {
+	keys, err := u.db.ListAPIKeysByUser(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []response.APIKeyResponse{}
+	for _, key := range keys {
+		result = append(result, apiKeyResponse(key))
+	}
+
+	return result, nil
+}
+
+func (u *UserService) revokeAPIKey(ctx context.Context, userUUID, keyUUID uuid.UUID) error {
+	return u.db.ExecTx(ctx, func(store db.Store) error {
+		rows, err := store.RevokeAPIKey(ctx, db.RevokeAPIKeyParams{
+			KeyUuid:  keyUUID,
+			UserUuid: userUUID,
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrAPIKeyNotFound
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionAPIKeyRevoke,
+			EntityType: audit.EntityAPIKey,
+			EntityID:   keyUUID.String(),
+		})
+	})
+}
+
+func apiKeyResponse(key db.ApiKey) response.APIKeyResponse {
+	result := response.APIKeyResponse{
+		KeyUUID:    key.KeyUuid,
+		UserUUID:   key.UserUuid,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedBy:  key.CreatedBy,
+		LastUsedIP: key.LastUsedIp,
+		CreatedAt:  key.CreatedAt,
+	}
+	if key.ExpiresAt.Valid {
+		result.ExpiresAt = &key.ExpiresAt.Time
+	}
+	if key.LastUsedAt.Valid {
+		result.LastUsedAt = &key.LastUsedAt.Time
+	}
+	if key.RevokedAt.Valid {
+		result.RevokedAt = &key.RevokedAt.Time
+	}
+
+	return result
+}
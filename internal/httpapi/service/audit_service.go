@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type AuditService struct {
+	db db.Store
+}
+
+func NewAuditService(db db.Store) *AuditService {
+	return &AuditService{
+		db: db,
+	}
+}
+
+// ListAuditLogs returns the audit log entries matching the filters of req, newest first, with the
+// total number of matching entries.
+func (a *AuditService) ListAuditLogs(ctx context.Context, req request.ListAuditLogRequest) ([]response.AuditLogResponse, int64, error) {
+	filter := db.CountAuditLogsParams{
+		Action:     pgtype.Text{String: req.Action, Valid: req.Action != ""},
+		EntityType: pgtype.Text{String: req.EntityType, Valid: req.EntityType != ""},
+		EntityID:   pgtype.Text{String: req.EntityID, Valid: req.EntityID != ""},
+		FromTime:   pgtype.Timestamptz{Time: req.From, Valid: !req.From.IsZero()},
+		ToTime:     pgtype.Timestamptz{Time: req.To, Valid: !req.To.IsZero()},
+	}
+	if req.ActorUUID != "" {
+		actorUUID, err := uuid.Parse(req.ActorUUID)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.ActorUuid = pgtype.UUID{Bytes: actorUUID, Valid: true}
+	}
+
+	logs, err := a.db.ListAuditLogs(ctx, db.ListAuditLogsParams{
+		ActorUuid:  filter.ActorUuid,
+		Action:     filter.Action,
+		EntityType: filter.EntityType,
+		EntityID:   filter.EntityID,
+		FromTime:   filter.FromTime,
+		ToTime:     filter.ToTime,
+		PageLimit:  req.Limit,
+		PageOffset: (req.Page - 1) * req.Limit,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	countTotal, err := a.db.CountAuditLogs(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := []response.AuditLogResponse{}
+	for _, entry := range logs {
+		item := response.AuditLogResponse{
+			AuditUUID:  entry.AuditUuid,
+			ActorRole:  entry.ActorRole,
+			Action:     entry.Action,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Before:     entry.Before,
+			After:      entry.After,
+			ClientIP:   entry.ClientIp,
+			UserAgent:  entry.UserAgent,
+			CreatedAt:  entry.CreatedAt,
+		}
+		if entry.ActorUuid.Valid {
+			actorUUID := uuid.UUID(entry.ActorUuid.Bytes)
+			item.ActorUUID = &actorUUID
+		}
+		result = append(result, item)
+	}
+
+	return result, countTotal, nil
+}
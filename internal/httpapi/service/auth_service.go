@@ -3,59 +3,185 @@ package service
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	"github.com/fajaramaulana/simple_bank_project/internal/email"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
-	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordreset"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/securityevent"
+	"github.com/fajaramaulana/simple_bank_project/internal/stepup"
+	"github.com/google/uuid"
 )
 
 var (
 	ErrUserNotFound      = fmt.Errorf("user not found")
 	ErrorInvalidPassword = fmt.Errorf("invalid password")
+	// ErrUserBlocked is returned when an admin has blocked the user.
+	ErrUserBlocked = fmt.Errorf("user is blocked")
+	// ErrPasswordResetRequired is returned when an admin has forced the user to reset the password.
+	ErrPasswordResetRequired = fmt.Errorf("password reset required")
+	// ErrSessionNotFound is returned when the session does not exist, belongs to another user or was already revoked.
+	ErrSessionNotFound = fmt.Errorf("session not found")
+	// ErrRefreshTokenReused is returned when a refresh token that was already exchanged is presented again.
+	// The whole session family is revoked, so the user has to sign in again.
+	ErrRefreshTokenReused = fmt.Errorf("refresh token reused")
+	// ErrInvalidUnlockCode is returned when a login unlock code is wrong, used up or expired.
+	ErrInvalidUnlockCode = fmt.Errorf("invalid or expired unlock code")
 )
 
 type AuthService struct {
 	db          db.Store
 	configToken map[string]string
+	maker       token.Maker
+	emails      email.Queue
+	guard       *lockout.Guard
+	policy      *passwordpolicy.Policy
+	hasher      *passwordhash.Hasher
+	verifier    *emailverification.Verifier
+	stepUp      *stepup.Authenticator
+	devices     *device.Registry
+	revocations *revocation.List
+	resets      *passwordreset.Limiter
 }
 
-func NewAuthService(db db.Store, configToken map[string]string) *AuthService {
+func NewAuthService(db db.Store, configToken map[string]string, maker token.Maker, emails email.Queue, guard *lockout.Guard, policy *passwordpolicy.Policy, hasher *passwordhash.Hasher, verifier *emailverification.Verifier, stepUp *stepup.Authenticator, devices *device.Registry, revocations *revocation.List, resets *passwordreset.Limiter) *AuthService {
 	return &AuthService{
 		db:          db,
 		configToken: configToken,
+		maker:       maker,
+		emails:      emails,
+		guard:       guard,
+		policy:      policy,
+		hasher:      hasher,
+		verifier:    verifier,
+		stepUp:      stepUp,
+		devices:     devices,
+		revocations: revocations,
+		resets:      resets,
 	}
 }
 
-func (a *AuthService) Login(ctx context.Context, username, password, userAgent, ClientIP string) (response.AuthLoginResponse, error) {
+// Login checks the password of username. Failed attempts slow down further attempts for the
+// username and the client IP, and lock them out for a while when they keep failing.
+func (a *AuthService) Login(ctx context.Context, username, password, deviceToken, userAgent, ClientIP string) (response.AuthLoginResponse, error) {
+	attempt := lockout.Attempt{Username: username, ClientIP: ClientIP, UserAgent: userAgent}
+	if err := a.guard.Check(ctx, attempt); err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+
 	detailLogin, err := a.db.GetDetailLoginByUsername(ctx, username)
 	if err != nil {
-		if err.Error() == "sql: no rows in result set" {
+		if err.Error() == "sql: no rows in result set" || err.Error() == "no rows in result set" {
+			a.loginFailed(ctx, attempt, nil)
 			return response.AuthLoginResponse{}, ErrUserNotFound
 		}
 		return response.AuthLoginResponse{}, err
 	}
 
 	// check password
-	err = util.CheckPasswordBcrypt(password, detailLogin.HashedPassword)
+	rehash, err := a.hasher.Verify(password, detailLogin.HashedPassword)
 	if err != nil {
+		a.loginFailed(ctx, attempt, &lockout.User{UUID: detailLogin.UserUuid, Email: detailLogin.Email})
 		return response.AuthLoginResponse{}, ErrorInvalidPassword
 	}
 
-	// generate token with paseto
-	maker, err := token.NewPasetoMaker(a.configToken["token_secret"])
+	if detailLogin.IsBlocked {
+		return response.AuthLoginResponse{}, ErrUserBlocked
+	}
+	if detailLogin.PasswordResetRequired {
+		return response.AuthLoginResponse{}, ErrPasswordResetRequired
+	}
+	if rehash {
+		a.rehashPassword(ctx, detailLogin.UserUuid, password, detailLogin.HashedPassword)
+	}
+
+	maker := a.maker
+
+	user := response.UserGetSimple{
+		UserUUID:      detailLogin.UserUuid.String(),
+		Username:      detailLogin.Username,
+		FullName:      detailLogin.FullName,
+		Email:         detailLogin.Email,
+		EmailVerified: emailVerified(detailLogin.VerifiedEmailAt),
+	}
+
+	// users with two-factor authentication, or whose role requires it, finish the login with a code
+	challenge, err := a.mfaChallenge(ctx, maker, detailLogin.UserUuid, detailLogin.Role)
 	if err != nil {
 		return response.AuthLoginResponse{}, err
 	}
+	if challenge != nil {
+		// the failed logins are only cleared once the code is verified too, or a new login
+		// would reset the count of wrong codes
+		challenge.User = user
+		return *challenge, nil
+	}
+
+	if err := a.guard.Succeed(ctx, attempt); err != nil {
+		log.Printf("Error: cannot clear failed logins of %s: %s", username, err.Error())
+	}
+
+	return a.completeLogin(ctx, maker, detailLogin.UserUuid, detailLogin.Role, user, deviceToken, userAgent, ClientIP)
+}
+
+// loginFailed counts a failed login. The login fails anyway, so an error is only logged.
+func (a *AuthService) loginFailed(ctx context.Context, attempt lockout.Attempt, user *lockout.User) {
+	if err := a.guard.Fail(ctx, attempt, user); err != nil {
+		log.Printf("Error: cannot count failed login of %s: %s", attempt.Username, err.Error())
+	}
+}
+
+// rehashPassword replaces an outdated hash of the password the user just signed in with. The
+// login succeeds with the old hash anyway, so an error is only logged.
+func (a *AuthService) rehashPassword(ctx context.Context, userUUID uuid.UUID, password, oldHash string) {
+	newHash, err := a.hasher.Hash(password)
+	if err == nil {
+		_, err = a.db.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+			NewHashedPassword: newHash,
+			UserUuid:          userUUID,
+			OldHashedPassword: oldHash,
+		})
+	}
+	if err != nil {
+		log.Printf("Error: cannot rehash password of %s: %s", userUUID, err.Error())
+	}
+}
+
+// UnlockLogin lifts a login lockout with the code emailed when it was locked.
+func (a *AuthService) UnlockLogin(ctx context.Context, code, userAgent, clientIP string) error {
+	err := a.guard.Unlock(ctx, code, clientIP, userAgent)
+	if err == lockout.ErrInvalidUnlockCode {
+		return ErrInvalidUnlockCode
+	}
+	return err
+}
+
+// startSession creates a new session family for the user and returns its access and refresh tokens.
+func (a *AuthService) startSession(ctx context.Context, maker token.Maker, userUUID uuid.UUID, role string, user response.UserGetSimple, userAgent, clientIP string) (response.AuthLoginResponse, error) {
 	accessTokenDuration, err := time.ParseDuration(a.configToken["access_token_duration"])
 	if err != nil {
 		return response.AuthLoginResponse{}, err
 	}
 
-	role := detailLogin.Role
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+	// the session starts right after the user authenticated
+	authTime := time.Now()
 
-	accessToken, _, err := maker.CreateToken(detailLogin.UserUuid.String(), accessTokenDuration, role)
+	accessToken, _, err := maker.CreateToken(userUUID.String(), sessionID, token.KindAccess, accessTokenDuration, role, authTime)
 	if err != nil {
 		return response.AuthLoginResponse{}, err
 	}
@@ -65,7 +191,7 @@ func (a *AuthService) Login(ctx context.Context, username, password, userAgent,
 		return response.AuthLoginResponse{}, err
 	}
 
-	refreshToken, payloadRefresh, err := maker.CreateToken(detailLogin.UserUuid.String(), refreshTokenDuration, role)
+	refreshToken, payloadRefresh, err := maker.CreateToken(userUUID.String(), sessionID, token.KindRefresh, refreshTokenDuration, role, authTime)
 	if err != nil {
 		return response.AuthLoginResponse{}, err
 	}
@@ -73,10 +199,11 @@ func (a *AuthService) Login(ctx context.Context, username, password, userAgent,
 	// save refresh token to db
 	session, err := a.db.CreateSession(ctx, db.CreateSessionParams{
 		ID:           payloadRefresh.ID,
-		UserUuid:     detailLogin.UserUuid,
+		FamilyID:     sessionID,
+		UserUuid:     userUUID,
 		RefreshToken: refreshToken,
 		UserAgent:    userAgent,
-		ClientIp:     ClientIP,
+		ClientIp:     clientIP,
 		IsBlocked:    false,
 		ExpiresAt:    payloadRefresh.ExpiredAt,
 	})
@@ -86,25 +213,17 @@ func (a *AuthService) Login(ctx context.Context, username, password, userAgent,
 	}
 
 	return response.AuthLoginResponse{
-		SessionId:    session.ID.String(),
+		SessionId:    session.FamilyID.String(),
 		AcessToken:   accessToken,
 		RefreshToken: refreshToken,
-		User: response.UserGetSimple{
-			UserUUID: detailLogin.UserUuid.String(),
-			Username: detailLogin.Username,
-			FullName: detailLogin.FullName,
-			Email:    detailLogin.Email,
-		},
+		User:         user,
 	}, nil
 }
 
 func (a *AuthService) RefreshToken(ctx context.Context, refreshToken, userAgent, ClientIP string) (response.AuthLoginResponse, error) {
-	maker, err := token.NewPasetoMaker(a.configToken["token_secret"])
-	if err != nil {
-		return response.AuthLoginResponse{}, err
-	}
+	maker := a.maker
 
-	payload, err := maker.VerifyToken(refreshToken)
+	payload, err := maker.VerifyToken(refreshToken, token.KindRefresh, token.AudienceRefresh)
 	if err != nil {
 		return response.AuthLoginResponse{}, err
 	}
@@ -115,11 +234,21 @@ func (a *AuthService) RefreshToken(ctx context.Context, refreshToken, userAgent,
 		return response.AuthLoginResponse{}, err
 	}
 
+	if session.RefreshToken != refreshToken {
+		return response.AuthLoginResponse{}, fmt.Errorf("mismatched session token")
+	}
+
 	// check if session is blocked
 	if session.IsBlocked {
 		return response.AuthLoginResponse{}, fmt.Errorf("session is blocked")
 	}
 
+	// a refresh token that was already exchanged is being replayed, so it has leaked
+	if session.RotatedAt.Valid {
+		a.revokeReusedSessionFamily(ctx, session, userAgent, ClientIP)
+		return response.AuthLoginResponse{}, ErrRefreshTokenReused
+	}
+
 	// check if session is expired
 	if time.Now().After(session.ExpiresAt) {
 		return response.AuthLoginResponse{}, fmt.Errorf("session is expired")
@@ -136,9 +265,26 @@ func (a *AuthService) RefreshToken(ctx context.Context, refreshToken, userAgent,
 	if err != nil {
 		return response.AuthLoginResponse{}, ErrUserNotFound
 	}
+	if detailUser.IsBlocked {
+		return response.AuthLoginResponse{}, ErrUserBlocked
+	}
+	if detailUser.PasswordResetRequired {
+		return response.AuthLoginResponse{}, ErrPasswordResetRequired
+	}
 	role := detailUser.Role
 
-	accessToken, _, err := maker.CreateToken(session.UserUuid.String(), accessTokenDuration, role)
+	// claim the session, a concurrent refresh with the same token loses and counts as reuse
+	rotated, err := a.db.RotateSession(ctx, session.ID)
+	if err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+	if rotated == 0 {
+		a.revokeReusedSessionFamily(ctx, session, userAgent, ClientIP)
+		return response.AuthLoginResponse{}, ErrRefreshTokenReused
+	}
+
+	// refreshing is no authentication, the new tokens keep the time of the login
+	accessToken, _, err := maker.CreateToken(session.UserUuid.String(), session.FamilyID, token.KindAccess, accessTokenDuration, role, payload.AuthTime)
 	if err != nil {
 		return response.AuthLoginResponse{}, err
 	}
@@ -148,7 +294,7 @@ func (a *AuthService) RefreshToken(ctx context.Context, refreshToken, userAgent,
 		return response.AuthLoginResponse{}, err
 	}
 
-	refreshToken, payloadRefresh, err := maker.CreateToken(session.UserUuid.String(), refreshTokenDuration, role)
+	refreshToken, payloadRefresh, err := maker.CreateToken(session.UserUuid.String(), session.FamilyID, token.KindRefresh, refreshTokenDuration, role, payload.AuthTime)
 	if err != nil {
 		return response.AuthLoginResponse{}, err
 	}
@@ -156,6 +302,7 @@ func (a *AuthService) RefreshToken(ctx context.Context, refreshToken, userAgent,
 	// save refresh token to db
 	session, err = a.db.CreateSession(ctx, db.CreateSessionParams{
 		ID:           payloadRefresh.ID,
+		FamilyID:     session.FamilyID,
 		UserUuid:     session.UserUuid,
 		RefreshToken: refreshToken,
 		UserAgent:    userAgent,
@@ -169,14 +316,166 @@ func (a *AuthService) RefreshToken(ctx context.Context, refreshToken, userAgent,
 	}
 
 	return response.AuthLoginResponse{
-		SessionId:    session.ID.String(),
+		SessionId:    session.FamilyID.String(),
 		AcessToken:   accessToken,
 		RefreshToken: refreshToken,
 		User: response.UserGetSimple{
-			UserUUID: session.UserUuid.String(),
-			Username: detailUser.Username,
-			FullName: detailUser.FullName,
-			Email:    detailUser.Email,
+			UserUUID:      session.UserUuid.String(),
+			Username:      detailUser.Username,
+			FullName:      detailUser.FullName,
+			Email:         detailUser.Email,
+			EmailVerified: emailVerified(detailUser.VerifiedEmailAt),
 		},
 	}, nil
 }
+
+// Logout revokes the session the access token was issued for, so neither its refresh token nor
+// its access tokens can be used again.
+func (a *AuthService) Logout(ctx context.Context, authPayload *token.Payload) error {
+	return a.RevokeSession(ctx, authPayload, sessionIDOf(authPayload))
+}
+
+// ListSessions returns the active sessions of the authenticated user, newest first.
+// The session of the current access token is marked as current.
+func (a *AuthService) ListSessions(ctx context.Context, authPayload *token.Payload) ([]response.SessionResponse, error) {
+	sessions, err := a.db.ListActiveSessionsByUser(ctx, authPayload.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []response.SessionResponse{}
+	for _, session := range sessions {
+		result = append(result, response.SessionResponse{
+			SessionID: session.FamilyID,
+			UserAgent: session.UserAgent,
+			ClientIP:  session.ClientIp,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+			Current:   session.FamilyID == sessionIDOf(authPayload),
+		})
+	}
+
+	return result, nil
+}
+
+// ListSecurityEvents returns the security history of the authenticated user, newest first.
+func (a *AuthService) ListSecurityEvents(ctx context.Context, authPayload *token.Payload, req request.ListSecurityEventRequest) ([]response.SecurityEventResponse, error) {
+	events, err := a.db.ListSecurityEventsByUser(ctx, db.ListSecurityEventsByUserParams{
+		UserUuid:   authPayload.UserUUID,
+		PageLimit:  req.Limit,
+		PageOffset: (req.Page - 1) * req.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := []response.SecurityEventResponse{}
+	for _, event := range events {
+		result = append(result, response.SecurityEventResponse{
+			EventType: event.EventType,
+			ClientIP:  event.ClientIp,
+			UserAgent: event.UserAgent,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// RevokeSession revokes one session of the authenticated user and the access tokens issued for it.
+func (a *AuthService) RevokeSession(ctx context.Context, authPayload *token.Payload, sessionID uuid.UUID) error {
+	rows, err := a.db.BlockSessionFamily(ctx, db.BlockSessionFamilyParams{
+		FamilyID: sessionID,
+		UserUuid: authPayload.UserUUID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrSessionNotFound
+	}
+
+	return a.revocations.RevokeSession(ctx, sessionID)
+}
+
+// RevokeOtherSessions revokes every session of the authenticated user except the current one
+// and returns the number of revoked sessions.
+func (a *AuthService) RevokeOtherSessions(ctx context.Context, authPayload *token.Payload) (int64, error) {
+	familyIDs, err := a.db.BlockOtherUserSessions(ctx, db.BlockOtherUserSessionsParams{
+		UserUuid: authPayload.UserUUID,
+		FamilyID: sessionIDOf(authPayload),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return revokeSessions(ctx, a.revocations, familyIDs)
+}
+
+// revokeReusedSessionFamily blocks every session of the family of a replayed refresh token and
+// records the reuse in the audit log and in the security history of the user.
+func (a *AuthService) revokeReusedSessionFamily(ctx context.Context, session db.Session, userAgent, clientIP string) {
+	ctx = audit.WithActor(ctx, audit.Actor{
+		UserUUID:  session.UserUuid,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+	})
+	err := a.db.ExecTx(ctx, func(store db.Store) error {
+		revoked, err := store.BlockSessionFamily(ctx, db.BlockSessionFamilyParams{
+			FamilyID: session.FamilyID,
+			UserUuid: session.UserUuid,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionSessionRefreshReuse,
+			EntityType: audit.EntitySession,
+			EntityID:   session.FamilyID.String(),
+			After: map[string]any{
+				"session_id": session.ID,
+				"revoked":    revoked,
+			},
+		})
+	})
+	if err != nil {
+		log.Printf("Error: cannot revoke session family %s: %s", session.FamilyID, err.Error())
+	}
+	if err := a.revocations.RevokeSession(ctx, session.FamilyID); err != nil {
+		log.Printf("Error: cannot revoke access tokens of session family %s: %s", session.FamilyID, err.Error())
+	}
+	securityevent.Record(ctx, a.db, securityevent.Event{
+		UserUUID:  session.UserUuid,
+		Type:      securityevent.TypeSessionRefreshReuse,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+	})
+}
+
+// sessionIDOf returns the session family a token belongs to. Refresh tokens issued before tokens
+// carried the session ID started their own family, keyed by their own ID.
+func sessionIDOf(payload *token.Payload) uuid.UUID {
+	if payload.SessionID == uuid.Nil {
+		return payload.ID
+	}
+
+	return payload.SessionID
+}
+
+// revokeSessions revokes the access tokens of the sessions of familyIDs and returns the number of
+// sessions. The IDs repeat once per refresh token of a session.
+func revokeSessions(ctx context.Context, revocations *revocation.List, familyIDs []uuid.UUID) (int64, error) {
+	revoked := make(map[uuid.UUID]bool)
+	for _, familyID := range familyIDs {
+		if revoked[familyID] {
+			continue
+		}
+		if err := revocations.RevokeSession(ctx, familyID); err != nil {
+			return 0, err
+		}
+		revoked[familyID] = true
+	}
+
+	return int64(len(revoked)), nil
+}
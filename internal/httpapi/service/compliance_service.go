@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/risk"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ComplianceService struct {
+	db   db.Store
+	risk *risk.Engine
+}
+
+func NewComplianceService(db db.Store, riskEngine *risk.Engine) *ComplianceService {
+	return &ComplianceService{
+		db:   db,
+		risk: riskEngine,
+	}
+}
+
+// ListComplianceReviews returns the sanctions screening matches with the given status, oldest first.
+func (c *ComplianceService) ListComplianceReviews(ctx context.Context, param db.ListComplianceReviewsParams) ([]response.ComplianceReviewResponse, int64, error) {
+	if param.Status == "" {
+		param.Status = "pending"
+	}
+
+	reviews, err := c.db.ListComplianceReviews(ctx, param)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	countTotal, err := c.db.CountComplianceReviews(ctx, param.Status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := []response.ComplianceReviewResponse{}
+	for _, review := range reviews {
+		item := response.ComplianceReviewResponse{
+			ReviewUUID:    review.ReviewUuid,
+			Kind:          review.Kind,
+			UserUUID:      review.UserUuid,
+			ScreenedName:  review.ScreenedName,
+			ListReference: review.ListReference,
+			ListedName:    review.ListedName,
+			Score:         review.Score,
+			Status:        review.Status,
+			CreatedAt:     review.CreatedAt,
+		}
+		if review.FromAccountUuid.Valid {
+			fromAccountUUID := uuid.UUID(review.FromAccountUuid.Bytes)
+			item.FromAccountUUID = &fromAccountUUID
+		}
+		if review.ToAccountUuid.Valid {
+			toAccountUUID := uuid.UUID(review.ToAccountUuid.Bytes)
+			item.ToAccountUUID = &toAccountUUID
+		}
+		if review.Amount.Valid {
+			amount := review.Amount.Int.String()
+			item.Amount = &amount
+		}
+		if review.Currency.Valid {
+			item.Currency = &review.Currency.String
+		}
+		if review.ReviewedBy.Valid {
+			reviewedBy := uuid.UUID(review.ReviewedBy.Bytes)
+			item.ReviewedBy = &reviewedBy
+		}
+		if review.ReviewedAt.Valid {
+			item.ReviewedAt = &review.ReviewedAt.Time
+		}
+		result = append(result, item)
+	}
+
+	return result, countTotal, nil
+}
+
+// ClearComplianceReview marks a match as a false positive. A held signup or rename can move money again. A
+// held transfer is evaluated by the risk engine, as it would have been without the match, and only
+// booked when allowed; otherwise it waits for the review of its risk decision or stays denied.
+func (c *ComplianceService) ClearComplianceReview(ctx context.Context, reviewUUID uuid.UUID, authPayload *token.Payload) (response.ComplianceDecisionResponse, error) {
+	review, err := c.getPendingReview(ctx, reviewUUID)
+	if err != nil {
+		return response.ComplianceDecisionResponse{}, err
+	}
+
+	param := db.ClearComplianceReviewTxParam{
+		ReviewUUID: reviewUUID,
+		Reviewer:   authPayload.UserUUID,
+	}
+	if review.Kind == "transfer" {
+		amount, err := review.Amount.Int64Value()
+		if err != nil {
+			return response.ComplianceDecisionResponse{}, err
+		}
+
+		// the device of the original request is not kept, so the device rule sees an unknown device
+		assessment, err := c.risk.Evaluate(ctx, risk.Input{
+			UserUUID:      review.UserUuid,
+			FromAccountID: review.FromAccountID.Int64,
+			ToAccountID:   review.ToAccountID.Int64,
+			Amount:        amount.Int64,
+			Currency:      review.Currency.String,
+			At:            time.Now(),
+		})
+		if err != nil {
+			return response.ComplianceDecisionResponse{}, err
+		}
+
+		param.RiskDecision = db.CreateRiskDecisionParams{
+			UserUuid:      review.UserUuid,
+			FromAccountID: review.FromAccountID.Int64,
+			ToAccountID:   review.ToAccountID.Int64,
+			Amount:        review.Amount,
+			Currency:      review.Currency.String,
+			Decision:      string(assessment.Decision),
+			Reasons:       assessment.Reasons,
+		}
+		// the membership may have changed while the transfer was waiting
+		param.CheckMember = func(member db.AccountMember) error {
+			return checkMemberTransfer(member, amount.Int64)
+		}
+	}
+
+	var res db.ClearComplianceReviewTxResult
+	err = c.db.ExecTx(ctx, func(store db.Store) error {
+		res, err = store.ClearComplianceReviewTx(ctx, param)
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionComplianceReviewClear,
+			EntityType: audit.EntityComplianceReview,
+			EntityID:   review.ReviewUuid.String(),
+			Before:     review,
+			After:      res.Review,
+		})
+	})
+	if err != nil {
+		return response.ComplianceDecisionResponse{}, err
+	}
+
+	result := response.ComplianceDecisionResponse{
+		ReviewUUID: res.Review.ReviewUuid,
+		Kind:       res.Review.Kind,
+		Status:     res.Review.Status,
+	}
+	if res.Review.Kind == "transfer" {
+		result.RiskDecision = &response.TransferDecisionResponse{
+			DecisionUUID: res.RiskDecision.DecisionUuid,
+			Decision:     res.RiskDecision.Decision,
+			Reasons:      res.RiskDecision.Reasons,
+		}
+	}
+	if res.Transfer.Transaction.TransactionUuid != uuid.Nil {
+		result.Transaction = &response.SuccessTransactionResponse{
+			TransactionUUID: res.Transfer.Transaction.TransactionUuid.String(),
+			FromAccountUUID: res.Transfer.FromAccount.AccountUuid.String(),
+			ToAccountUUID:   res.Transfer.ToAccount.AccountUuid.String(),
+			Amount:          res.Transfer.Transaction.Amount.Int.String(),
+			Currency:        res.Review.Currency.String,
+			LastedBalance:   res.Transfer.FromAccount.Balance.Int.String(),
+			Type:            "transfer",
+		}
+	}
+
+	return result, nil
+}
+
+// ConfirmComplianceReview confirms a match. A held signup or rename stays blocked from moving money and a
+// held transfer is never booked.
+func (c *ComplianceService) ConfirmComplianceReview(ctx context.Context, reviewUUID uuid.UUID, authPayload *token.Payload) (response.ComplianceDecisionResponse, error) {
+	before, err := c.getPendingReview(ctx, reviewUUID)
+	if err != nil {
+		return response.ComplianceDecisionResponse{}, err
+	}
+
+	var review db.ComplianceReview
+	err = c.db.ExecTx(ctx, func(store db.Store) error {
+		review, err = store.ReviewComplianceReview(ctx, db.ReviewComplianceReviewParams{
+			Status:     "confirmed",
+			ReviewedBy: pgtype.UUID{Bytes: authPayload.UserUUID, Valid: true},
+			ReviewUuid: reviewUUID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionComplianceReviewConfirm,
+			EntityType: audit.EntityComplianceReview,
+			EntityID:   review.ReviewUuid.String(),
+			Before:     before,
+			After:      review,
+		})
+	})
+	if err != nil {
+		return response.ComplianceDecisionResponse{}, err
+	}
+
+	return response.ComplianceDecisionResponse{
+		ReviewUUID: review.ReviewUuid,
+		Kind:       review.Kind,
+		Status:     review.Status,
+	}, nil
+}
+
+func (c *ComplianceService) getPendingReview(ctx context.Context, reviewUUID uuid.UUID) (db.ComplianceReview, error) {
+	review, err := c.db.GetComplianceReviewByUUID(ctx, reviewUUID)
+	if err != nil {
+		return db.ComplianceReview{}, err
+	}
+
+	if review.Status != "pending" {
+		return db.ComplianceReview{}, errors.New("compliance review already decided")
+	}
+
+	return review, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/google/uuid"
+)
+
+// completeLogin records the device of a user who authenticated and starts the session, unless the
+// device has to be confirmed with the emailed code first.
+// The response carries the device token the client has to send with its next logins.
+func (a *AuthService) completeLogin(ctx context.Context, maker token.Maker, userUUID uuid.UUID, role string, user response.UserGetSimple, deviceToken, userAgent, clientIP string) (response.AuthLoginResponse, error) {
+	deviceToken, err := a.devices.SignIn(ctx, device.Login{
+		UserUUID:    userUUID,
+		Email:       user.Email,
+		DeviceToken: deviceToken,
+		UserAgent:   userAgent,
+		ClientIP:    clientIP,
+	})
+	if errors.Is(err, device.ErrConfirmationRequired) {
+		return response.AuthLoginResponse{DeviceConfirmationRequired: true, DeviceToken: deviceToken, User: user}, nil
+	}
+	if err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+
+	result, err := a.startSession(ctx, maker, userUUID, role, user, userAgent, clientIP)
+	if err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+	result.DeviceToken = deviceToken
+	return result, nil
+}
+
+// ConfirmDevice completes a login from a new device with the code emailed to the user. The code
+// only works from the device holding the device token the login returned.
+func (a *AuthService) ConfirmDevice(ctx context.Context, code, deviceToken, userAgent, clientIP string) (response.AuthLoginResponse, error) {
+	userUUID, err := a.devices.Confirm(ctx, code, deviceToken, userAgent, clientIP)
+	if err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+
+	user, err := a.db.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		return response.AuthLoginResponse{}, ErrUserNotFound
+	}
+	if user.IsBlocked {
+		return response.AuthLoginResponse{}, ErrUserBlocked
+	}
+	if user.PasswordResetRequired {
+		return response.AuthLoginResponse{}, ErrPasswordResetRequired
+	}
+
+	result, err := a.startSession(ctx, a.maker, user.UserUuid, user.Role, response.UserGetSimple{
+		UserUUID:      user.UserUuid.String(),
+		Username:      user.Username,
+		FullName:      user.FullName,
+		Email:         user.Email,
+		EmailVerified: emailVerified(user.VerifiedEmailAt),
+	}, userAgent, clientIP)
+	if err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+	result.DeviceToken = deviceToken
+	return result, nil
+}
+
+// ListDevices returns the trusted devices of the authenticated user, most recently seen first. The
+// device holding deviceToken is marked as current.
+func (a *AuthService) ListDevices(ctx context.Context, authPayload *token.Payload, deviceToken string) ([]response.DeviceResponse, error) {
+	devices, err := a.devices.List(ctx, authPayload.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := device.Fingerprint(deviceToken)
+	result := []response.DeviceResponse{}
+	for _, d := range devices {
+		result = append(result, response.DeviceResponse{
+			DeviceUUID:   d.DeviceUuid,
+			UserAgent:    d.UserAgent,
+			LastClientIP: d.LastClientIp,
+			TrustedAt:    d.TrustedAt.Time,
+			LastSeenAt:   d.LastSeenAt,
+			CreatedAt:    d.CreatedAt,
+			Current:      current != "" && d.Fingerprint == current,
+		})
+	}
+
+	return result, nil
+}
+
+// RemoveDevice takes a device off the trusted devices of the authenticated user.
+func (a *AuthService) RemoveDevice(ctx context.Context, authPayload *token.Payload, deviceUUID uuid.UUID, userAgent, clientIP string) error {
+	return a.devices.Remove(ctx, authPayload.UserUUID, deviceUUID, clientIP, userAgent)
+}
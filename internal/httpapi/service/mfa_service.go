@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/mfa"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// mfaChallengeDuration is how long the user has to enter the two-factor code after the password was checked.
+const mfaChallengeDuration = 5 * time.Minute
+
+// totpIssuer is the name authenticator apps show next to the one-time passwords.
+const totpIssuer = "Simple Bank"
+
+var (
+	// ErrInvalidMFAToken is returned when the MFA token of a two-factor login is invalid or expired.
+	ErrInvalidMFAToken = fmt.Errorf("invalid mfa token")
+	// ErrInvalidMFACode is returned when the code is neither a valid one-time password nor an unused recovery code.
+	ErrInvalidMFACode = fmt.Errorf("invalid two-factor code")
+	// ErrMFANotEnrolled is returned when the user has not started or has not enabled two-factor authentication.
+	ErrMFANotEnrolled = fmt.Errorf("two-factor authentication is not enrolled")
+	// ErrMFAAlreadyEnabled is returned when enrolling while two-factor authentication is already enabled.
+	ErrMFAAlreadyEnabled = fmt.Errorf("two-factor authentication is already enabled")
+	// ErrMFARequiredByRole is returned when disabling two-factor authentication the role of the user requires.
+	ErrMFARequiredByRole = fmt.Errorf("two-factor authentication is required for the role")
+)
+
+// mfaChallenge returns the response of the first login step when the user has to enter a two-factor
+// code, or nil when the password is enough. Users whose role requires two-factor authentication but
+// who have not enabled it yet get a challenge too, and enroll with the MFA token.
+func (a *AuthService) mfaChallenge(ctx context.Context, maker token.Maker, userUUID uuid.UUID, role string) (*response.AuthLoginResponse, error) {
+	enabled, err := mfa.Enabled(ctx, a.db, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		required, err := a.roleRequiresMFA(ctx, role)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+	}
+
+	mfaToken, _, err := maker.CreateToken(userUUID.String(), uuid.Nil, token.KindMFA, mfaChallengeDuration, role, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.AuthLoginResponse{
+		MFARequired:           true,
+		MFAToken:              mfaToken,
+		MFAEnrollmentRequired: !enabled,
+	}, nil
+}
+
+// VerifyLoginMFA completes a two-factor login with a one-time password or a recovery code. A user
+// enrolling during the login enables two-factor authentication with the first one-time password
+// and gets the recovery codes in the response. Wrong codes count as failed logins of the user, and
+// the MFA token is revoked once they lock the user out.
+func (a *AuthService) VerifyLoginMFA(ctx context.Context, mfaToken, code, deviceToken, userAgent, clientIP string) (response.AuthLoginResponse, error) {
+	maker := a.maker
+
+	payload, err := maker.VerifyToken(mfaToken, token.KindMFA, token.AudienceMFA)
+	if err != nil {
+		return response.AuthLoginResponse{}, ErrInvalidMFAToken
+	}
+	err = a.revocations.Check(ctx, revocation.Token{
+		ID:        payload.ID,
+		SessionID: payload.SessionID,
+		UserUUID:  payload.UserUUID,
+		IssuedAt:  payload.IssuedAt,
+		ExpiredAt: payload.ExpiredAt,
+	})
+	if errors.Is(err, revocation.ErrRevoked) {
+		return response.AuthLoginResponse{}, ErrInvalidMFAToken
+	}
+	if err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+
+	user, err := a.db.GetUserByUserUUID(ctx, payload.UserUUID)
+	if err != nil {
+		return response.AuthLoginResponse{}, ErrUserNotFound
+	}
+
+	attempt := lockout.Attempt{Username: user.Username, ClientIP: clientIP, UserAgent: userAgent}
+	if err := a.guard.Check(ctx, attempt); err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+	if user.IsBlocked {
+		return response.AuthLoginResponse{}, ErrUserBlocked
+	}
+	if user.PasswordResetRequired {
+		return response.AuthLoginResponse{}, ErrPasswordResetRequired
+	}
+
+	totp, err := a.getUserTOTP(ctx, user.UserUuid)
+	if err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+
+	var recoveryCodes []string
+	if totp.ConfirmedAt.Valid {
+		err = a.verifyMFACode(ctx, totp, code)
+	} else {
+		recoveryCodes, err = a.enableTOTP(ctx, totp, code)
+	}
+	if errors.Is(err, ErrInvalidMFACode) {
+		a.mfaFailed(ctx, attempt, user, payload)
+	}
+	if err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+
+	if err := a.guard.Succeed(ctx, attempt); err != nil {
+		log.Printf("Error: cannot clear failed logins of %s: %s", user.Username, err.Error())
+	}
+
+	result, err := a.completeLogin(ctx, maker, user.UserUuid, user.Role, response.UserGetSimple{
+		UserUUID:      user.UserUuid.String(),
+		Username:      user.Username,
+		FullName:      user.FullName,
+		Email:         user.Email,
+		EmailVerified: emailVerified(user.VerifiedEmailAt),
+	}, deviceToken, userAgent, clientIP)
+	if err != nil {
+		return response.AuthLoginResponse{}, err
+	}
+
+	result.RecoveryCodes = recoveryCodes
+	return result, nil
+}
+
+// mfaFailed counts a wrong two-factor code as a failed login. Once the user is locked out, the MFA
+// token is revoked as well, so the login has to start over with the password after the lockout.
+func (a *AuthService) mfaFailed(ctx context.Context, attempt lockout.Attempt, user db.GetUserByUserUUIDRow, payload *token.Payload) {
+	a.loginFailed(ctx, attempt, &lockout.User{UUID: user.UserUuid, Email: user.Email})
+
+	var locked *lockout.LockedError
+	if err := a.guard.Check(ctx, attempt); !errors.As(err, &locked) || !locked.Locked {
+		return
+	}
+	if err := a.revocations.Revoke(ctx, payload.ID, payload.ExpiredAt); err != nil {
+		log.Printf("Error: cannot revoke mfa token of %s: %s", user.Username, err.Error())
+	}
+}
+
+// EnrollLoginMFA starts the enrollment of a user whose role requires two-factor authentication
+// during the login, authenticated by the MFA token of the first login step.
+func (a *AuthService) EnrollLoginMFA(ctx context.Context, mfaToken string) (response.TOTPEnrollmentResponse, error) {
+	maker := a.maker
+
+	payload, err := maker.VerifyToken(mfaToken, token.KindMFA, token.AudienceMFA)
+	if err != nil {
+		return response.TOTPEnrollmentResponse{}, ErrInvalidMFAToken
+	}
+
+	return a.enrollTOTP(ctx, payload.UserUUID)
+}
+
+// EnrollTOTP generates a new TOTP secret for the authenticated user. Two-factor authentication is
+// only enabled once the user confirms the secret with a code from the authenticator app.
+func (a *AuthService) EnrollTOTP(ctx context.Context, authPayload *token.Payload) (response.TOTPEnrollmentResponse, error) {
+	return a.enrollTOTP(ctx, authPayload.UserUUID)
+}
+
+// ConfirmTOTP enables two-factor authentication with the first code of the pending secret and
+// returns the recovery codes, which are not shown again.
+func (a *AuthService) ConfirmTOTP(ctx context.Context, authPayload *token.Payload, code string) (response.RecoveryCodesResponse, error) {
+	totp, err := a.getUserTOTP(ctx, authPayload.UserUUID)
+	if err != nil {
+		return response.RecoveryCodesResponse{}, err
+	}
+	if totp.ConfirmedAt.Valid {
+		return response.RecoveryCodesResponse{}, ErrMFAAlreadyEnabled
+	}
+
+	recoveryCodes, err := a.enableTOTP(ctx, totp, code)
+	if err != nil {
+		return response.RecoveryCodesResponse{}, err
+	}
+
+	return response.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// DisableTOTP turns two-factor authentication off after checking a current code.
+func (a *AuthService) DisableTOTP(ctx context.Context, authPayload *token.Payload, code string) error {
+	required, err := a.roleRequiresMFA(ctx, authPayload.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequiredByRole
+	}
+
+	totp, err := a.getUserTOTP(ctx, authPayload.UserUUID)
+	if err != nil {
+		return err
+	}
+	if err := a.verifyMFACode(ctx, totp, code); err != nil {
+		return err
+	}
+
+	err = a.db.ExecTx(ctx, func(store db.Store) error {
+		if err := store.DisableTOTPTx(ctx, authPayload.UserUUID); err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionUserMFADisable,
+			EntityType: audit.EntityUser,
+			EntityID:   authPayload.UserUUID.String(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrMFANotEnrolled
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a current code.
+func (a *AuthService) RegenerateRecoveryCodes(ctx context.Context, authPayload *token.Payload, code string) (response.RecoveryCodesResponse, error) {
+	totp, err := a.getUserTOTP(ctx, authPayload.UserUUID)
+	if err != nil {
+		return response.RecoveryCodesResponse{}, err
+	}
+	if err := a.verifyMFACode(ctx, totp, code); err != nil {
+		return response.RecoveryCodesResponse{}, err
+	}
+
+	recoveryCodes, err := mfa.GenerateRecoveryCodes(mfa.RecoveryCodeCount)
+	if err != nil {
+		return response.RecoveryCodesResponse{}, err
+	}
+
+	err = a.db.ReplaceRecoveryCodesTx(ctx, authPayload.UserUUID, mfa.HashRecoveryCodes(recoveryCodes))
+	if err != nil {
+		return response.RecoveryCodesResponse{}, err
+	}
+
+	return response.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (a *AuthService) enrollTOTP(ctx context.Context, userUUID uuid.UUID) (response.TOTPEnrollmentResponse, error) {
+	user, err := a.db.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		return response.TOTPEnrollmentResponse{}, ErrUserNotFound
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		return response.TOTPEnrollmentResponse{}, err
+	}
+
+	// replaces a pending secret, but never a confirmed one
+	_, err = a.db.CreateUserTOTP(ctx, db.CreateUserTOTPParams{
+		UserUuid: userUUID,
+		Secret:   secret,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return response.TOTPEnrollmentResponse{}, ErrMFAAlreadyEnabled
+		}
+		return response.TOTPEnrollmentResponse{}, err
+	}
+
+	return response.TOTPEnrollmentResponse{
+		Secret:     secret,
+		OtpauthURI: mfa.URI(totpIssuer, user.Username, secret),
+	}, nil
+}
+
+// enableTOTP confirms the pending secret of totp with code and issues the recovery codes.
+func (a *AuthService) enableTOTP(ctx context.Context, totp db.UserTotp, code string) ([]string, error) {
+	step, ok := mfa.Validate(totp.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	recoveryCodes, err := mfa.GenerateRecoveryCodes(mfa.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.db.ExecTx(ctx, func(store db.Store) error {
+		err := store.EnableTOTPTx(ctx, db.EnableTOTPTxParam{
+			UserUUID:   totp.UserUuid,
+			Step:       step,
+			CodeHashes: mfa.HashRecoveryCodes(recoveryCodes),
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionUserMFAEnable,
+			EntityType: audit.EntityUser,
+			EntityID:   totp.UserUuid.String(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+func (a *AuthService) verifyMFACode(ctx context.Context, totp db.UserTotp, code string) error {
+	err := mfa.VerifyCode(ctx, a.db, totp, code)
+	switch {
+	case errors.Is(err, mfa.ErrInvalidCode):
+		return ErrInvalidMFACode
+	case errors.Is(err, mfa.ErrNotEnabled):
+		return ErrMFANotEnrolled
+	}
+	return err
+}
+
+func (a *AuthService) getUserTOTP(ctx context.Context, userUUID uuid.UUID) (db.UserTotp, error) {
+	totp, err := a.db.GetUserTOTP(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.UserTotp{}, ErrMFANotEnrolled
+		}
+		return db.UserTotp{}, err
+	}
+
+	return totp, nil
+}
+
+// roleRequiresMFA reports whether an admin made two-factor authentication mandatory for role.
+func (a *AuthService) roleRequiresMFA(ctx context.Context, role string) (bool, error) {
+	setting, err := a.db.GetRoleSetting(ctx, role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return setting.MfaRequired, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordreset"
+)
+
+// ErrInvalidResetCode is returned when a password reset code is wrong, used up or expired.
+var ErrInvalidResetCode = fmt.Errorf("invalid or expired password reset code")
+
+// RequestPasswordReset emails a password reset code to address. It succeeds whether or not the
+// address belongs to a user, so the response does not reveal which addresses are registered.
+// Requesting codes too often for the address or from clientIP returns a *passwordreset.LimitedError.
+func (a *AuthService) RequestPasswordReset(ctx context.Context, address, clientIP string) error {
+	_, _, err := passwordreset.Request(ctx, a.db, a.emails, a.resets, address, clientIP)
+	return err
+}
+
+// ResetPassword sets a new password with the code emailed by RequestPasswordReset and signs the
+// user out of every session, revoking the access tokens issued before the password changed. The
+// password has to satisfy the password policy.
+func (a *AuthService) ResetPassword(ctx context.Context, address, code, newPassword string) error {
+	user, err := passwordreset.Reset(ctx, a.db, a.policy, a.hasher, address, code, newPassword)
+	if err != nil {
+		if err == passwordreset.ErrInvalidCode {
+			return ErrInvalidResetCode
+		}
+		return err
+	}
+
+	return a.revocations.RevokeIssuedBefore(ctx, user.UserUuid, user.PasswordChangedAt)
+}
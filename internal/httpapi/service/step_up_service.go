@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+)
+
+// StepUp authenticates the user again with the password, or a two-factor code when code is set,
+// and issues an access token of the same session with a new authentication time for the
+// operations that require a recent authentication.
+func (a *AuthService) StepUp(ctx context.Context, authPayload *token.Payload, password, code, userAgent, clientIP string) (response.StepUpResponse, error) {
+	err := a.stepUp.Authenticate(ctx, authPayload.UserUUID, password, code, clientIP, userAgent)
+	if err != nil {
+		return response.StepUpResponse{}, err
+	}
+
+	accessTokenDuration, err := time.ParseDuration(a.configToken["access_token_duration"])
+	if err != nil {
+		return response.StepUpResponse{}, err
+	}
+
+	accessToken, payload, err := a.maker.CreateToken(authPayload.UserUUID.String(), authPayload.SessionID, token.KindAccess, accessTokenDuration, authPayload.Role, time.Now())
+	if err != nil {
+		return response.StepUpResponse{}, err
+	}
+
+	return response.StepUpResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: payload.ExpiredAt,
+		AuthTime:             payload.AuthTime,
+	}, nil
+}
@@ -4,26 +4,49 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
+	"time"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/helper"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/risk"
+	"github.com/fajaramaulana/simple_bank_project/internal/screening"
+	"github.com/fajaramaulana/simple_bank_project/internal/stepup"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type TransactionService struct {
-	db db.Store
+	db       db.Store
+	risk     *risk.Engine
+	screener *screening.Screener
+	stepUp   *stepup.Policy
 }
 
-func NewTransactionService(db db.Store) *TransactionService {
+func NewTransactionService(db db.Store, riskEngine *risk.Engine, screener *screening.Screener, stepUp *stepup.Policy) *TransactionService {
 	return &TransactionService{
-		db: db,
+		db:       db,
+		risk:     riskEngine,
+		screener: screener,
+		stepUp:   stepUp,
 	}
 }
 
-func (a *TransactionService) CreateTransferTrans(ctx context.Context, req *request.CreateTransferRequest, authPayload *token.Payload) (response.SuccessTransactionResponse, error) {
+// CreateTransferTrans validates and books a transfer. Before booking, the counterparty is screened
+// against the sanctions list and a match is held for compliance review with a *screening.HoldError.
+// The transfer is then evaluated by the risk engine and the decision is stored. A transfer that is
+// denied or held for review is not booked and a *risk.DecisionError is returned instead. A large
+// transfer made with a token of an old authentication returns a *stepup.RequiredError.
+func (a *TransactionService) CreateTransferTrans(ctx context.Context, req *request.CreateTransferRequest, authPayload *token.Payload, deviceToken string) (response.SuccessTransactionResponse, error) {
+	if err := a.stepUp.Require(stepup.OperationTransfer, req.Amount, authPayload.AuthTime); err != nil {
+		return response.SuccessTransactionResponse{}, err
+	}
+
 	fromAccountUUID, err := helper.ConvertStringToUUID(req.FromAccountUUID)
 	if err != nil {
 		return response.SuccessTransactionResponse{}, fmt.Errorf("%w: from account uuid not valid", err)
@@ -36,9 +59,13 @@ func (a *TransactionService) CreateTransferTrans(ctx context.Context, req *reque
 
 	fmt.Printf("%# v\n", dataFromAccount.UserUuid.String())
 	fmt.Printf("%# v\n", authPayload.UserUUID.String())
-	// check if from account belongs to user
-	if dataFromAccount.UserUuid != authPayload.UserUUID {
-		return response.SuccessTransactionResponse{}, errors.New("unauthorized")
+	// check if the user is a member of from account who may transfer the amount
+	member, err := getAccountMember(ctx, a.db, dataFromAccount.ID, authPayload.UserUUID)
+	if err != nil {
+		return response.SuccessTransactionResponse{}, err
+	}
+	if err := checkMemberTransfer(member, req.Amount); err != nil {
+		return response.SuccessTransactionResponse{}, err
 	}
 	// end check if from account exists
 
@@ -56,6 +83,11 @@ func (a *TransactionService) CreateTransferTrans(ctx context.Context, req *reque
 	}
 	// end check if to account exists
 
+	// pockets are funded from their account with a pocket move, not with a transfer
+	if dataFromAccount.ParentAccountID.Valid || dataToAccount.ParentAccountID.Valid {
+		return response.SuccessTransactionResponse{}, ErrPocketTransfer
+	}
+
 	// convert existing balance to float64
 	fromAccountBalance, err := strconv.ParseFloat(dataFromAccount.Balance.Int.String(), 64)
 	if err != nil {
@@ -83,12 +115,81 @@ func (a *TransactionService) CreateTransferTrans(ctx context.Context, req *reque
 		return response.SuccessTransactionResponse{}, errors.New("both account currency not same")
 	}
 
-	// create transaction
-	arg := db.TransferTxParam{
+	// users whose signup or new name matched the sanctions list cannot move money until compliance clears them
+	holds, err := a.db.CountUserComplianceHolds(ctx, authPayload.UserUUID)
+	if err != nil {
+		return response.SuccessTransactionResponse{}, err
+	}
+	if holds > 0 {
+		return response.SuccessTransactionResponse{}, errors.New("account under compliance review")
+	}
+
+	// screen the counterparty against the sanctions list
+	if match, matched := a.screener.Screen(dataToAccount.Owner); matched {
+		review, err := a.db.CreateComplianceReview(ctx, db.CreateComplianceReviewParams{
+			Kind:          "transfer",
+			UserUuid:      authPayload.UserUUID,
+			ScreenedName:  dataToAccount.Owner,
+			ListReference: match.Reference,
+			ListedName:    match.ListedName,
+			Score:         match.Score,
+			FromAccountID: pgtype.Int8{Int64: dataFromAccount.ID, Valid: true},
+			ToAccountID:   pgtype.Int8{Int64: dataToAccount.ID, Valid: true},
+			Amount:        pgtype.Numeric{Int: big.NewInt(req.Amount), Exp: 0, Valid: true},
+			Currency:      pgtype.Text{String: req.Currency, Valid: true},
+		})
+		if err != nil {
+			return response.SuccessTransactionResponse{}, err
+		}
+
+		return response.SuccessTransactionResponse{}, &screening.HoldError{
+			ReviewUUID: review.ReviewUuid,
+			Match:      match,
+		}
+	}
+
+	// evaluate the transfer against the risk rules
+	assessment, err := a.risk.Evaluate(ctx, risk.Input{
+		UserUUID:      authPayload.UserUUID,
+		FromAccountID: dataFromAccount.ID,
+		ToAccountID:   dataToAccount.ID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		DeviceToken:   deviceToken,
+		At:            time.Now(),
+	})
+	if err != nil {
+		return response.SuccessTransactionResponse{}, err
+	}
+
+	decision, err := a.db.CreateRiskDecision(ctx, db.CreateRiskDecisionParams{
+		UserUuid:      authPayload.UserUUID,
 		FromAccountID: dataFromAccount.ID,
 		ToAccountID:   dataToAccount.ID,
-		Amount:        int64(transferAmount),
-		Type:          "transfer",
+		Amount:        pgtype.Numeric{Int: big.NewInt(req.Amount), Exp: 0, Valid: true},
+		Currency:      req.Currency,
+		Decision:      string(assessment.Decision),
+		Reasons:       assessment.Reasons,
+	})
+	if err != nil {
+		return response.SuccessTransactionResponse{}, err
+	}
+
+	if assessment.Decision != risk.DecisionAllow {
+		return response.SuccessTransactionResponse{}, &risk.DecisionError{
+			DecisionUUID: decision.DecisionUuid,
+			Decision:     assessment.Decision,
+			Reasons:      assessment.Reasons,
+		}
+	}
+
+	// create transaction
+	arg := db.TransferTxParam{
+		FromAccountID:  dataFromAccount.ID,
+		ToAccountID:    dataToAccount.ID,
+		Amount:         int64(transferAmount),
+		Type:           "transfer",
+		RiskDecisionID: decision.ID,
 	}
 
 	res, err := a.db.TransferTx(ctx, arg)
@@ -108,3 +209,133 @@ func (a *TransactionService) CreateTransferTrans(ctx context.Context, req *reque
 	}, nil
 
 }
+
+// ListTransferReviews returns the transfers held for review, oldest first.
+func (a *TransactionService) ListTransferReviews(ctx context.Context, param db.ListRiskDecisionsPendingReviewParams) ([]response.TransferReviewResponse, int64, error) {
+	decisions, err := a.db.ListRiskDecisionsPendingReview(ctx, param)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	countTotal, err := a.db.CountRiskDecisionsPendingReview(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := []response.TransferReviewResponse{}
+	for _, decision := range decisions {
+		result = append(result, response.TransferReviewResponse{
+			DecisionUUID:    decision.DecisionUuid,
+			UserUUID:        decision.UserUuid,
+			FromAccountUUID: decision.FromAccountUuid,
+			ToAccountUUID:   decision.ToAccountUuid,
+			Amount:          decision.Amount.Int.String(),
+			Currency:        decision.Currency,
+			Reasons:         decision.Reasons,
+			CreatedAt:       decision.CreatedAt,
+		})
+	}
+
+	return result, countTotal, nil
+}
+
+// ApproveTransferReview books a transfer that was held for review.
+func (a *TransactionService) ApproveTransferReview(ctx context.Context, decisionUUID uuid.UUID, authPayload *token.Payload) (response.SuccessTransactionResponse, error) {
+	decision, err := a.getPendingReview(ctx, decisionUUID)
+	if err != nil {
+		return response.SuccessTransactionResponse{}, err
+	}
+
+	amount, err := decision.Amount.Int64Value()
+	if err != nil {
+		return response.SuccessTransactionResponse{}, err
+	}
+
+	// the balance and the membership of the requester may have changed while the transfer was
+	// waiting, both are checked again when it is booked
+	var res db.TransferTxResult
+	err = a.db.ExecTx(ctx, func(store db.Store) error {
+		res, err = store.ApproveTransferReviewTx(ctx, db.ApproveTransferReviewTxParam{
+			DecisionUUID: decisionUUID,
+			Reviewer:     authPayload.UserUUID,
+			CheckMember: func(member db.AccountMember) error {
+				return checkMemberTransfer(member, amount.Int64)
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionTransferReviewApprove,
+			EntityType: audit.EntityRiskDecision,
+			EntityID:   decision.DecisionUuid.String(),
+			Before:     decision,
+			After:      map[string]any{"review_status": "approved", "transaction_uuid": res.Transaction.TransactionUuid},
+		})
+	})
+	if err != nil {
+		return response.SuccessTransactionResponse{}, err
+	}
+
+	return response.SuccessTransactionResponse{
+		TransactionUUID: res.Transaction.TransactionUuid.String(),
+		FromAccountUUID: res.FromAccount.AccountUuid.String(),
+		ToAccountUUID:   res.ToAccount.AccountUuid.String(),
+		Amount:          res.Transaction.Amount.Int.String(),
+		Currency:        decision.Currency,
+		LastedBalance:   res.FromAccount.Balance.Int.String(),
+		Type:            "transfer",
+	}, nil
+}
+
+// RejectTransferReview rejects a transfer that was held for review.
+func (a *TransactionService) RejectTransferReview(ctx context.Context, decisionUUID uuid.UUID, authPayload *token.Payload) (response.TransferDecisionResponse, error) {
+	before, err := a.getPendingReview(ctx, decisionUUID)
+	if err != nil {
+		return response.TransferDecisionResponse{}, err
+	}
+
+	var decision db.RiskDecision
+	err = a.db.ExecTx(ctx, func(store db.Store) error {
+		decision, err = store.ReviewRiskDecision(ctx, db.ReviewRiskDecisionParams{
+			ReviewStatus: pgtype.Text{String: "rejected", Valid: true},
+			ReviewedBy:   pgtype.UUID{Bytes: authPayload.UserUUID, Valid: true},
+			DecisionUuid: decisionUUID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionTransferReviewReject,
+			EntityType: audit.EntityRiskDecision,
+			EntityID:   decision.DecisionUuid.String(),
+			Before:     before,
+			After:      decision,
+		})
+	})
+	if err != nil {
+		return response.TransferDecisionResponse{}, err
+	}
+
+	return response.TransferDecisionResponse{
+		DecisionUUID: decision.DecisionUuid,
+		Decision:     decision.Decision,
+		Reasons:      decision.Reasons,
+		ReviewStatus: decision.ReviewStatus.String,
+	}, nil
+}
+
+func (a *TransactionService) getPendingReview(ctx context.Context, decisionUUID uuid.UUID) (db.RiskDecision, error) {
+	decision, err := a.db.GetRiskDecisionByUUID(ctx, decisionUUID)
+	if err != nil {
+		return db.RiskDecision{}, err
+	}
+
+	if decision.Decision != string(risk.DecisionReview) || decision.ReviewStatus.Valid {
+		return db.RiskDecision{}, errors.New("transfer review already decided")
+	}
+
+	return decision, nil
+}
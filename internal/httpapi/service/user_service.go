@@ -3,24 +3,48 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
-	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/screening"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type UserService struct {
-	db db.Store
+	db          db.Store
+	screener    *screening.Screener
+	authorizer  *authz.Authorizer
+	policy      *passwordpolicy.Policy
+	hasher      *passwordhash.Hasher
+	revocations *revocation.List
 }
 
-func NewUserService(db db.Store) *UserService {
+func NewUserService(db db.Store, screener *screening.Screener, authorizer *authz.Authorizer, policy *passwordpolicy.Policy, hasher *passwordhash.Hasher, revocations *revocation.List) *UserService {
 	return &UserService{
-		db: db,
+		db:          db,
+		screener:    screener,
+		authorizer:  authorizer,
+		policy:      policy,
+		hasher:      hasher,
+		revocations: revocations,
 	}
 }
 
 func (u *UserService) CreateUser(ctx context.Context, request *request.CreateUserRequest) (response.UserResponseCreate, error) {
+	if err := u.policy.Validate(request.Password, request.Username, request.Email); err != nil {
+		return response.UserResponseCreate{}, err
+	}
+
 	// check if user already exists
 	user, err := u.db.GetUserByEmail(ctx, request.Email)
 	if err != nil {
@@ -52,27 +76,361 @@ func (u *UserService) CreateUser(ctx context.Context, request *request.CreateUse
 		}, errors.New("username already exists")
 	}
 
+	// screen the full name against the sanctions list
+	match, matched := u.screener.Screen(request.FullName)
+
 	// create user
-	hashPass, err := util.MakePasswordBcrypt(request.Password)
+	hashPass, err := u.hasher.Hash(request.Password)
 	if err != nil {
 		return response.UserResponseCreate{}, err
 	}
 	request.Password = hashPass
-	userCreate, err := u.db.CreateUserWithAccountTx(ctx, *request)
+
+	// a matching user is created but held until compliance clears the match
+	param := db.CreateUserWithAccountTxParam{User: *request}
+	if matched {
+		param.SanctionsMatch = &match
+	}
+	var result response.UserResponseCreate
+	err = u.db.ExecTx(ctx, func(store db.Store) error {
+		userCreate, err := store.CreateUserWithAccountTx(ctx, param)
+		if err != nil {
+			return err
+		}
+
+		complianceStatus := ""
+		if userCreate.ComplianceReview != nil {
+			complianceStatus = "pending_review"
+		}
+
+		result = response.UserResponseCreate{
+			UserUUID: userCreate.User.UserUUID,
+			Username: userCreate.User.Username,
+			FullName: userCreate.User.FullName,
+			Email:    userCreate.User.Email,
+			Account: response.AccountResponseSimple{
+				AccountUUID: userCreate.Account.AccountUUID,
+				Owner:       userCreate.Account.Owner,
+				Currency:    userCreate.Account.Currency,
+				Balance:     userCreate.Account.Balance,
+			},
+			ComplianceStatus: complianceStatus,
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionUserCreate,
+			EntityType: audit.EntityUser,
+			EntityID:   result.UserUUID,
+			After:      result,
+		})
+	})
 	if err != nil {
 		return response.UserResponseCreate{}, err
 	}
 
-	return response.UserResponseCreate{
-		UserUUID: userCreate.User.UserUUID,
-		Username: userCreate.User.Username,
-		FullName: userCreate.User.FullName,
-		Email:    userCreate.User.Email,
-		Account: response.AccountResponseSimple{
-			AccountUUID: userCreate.Account.AccountUUID,
-			Owner:       userCreate.Account.Owner,
-			Currency:    userCreate.Account.Currency,
-			Balance:     userCreate.Account.Balance,
-		},
-	}, nil
+	return result, nil
+}
+
+// ListUsers returns the users matching the search and filters of req with the total number of
+// matching users. The search matches part of the username, email or full name.
+func (u *UserService) ListUsers(ctx context.Context, req request.ListUserRequest) ([]response.UserAdminResponse, int64, error) {
+	filter := db.CountUsersParams{
+		Search: pgtype.Text{String: req.Search, Valid: req.Search != ""},
+		Role:   pgtype.Text{String: req.Role, Valid: req.Role != ""},
+	}
+	if req.Blocked != nil {
+		filter.IsBlocked = pgtype.Bool{Bool: *req.Blocked, Valid: true}
+	}
+
+	users, err := u.db.ListUsers(ctx, db.ListUsersParams{
+		Search:     filter.Search,
+		Role:       filter.Role,
+		IsBlocked:  filter.IsBlocked,
+		PageLimit:  req.Limit,
+		PageOffset: (req.Page - 1) * req.Limit,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	countTotal, err := u.db.CountUsers(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := []response.UserAdminResponse{}
+	for _, user := range users {
+		result = append(result, response.UserAdminResponse{
+			UserUUID:              user.UserUuid,
+			Username:              user.Username,
+			FullName:              user.FullName,
+			Email:                 user.Email,
+			Role:                  user.Role,
+			IsBlocked:             user.IsBlocked,
+			PasswordResetRequired: user.PasswordResetRequired,
+			EmailVerified:         !user.VerifiedEmailAt.IsZero(),
+			CreatedAt:             user.CreatedAt,
+		})
+	}
+
+	return result, countTotal, nil
+}
+
+// GetUserDetail returns a user with all of the user's accounts and sessions.
+func (u *UserService) GetUserDetail(ctx context.Context, userUUID uuid.UUID) (response.UserDetailResponse, error) {
+	user, err := u.db.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		return response.UserDetailResponse{}, err
+	}
+
+	accounts, err := u.db.GetAccountByUserUUIDMany(ctx, userUUID)
+	if err != nil {
+		return response.UserDetailResponse{}, err
+	}
+
+	sessions, err := u.db.ListSessionsByUser(ctx, userUUID)
+	if err != nil {
+		return response.UserDetailResponse{}, err
+	}
+
+	result := response.UserDetailResponse{
+		UserUUID:              user.UserUuid,
+		Username:              user.Username,
+		FullName:              user.FullName,
+		Email:                 user.Email,
+		Role:                  user.Role,
+		IsBlocked:             user.IsBlocked,
+		PasswordResetRequired: user.PasswordResetRequired,
+		CreatedAt:             user.CreatedAt,
+		Accounts:              []response.AccountResponseSimple{},
+		Sessions:              []response.UserSessionResponse{},
+	}
+	for _, account := range accounts {
+		result.Accounts = append(result.Accounts, response.AccountResponseSimple{
+			AccountUUID: account.AccountUuid,
+			Owner:       account.Owner,
+			Currency:    account.Currency,
+			Balance:     account.Balance.Int.String(),
+		})
+	}
+	for _, session := range sessions {
+		result.Sessions = append(result.Sessions, response.UserSessionResponse{
+			SessionID: session.ID,
+			UserAgent: session.UserAgent,
+			ClientIP:  session.ClientIp,
+			IsBlocked: session.IsBlocked,
+			ExpiresAt: session.ExpiresAt,
+			CreatedAt: session.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// BlockUser blocks a user from signing in and blocks all of the user's sessions, so no token
+// issued to the user can be used again.
+func (u *UserService) BlockUser(ctx context.Context, userUUID uuid.UUID, authPayload *token.Payload) (response.UserStatusResponse, error) {
+	before, err := u.getManagedUser(ctx, userUUID, authPayload)
+	if err != nil {
+		return response.UserStatusResponse{}, err
+	}
+
+	var result response.UserStatusResponse
+	err = u.db.ExecTx(ctx, func(store db.Store) error {
+		user, err := store.BlockUserTx(ctx, userUUID)
+		if err != nil {
+			return err
+		}
+
+		result = userStatusResponse(user)
+		return writeUserChange(ctx, store, audit.ActionUserBlock, before, result)
+	})
+	if err != nil {
+		return response.UserStatusResponse{}, err
+	}
+
+	if err := u.revocations.RevokeIssuedBefore(ctx, userUUID, time.Now()); err != nil {
+		return response.UserStatusResponse{}, err
+	}
+
+	return result, nil
+}
+
+// UnblockUser lets a blocked user sign in again. Sessions blocked along with the user stay
+// blocked.
+func (u *UserService) UnblockUser(ctx context.Context, userUUID uuid.UUID, authPayload *token.Payload) (response.UserStatusResponse, error) {
+	before, err := u.getManagedUser(ctx, userUUID, authPayload)
+	if err != nil {
+		return response.UserStatusResponse{}, err
+	}
+
+	var result response.UserStatusResponse
+	err = u.db.ExecTx(ctx, func(store db.Store) error {
+		user, err := store.UpdateUserBlocked(ctx, db.UpdateUserBlockedParams{
+			UserUuid:  userUUID,
+			IsBlocked: false,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = userStatusResponse(user)
+		return writeUserChange(ctx, store, audit.ActionUserUnblock, before, result)
+	})
+	if err != nil {
+		return response.UserStatusResponse{}, err
+	}
+
+	return result, nil
+}
+
+// ChangeUserRole changes the role of a user. Granting a role that holds users:manage:privileged
+// requires that permission too. The new role takes effect from the user's next token.
+func (u *UserService) ChangeUserRole(ctx context.Context, userUUID uuid.UUID, role string, authPayload *token.Payload) (response.UserStatusResponse, error) {
+	before, err := u.getManagedUser(ctx, userUUID, authPayload)
+	if err != nil {
+		return response.UserStatusResponse{}, err
+	}
+
+	if !u.canManageRole(ctx, authPayload.Role, role) {
+		return response.UserStatusResponse{}, errors.New("unauthorized")
+	}
+
+	var result response.UserStatusResponse
+	err = u.db.ExecTx(ctx, func(store db.Store) error {
+		user, err := store.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+			UserUuid: userUUID,
+			Role:     role,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = userStatusResponse(db.UpdateUserBlockedRow(user))
+		return writeUserChange(ctx, store, audit.ActionUserRoleChange, before, result)
+	})
+	if err != nil {
+		return response.UserStatusResponse{}, err
+	}
+
+	return result, nil
+}
+
+// ForcePasswordReset makes a user reset the password before signing in again, blocks all of the
+// user's sessions and revokes the access tokens issued so far.
+func (u *UserService) ForcePasswordReset(ctx context.Context, userUUID uuid.UUID, authPayload *token.Payload) (response.UserStatusResponse, error) {
+	before, err := u.getManagedUser(ctx, userUUID, authPayload)
+	if err != nil {
+		return response.UserStatusResponse{}, err
+	}
+
+	var result response.UserStatusResponse
+	err = u.db.ExecTx(ctx, func(store db.Store) error {
+		user, err := store.RequirePasswordResetTx(ctx, userUUID)
+		if err != nil {
+			return err
+		}
+
+		result = userStatusResponse(db.UpdateUserBlockedRow(user))
+		return writeUserChange(ctx, store, audit.ActionUserPasswordResetForce, before, result)
+	})
+	if err != nil {
+		return response.UserStatusResponse{}, err
+	}
+
+	if err := u.revocations.RevokeIssuedBefore(ctx, userUUID, time.Now()); err != nil {
+		return response.UserStatusResponse{}, err
+	}
+
+	return result, nil
+}
+
+// SetRoleMFARequirement makes two-factor authentication mandatory, or optional again, for every
+// user with role. Users of the role who have not enabled it are asked to enroll at their next login.
+func (u *UserService) SetRoleMFARequirement(ctx context.Context, role string, required bool) (response.RoleSettingResponse, error) {
+	before, err := u.db.GetRoleSetting(ctx, role)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return response.RoleSettingResponse{}, err
+	}
+
+	var result response.RoleSettingResponse
+	err = u.db.ExecTx(ctx, func(store db.Store) error {
+		setting, err := store.SetRoleMFARequired(ctx, db.SetRoleMFARequiredParams{
+			Role:        role,
+			MfaRequired: required,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = response.RoleSettingResponse{
+			Role:        setting.Role,
+			MFARequired: setting.MfaRequired,
+			UpdatedAt:   setting.UpdatedAt,
+		}
+
+		entry := audit.Entry{
+			Action:     audit.ActionRoleMFARequirementSet,
+			EntityType: audit.EntityRole,
+			EntityID:   role,
+			After:      result,
+		}
+		if before.Role != "" {
+			entry.Before = before
+		}
+		return audit.Write(ctx, store, entry)
+	})
+	if err != nil {
+		return response.RoleSettingResponse{}, err
+	}
+
+	return result, nil
+}
+
+// getManagedUser returns the user an admin is about to change. Admins cannot change themselves,
+// and users holding users:manage:privileged can only be changed by admins holding it too.
+func (u *UserService) getManagedUser(ctx context.Context, userUUID uuid.UUID, authPayload *token.Payload) (db.GetUserByUserUUIDRow, error) {
+	if userUUID == authPayload.UserUUID {
+		return db.GetUserByUserUUIDRow{}, errors.New("cannot manage own user")
+	}
+
+	user, err := u.db.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		return db.GetUserByUserUUIDRow{}, err
+	}
+
+	if !u.canManageRole(ctx, authPayload.Role, user.Role) {
+		return db.GetUserByUserUUIDRow{}, errors.New("unauthorized")
+	}
+
+	return user, nil
+}
+
+// canManageRole reports whether an admin with adminRole can manage users with role.
+func (u *UserService) canManageRole(ctx context.Context, adminRole, role string) bool {
+	return !u.authorizer.Can(ctx, role, authz.UsersManagePrivileged) || u.authorizer.Can(ctx, adminRole, authz.UsersManagePrivileged)
+}
+
+// writeUserChange writes the audit entry of an admin change to a user with store, which runs in
+// the transaction of the change.
+func writeUserChange(ctx context.Context, store db.Store, action string, before db.GetUserByUserUUIDRow, after response.UserStatusResponse) error {
+	return audit.Write(ctx, store, audit.Entry{
+		Action:     action,
+		EntityType: audit.EntityUser,
+		EntityID:   after.UserUUID.String(),
+		Before:     before,
+		After:      after,
+	})
+}
+
+// userStatusResponse converts the row returned by the user status updates, which all share the
+// columns of UpdateUserBlockedRow.
+func userStatusResponse(user db.UpdateUserBlockedRow) response.UserStatusResponse {
+	return response.UserStatusResponse{
+		UserUUID:              user.UserUuid,
+		Username:              user.Username,
+		Role:                  user.Role,
+		IsBlocked:             user.IsBlocked,
+		PasswordResetRequired: user.PasswordResetRequired,
+	}
 }
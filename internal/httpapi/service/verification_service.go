@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+)
+
+// VerifyEmail verifies the email address a verification code was emailed to.
+func (a *AuthService) VerifyEmail(ctx context.Context, code string) error {
+	_, err := a.verifier.Verify(ctx, code)
+	return err
+}
+
+// ResendVerificationEmail emails a new verification code to the authenticated user, replacing the
+// previous one. Codes are sent at most once per resend interval.
+func (a *AuthService) ResendVerificationEmail(ctx context.Context, authPayload *token.Payload) error {
+	return a.verifier.Resend(ctx, authPayload.UserUUID)
+}
+
+// emailVerified tells whether an address was verified at verifiedAt, the zero time meaning never.
+func emailVerified(verifiedAt time.Time) *bool {
+	verified := !verifiedAt.IsZero()
+	return &verified
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/request"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/response"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
+	"github.com/fajaramaulana/simple_bank_project/internal/webhook"
+	"github.com/google/uuid"
+)
+
+type WebhookService struct {
+	db         db.Store
+	authorizer *authz.Authorizer
+}
+
+func NewWebhookService(db db.Store, authorizer *authz.Authorizer) *WebhookService {
+	return &WebhookService{
+		db:         db,
+		authorizer: authorizer,
+	}
+}
+
+// CreateWebhook registers a webhook endpoint for the authenticated user. URLs pointing at internal
+// addresses return webhook.ErrForbiddenTarget. The generated signing secret is only returned here.
+func (w *WebhookService) CreateWebhook(ctx context.Context, request *request.CreateWebhookRequest, authPayload *token.Payload) (response.WebhookResponseCreate, error) {
+	if err := webhook.ValidateURL(request.URL); err != nil {
+		return response.WebhookResponseCreate{}, webhook.ErrForbiddenTarget
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return response.WebhookResponseCreate{}, err
+	}
+
+	var result response.WebhookResponseCreate
+	err = w.db.ExecTx(ctx, func(store db.Store) error {
+		subscription, err := store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+			UserUuid:   authPayload.UserUUID,
+			Url:        request.URL,
+			EventTypes: request.EventTypes,
+			Secret:     secret,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = response.WebhookResponseCreate{
+			WebhookResponse: webhookResponse(subscription),
+			Secret:          subscription.Secret,
+		}
+
+		// the signing secret is never written to the audit log
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionWebhookCreate,
+			EntityType: audit.EntityWebhook,
+			EntityID:   subscription.SubscriptionUuid.String(),
+			After:      result.WebhookResponse,
+		})
+	})
+	if err != nil {
+		return response.WebhookResponseCreate{}, err
+	}
+
+	return result, nil
+}
+
+func (w *WebhookService) ListWebhooks(ctx context.Context, authPayload *token.Payload) ([]response.WebhookResponse, error) {
+	subscriptions, err := w.db.ListWebhookSubscriptionsByUserUUID(ctx, authPayload.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []response.WebhookResponse{}
+	for _, subscription := range subscriptions {
+		result = append(result, webhookResponse(subscription))
+	}
+
+	return result, nil
+}
+
+func (w *WebhookService) DeleteWebhook(ctx context.Context, webhookUUID uuid.UUID, authPayload *token.Payload) error {
+	subscription, err := w.getWebhook(ctx, webhookUUID, authPayload)
+	if err != nil {
+		return err
+	}
+
+	return w.db.ExecTx(ctx, func(store db.Store) error {
+		_, err := store.SoftDeleteWebhookSubscription(ctx, subscription.SubscriptionUuid)
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, store, audit.Entry{
+			Action:     audit.ActionWebhookDelete,
+			EntityType: audit.EntityWebhook,
+			EntityID:   subscription.SubscriptionUuid.String(),
+			Before:     webhookResponse(subscription),
+		})
+	})
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first, with the total number of deliveries.
+func (w *WebhookService) ListDeliveries(ctx context.Context, webhookUUID uuid.UUID, req request.ListWebhookDeliveryRequest, authPayload *token.Payload) ([]response.WebhookDeliveryResponse, int64, error) {
+	subscription, err := w.getWebhook(ctx, webhookUUID, authPayload)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	deliveries, err := w.db.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          req.Limit,
+		Offset:         (req.Page - 1) * req.Limit,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	countTotal, err := w.db.CountWebhookDeliveries(ctx, subscription.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := []response.WebhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		result = append(result, webhookDeliveryResponse(delivery))
+	}
+
+	return result, countTotal, nil
+}
+
+// Redeliver queues a delivery to be sent again right away, including one that reached the dead-letter state.
+func (w *WebhookService) Redeliver(ctx context.Context, webhookUUID uuid.UUID, deliveryUUID uuid.UUID, authPayload *token.Payload) (response.WebhookDeliveryResponse, error) {
+	subscription, err := w.getWebhook(ctx, webhookUUID, authPayload)
+	if err != nil {
+		return response.WebhookDeliveryResponse{}, err
+	}
+
+	delivery, err := w.db.GetWebhookDeliveryByUUID(ctx, deliveryUUID)
+	if err != nil {
+		return response.WebhookDeliveryResponse{}, err
+	}
+
+	if delivery.SubscriptionID != subscription.ID {
+		return response.WebhookDeliveryResponse{}, errors.New("no rows in result set")
+	}
+
+	delivery, err = w.db.RedeliverWebhookDelivery(ctx, delivery.DeliveryUuid)
+	if err != nil {
+		return response.WebhookDeliveryResponse{}, err
+	}
+
+	return webhookDeliveryResponse(delivery), nil
+}
+
+// getWebhook returns the subscription if it belongs to the authenticated user or the user's role
+// has webhooks:manage:any.
+func (w *WebhookService) getWebhook(ctx context.Context, webhookUUID uuid.UUID, authPayload *token.Payload) (db.WebhookSubscription, error) {
+	subscription, err := w.db.GetWebhookSubscriptionByUUID(ctx, webhookUUID)
+	if err != nil {
+		return db.WebhookSubscription{}, err
+	}
+
+	if subscription.UserUuid != authPayload.UserUUID && !w.authorizer.Can(ctx, authPayload.Role, authz.WebhooksManageAny) {
+		return db.WebhookSubscription{}, errors.New("unauthorized")
+	}
+
+	return subscription, nil
+}
+
+func webhookResponse(subscription db.WebhookSubscription) response.WebhookResponse {
+	return response.WebhookResponse{
+		WebhookUUID: subscription.SubscriptionUuid,
+		URL:         subscription.Url,
+		EventTypes:  subscription.EventTypes,
+		CreatedAt:   subscription.CreatedAt,
+	}
+}
+
+func webhookDeliveryResponse(delivery db.WebhookDelivery) response.WebhookDeliveryResponse {
+	result := response.WebhookDeliveryResponse{
+		DeliveryUUID:  delivery.DeliveryUuid,
+		EventUUID:     delivery.EventUuid,
+		EventType:     delivery.EventType,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+	}
+
+	if delivery.LastStatusCode.Valid {
+		result.LastStatusCode = &delivery.LastStatusCode.Int32
+	}
+
+	if delivery.LastError.Valid {
+		result.LastError = &delivery.LastError.String
+	}
+
+	if delivery.DeliveredAt.Valid {
+		result.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+
+	return result
+}
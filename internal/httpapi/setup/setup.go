@@ -2,19 +2,39 @@ package setup
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"fmt"
 	"log"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/authz"
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	"github.com/fajaramaulana/simple_bank_project/internal/email"
+	"github.com/fajaramaulana/simple_bank_project/internal/emailverification"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/controller"
+	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/handler/token"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/router"
 	"github.com/fajaramaulana/simple_bank_project/internal/httpapi/service"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordreset"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/fajaramaulana/simple_bank_project/internal/risk"
+	"github.com/fajaramaulana/simple_bank_project/internal/screening"
+	"github.com/fajaramaulana/simple_bank_project/internal/servertls"
+	"github.com/fajaramaulana/simple_bank_project/internal/signingkey"
+	"github.com/fajaramaulana/simple_bank_project/internal/stepup"
 	"github.com/fajaramaulana/simple_bank_project/util"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 
 	_ "github.com/lib/pq"
 )
@@ -47,7 +67,7 @@ func DbConnection(config util.Config) *sql.DB {
 // If the 'users' table is empty, it inserts default user data into the table.
 // Then, it creates instances of various services, controllers, and the router.
 // Finally, it starts the server on the specified port.
-func InitializeAndStartAppHTTPApi(config util.Config, conn *pgxpool.Pool) {
+func InitializeAndStartAppHTTPApi(config util.Config, conn *pgxpool.Pool, redisClient *redis.Client, signingKeys *signingkey.KeySet) {
 
 	// checking table user is empty or not and return count
 	var count int
@@ -86,29 +106,124 @@ func InitializeAndStartAppHTTPApi(config util.Config, conn *pgxpool.Pool) {
 		"access_token_duration":  config.AccessTokenDuration.String(),
 		"refresh_token_duration": config.RefreshTokenDuration.String(),
 	}
+
+	// sanctions screening
+	sanctionsList, err := screening.LoadList(config.SanctionsListFile)
+	if err != nil {
+		log.Fatal("Cannot load sanctions list: ", err)
+	}
+	screener := screening.NewScreener(sanctionsList, config.SanctionsThreshold)
+
+	// permissions of every role are read from the role_permissions table
+	authorizer := authz.NewAuthorizer(store, config.PermissionCacheTTL)
+
 	// account
-	accountService := service.NewAccountService(store)
+	accountService := service.NewAccountService(store, authorizer)
 	accountController := controller.NewAccountController(accountService)
 
 	// transfer
-	transferService := service.NewTransactionService(store)
+	riskConfig, err := risk.LoadConfig(config.RiskRulesFile)
+	if err != nil {
+		log.Fatal("Cannot load risk rules: ", err)
+	}
+	riskEngine, err := risk.NewEngineFromConfig(store, riskConfig)
+	if err != nil {
+		log.Fatal("Cannot create risk engine: ", err)
+	}
+	// large transfers and credential changes need a recent authentication
+	stepUpPolicy := stepup.NewPolicy(stepup.Config{
+		Rules: map[string]stepup.Rule{
+			stepup.OperationTransfer:       {MaxAge: config.StepUpTransferMaxAge, MinAmount: config.StepUpTransferMinAmount},
+			stepup.OperationPasswordChange: {MaxAge: config.StepUpPasswordChangeMaxAge},
+			stepup.OperationEmailChange:    {MaxAge: config.StepUpEmailChangeMaxAge},
+		},
+	})
+	transferService := service.NewTransactionService(store, riskEngine, screener, stepUpPolicy)
 	transferController := controller.NewTransactionController(transferService)
 
 	// user
-	userService := service.NewUserService(store)
+	passwordHasher, err := passwordhash.NewHasher(passwordhash.Config{
+		Algorithm: config.PasswordHashAlgorithm,
+		Argon2id: passwordhash.Argon2idParams{
+			Memory:      config.PasswordArgon2Memory,
+			Iterations:  config.PasswordArgon2Iterations,
+			Parallelism: config.PasswordArgon2Parallelism,
+		},
+		BcryptCost: config.PasswordBcryptCost,
+	})
+	if err != nil {
+		log.Fatal("Cannot create password hasher: ", err)
+	}
+	passwordPolicy := passwordpolicy.NewPolicy(passwordpolicy.Config{
+		MinLength:           config.PasswordMinLength,
+		MinCharacterClasses: config.PasswordMinCharacterClasses,
+		HistorySize:         config.PasswordHistorySize,
+	}, passwordHasher)
+	// access tokens revoked before they expire
+	revocations := revocation.NewList(redisClient, revocation.Config{
+		TokenLifetime: config.AccessTokenDuration,
+		CacheTTL:      config.TokenRevocationCacheTTL,
+	})
+	userService := service.NewUserService(store, screener, authorizer, passwordPolicy, passwordHasher, revocations)
 	userController := controller.NewUserController(userService)
 
 	// auth
-	authService := service.NewAuthService(store, configToken)
+	emails := email.NewRedisQueue(redisClient)
+	loginGuard := lockout.NewGuard(redisClient, store, emails, lockout.Config{
+		MaxFailures:  config.LoginMaxFailures,
+		LockDuration: config.LoginLockoutDuration,
+	})
+	tokenMaker, err := token.NewMaker(config.TokenSigningAlgorithm, config.TokenSymmetricKey, signingKeys)
+	if err != nil {
+		log.Fatal("Cannot create token maker: ", err)
+	}
+	emailVerifier := emailverification.NewVerifier(store, emails, emailverification.Config{
+		Required:       config.EmailVerificationRequired,
+		ResendInterval: config.EmailVerificationResendInterval,
+	})
+	deviceRegistry := device.NewRegistry(redisClient, store, emails, device.Config{
+		RequireConfirmation: config.DeviceConfirmationRequired,
+		ConfirmationCodeTTL: config.DeviceConfirmationCodeTTL,
+	})
+	resetLimiter := passwordreset.NewLimiter(redisClient, passwordreset.LimitConfig{
+		AddressInterval: config.PasswordResetRequestInterval,
+		MaxPerIP:        config.PasswordResetMaxRequestsPerIP,
+	})
+	authService := service.NewAuthService(store, configToken, tokenMaker, emails, loginGuard, passwordPolicy, passwordHasher, emailVerifier, stepup.NewAuthenticator(store, passwordHasher, loginGuard), deviceRegistry, revocations, resetLimiter)
 	authController := controller.NewAuthController(authService)
 
-	server, err := router.NewRouter(accountController, transferController, userController, authController, configToken)
+	// webhook
+	webhookService := service.NewWebhookService(store, authorizer)
+	webhookController := controller.NewWebhookController(webhookService)
+
+	// compliance
+	complianceService := service.NewComplianceService(store, riskEngine)
+	complianceController := controller.NewComplianceController(complianceService)
+
+	// audit
+	auditService := service.NewAuditService(store)
+	auditController := controller.NewAuditController(auditService)
+
+	server, err := router.NewRouter(accountController, transferController, userController, authController, webhookController, complianceController, auditController, authorizer, tokenMaker, signingKeys, store, emailVerifier, revocations)
 	if err != nil {
 		log.Fatal("Cannot create router: ", err)
 	}
 
+	var tlsConfig *tls.Config
+	if config.HTTPTLSCertFile != "" {
+		certs, err := servertls.New(servertls.Config{
+			CertFile:       config.HTTPTLSCertFile,
+			KeyFile:        config.HTTPTLSKeyFile,
+			ReloadInterval: config.TLSReloadInterval,
+		})
+		if err != nil {
+			log.Fatal("Cannot load TLS certificate: ", err)
+		}
+		tlsConfig = certs.ServerConfig()
+	}
+
 	PORT := config.Port
-	server.StartServer(PORT)
+	server.StartServer(PORT, tlsConfig)
 }
 
 // InitializeAndStartAppTest initializes and starts the test application with the given store.
@@ -118,6 +233,12 @@ func InitializeAndStartAppHTTPApi(config util.Config, conn *pgxpool.Pool) {
 func InitializeAndStartAppTest(t *testing.T, store db.Store) *router.Router {
 	// Check environment variables
 
+	// a mocked store runs the transactions of the services on itself
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().ExecTx(gomock.Any(), gomock.Any()).AnyTimes().
+			DoAndReturn(func(_ context.Context, fn func(db.Store) error) error { return fn(mockStore) })
+	}
+
 	// Config token
 	configToken := map[string]string{
 		"token_secret":           util.RandomString(32),
@@ -126,20 +247,47 @@ func InitializeAndStartAppTest(t *testing.T, store db.Store) *router.Router {
 	}
 
 	// Initialize services and controllers
-	accountService := service.NewAccountService(store)
+	screener := screening.NewScreener(&screening.List{}, 0)
+	authorizer := authz.NewAuthorizer(authz.DefaultRolePermissions, 0)
+
+	accountService := service.NewAccountService(store, authorizer)
 	accountController := controller.NewAccountController(accountService)
 
-	transferService := service.NewTransactionService(store)
+	riskEngine, err := risk.NewEngineFromConfig(store, risk.DefaultConfig())
+	require.NoError(t, err)
+	transferService := service.NewTransactionService(store, riskEngine, screener, stepup.NewPolicy(stepup.DefaultConfig()))
 	transferController := controller.NewTransactionController(transferService)
 
-	userService := service.NewUserService(store)
+	// bcrypt keeps hashing fast in tests
+	passwordHasher, err := passwordhash.NewHasher(passwordhash.Config{Algorithm: passwordhash.AlgorithmBcrypt})
+	require.NoError(t, err)
+	passwordPolicy := passwordpolicy.NewPolicy(passwordpolicy.DefaultConfig(), passwordHasher)
+	// no emails are queued in tests, failed logins and revoked tokens are kept in an in-memory redis
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	revocations := revocation.NewList(rdb, revocation.DefaultConfig())
+	userService := service.NewUserService(store, screener, authorizer, passwordPolicy, passwordHasher, revocations)
 	userController := controller.NewUserController(userService)
 
-	authService := service.NewAuthService(store, configToken)
+	loginGuard := lockout.NewGuard(rdb, store, nil, lockout.DefaultConfig())
+	tokenMaker, err := token.NewPasetoMaker(configToken["token_secret"])
+	require.NoError(t, err)
+	emailVerifier := emailverification.NewVerifier(store, nil, emailverification.DefaultConfig())
+	resetLimiter := passwordreset.NewLimiter(rdb, passwordreset.DefaultLimitConfig())
+	authService := service.NewAuthService(store, configToken, tokenMaker, nil, loginGuard, passwordPolicy, passwordHasher, emailVerifier, stepup.NewAuthenticator(store, passwordHasher, loginGuard), device.NewRegistry(rdb, store, nil, device.DefaultConfig()), revocations, resetLimiter)
 	authController := controller.NewAuthController(authService)
 
+	webhookService := service.NewWebhookService(store, authorizer)
+	webhookController := controller.NewWebhookController(webhookService)
+
+	complianceService := service.NewComplianceService(store, riskEngine)
+	complianceController := controller.NewComplianceController(complianceService)
+
+	// audit
+	auditService := service.NewAuditService(store)
+	auditController := controller.NewAuditController(auditService)
+
 	// Create router
-	server, err := router.NewRouter(accountController, transferController, userController, authController, configToken)
+	server, err := router.NewRouter(accountController, transferController, userController, authController, webhookController, complianceController, auditController, authorizer, tokenMaker, nil, store, emailVerifier, revocations)
 	require.NoError(t, err)
 
 	return server
@@ -0,0 +1,323 @@
+// Package lockout protects the login against guessing of passwords and two-factor codes. Failed
+// attempts are counted in Redis per username and per client IP. Every failure makes the next
+// attempt wait longer, and too many failures lock the username, or the IP, out for a while. A
+// locked user is emailed a code that unlocks the login right away.
+package lockout
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/email"
+	"github.com/fajaramaulana/simple_bank_project/internal/securityevent"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	attemptsKeyPrefix = "login_attempts:"
+	unlockKeyPrefix   = "login_unlock:"
+
+	fieldFailures    = "failures"
+	fieldNextAttempt = "next_attempt_at"
+	fieldLockedUntil = "locked_until"
+
+	// unlockCodeSize is the length of an unlock code in random bytes.
+	unlockCodeSize = 16
+)
+
+// ErrInvalidUnlockCode is returned when an unlock code is unknown, used up or expired.
+var ErrInvalidUnlockCode = errors.New("invalid or expired unlock code")
+
+// LockedError is returned when a login must not be attempted yet. It is either a short delay after
+// a failure, or a lockout after too many failures.
+type LockedError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LockedError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed login attempts, locked for %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("too many failed login attempts, retry in %s", e.RetryAfter)
+}
+
+// Config tunes the protection. Zero values fall back to DefaultConfig.
+type Config struct {
+	// MaxFailures is the number of failures in a row that locks a username.
+	MaxFailures int
+	// MaxIPFailures is the number of failures in a row that locks a client IP. It is higher than
+	// MaxFailures because many users can share an address.
+	MaxIPFailures int
+	// LockDuration is how long a lockout lasts.
+	LockDuration time.Duration
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+	// BaseDelay is the delay after the first failure that is slowed down, doubled after every
+	// further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// UnlockCodeTTL is how long the emailed unlock code is valid.
+	UnlockCodeTTL time.Duration
+}
+
+// DefaultConfig returns the protection used unless configured otherwise.
+func DefaultConfig() Config {
+	return Config{
+		MaxFailures:   5,
+		MaxIPFailures: 20,
+		LockDuration:  15 * time.Minute,
+		Window:        15 * time.Minute,
+		BaseDelay:     time.Second,
+		MaxDelay:      30 * time.Second,
+		UnlockCodeTTL: 24 * time.Hour,
+	}
+}
+
+// Store is the data the guard writes. db.Store satisfies it.
+type Store interface {
+	securityevent.Store
+}
+
+// Attempt identifies a login attempt.
+type Attempt struct {
+	Username  string
+	ClientIP  string
+	UserAgent string
+}
+
+// User is the account a username belongs to, if any.
+type User struct {
+	UUID  uuid.UUID
+	Email string
+}
+
+// Guard counts failed logins and decides when a login may be attempted again.
+type Guard struct {
+	rdb    *redis.Client
+	store  Store
+	emails email.Queue
+	config Config
+}
+
+// NewGuard creates a guard that keeps its counters in rdb.
+func NewGuard(rdb *redis.Client, store Store, emails email.Queue, config Config) *Guard {
+	defaults := DefaultConfig()
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = defaults.MaxFailures
+	}
+	if config.MaxIPFailures <= 0 {
+		config.MaxIPFailures = defaults.MaxIPFailures
+	}
+	if config.LockDuration <= 0 {
+		config.LockDuration = defaults.LockDuration
+	}
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaults.BaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaults.MaxDelay
+	}
+	if config.UnlockCodeTTL <= 0 {
+		config.UnlockCodeTTL = defaults.UnlockCodeTTL
+	}
+
+	return &Guard{rdb: rdb, store: store, emails: emails, config: config}
+}
+
+// Check returns a *LockedError when attempt has to wait, because of its username or its client IP.
+func (g *Guard) Check(ctx context.Context, attempt Attempt) error {
+	now := time.Now()
+
+	var wait *LockedError
+	for _, key := range []string{userKey(attempt.Username), ipKey(attempt.ClientIP)} {
+		values, err := g.rdb.HMGet(ctx, key, fieldLockedUntil, fieldNextAttempt).Result()
+		if err != nil {
+			return err
+		}
+
+		if until := unixMilli(values[0]); until.After(now) {
+			return &LockedError{RetryAfter: until.Sub(now).Round(time.Second), Locked: true}
+		}
+		if next := unixMilli(values[1]); next.After(now) && (wait == nil || next.Sub(now) > wait.RetryAfter) {
+			wait = &LockedError{RetryAfter: next.Sub(now).Round(time.Second)}
+		}
+	}
+
+	if wait != nil {
+		if wait.RetryAfter < time.Second {
+			wait.RetryAfter = time.Second
+		}
+		return wait
+	}
+	return nil
+}
+
+// Fail counts a failed attempt. When it locks the username of user, the lockout is recorded in the
+// user's security history and the user is emailed an unlock code. user is nil for unknown usernames,
+// which are counted all the same so they cannot be told apart.
+func (g *Guard) Fail(ctx context.Context, attempt Attempt, user *User) error {
+	_, err := g.fail(ctx, ipKey(attempt.ClientIP), g.config.MaxIPFailures, g.config.MaxFailures)
+	if err != nil {
+		return err
+	}
+
+	locked, err := g.fail(ctx, userKey(attempt.Username), g.config.MaxFailures, 1)
+	if err != nil || !locked || user == nil {
+		return err
+	}
+
+	securityevent.Record(ctx, g.store, securityevent.Event{
+		UserUUID:  user.UUID,
+		Type:      securityevent.TypeLoginLocked,
+		ClientIP:  attempt.ClientIP,
+		UserAgent: attempt.UserAgent,
+	})
+
+	return g.sendUnlockCode(ctx, attempt.Username, *user)
+}
+
+// Succeed forgets the failures of the username of attempt after a successful login.
+func (g *Guard) Succeed(ctx context.Context, attempt Attempt) error {
+	return g.rdb.Del(ctx, userKey(attempt.Username)).Err()
+}
+
+// Unlock lifts the lockout of the user the emailed code was issued for. The code works once.
+func (g *Guard) Unlock(ctx context.Context, code, clientIP, userAgent string) error {
+	data, err := g.rdb.GetDel(ctx, unlockKey(code)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ErrInvalidUnlockCode
+		}
+		return err
+	}
+
+	var unlock unlockData
+	if err := json.Unmarshal([]byte(data), &unlock); err != nil {
+		return err
+	}
+
+	if err := g.rdb.Del(ctx, userKey(unlock.Username)).Err(); err != nil {
+		return err
+	}
+
+	securityevent.Record(ctx, g.store, securityevent.Event{
+		UserUUID:  unlock.UserUUID,
+		Type:      securityevent.TypeLoginUnlocked,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+	})
+
+	return nil
+}
+
+type unlockData struct {
+	UserUUID uuid.UUID `json:"user_uuid"`
+	Username string    `json:"username"`
+}
+
+func (g *Guard) sendUnlockCode(ctx context.Context, username string, user User) error {
+	raw := make([]byte, unlockCodeSize)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	code := hex.EncodeToString(raw)
+
+	data, err := json.Marshal(unlockData{UserUUID: user.UUID, Username: username})
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(g.config.UnlockCodeTTL)
+	if err := g.rdb.Set(ctx, unlockKey(code), data, g.config.UnlockCodeTTL).Err(); err != nil {
+		return err
+	}
+
+	return g.emails.QueueAccountUnlock(ctx, user.UUID, user.Email, code, expiresAt)
+}
+
+// fail counts a failure of key and sets when the next attempt is allowed. The first free failures
+// are not slowed down. It reports whether the failure locked key.
+func (g *Guard) fail(ctx context.Context, key string, maxFailures, free int) (bool, error) {
+	var failures *redis.IntCmd
+	_, err := g.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		failures = pipe.HIncrBy(ctx, key, fieldFailures, 1)
+		pipe.Expire(ctx, key, g.config.Window)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	n := int(failures.Val())
+
+	if n >= maxFailures {
+		// start counting again once the lockout is over
+		_, err = g.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, fieldFailures, 0, fieldLockedUntil, now.Add(g.config.LockDuration).UnixMilli())
+			pipe.Expire(ctx, key, g.config.LockDuration)
+			return nil
+		})
+		return err == nil, err
+	}
+
+	if delay := g.delay(n, free); delay > 0 {
+		return false, g.rdb.HSet(ctx, key, fieldNextAttempt, now.Add(delay).UnixMilli()).Err()
+	}
+	return false, nil
+}
+
+// delay returns how long to wait after the nth failure in a row.
+func (g *Guard) delay(n, free int) time.Duration {
+	if n <= free {
+		return 0
+	}
+
+	delay := g.config.BaseDelay
+	for i := free + 1; i < n && delay < g.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > g.config.MaxDelay {
+		delay = g.config.MaxDelay
+	}
+	return delay
+}
+
+func userKey(username string) string {
+	// usernames are looked up case sensitively, but guessing should not get a fresh counter per casing
+	return attemptsKeyPrefix + "user:" + strings.ToLower(username)
+}
+
+func ipKey(clientIP string) string {
+	return attemptsKeyPrefix + "ip:" + clientIP
+}
+
+func unlockKey(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return unlockKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+func unixMilli(value interface{}) time.Time {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}
+	}
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
@@ -0,0 +1,161 @@
+package lockout_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/securityevent"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// emailQueue records the queued unlock codes instead of sending them.
+type emailQueue struct {
+	codes map[uuid.UUID]string
+}
+
+func (q *emailQueue) QueueVerification(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueuePasswordReset(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueueAccountUnlock(_ context.Context, userUUID uuid.UUID, _, code string, _ time.Time) error {
+	if q.codes == nil {
+		q.codes = make(map[uuid.UUID]string)
+	}
+	q.codes[userUUID] = code
+	return nil
+}
+
+func (q *emailQueue) QueueDeviceConfirmation(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueueNewDeviceAlert(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+// newGuard returns a guard whose delay outlasts a check right after a failure, but is over well
+// within waitOut.
+func newGuard(t *testing.T, store lockout.Store, queue *emailQueue) *lockout.Guard {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	return lockout.NewGuard(rdb, store, queue, lockout.Config{
+		MaxFailures:   3,
+		MaxIPFailures: 10,
+		BaseDelay:     200 * time.Millisecond,
+		MaxDelay:      200 * time.Millisecond,
+	})
+}
+
+// waitOut fails the test unless the delay after a failure is over soon.
+func waitOut(t *testing.T, guard *lockout.Guard, attempt lockout.Attempt) {
+	require.Eventually(t, func() bool {
+		return guard.Check(context.Background(), attempt) == nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestGuardDelaysRepeatedFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	guard := newGuard(t, mockdb.NewMockStore(ctrl), &emailQueue{})
+	attempt := lockout.Attempt{Username: util.RandomUsername(), ClientIP: "10.0.0.1"}
+	ctx := context.Background()
+
+	require.NoError(t, guard.Check(ctx, attempt))
+
+	// the first failure is not slowed down
+	require.NoError(t, guard.Fail(ctx, attempt, nil))
+	require.NoError(t, guard.Check(ctx, attempt))
+
+	require.NoError(t, guard.Fail(ctx, attempt, nil))
+	var lockedErr *lockout.LockedError
+	require.True(t, errors.As(guard.Check(ctx, attempt), &lockedErr))
+	require.False(t, lockedErr.Locked)
+	require.Equal(t, time.Second, lockedErr.RetryAfter)
+
+	waitOut(t, guard, attempt)
+}
+
+func TestGuardLocksAndUnlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	queue := &emailQueue{}
+	guard := newGuard(t, store, queue)
+	user := lockout.User{UUID: util.RandomUUID(), Email: util.RandomEmail()}
+	attempt := lockout.Attempt{Username: util.RandomUsername(), ClientIP: "10.0.0.2", UserAgent: "test"}
+	ctx := context.Background()
+
+	gomock.InOrder(
+		store.EXPECT().CreateSecurityEvent(gomock.Any(), db.CreateSecurityEventParams{
+			UserUuid:  user.UUID,
+			EventType: securityevent.TypeLoginLocked,
+			ClientIp:  attempt.ClientIP,
+			UserAgent: attempt.UserAgent,
+		}).Times(1),
+		store.EXPECT().CreateSecurityEvent(gomock.Any(), db.CreateSecurityEventParams{
+			UserUuid:  user.UUID,
+			EventType: securityevent.TypeLoginUnlocked,
+			ClientIp:  "10.0.0.3",
+			UserAgent: "browser",
+		}).Times(1),
+	)
+
+	for i := 0; i < 3; i++ {
+		waitOut(t, guard, attempt)
+		require.NoError(t, guard.Fail(ctx, attempt, &user))
+	}
+
+	var lockedErr *lockout.LockedError
+	require.True(t, errors.As(guard.Check(ctx, attempt), &lockedErr))
+	require.True(t, lockedErr.Locked)
+	require.Greater(t, lockedErr.RetryAfter, 14*time.Minute)
+
+	code := queue.codes[user.UUID]
+	require.NotEmpty(t, code)
+
+	require.ErrorIs(t, guard.Unlock(ctx, "not-a-code", "10.0.0.3", "browser"), lockout.ErrInvalidUnlockCode)
+	require.NoError(t, guard.Unlock(ctx, code, "10.0.0.3", "browser"))
+	require.NoError(t, guard.Check(ctx, attempt))
+
+	// the code works once
+	require.ErrorIs(t, guard.Unlock(ctx, code, "10.0.0.3", "browser"), lockout.ErrInvalidUnlockCode)
+}
+
+func TestGuardCountsUsernamesCaseInsensitively(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	guard := newGuard(t, mockdb.NewMockStore(ctrl), &emailQueue{})
+	ctx := context.Background()
+
+	require.NoError(t, guard.Fail(ctx, lockout.Attempt{Username: "Alice", ClientIP: "10.0.0.4"}, nil))
+	require.NoError(t, guard.Fail(ctx, lockout.Attempt{Username: "alice", ClientIP: "10.0.0.5"}, nil))
+
+	var lockedErr *lockout.LockedError
+	require.True(t, errors.As(guard.Check(ctx, lockout.Attempt{Username: "ALICE", ClientIP: "10.0.0.6"}), &lockedErr))
+}
+
+func TestGuardSucceedClearsFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	guard := newGuard(t, mockdb.NewMockStore(ctrl), &emailQueue{})
+	attempt := lockout.Attempt{Username: util.RandomUsername(), ClientIP: "10.0.0.7"}
+	ctx := context.Background()
+
+	require.NoError(t, guard.Fail(ctx, attempt, nil))
+	require.NoError(t, guard.Fail(ctx, attempt, nil))
+	waitOut(t, guard, attempt)
+	require.NoError(t, guard.Succeed(ctx, attempt))
+
+	// counting starts over, so the next failure is free again
+	require.NoError(t, guard.Fail(ctx, attempt, nil))
+	require.NoError(t, guard.Check(ctx, attempt))
+}
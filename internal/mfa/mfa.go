@@ -0,0 +1,210 @@
+// Package mfa implements two-factor authentication with RFC 6238 time-based one-time passwords
+// and the single-use recovery codes that stand in for them.
+package mfa
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// Period is how long a one-time password is valid.
+	Period = 30 * time.Second
+	// Digits is the length of a one-time password.
+	Digits = 6
+	// skew is the number of periods a code may be behind or ahead, to allow for clock drift.
+	skew = 1
+	// secretSize is the length of a generated secret in bytes, the size of an HMAC-SHA1 key.
+	secretSize = 20
+
+	// RecoveryCodeCount is the number of recovery codes issued at once.
+	RecoveryCodeCount = 10
+	// recoveryCodeSize is the length of a recovery code in random bytes, 16 base32 characters.
+	recoveryCodeSize = 10
+)
+
+var (
+	// ErrInvalidCode is returned when a code matches neither the one-time password nor an unused recovery code.
+	ErrInvalidCode = errors.New("invalid two-factor code")
+	// ErrNotEnabled is returned when the user has not enabled two-factor authentication.
+	ErrNotEnabled = errors.New("two-factor authentication is not enabled")
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random base32 encoded secret.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(secret), nil
+}
+
+// URI returns the otpauth URI authenticator apps read, usually from a QR code, to add secret.
+func URI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// Step returns the time step t falls in.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// Code returns the one-time password of secret for step.
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%mod), nil
+}
+
+// Validate checks code against secret at time now and returns the step it matched.
+func Validate(secret, code string, now time.Time) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(now)
+	for step := current - skew; step <= current+skew; step++ {
+		expected, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns n new recovery codes formatted as xxxxxxxx-xxxxxxxx.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		raw := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(raw))
+		codes = append(codes, code[:len(code)/2]+"-"+code[len(code)/2:])
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash a recovery code is stored as. Recovery codes are random
+// enough that a fast hash is sufficient, which lets them be looked up by hash.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashRecoveryCodes hashes every code with HashRecoveryCode.
+func HashRecoveryCodes(codes []string) []string {
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return hashes
+}
+
+// Store is the data verification writes. db.Store satisfies it.
+type Store interface {
+	GetUserTOTP(ctx context.Context, userUuid uuid.UUID) (db.UserTotp, error)
+	UseTOTPStep(ctx context.Context, arg db.UseTOTPStepParams) (int64, error)
+	UseRecoveryCode(ctx context.Context, arg db.UseRecoveryCodeParams) (int64, error)
+}
+
+// Enabled reports whether the user has confirmed a TOTP secret.
+func Enabled(ctx context.Context, store Store, userUUID uuid.UUID) (bool, error) {
+	totp, err := store.GetUserTOTP(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return totp.ConfirmedAt.Valid, nil
+}
+
+// VerifyCode checks code against the confirmed TOTP secret of totp, or else uses it up as one of
+// the unused recovery codes of the user. A one-time password is only accepted once.
+func VerifyCode(ctx context.Context, store Store, totp db.UserTotp, code string) error {
+	if !totp.ConfirmedAt.Valid {
+		return ErrNotEnabled
+	}
+
+	if step, ok := Validate(totp.Secret, code, time.Now()); ok {
+		used, err := store.UseTOTPStep(ctx, db.UseTOTPStepParams{
+			UserUuid:     totp.UserUuid,
+			LastUsedStep: step,
+		})
+		if err != nil {
+			return err
+		}
+		if used == 0 {
+			// the code, or a later one, was already used
+			return ErrInvalidCode
+		}
+		return nil
+	}
+
+	used, err := store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		UserUuid: totp.UserUuid,
+		CodeHash: HashRecoveryCode(code),
+	})
+	if err != nil {
+		return err
+	}
+	if used == 0 {
+		return ErrInvalidCode
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package mfa
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 secret of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B lists 8-digit codes, a 6-digit code is their last 6 digits
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, tc := range testCases {
+		code, err := Code(rfcSecret, Step(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, tc.code, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := Code(secret, Step(now))
+	require.NoError(t, err)
+
+	step, ok := Validate(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, Step(now), step)
+
+	// codes of the neighbouring periods are accepted for clock drift
+	_, ok = Validate(secret, code, now.Add(Period))
+	require.True(t, ok)
+
+	_, ok = Validate(secret, code, now.Add(3*Period))
+	require.False(t, ok)
+
+	_, ok = Validate(secret, "12345", now)
+	require.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Simple Bank", "alice", rfcSecret)
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/Simple%20Bank:alice?"))
+	require.Contains(t, uri, "secret="+rfcSecret)
+	require.Contains(t, uri, "digits=6")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		require.Len(t, code, 17)
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+
+	// hashing ignores case and the separator
+	code := codes[0]
+	require.Equal(t, HashRecoveryCode(code), HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(code, "-", ""))))
+	require.Equal(t, HashRecoveryCodes(codes)[0], HashRecoveryCode(code))
+}
@@ -0,0 +1,56 @@
+package passwordhash
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// defaultBcryptCost is the cost of bcrypt hashes unless configured otherwise.
+const defaultBcryptCost = bcrypt.DefaultCost
+
+type bcryptAlgorithm struct {
+	cost int
+}
+
+// NewBcrypt creates the bcrypt algorithm hashing with cost, or the default cost when it is zero.
+// Its hashes use the modular crypt format bcrypt always had, $2a$<cost>$<salt and key>.
+func NewBcrypt(cost int) Algorithm {
+	if cost == 0 {
+		cost = defaultBcryptCost
+	}
+
+	return &bcryptAlgorithm{cost: cost}
+}
+
+func (b *bcryptAlgorithm) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashed), nil
+}
+
+func (b *bcryptAlgorithm) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b *bcryptAlgorithm) Verify(password, encoded string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatchedPassword
+	}
+
+	return err
+}
+
+func (b *bcryptAlgorithm) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true
+	}
+
+	return cost != b.cost
+}
@@ -0,0 +1,119 @@
+// Package passwordhash hashes passwords into strings that name the algorithm and its parameters,
+// in the PHC string format, so stored hashes can be verified after the defaults change. New
+// passwords are hashed with the preferred algorithm, and hashes made with an older algorithm or
+// weaker parameters are reported so they can be replaced at the next successful login.
+package passwordhash
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Algorithm names accepted in Config.
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	// ErrMismatchedPassword is returned when a password does not match a hash.
+	ErrMismatchedPassword = errors.New("password does not match")
+	// ErrUnknownHash is returned for a hash no configured algorithm understands.
+	ErrUnknownHash = errors.New("unknown password hash format")
+)
+
+// Algorithm hashes passwords with one algorithm. Implementations recognise their own hashes, so
+// several algorithms can verify the hashes of a single table.
+type Algorithm interface {
+	// Hash returns the encoded hash of password with a new random salt.
+	Hash(password string) (string, error)
+	// Identifies reports whether encoded was made by this algorithm.
+	Identifies(encoded string) bool
+	// Verify checks password against encoded, returning ErrMismatchedPassword when it differs.
+	Verify(password, encoded string) error
+	// NeedsRehash reports whether encoded was made with other parameters than Hash uses now.
+	NeedsRehash(encoded string) bool
+}
+
+// Config selects the algorithm new passwords are hashed with. Zero values fall back to
+// DefaultConfig.
+type Config struct {
+	// Algorithm is AlgorithmArgon2id or AlgorithmBcrypt.
+	Algorithm string
+	Argon2id  Argon2idParams
+	// BcryptCost is the cost of new bcrypt hashes.
+	BcryptCost int
+}
+
+// DefaultConfig returns the hashing used unless configured otherwise.
+func DefaultConfig() Config {
+	return Config{
+		Algorithm:  AlgorithmArgon2id,
+		Argon2id:   DefaultArgon2idParams(),
+		BcryptCost: defaultBcryptCost,
+	}
+}
+
+// Hasher hashes new passwords with a preferred algorithm and verifies hashes of every algorithm it
+// knows.
+type Hasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+}
+
+// New creates a hasher hashing with preferred and verifying hashes of preferred and others.
+func New(preferred Algorithm, others ...Algorithm) *Hasher {
+	return &Hasher{
+		preferred:  preferred,
+		algorithms: append([]Algorithm{preferred}, others...),
+	}
+}
+
+// NewHasher creates a hasher preferring the algorithm of config and verifying argon2id and bcrypt
+// hashes.
+func NewHasher(config Config) (*Hasher, error) {
+	defaults := DefaultConfig()
+	if config.Algorithm == "" {
+		config.Algorithm = defaults.Algorithm
+	}
+
+	argon2id := NewArgon2id(config.Argon2id)
+	bcrypt := NewBcrypt(config.BcryptCost)
+
+	switch config.Algorithm {
+	case AlgorithmArgon2id:
+		return New(argon2id, bcrypt), nil
+	case AlgorithmBcrypt:
+		return New(bcrypt, argon2id), nil
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", config.Algorithm)
+	}
+}
+
+// Hash returns the encoded hash of password with the preferred algorithm.
+func (h *Hasher) Hash(password string) (string, error) {
+	if len(password) == 0 {
+		return "", errors.New("password can't be empty")
+	}
+
+	return h.preferred.Hash(password)
+}
+
+// Verify checks password against encoded. When it matches, rehash tells whether encoded should be
+// replaced by a new hash of password, because it was made with another algorithm than the
+// preferred one or with outdated parameters.
+func (h *Hasher) Verify(password, encoded string) (rehash bool, err error) {
+	for _, algorithm := range h.algorithms {
+		if !algorithm.Identifies(encoded) {
+			continue
+		}
+
+		if err := algorithm.Verify(password, encoded); err != nil {
+			return false, err
+		}
+
+		return algorithm != h.preferred || algorithm.NeedsRehash(encoded), nil
+	}
+
+	return false, ErrUnknownHash
+}
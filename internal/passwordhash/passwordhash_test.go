@@ -0,0 +1,110 @@
+package passwordhash_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cheapArgon2id keeps the tests fast.
+var cheapArgon2id = passwordhash.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestHashArgon2id(t *testing.T) {
+	hasher, err := passwordhash.NewHasher(passwordhash.Config{Argon2id: cheapArgon2id})
+	require.NoError(t, err)
+
+	password := util.RandomWord()
+	hash1, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hash1, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	hash2, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.NotEqual(t, hash1, hash2)
+
+	rehash, err := hasher.Verify(password, hash1)
+	require.NoError(t, err)
+	require.False(t, rehash)
+
+	_, err = hasher.Verify(util.RandomWord()+"x", hash1)
+	require.ErrorIs(t, err, passwordhash.ErrMismatchedPassword)
+
+	_, err = hasher.Hash("")
+	require.Error(t, err)
+}
+
+func TestVerifyBcryptWithArgon2idPreferred(t *testing.T) {
+	hasher, err := passwordhash.NewHasher(passwordhash.Config{Argon2id: cheapArgon2id})
+	require.NoError(t, err)
+
+	password := util.RandomWord()
+	hash, err := util.MakePasswordBcrypt(password)
+	require.NoError(t, err)
+
+	rehash, err := hasher.Verify(password, hash)
+	require.NoError(t, err)
+	require.True(t, rehash)
+
+	rehash, err = hasher.Verify(password+"x", hash)
+	require.ErrorIs(t, err, passwordhash.ErrMismatchedPassword)
+	require.False(t, rehash)
+}
+
+func TestVerifyOutdatedParameters(t *testing.T) {
+	old, err := passwordhash.NewHasher(passwordhash.Config{Argon2id: cheapArgon2id})
+	require.NoError(t, err)
+	stronger := cheapArgon2id
+	stronger.Iterations = 2
+	current, err := passwordhash.NewHasher(passwordhash.Config{Argon2id: stronger})
+	require.NoError(t, err)
+
+	password := util.RandomWord()
+	hash, err := old.Hash(password)
+	require.NoError(t, err)
+
+	rehash, err := current.Verify(password, hash)
+	require.NoError(t, err)
+	require.True(t, rehash)
+
+	hash, err = current.Hash(password)
+	require.NoError(t, err)
+	rehash, err = current.Verify(password, hash)
+	require.NoError(t, err)
+	require.False(t, rehash)
+}
+
+func TestVerifyBcryptCost(t *testing.T) {
+	hasher, err := passwordhash.NewHasher(passwordhash.Config{Algorithm: passwordhash.AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+
+	password := util.RandomWord()
+	hash, err := hasher.Hash(password)
+	require.NoError(t, err)
+	rehash, err := hasher.Verify(password, hash)
+	require.NoError(t, err)
+	require.False(t, rehash)
+
+	hash, err = util.MakePasswordBcrypt(password)
+	require.NoError(t, err)
+	rehash, err = hasher.Verify(password, hash)
+	require.NoError(t, err)
+	require.True(t, rehash)
+}
+
+func TestVerifyUnknownHash(t *testing.T) {
+	hasher, err := passwordhash.NewHasher(passwordhash.DefaultConfig())
+	require.NoError(t, err)
+
+	_, err = hasher.Verify("secret", "plain-text")
+	require.ErrorIs(t, err, passwordhash.ErrUnknownHash)
+
+	_, err = hasher.Verify("secret", "$argon2id$v=19$m=x$salt$key")
+	require.ErrorIs(t, err, passwordhash.ErrUnknownHash)
+
+	_, err = passwordhash.NewHasher(passwordhash.Config{Algorithm: "md5"})
+	require.Error(t, err)
+}
@@ -0,0 +1,186 @@
+// Package passwordpolicy decides which passwords users may choose: long enough, mixing character
+// classes, not built from the username or email address, not among well known breached passwords
+// and not one of the user's recent passwords.
+package passwordpolicy
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/google/uuid"
+)
+
+// ErrWeakPassword is wrapped by every error telling why a password is refused. Its message is
+// meant to be shown to the user.
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// minIdentityLength is the length from which parts of the username or email address are looked
+// for in passwords. Shorter parts would refuse too many passwords by chance.
+const minIdentityLength = 3
+
+//go:embed breached_passwords.txt
+var breachedPasswordList string
+
+// breached holds the bundled breached passwords in lower case.
+var breached = parseList(breachedPasswordList)
+
+func parseList(list string) map[string]bool {
+	passwords := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = true
+	}
+	return passwords
+}
+
+// Store is the data the password history is read from. db.Store satisfies it.
+type Store interface {
+	ListRecentPasswordHashes(ctx context.Context, arg db.ListRecentPasswordHashesParams) ([]string, error)
+}
+
+// Config tunes the policy. Zero values fall back to DefaultConfig.
+type Config struct {
+	// MinLength is the minimum number of characters.
+	MinLength int
+	// MinCharacterClasses is how many of lower case letters, upper case letters, digits and
+	// symbols a password has to mix.
+	MinCharacterClasses int
+	// HistorySize is how many recent passwords, the current one included, cannot be reused.
+	HistorySize int
+}
+
+// DefaultConfig returns the policy used unless configured otherwise.
+func DefaultConfig() Config {
+	return Config{
+		MinLength:           8,
+		MinCharacterClasses: 3,
+		HistorySize:         5,
+	}
+}
+
+// Policy checks passwords against a Config.
+type Policy struct {
+	config Config
+	hasher *passwordhash.Hasher
+}
+
+// NewPolicy creates a policy enforcing config. hasher verifies the recent passwords.
+func NewPolicy(config Config, hasher *passwordhash.Hasher) *Policy {
+	defaults := DefaultConfig()
+	if config.MinLength <= 0 {
+		config.MinLength = defaults.MinLength
+	}
+	if config.MinCharacterClasses <= 0 {
+		config.MinCharacterClasses = defaults.MinCharacterClasses
+	}
+	if config.HistorySize <= 0 {
+		config.HistorySize = defaults.HistorySize
+	}
+
+	return &Policy{config: config, hasher: hasher}
+}
+
+// Validate checks the strength of password for a user identified by identities, such as the
+// username and the email address, which must not appear in it.
+func (p *Policy) Validate(password string, identities ...string) error {
+	if len([]rune(password)) < p.config.MinLength {
+		return fmt.Errorf("%w: it must be at least %d characters long", ErrWeakPassword, p.config.MinLength)
+	}
+
+	if characterClasses(password) < p.config.MinCharacterClasses {
+		return fmt.Errorf("%w: it must mix at least %d of lower case letters, upper case letters, digits and symbols", ErrWeakPassword, p.config.MinCharacterClasses)
+	}
+
+	lower := strings.ToLower(password)
+	for _, identity := range identityParts(identities) {
+		if strings.Contains(lower, identity) {
+			return fmt.Errorf("%w: it must not contain the username or email address", ErrWeakPassword)
+		}
+	}
+
+	if breached[lower] {
+		return fmt.Errorf("%w: it appears in a list of breached passwords", ErrWeakPassword)
+	}
+
+	return nil
+}
+
+// CheckReuse refuses password when it is the current password of the user or one of the passwords
+// the user had before it, up to HistorySize passwords in all.
+func (p *Policy) CheckReuse(ctx context.Context, store Store, userUUID uuid.UUID, password string) error {
+	hashes, err := store.ListRecentPasswordHashes(ctx, db.ListRecentPasswordHashesParams{
+		UserUuid:     userUUID,
+		HistoryLimit: int32(p.config.HistorySize - 1),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if _, err := p.hasher.Verify(password, hash); err == nil {
+			return fmt.Errorf("%w: it must differ from the last %d passwords", ErrWeakPassword, p.config.HistorySize)
+		}
+	}
+
+	return nil
+}
+
+// Check validates password like Validate and refuses recent passwords of the user like CheckReuse.
+func (p *Policy) Check(ctx context.Context, store Store, userUUID uuid.UUID, password string, identities ...string) error {
+	if err := p.Validate(password, identities...); err != nil {
+		return err
+	}
+
+	return p.CheckReuse(ctx, store, userUUID, password)
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
+
+// identityParts returns the lower cased identities, with the local part of email addresses, that
+// are long enough to be looked for.
+func identityParts(identities []string) []string {
+	parts := []string{}
+	for _, identity := range identities {
+		identity = strings.ToLower(strings.TrimSpace(identity))
+		if local, _, ok := strings.Cut(identity, "@"); ok {
+			identity = local
+		}
+		if len(identity) >= minIdentityLength {
+			parts = append(parts, identity)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,82 @@
+package passwordpolicy_test
+
+import (
+	"context"
+	"testing"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// newHasher returns an argon2id hasher with parameters cheap enough for tests.
+func newHasher(t *testing.T) *passwordhash.Hasher {
+	hasher, err := passwordhash.NewHasher(passwordhash.Config{
+		Argon2id: passwordhash.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1},
+	})
+	require.NoError(t, err)
+	return hasher
+}
+
+func TestValidate(t *testing.T) {
+	policy := passwordpolicy.NewPolicy(passwordpolicy.DefaultConfig(), newHasher(t))
+
+	testCases := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{name: "OK", password: "Tr4vel-Mug", valid: true},
+		{name: "TooShort", password: "Ab1!x"},
+		{name: "TooFewClasses", password: "alllowercase1"},
+		{name: "ContainsUsername", password: "Xx-JohnDoe-42"},
+		{name: "ContainsEmailLocalPart", password: "jd.work!2024A"},
+		{name: "Breached", password: "Password123!"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Validate(tc.password, "johndoe", "jd.work@example.com")
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, passwordpolicy.ErrWeakPassword)
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	policy := passwordpolicy.NewPolicy(passwordpolicy.Config{MinLength: 16, MinCharacterClasses: 1}, newHasher(t))
+
+	require.ErrorIs(t, policy.Validate("Tr4vel-Mug"), passwordpolicy.ErrWeakPassword)
+	require.NoError(t, policy.Validate("correcthorsebatterystaple"))
+}
+
+func TestCheckReuse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	hasher := newHasher(t)
+	policy := passwordpolicy.NewPolicy(passwordpolicy.Config{HistorySize: 3}, hasher)
+
+	userUUID := uuid.New()
+	current, err := hasher.Hash("Current-Pass1")
+	require.NoError(t, err)
+	previous, err := util.MakePasswordBcrypt("Previous-Pass1")
+	require.NoError(t, err)
+
+	store.EXPECT().
+		ListRecentPasswordHashes(gomock.Any(), db.ListRecentPasswordHashesParams{UserUuid: userUUID, HistoryLimit: 2}).
+		Times(3).
+		Return([]string{current, previous}, nil)
+
+	ctx := context.Background()
+	require.ErrorIs(t, policy.CheckReuse(ctx, store, userUUID, "Current-Pass1"), passwordpolicy.ErrWeakPassword)
+	require.ErrorIs(t, policy.CheckReuse(ctx, store, userUUID, "Previous-Pass1"), passwordpolicy.ErrWeakPassword)
+	require.NoError(t, policy.CheckReuse(ctx, store, userUUID, "Brand-New-Pass1"))
+}
@@ -0,0 +1,110 @@
+package passwordreset
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const limitKeyPrefix = "password_reset_requests:"
+
+// LimitedError is returned when a password reset code must not be requested yet.
+type LimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LimitedError) Error() string {
+	return fmt.Sprintf("too many password reset requests, retry in %s", e.RetryAfter)
+}
+
+// LimitConfig tunes how often codes can be requested. Zero values fall back to DefaultLimitConfig.
+type LimitConfig struct {
+	// AddressInterval is the least time between two codes for an email address.
+	AddressInterval time.Duration
+	// MaxPerIP is the number of codes a client IP can request per IPWindow.
+	MaxPerIP int
+	IPWindow time.Duration
+}
+
+// DefaultLimitConfig returns the limits used unless configured otherwise.
+func DefaultLimitConfig() LimitConfig {
+	return LimitConfig{
+		AddressInterval: time.Minute,
+		MaxPerIP:        10,
+		IPWindow:        time.Hour,
+	}
+}
+
+// Limiter throttles password reset requests per email address and per client IP, so codes cannot
+// be re-issued to get fresh attempts or to flood an inbox. Unknown addresses are throttled the
+// same as registered ones.
+type Limiter struct {
+	rdb    *redis.Client
+	config LimitConfig
+}
+
+// NewLimiter creates a limiter that keeps its counters in rdb.
+func NewLimiter(rdb *redis.Client, config LimitConfig) *Limiter {
+	defaults := DefaultLimitConfig()
+	if config.AddressInterval <= 0 {
+		config.AddressInterval = defaults.AddressInterval
+	}
+	if config.MaxPerIP <= 0 {
+		config.MaxPerIP = defaults.MaxPerIP
+	}
+	if config.IPWindow <= 0 {
+		config.IPWindow = defaults.IPWindow
+	}
+
+	return &Limiter{rdb: rdb, config: config}
+}
+
+// Allow counts a request for a code to address from clientIP and returns a *LimitedError when
+// either of them requested too many.
+func (l *Limiter) Allow(ctx context.Context, address, clientIP string) error {
+	ipKey := limitKeyPrefix + "ip:" + clientIP
+	var requests *redis.IntCmd
+	_, err := l.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		requests = pipe.Incr(ctx, ipKey)
+		pipe.ExpireNX(ctx, ipKey, l.config.IPWindow)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if requests.Val() > int64(l.config.MaxPerIP) {
+		return l.limited(ctx, ipKey)
+	}
+
+	addressKey := limitKeyPrefix + "address:" + hashAddress(address)
+	allowed, err := l.rdb.SetNX(ctx, addressKey, 1, l.config.AddressInterval).Result()
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return l.limited(ctx, addressKey)
+	}
+	return nil
+}
+
+func (l *Limiter) limited(ctx context.Context, key string) error {
+	ttl, err := l.rdb.PTTL(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return &LimitedError{RetryAfter: ttl.Round(time.Second)}
+}
+
+// hashAddress keys the limit of an address without keeping the address itself in Redis.
+func hashAddress(address string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(address))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,180 @@
+// Package passwordreset implements the forgotten password flow: a single-use code is emailed to the
+// user and exchanged, together with the email address, for a new password.
+package passwordreset
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/email"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// CodeTTL is how long a password reset code is valid.
+	CodeTTL = 15 * time.Minute
+	// MaxAttempts is the number of wrong codes after which a code stops working.
+	MaxAttempts = 5
+	// codeDigits is the length of a password reset code.
+	codeDigits = 6
+)
+
+// ErrInvalidCode is returned when the code is wrong, used up or expired, or the email address is
+// unknown. The cases are not told apart so the flow does not reveal which addresses exist.
+var ErrInvalidCode = errors.New("invalid or expired password reset code")
+
+// Store is the data the password reset flow reads and writes. db.Store satisfies it.
+type Store interface {
+	GetUserByEmail(ctx context.Context, email string) (db.GetUserByEmailRow, error)
+	GetActivePasswordReset(ctx context.Context, arg db.GetActivePasswordResetParams) (db.PasswordReset, error)
+	IncrementPasswordResetAttempts(ctx context.Context, arg db.IncrementPasswordResetAttemptsParams) (int64, error)
+	CreatePasswordResetTx(ctx context.Context, arg db.CreatePasswordResetParams) (db.PasswordReset, error)
+	ResetPasswordTx(ctx context.Context, param db.ResetPasswordTxParam) (db.UpdateUserPasswordRow, error)
+	ExecTx(ctx context.Context, fn func(store db.Store) error) error
+	passwordpolicy.Store
+}
+
+// GenerateCode returns a new random numeric code.
+func GenerateCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < codeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", codeDigits, n), nil
+}
+
+// Request issues a password reset code for the user with address and queues the email carrying
+// it. It returns the user the code was issued for, or false when address is unknown, which
+// callers must not reveal. Requests over the limits of limiter return a *LimitedError.
+func Request(ctx context.Context, store Store, queue email.Queue, limiter *Limiter, address, clientIP string) (db.GetUserByEmailRow, bool, error) {
+	if err := limiter.Allow(ctx, address, clientIP); err != nil {
+		return db.GetUserByEmailRow{}, false, err
+	}
+
+	// the code is hashed before the lookup so unknown addresses do not answer noticeably faster
+	code, err := GenerateCode()
+	if err != nil {
+		return db.GetUserByEmailRow{}, false, err
+	}
+	codeHash, err := util.MakePasswordBcrypt(code)
+	if err != nil {
+		return db.GetUserByEmailRow{}, false, err
+	}
+
+	user, err := store.GetUserByEmail(ctx, address)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GetUserByEmailRow{}, false, nil
+		}
+		return db.GetUserByEmailRow{}, false, err
+	}
+
+	reset, err := store.CreatePasswordResetTx(ctx, db.CreatePasswordResetParams{
+		UserUuid:  user.UserUuid,
+		CodeHash:  codeHash,
+		ExpiresAt: time.Now().Add(CodeTTL),
+	})
+	if err != nil {
+		return db.GetUserByEmailRow{}, false, err
+	}
+
+	err = queue.QueuePasswordReset(ctx, user.UserUuid, user.Email, code, reset.ExpiresAt)
+	if err != nil {
+		return db.GetUserByEmailRow{}, false, err
+	}
+
+	return user, true, nil
+}
+
+// Reset exchanges code for setting the password of the user with address to newPassword, which has
+// to satisfy policy and is hashed with hasher. It also signs the user out everywhere and lifts a password reset required by
+// an admin. Every try uses up one of the MaxAttempts of the code, a password refused by the policy
+// leaves the code usable for the remaining ones. The reset is written to the audit log in the same
+// transaction.
+func Reset(ctx context.Context, store Store, policy *passwordpolicy.Policy, hasher *passwordhash.Hasher, address, code, newPassword string) (db.UpdateUserPasswordRow, error) {
+	user, err := store.GetUserByEmail(ctx, address)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.UpdateUserPasswordRow{}, ErrInvalidCode
+		}
+		return db.UpdateUserPasswordRow{}, err
+	}
+
+	reset, err := store.GetActivePasswordReset(ctx, db.GetActivePasswordResetParams{
+		UserUuid:    user.UserUuid,
+		MaxAttempts: MaxAttempts,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.UpdateUserPasswordRow{}, ErrInvalidCode
+		}
+		return db.UpdateUserPasswordRow{}, err
+	}
+
+	// the attempt is counted before the slow compare, so concurrent guesses cannot all pass the limit
+	counted, err := store.IncrementPasswordResetAttempts(ctx, db.IncrementPasswordResetAttemptsParams{
+		ID:          reset.ID,
+		MaxAttempts: MaxAttempts,
+	})
+	if err != nil {
+		return db.UpdateUserPasswordRow{}, err
+	}
+	if counted == 0 {
+		return db.UpdateUserPasswordRow{}, ErrInvalidCode
+	}
+
+	if err := util.CheckPasswordBcrypt(code, reset.CodeHash); err != nil {
+		return db.UpdateUserPasswordRow{}, ErrInvalidCode
+	}
+
+	if err := policy.Check(ctx, store, user.UserUuid, newPassword, user.Username, user.Email); err != nil {
+		return db.UpdateUserPasswordRow{}, err
+	}
+
+	hashedPassword, err := hasher.Hash(newPassword)
+	if err != nil {
+		return db.UpdateUserPasswordRow{}, err
+	}
+
+	var result db.UpdateUserPasswordRow
+	err = store.ExecTx(ctx, func(tx db.Store) error {
+		result, err = tx.ResetPasswordTx(ctx, db.ResetPasswordTxParam{
+			ResetID:        reset.ID,
+			UserUUID:       user.UserUuid,
+			HashedPassword: hashedPassword,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit.Write(ctx, tx, audit.Entry{
+			Action:     audit.ActionUserPasswordReset,
+			EntityType: audit.EntityUser,
+			EntityID:   user.UserUuid.String(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// the code was used by a concurrent request
+			return db.UpdateUserPasswordRow{}, ErrInvalidCode
+		}
+		return db.UpdateUserPasswordRow{}, err
+	}
+
+	return result, nil
+}
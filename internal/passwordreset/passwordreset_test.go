@@ -0,0 +1,304 @@
+package passwordreset_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/audit"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordpolicy"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordreset"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// emailQueue records the queued password reset codes instead of sending them.
+type emailQueue struct {
+	codes map[uuid.UUID]string
+}
+
+func (q *emailQueue) QueueVerification(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueuePasswordReset(_ context.Context, userUUID uuid.UUID, _, code string, _ time.Time) error {
+	if q.codes == nil {
+		q.codes = make(map[uuid.UUID]string)
+	}
+	q.codes[userUUID] = code
+	return nil
+}
+
+func (q *emailQueue) QueueAccountUnlock(_ context.Context, userUUID uuid.UUID, _, code string, _ time.Time) error {
+	if q.codes == nil {
+		q.codes = make(map[uuid.UUID]string)
+	}
+	q.codes[userUUID] = code
+	return nil
+}
+
+func (q *emailQueue) QueueDeviceConfirmation(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (q *emailQueue) QueueNewDeviceAlert(_ context.Context, _ uuid.UUID, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func newLimiter(t *testing.T) *passwordreset.Limiter {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	return passwordreset.NewLimiter(rdb, passwordreset.DefaultLimitConfig())
+}
+
+func randomUser() db.GetUserByEmailRow {
+	return db.GetUserByEmailRow{
+		UserUuid: util.RandomUUID(),
+		Username: util.RandomUsername(),
+		Email:    util.RandomEmail(),
+	}
+}
+
+func TestGenerateCode(t *testing.T) {
+	code, err := passwordreset.GenerateCode()
+	require.NoError(t, err)
+	require.Len(t, code, 6)
+	require.Regexp(t, "^[0-9]{6}$", code)
+}
+
+func TestRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	queue := &emailQueue{}
+	user := randomUser()
+
+	var stored db.CreatePasswordResetParams
+	store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+	store.EXPECT().CreatePasswordResetTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+			stored = arg
+			return db.PasswordReset{UserUuid: arg.UserUuid, CodeHash: arg.CodeHash, ExpiresAt: arg.ExpiresAt}, nil
+		})
+
+	result, found, err := passwordreset.Request(context.Background(), store, queue, newLimiter(t), user.Email, "127.0.0.1")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, user.UserUuid, result.UserUuid)
+
+	// only the hash of the emailed code is stored
+	code := queue.codes[user.UserUuid]
+	require.NotEmpty(t, code)
+	require.NotEqual(t, code, stored.CodeHash)
+	require.NoError(t, util.CheckPasswordBcrypt(code, stored.CodeHash))
+	require.WithinDuration(t, time.Now().Add(passwordreset.CodeTTL), stored.ExpiresAt, time.Minute)
+}
+
+func TestRequestUnknownEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	queue := &emailQueue{}
+
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(1).Return(db.GetUserByEmailRow{}, pgx.ErrNoRows)
+	store.EXPECT().CreatePasswordResetTx(gomock.Any(), gomock.Any()).Times(0)
+
+	_, found, err := passwordreset.Request(context.Background(), store, queue, newLimiter(t), util.RandomEmail(), "127.0.0.1")
+	require.NoError(t, err)
+	require.False(t, found)
+	require.Empty(t, queue.codes)
+}
+
+func TestReset(t *testing.T) {
+	user := randomUser()
+	codeHash, err := util.MakePasswordBcrypt("123456")
+	require.NoError(t, err)
+	reset := db.PasswordReset{ID: 1, UserUuid: user.UserUuid, CodeHash: codeHash}
+	currentHash, err := util.MakePasswordBcrypt("Current-Pass1")
+	require.NoError(t, err)
+	hasher, err := passwordhash.NewHasher(passwordhash.Config{
+		Argon2id: passwordhash.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		code       string
+		password   string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), db.GetActivePasswordResetParams{
+					UserUuid:    user.UserUuid,
+					MaxAttempts: passwordreset.MaxAttempts,
+				}).Times(1).Return(reset, nil)
+				store.EXPECT().IncrementPasswordResetAttempts(gomock.Any(), db.IncrementPasswordResetAttemptsParams{
+					ID:          reset.ID,
+					MaxAttempts: passwordreset.MaxAttempts,
+				}).Times(1).Return(int64(1), nil)
+				store.EXPECT().ListRecentPasswordHashes(gomock.Any(), gomock.Any()).Times(1).Return([]string{currentHash}, nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, param db.ResetPasswordTxParam) (db.UpdateUserPasswordRow, error) {
+						require.Equal(t, reset.ID, param.ResetID)
+						require.Equal(t, user.UserUuid, param.UserUUID)
+						require.True(t, strings.HasPrefix(param.HashedPassword, "$argon2id$"))
+						rehash, err := hasher.Verify("Tr4vel-Mug", param.HashedPassword)
+						require.NoError(t, err)
+						require.False(t, rehash)
+						return db.UpdateUserPasswordRow{UserUuid: user.UserUuid}, nil
+					})
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, audit.ActionUserPasswordReset, arg.Action)
+						require.Equal(t, user.UserUuid.String(), arg.EntityID)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "WrongCode",
+			code: "654321",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(1).Return(reset, nil)
+				store.EXPECT().IncrementPasswordResetAttempts(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, passwordreset.ErrInvalidCode)
+			},
+		},
+		{
+			name: "AttemptsUsedUpConcurrently",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(1).Return(reset, nil)
+				store.EXPECT().IncrementPasswordResetAttempts(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, passwordreset.ErrInvalidCode)
+			},
+		},
+		{
+			name:     "WeakPassword",
+			code:     "123456",
+			password: "password",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(1).Return(reset, nil)
+				store.EXPECT().IncrementPasswordResetAttempts(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, passwordpolicy.ErrWeakPassword)
+			},
+		},
+		{
+			name:     "CurrentPassword",
+			code:     "123456",
+			password: "Current-Pass1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(1).Return(reset, nil)
+				store.EXPECT().IncrementPasswordResetAttempts(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().ListRecentPasswordHashes(gomock.Any(), gomock.Any()).Times(1).Return([]string{currentHash}, nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, passwordpolicy.ErrWeakPassword)
+			},
+		},
+		{
+			name: "NoActiveCode",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(1).Return(db.PasswordReset{}, pgx.ErrNoRows)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, passwordreset.ErrInvalidCode)
+			},
+		},
+		{
+			name: "UnknownEmail",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(db.GetUserByEmailRow{}, pgx.ErrNoRows)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, passwordreset.ErrInvalidCode)
+			},
+		},
+		{
+			name: "UsedConcurrently",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(1).Return(reset, nil)
+				store.EXPECT().IncrementPasswordResetAttempts(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().ListRecentPasswordHashes(gomock.Any(), gomock.Any()).Times(1).Return([]string{currentHash}, nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(1).Return(db.UpdateUserPasswordRow{}, pgx.ErrNoRows)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, passwordreset.ErrInvalidCode)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ExecTx(gomock.Any(), gomock.Any()).AnyTimes().
+				DoAndReturn(func(ctx context.Context, fn func(db.Store) error) error { return fn(store) })
+			tc.buildStubs(store)
+
+			password := tc.password
+			if password == "" {
+				password = "Tr4vel-Mug"
+			}
+
+			policy := passwordpolicy.NewPolicy(passwordpolicy.DefaultConfig(), hasher)
+			_, err := passwordreset.Reset(context.Background(), store, policy, hasher, user.Email, tc.code, password)
+			tc.checkError(t, err)
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	limiter := passwordreset.NewLimiter(rdb, passwordreset.LimitConfig{MaxPerIP: 2})
+	ctx := context.Background()
+
+	require.NoError(t, limiter.Allow(ctx, "a@example.com", "10.0.0.1"))
+
+	// the address waits for the interval, whatever the casing and the client IP
+	err := limiter.Allow(ctx, " A@example.com", "10.0.0.2")
+	var limited *passwordreset.LimitedError
+	require.ErrorAs(t, err, &limited)
+	require.Greater(t, limited.RetryAfter, time.Duration(0))
+
+	require.NoError(t, limiter.Allow(ctx, "b@example.com", "10.0.0.1"))
+
+	// the client IP used up its requests
+	err = limiter.Allow(ctx, "c@example.com", "10.0.0.1")
+	require.ErrorAs(t, err, &limited)
+}
@@ -0,0 +1,216 @@
+// Package revocation keeps the access tokens revoked before they expire. A token is revoked on
+// its own, along with the session it belongs to, or along with every token issued to its user
+// before some time, e.g. when the password changes. Revocations are kept in Redis only as long as
+// a revoked token could still be valid. Both APIs check every access token against the list
+// through a small in-process cache, so a revocation made by another instance takes effect within
+// the cache TTL.
+package revocation
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	tokenKeyPrefix = "revoked_token:"
+	userKeyPrefix  = "tokens_revoked_before:"
+)
+
+// ErrRevoked is returned when a token was revoked before it expired.
+var ErrRevoked = errors.New("token has been revoked")
+
+// Config tunes the list. Zero values fall back to DefaultConfig.
+type Config struct {
+	// TokenLifetime is the longest an access token is valid. The revocations of sessions and the
+	// revocations of every token of a user are kept that long.
+	TokenLifetime time.Duration
+	// CacheTTL is how long the answer for a token is cached in process.
+	CacheTTL time.Duration
+	// CacheSize is the number of tokens whose answer is cached.
+	CacheSize int
+}
+
+// DefaultConfig returns the configuration used unless configured otherwise.
+func DefaultConfig() Config {
+	return Config{
+		TokenLifetime: 15 * time.Minute,
+		CacheTTL:      5 * time.Second,
+		CacheSize:     10000,
+	}
+}
+
+// Token is an access token checked against the list.
+type Token struct {
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	UserUUID  uuid.UUID
+	IssuedAt  time.Time
+	ExpiredAt time.Time
+}
+
+// raiseCutoff sets the time before which the tokens of a user are revoked, unless a later time is
+// already set. Times are in microseconds, which Lua numbers hold exactly.
+var raiseCutoff = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+if tonumber(ARGV[1]) > current then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+end
+return 0
+`)
+
+// List is the list of revoked access tokens.
+type List struct {
+	rdb    *redis.Client
+	config Config
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]cachedAnswer
+}
+
+type cachedAnswer struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+// NewList creates a list kept in rdb.
+func NewList(rdb *redis.Client, config Config) *List {
+	defaults := DefaultConfig()
+	if config.TokenLifetime <= 0 {
+		config.TokenLifetime = defaults.TokenLifetime
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = defaults.CacheTTL
+	}
+	if config.CacheSize <= 0 {
+		config.CacheSize = defaults.CacheSize
+	}
+
+	return &List{rdb: rdb, config: config, cache: make(map[uuid.UUID]cachedAnswer)}
+}
+
+// Revoke revokes the token id until it expires at expiresAt.
+func (l *List) Revoke(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := l.rdb.Set(ctx, tokenKeyPrefix+id.String(), 1, ttl).Err(); err != nil {
+		return err
+	}
+
+	l.clearCache()
+	return nil
+}
+
+// RevokeSession revokes every access token issued for the session sessionID.
+func (l *List) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	return l.Revoke(ctx, sessionID, time.Now().Add(l.config.TokenLifetime))
+}
+
+// RevokeIssuedBefore revokes every access token issued to the user before before.
+func (l *List) RevokeIssuedBefore(ctx context.Context, userUUID uuid.UUID, before time.Time) error {
+	err := raiseCutoff.Run(ctx, l.rdb, []string{userKeyPrefix + userUUID.String()},
+		before.UnixMicro(), l.config.TokenLifetime.Milliseconds()).Err()
+	if err != nil {
+		return err
+	}
+
+	l.clearCache()
+	return nil
+}
+
+// Check returns ErrRevoked when token was revoked on its own, along with its session or along
+// with the tokens issued to its user before some time.
+func (l *List) Check(ctx context.Context, token Token) error {
+	now := time.Now()
+
+	l.mu.Lock()
+	cached, ok := l.cache[token.ID]
+	l.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		if cached.revoked {
+			return ErrRevoked
+		}
+		return nil
+	}
+
+	revoked, err := l.lookup(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	// the answer is not kept past the expiry of the token, it is rejected as expired from then on
+	expiresAt := now.Add(l.config.CacheTTL)
+	if token.ExpiredAt.Before(expiresAt) {
+		expiresAt = token.ExpiredAt
+	}
+	l.remember(token.ID, cachedAnswer{revoked: revoked, expiresAt: expiresAt}, now)
+
+	if revoked {
+		return ErrRevoked
+	}
+	return nil
+}
+
+func (l *List) lookup(ctx context.Context, token Token) (bool, error) {
+	keys := []string{userKeyPrefix + token.UserUUID.String(), tokenKeyPrefix + token.ID.String()}
+	if token.SessionID != uuid.Nil {
+		keys = append(keys, tokenKeyPrefix+token.SessionID.String())
+	}
+
+	values, err := l.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return false, err
+	}
+
+	for _, value := range values[1:] {
+		if value != nil {
+			return true, nil
+		}
+	}
+
+	if value, ok := values[0].(string); ok {
+		cutoff, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		if token.IssuedAt.Before(time.UnixMicro(cutoff)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (l *List) remember(id uuid.UUID, answer cachedAnswer, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.cache) >= l.config.CacheSize {
+		for cachedID, cached := range l.cache {
+			if !now.Before(cached.expiresAt) {
+				delete(l.cache, cachedID)
+			}
+		}
+	}
+	// every answer is still fresh, start over rather than grow past the size
+	if len(l.cache) >= l.config.CacheSize {
+		l.cache = make(map[uuid.UUID]cachedAnswer)
+	}
+
+	l.cache[id] = answer
+}
+
+// clearCache forgets the cached answers, so a revocation made by this instance takes effect at once.
+func (l *List) clearCache() {
+	l.mu.Lock()
+	l.cache = make(map[uuid.UUID]cachedAnswer)
+	l.mu.Unlock()
+}
@@ -0,0 +1,86 @@
+package revocation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fajaramaulana/simple_bank_project/internal/revocation"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newToken(userUUID uuid.UUID, issuedAt time.Time) revocation.Token {
+	return revocation.Token{
+		ID:        uuid.New(),
+		SessionID: uuid.New(),
+		UserUUID:  userUUID,
+		IssuedAt:  issuedAt,
+		ExpiredAt: issuedAt.Add(time.Minute),
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	list := revocation.NewList(rdb, revocation.DefaultConfig())
+	ctx := context.Background()
+
+	token := newToken(uuid.New(), time.Now())
+	sibling := newToken(token.UserUUID, time.Now())
+	sibling.SessionID = token.SessionID
+	other := newToken(token.UserUUID, time.Now())
+
+	require.NoError(t, list.Check(ctx, token))
+	require.NoError(t, list.Revoke(ctx, token.ID, token.ExpiredAt))
+	require.ErrorIs(t, list.Check(ctx, token), revocation.ErrRevoked)
+	require.NoError(t, list.Check(ctx, sibling))
+
+	require.NoError(t, list.RevokeSession(ctx, token.SessionID))
+	require.ErrorIs(t, list.Check(ctx, sibling), revocation.ErrRevoked)
+	require.NoError(t, list.Check(ctx, other))
+
+	// an expired token needs no revocation
+	require.NoError(t, list.Revoke(ctx, other.ID, time.Now().Add(-time.Second)))
+	require.NoError(t, list.Check(ctx, other))
+}
+
+func TestRevokeIssuedBefore(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	list := revocation.NewList(rdb, revocation.DefaultConfig())
+	ctx := context.Background()
+
+	userUUID := uuid.New()
+	changedAt := time.Now()
+	before := newToken(userUUID, changedAt.Add(-time.Second))
+	after := newToken(userUUID, changedAt.Add(time.Second))
+	otherUser := newToken(uuid.New(), changedAt.Add(-time.Second))
+
+	require.NoError(t, list.RevokeIssuedBefore(ctx, userUUID, changedAt))
+	require.ErrorIs(t, list.Check(ctx, before), revocation.ErrRevoked)
+	require.NoError(t, list.Check(ctx, after))
+	require.NoError(t, list.Check(ctx, otherUser))
+
+	// an earlier time does not bring revoked tokens back
+	require.NoError(t, list.RevokeIssuedBefore(ctx, userUUID, changedAt.Add(-time.Hour)))
+	require.ErrorIs(t, list.Check(ctx, before), revocation.ErrRevoked)
+}
+
+func TestCheckCachesAnswers(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	ctx := context.Background()
+
+	list := revocation.NewList(rdb, revocation.Config{CacheTTL: 50 * time.Millisecond})
+	// another instance sharing the same redis
+	remote := revocation.NewList(rdb, revocation.DefaultConfig())
+
+	token := newToken(uuid.New(), time.Now())
+	require.NoError(t, list.Check(ctx, token))
+
+	require.NoError(t, remote.Revoke(ctx, token.ID, token.ExpiredAt))
+	require.NoError(t, list.Check(ctx, token))
+	require.Eventually(t, func() bool {
+		return list.Check(ctx, token) != nil
+	}, time.Second, 10*time.Millisecond)
+}
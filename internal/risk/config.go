@@ -0,0 +1,171 @@
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	// the runtime image ships without a zoneinfo database
+	_ "time/tzdata"
+)
+
+// Duration is a time.Duration that is written as a string such as "1h30m" in the rules file.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+type VelocityConfig struct {
+	Enabled   bool     `json:"enabled"`
+	Window    Duration `json:"window"`
+	MaxCount  int64    `json:"max_count"`
+	MaxAmount int64    `json:"max_amount"`
+	Action    Decision `json:"action"`
+}
+
+type NewDeviceLargeAmountConfig struct {
+	Enabled         bool     `json:"enabled"`
+	MinDeviceAge    Duration `json:"min_device_age"`
+	AmountThreshold int64    `json:"amount_threshold"`
+	Action          Decision `json:"action"`
+}
+
+type NewRecipientConfig struct {
+	Enabled         bool     `json:"enabled"`
+	AmountThreshold int64    `json:"amount_threshold"`
+	Action          Decision `json:"action"`
+}
+
+type UnusualHoursConfig struct {
+	Enabled         bool     `json:"enabled"`
+	Timezone        string   `json:"timezone"`
+	StartHour       int      `json:"start_hour"`
+	EndHour         int      `json:"end_hour"`
+	AmountThreshold int64    `json:"amount_threshold"`
+	Action          Decision `json:"action"`
+}
+
+// Config holds the settings of the built-in rules.
+type Config struct {
+	Velocity             VelocityConfig             `json:"velocity"`
+	NewDeviceLargeAmount NewDeviceLargeAmountConfig `json:"new_device_large_amount"`
+	NewRecipient         NewRecipientConfig         `json:"new_recipient"`
+	UnusualHours         UnusualHoursConfig         `json:"unusual_hours"`
+}
+
+// DefaultConfig returns the rules used when no rules file is configured.
+func DefaultConfig() Config {
+	return Config{
+		Velocity: VelocityConfig{
+			Enabled:   true,
+			Window:    Duration(time.Hour),
+			MaxCount:  10,
+			MaxAmount: 50_000_000,
+			Action:    DecisionReview,
+		},
+		NewDeviceLargeAmount: NewDeviceLargeAmountConfig{
+			Enabled:         true,
+			MinDeviceAge:    Duration(24 * time.Hour),
+			AmountThreshold: 10_000_000,
+			Action:          DecisionReview,
+		},
+		NewRecipient: NewRecipientConfig{
+			Enabled:         true,
+			AmountThreshold: 25_000_000,
+			Action:          DecisionReview,
+		},
+		UnusualHours: UnusualHoursConfig{
+			Enabled:         true,
+			Timezone:        "Asia/Jakarta",
+			StartHour:       0,
+			EndHour:         5,
+			AmountThreshold: 5_000_000,
+			Action:          DecisionReview,
+		},
+	}
+}
+
+// LoadConfig reads the rules file at path on top of DefaultConfig.
+// An empty path returns DefaultConfig.
+func LoadConfig(path string) (Config, error) {
+	config := DefaultConfig()
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("cannot parse risk rules file %s: %w", path, err)
+	}
+
+	return config, config.validate()
+}
+
+func (c Config) validate() error {
+	actions := map[string]Decision{
+		"velocity":                c.Velocity.Action,
+		"new_device_large_amount": c.NewDeviceLargeAmount.Action,
+		"new_recipient":           c.NewRecipient.Action,
+		"unusual_hours":           c.UnusualHours.Action,
+	}
+
+	for name, action := range actions {
+		if !action.valid() {
+			return fmt.Errorf("risk rule %s has invalid action %q", name, action)
+		}
+	}
+
+	if c.UnusualHours.StartHour < 0 || c.UnusualHours.StartHour > 23 || c.UnusualHours.EndHour < 0 || c.UnusualHours.EndHour > 24 {
+		return fmt.Errorf("risk rule unusual_hours has invalid hours %d-%d", c.UnusualHours.StartHour, c.UnusualHours.EndHour)
+	}
+
+	return nil
+}
+
+// NewEngineFromConfig builds an engine with the enabled built-in rules.
+func NewEngineFromConfig(store Store, config Config) (*Engine, error) {
+	var rules []Rule
+
+	if config.Velocity.Enabled {
+		rules = append(rules, NewVelocityRule(store, config.Velocity))
+	}
+
+	if config.NewDeviceLargeAmount.Enabled {
+		rules = append(rules, NewNewDeviceLargeAmountRule(store, config.NewDeviceLargeAmount))
+	}
+
+	if config.NewRecipient.Enabled {
+		rules = append(rules, NewNewRecipientRule(store, config.NewRecipient))
+	}
+
+	if config.UnusualHours.Enabled {
+		rule, err := NewUnusualHoursRule(config.UnusualHours)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return NewEngine(rules...), nil
+}
@@ -0,0 +1,113 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Decision is the outcome of evaluating a transfer against the rules.
+type Decision string
+
+const (
+	DecisionAllow  Decision = "allow"
+	DecisionReview Decision = "review"
+	DecisionDeny   Decision = "deny"
+)
+
+// severity orders decisions so the strictest one wins.
+func (d Decision) severity() int {
+	switch d {
+	case DecisionDeny:
+		return 2
+	case DecisionReview:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (d Decision) valid() bool {
+	return d == DecisionAllow || d == DecisionReview || d == DecisionDeny
+}
+
+// Input describes the transfer being evaluated.
+type Input struct {
+	UserUUID      uuid.UUID
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	Currency      string
+	// DeviceToken is the device token the client sent, identifying its device in the device registry.
+	DeviceToken string
+	At          time.Time
+}
+
+// Outcome is what a single rule decided. Reason is empty when the rule allows the transfer.
+type Outcome struct {
+	Decision Decision
+	Reason   string
+}
+
+// Rule is a single risk check. Rules must be safe for concurrent use.
+type Rule interface {
+	Name() string
+	Evaluate(ctx context.Context, in Input) (Outcome, error)
+}
+
+// Result is the combined outcome of all rules.
+type Result struct {
+	Decision Decision
+	Reasons  []string
+}
+
+type Engine struct {
+	rules []Rule
+}
+
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// Evaluate runs every rule and returns the strictest decision together with the reasons of
+// all rules that did not allow the transfer.
+func (e *Engine) Evaluate(ctx context.Context, in Input) (Result, error) {
+	result := Result{
+		Decision: DecisionAllow,
+		Reasons:  []string{},
+	}
+
+	for _, rule := range e.rules {
+		outcome, err := rule.Evaluate(ctx, in)
+		if err != nil {
+			return Result{}, fmt.Errorf("risk rule %s: %w", rule.Name(), err)
+		}
+
+		if outcome.Decision == DecisionAllow {
+			continue
+		}
+
+		result.Reasons = append(result.Reasons, fmt.Sprintf("%s: %s", rule.Name(), outcome.Reason))
+		if outcome.Decision.severity() > result.Decision.severity() {
+			result.Decision = outcome.Decision
+		}
+	}
+
+	return result, nil
+}
+
+// DecisionError is returned when a transfer is not executed because of a risk decision.
+type DecisionError struct {
+	DecisionUUID uuid.UUID
+	Decision     Decision
+	Reasons      []string
+}
+
+func (e *DecisionError) Error() string {
+	if e.Decision == DecisionDeny {
+		return "transfer denied"
+	}
+	return "transfer held for review"
+}
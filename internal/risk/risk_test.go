@@ -0,0 +1,221 @@
+package risk
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type staticRule struct {
+	name    string
+	outcome Outcome
+}
+
+func (r staticRule) Name() string { return r.name }
+
+func (r staticRule) Evaluate(ctx context.Context, in Input) (Outcome, error) {
+	return r.outcome, nil
+}
+
+func TestEngineStrictestDecisionWins(t *testing.T) {
+	engine := NewEngine(
+		staticRule{name: "a", outcome: Outcome{Decision: DecisionAllow}},
+		staticRule{name: "b", outcome: Outcome{Decision: DecisionDeny, Reason: "too much"}},
+		staticRule{name: "c", outcome: Outcome{Decision: DecisionReview, Reason: "odd"}},
+	)
+
+	result, err := engine.Evaluate(context.Background(), Input{})
+	require.NoError(t, err)
+	require.Equal(t, DecisionDeny, result.Decision)
+	require.Equal(t, []string{"b: too much", "c: odd"}, result.Reasons)
+
+	result, err = NewEngine().Evaluate(context.Background(), Input{})
+	require.NoError(t, err)
+	require.Equal(t, DecisionAllow, result.Decision)
+	require.Empty(t, result.Reasons)
+}
+
+func TestVelocityRule(t *testing.T) {
+	config := VelocityConfig{Window: Duration(time.Hour), MaxCount: 3, MaxAmount: 1000, Action: DecisionDeny}
+
+	testCases := []struct {
+		name     string
+		count    int64
+		total    int64
+		amount   int64
+		decision Decision
+	}{
+		{name: "Allow", count: 1, total: 100, amount: 100, decision: DecisionAllow},
+		{name: "TooManyTransfers", count: 3, total: 100, amount: 100, decision: DecisionDeny},
+		{name: "TooMuchAmount", count: 1, total: 950, amount: 100, decision: DecisionDeny},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			in := Input{FromAccountID: 1, Amount: tc.amount, At: time.Now()}
+			store.EXPECT().
+				GetAccountTransferVelocity(gomock.Any(), gomock.Eq(db.GetAccountTransferVelocityParams{
+					FromAccountID: in.FromAccountID,
+					Since:         in.At.Add(-time.Hour),
+				})).
+				Times(1).
+				Return(db.GetAccountTransferVelocityRow{
+					TransferCount: tc.count,
+					TotalAmount:   pgtype.Numeric{Int: big.NewInt(tc.total), Valid: true},
+				}, nil)
+
+			outcome, err := NewVelocityRule(store, config).Evaluate(context.Background(), in)
+			require.NoError(t, err)
+			require.Equal(t, tc.decision, outcome.Decision)
+		})
+	}
+}
+
+func TestNewDeviceLargeAmountRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	config := NewDeviceLargeAmountConfig{MinDeviceAge: Duration(24 * time.Hour), AmountThreshold: 1000, Action: DecisionReview}
+	rule := NewNewDeviceLargeAmountRule(store, config)
+	deviceToken := strings.Repeat("ab", 32)
+	in := Input{UserUUID: uuid.New(), Amount: 1000, DeviceToken: deviceToken, At: time.Now()}
+	deviceParams := db.GetUserDeviceParams{UserUuid: in.UserUUID, Fingerprint: device.Fingerprint(deviceToken)}
+	trustedAt := pgtype.Timestamptz{Time: in.At.Add(-48 * time.Hour), Valid: true}
+
+	testCases := []struct {
+		name       string
+		userDevice db.UserDevice
+		err        error
+		decision   Decision
+	}{
+		{
+			name:     "unknown device",
+			err:      pgx.ErrNoRows,
+			decision: DecisionReview,
+		},
+		{
+			name:       "device first seen recently",
+			userDevice: db.UserDevice{TrustedAt: trustedAt, CreatedAt: in.At.Add(-time.Hour)},
+			decision:   DecisionReview,
+		},
+		{
+			name:       "untrusted device",
+			userDevice: db.UserDevice{CreatedAt: in.At.Add(-48 * time.Hour)},
+			decision:   DecisionReview,
+		},
+		{
+			name:       "known device",
+			userDevice: db.UserDevice{TrustedAt: trustedAt, CreatedAt: in.At.Add(-48 * time.Hour)},
+			decision:   DecisionAllow,
+		},
+	}
+
+	for _, tc := range testCases {
+		store.EXPECT().GetUserDevice(gomock.Any(), deviceParams).Times(1).Return(tc.userDevice, tc.err)
+		outcome, err := rule.Evaluate(context.Background(), in)
+		require.NoError(t, err, tc.name)
+		require.Equal(t, tc.decision, outcome.Decision, tc.name)
+		if tc.decision != DecisionAllow {
+			require.NotEmpty(t, outcome.Reason, tc.name)
+		}
+	}
+
+	// a client without a device token is on a new device, the user agent it sends does not count
+	in.DeviceToken = ""
+	outcome, err := rule.Evaluate(context.Background(), in)
+	require.NoError(t, err)
+	require.Equal(t, DecisionReview, outcome.Decision)
+
+	// small amounts are not checked at all
+	in.Amount = 999
+	outcome, err = rule.Evaluate(context.Background(), in)
+	require.NoError(t, err)
+	require.Equal(t, DecisionAllow, outcome.Decision)
+}
+
+func TestNewRecipientRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	rule := NewNewRecipientRule(store, NewRecipientConfig{AmountThreshold: 1000, Action: DecisionReview})
+	in := Input{FromAccountID: 1, ToAccountID: 2, Amount: 5000}
+
+	store.EXPECT().
+		CountTransfersBetweenAccounts(gomock.Any(), gomock.Eq(db.CountTransfersBetweenAccountsParams{FromAccountID: 1, ToAccountID: 2})).
+		Times(1).
+		Return(int64(0), nil)
+	outcome, err := rule.Evaluate(context.Background(), in)
+	require.NoError(t, err)
+	require.Equal(t, DecisionReview, outcome.Decision)
+
+	store.EXPECT().CountTransfersBetweenAccounts(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+	outcome, err = rule.Evaluate(context.Background(), in)
+	require.NoError(t, err)
+	require.Equal(t, DecisionAllow, outcome.Decision)
+}
+
+func TestUnusualHoursRule(t *testing.T) {
+	rule, err := NewUnusualHoursRule(UnusualHoursConfig{Timezone: "UTC", StartHour: 22, EndHour: 5, Action: DecisionReview})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		hour     int
+		decision Decision
+	}{
+		{hour: 23, decision: DecisionReview},
+		{hour: 2, decision: DecisionReview},
+		{hour: 5, decision: DecisionAllow},
+		{hour: 12, decision: DecisionAllow},
+	}
+
+	for _, tc := range testCases {
+		at := time.Date(2024, 1, 1, tc.hour, 30, 0, 0, time.UTC)
+		outcome, err := rule.Evaluate(context.Background(), Input{At: at})
+		require.NoError(t, err)
+		require.Equal(t, tc.decision, outcome.Decision, "hour %d", tc.hour)
+	}
+
+	_, err = NewUnusualHoursRule(UnusualHoursConfig{Timezone: "Nowhere/Invalid"})
+	require.Error(t, err)
+}
+
+func TestLoadConfig(t *testing.T) {
+	config, err := LoadConfig("")
+	require.NoError(t, err)
+	require.Equal(t, DefaultConfig(), config)
+
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"velocity":{"enabled":true,"window":"30m","max_count":2,"action":"deny"}}`), 0o600))
+
+	config, err = LoadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, Duration(30*time.Minute), config.Velocity.Window)
+	require.Equal(t, int64(2), config.Velocity.MaxCount)
+	require.Equal(t, DecisionDeny, config.Velocity.Action)
+	// rules missing from the file keep their defaults
+	require.Equal(t, DefaultConfig().UnusualHours, config.UnusualHours)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"new_recipient":{"action":"block"}}`), 0o600))
+
+	_, err = LoadConfig(invalid)
+	require.ErrorContains(t, err, "invalid action")
+}
@@ -0,0 +1,195 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/device"
+	"github.com/jackc/pgx/v5"
+)
+
+// Store is the data the built-in rules read. db.Store satisfies it.
+type Store interface {
+	GetAccountTransferVelocity(ctx context.Context, arg db.GetAccountTransferVelocityParams) (db.GetAccountTransferVelocityRow, error)
+	CountTransfersBetweenAccounts(ctx context.Context, arg db.CountTransfersBetweenAccountsParams) (int64, error)
+	GetUserDevice(ctx context.Context, arg db.GetUserDeviceParams) (db.UserDevice, error)
+}
+
+var allow = Outcome{Decision: DecisionAllow}
+
+// VelocityRule limits how many transfers, and how much money, can leave an account within a time window.
+type VelocityRule struct {
+	store  Store
+	config VelocityConfig
+}
+
+func NewVelocityRule(store Store, config VelocityConfig) *VelocityRule {
+	return &VelocityRule{store: store, config: config}
+}
+
+func (r *VelocityRule) Name() string { return "velocity" }
+
+func (r *VelocityRule) Evaluate(ctx context.Context, in Input) (Outcome, error) {
+	velocity, err := r.store.GetAccountTransferVelocity(ctx, db.GetAccountTransferVelocityParams{
+		FromAccountID: in.FromAccountID,
+		Since:         in.At.Add(-time.Duration(r.config.Window)),
+	})
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	if r.config.MaxCount > 0 && velocity.TransferCount+1 > r.config.MaxCount {
+		return Outcome{
+			Decision: r.config.Action,
+			Reason:   fmt.Sprintf("more than %d transfers within %s", r.config.MaxCount, time.Duration(r.config.Window)),
+		}, nil
+	}
+
+	total, err := velocity.TotalAmount.Int64Value()
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	if r.config.MaxAmount > 0 && total.Int64+in.Amount > r.config.MaxAmount {
+		return Outcome{
+			Decision: r.config.Action,
+			Reason:   fmt.Sprintf("more than %d transferred within %s", r.config.MaxAmount, time.Duration(r.config.Window)),
+		}, nil
+	}
+
+	return allow, nil
+}
+
+// NewDeviceLargeAmountRule flags large transfers made from a device the user has not logged in from before.
+// A device is known like it is at login: by the device token the server issued it, as a trusted
+// device in the device registry.
+type NewDeviceLargeAmountRule struct {
+	store  Store
+	config NewDeviceLargeAmountConfig
+}
+
+func NewNewDeviceLargeAmountRule(store Store, config NewDeviceLargeAmountConfig) *NewDeviceLargeAmountRule {
+	return &NewDeviceLargeAmountRule{store: store, config: config}
+}
+
+func (r *NewDeviceLargeAmountRule) Name() string { return "new_device_large_amount" }
+
+func (r *NewDeviceLargeAmountRule) Evaluate(ctx context.Context, in Input) (Outcome, error) {
+	if in.Amount < r.config.AmountThreshold {
+		return allow, nil
+	}
+
+	known, err := r.knownDevice(ctx, in)
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	if !known {
+		return Outcome{
+			Decision: r.config.Action,
+			Reason:   fmt.Sprintf("amount of at least %d from a device first seen less than %s ago", r.config.AmountThreshold, time.Duration(r.config.MinDeviceAge)),
+		}, nil
+	}
+
+	return allow, nil
+}
+
+// knownDevice reports whether the device of in is a trusted device of the user, first seen at
+// least MinDeviceAge before the transfer.
+func (r *NewDeviceLargeAmountRule) knownDevice(ctx context.Context, in Input) (bool, error) {
+	fingerprint := device.Fingerprint(in.DeviceToken)
+	if fingerprint == "" {
+		return false, nil
+	}
+
+	userDevice, err := r.store.GetUserDevice(ctx, db.GetUserDeviceParams{
+		UserUuid:    in.UserUUID,
+		Fingerprint: fingerprint,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return userDevice.TrustedAt.Valid && userDevice.CreatedAt.Before(in.At.Add(-time.Duration(r.config.MinDeviceAge))), nil
+}
+
+// NewRecipientRule flags the first transfer from an account to a recipient it never paid before.
+type NewRecipientRule struct {
+	store  Store
+	config NewRecipientConfig
+}
+
+func NewNewRecipientRule(store Store, config NewRecipientConfig) *NewRecipientRule {
+	return &NewRecipientRule{store: store, config: config}
+}
+
+func (r *NewRecipientRule) Name() string { return "new_recipient" }
+
+func (r *NewRecipientRule) Evaluate(ctx context.Context, in Input) (Outcome, error) {
+	if in.Amount < r.config.AmountThreshold {
+		return allow, nil
+	}
+
+	transfers, err := r.store.CountTransfersBetweenAccounts(ctx, db.CountTransfersBetweenAccountsParams{
+		FromAccountID: in.FromAccountID,
+		ToAccountID:   in.ToAccountID,
+	})
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	if transfers == 0 {
+		return Outcome{
+			Decision: r.config.Action,
+			Reason:   fmt.Sprintf("first transfer of at least %d to this recipient", r.config.AmountThreshold),
+		}, nil
+	}
+
+	return allow, nil
+}
+
+// UnusualHoursRule flags transfers made between StartHour (inclusive) and EndHour (exclusive) in the configured timezone.
+type UnusualHoursRule struct {
+	config   UnusualHoursConfig
+	location *time.Location
+}
+
+func NewUnusualHoursRule(config UnusualHoursConfig) (*UnusualHoursRule, error) {
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnusualHoursRule{config: config, location: location}, nil
+}
+
+func (r *UnusualHoursRule) Name() string { return "unusual_hours" }
+
+func (r *UnusualHoursRule) Evaluate(ctx context.Context, in Input) (Outcome, error) {
+	if in.Amount < r.config.AmountThreshold {
+		return allow, nil
+	}
+
+	hour := in.At.In(r.location).Hour()
+
+	unusual := hour >= r.config.StartHour && hour < r.config.EndHour
+	if r.config.StartHour > r.config.EndHour {
+		// the window wraps around midnight, e.g. 22 to 5
+		unusual = hour >= r.config.StartHour || hour < r.config.EndHour
+	}
+
+	if unusual {
+		return Outcome{
+			Decision: r.config.Action,
+			Reason:   fmt.Sprintf("transfer between %02d:00 and %02d:00 %s", r.config.StartHour, r.config.EndHour, r.config.Timezone),
+		}, nil
+	}
+
+	return allow, nil
+}
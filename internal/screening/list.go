@@ -0,0 +1,159 @@
+package screening
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Entry is a listed person or entity.
+type Entry struct {
+	Reference string   `json:"reference"`
+	Name      string   `json:"name"`
+	Aliases   []string `json:"aliases"`
+}
+
+// List is a loaded sanctions or watchlist.
+type List struct {
+	Entries []Entry
+}
+
+// LoadList reads the list at path. Files ending in .xml are read as the consolidated sanctions list
+// format, everything else as CSV. An empty path returns an empty list, which never matches.
+func LoadList(path string) (*List, error) {
+	if path == "" {
+		return &List{}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		entries, err = ParseXML(file)
+	} else {
+		entries, err = ParseCSV(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse sanctions list %s: %w", path, err)
+	}
+
+	return &List{Entries: entries}, nil
+}
+
+// ParseCSV reads a list with a header row. The name column is required; reference and aliases
+// are optional, aliases being separated by semicolons.
+func ParseCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	nameColumn, ok := columns["name"]
+	if !ok {
+		return nil, errors.New("missing name column")
+	}
+
+	field := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	entries := []Entry{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if nameColumn >= len(record) || strings.TrimSpace(record[nameColumn]) == "" {
+			continue
+		}
+
+		entry := Entry{
+			Reference: field(record, "reference"),
+			Name:      strings.TrimSpace(record[nameColumn]),
+		}
+		for _, alias := range strings.Split(field(record, "aliases"), ";") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+type xmlAlias struct {
+	Name string `xml:"ALIAS_NAME"`
+}
+
+type xmlParty struct {
+	Reference         string     `xml:"REFERENCE_NUMBER"`
+	FirstName         string     `xml:"FIRST_NAME"`
+	SecondName        string     `xml:"SECOND_NAME"`
+	ThirdName         string     `xml:"THIRD_NAME"`
+	FourthName        string     `xml:"FOURTH_NAME"`
+	IndividualAliases []xmlAlias `xml:"INDIVIDUAL_ALIAS"`
+	EntityAliases     []xmlAlias `xml:"ENTITY_ALIAS"`
+}
+
+type xmlConsolidatedList struct {
+	Individuals []xmlParty `xml:"INDIVIDUALS>INDIVIDUAL"`
+	Entities    []xmlParty `xml:"ENTITIES>ENTITY"`
+}
+
+// ParseXML reads the individuals and entities of a consolidated sanctions list.
+func ParseXML(r io.Reader) ([]Entry, error) {
+	var list xmlConsolidatedList
+	if err := xml.NewDecoder(r).Decode(&list); err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	for _, party := range append(list.Individuals, list.Entities...) {
+		name := strings.Join(strings.Fields(strings.Join([]string{party.FirstName, party.SecondName, party.ThirdName, party.FourthName}, " ")), " ")
+		if name == "" {
+			continue
+		}
+
+		entry := Entry{
+			Reference: strings.TrimSpace(party.Reference),
+			Name:      name,
+		}
+		for _, alias := range append(party.IndividualAliases, party.EntityAliases...) {
+			if aliasName := strings.TrimSpace(alias.Name); aliasName != "" {
+				entry.Aliases = append(entry.Aliases, aliasName)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
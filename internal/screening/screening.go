@@ -0,0 +1,196 @@
+package screening
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultThreshold is the similarity score from which a name is treated as a match.
+const DefaultThreshold = 0.9
+
+// Match is the best listed entry for a screened name.
+type Match struct {
+	Reference  string  `json:"reference"`
+	ListedName string  `json:"listed_name"`
+	Score      float64 `json:"score"`
+}
+
+// HoldError is returned when an operation is held in the compliance review queue because a name
+// matched the list.
+type HoldError struct {
+	ReviewUUID uuid.UUID
+	Match      Match
+}
+
+func (e *HoldError) Error() string {
+	return "held for compliance review"
+}
+
+// Screener matches names against a list.
+type Screener struct {
+	entries   []screenedEntry
+	threshold float64
+}
+
+type screenedEntry struct {
+	entry Entry
+	names [][]string
+}
+
+// NewScreener creates a screener for list. A threshold of 0 uses DefaultThreshold.
+func NewScreener(list *List, threshold float64) *Screener {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	screener := &Screener{threshold: threshold}
+	for _, entry := range list.Entries {
+		screened := screenedEntry{entry: entry}
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			if tokens := tokenize(name); len(tokens) > 0 {
+				screened.names = append(screened.names, tokens)
+			}
+		}
+		screener.entries = append(screener.entries, screened)
+	}
+
+	return screener
+}
+
+// Screen returns the best match for name, if any entry scores at least the threshold.
+func (s *Screener) Screen(name string) (Match, bool) {
+	tokens := tokenize(name)
+	if len(tokens) == 0 {
+		return Match{}, false
+	}
+
+	var best Match
+	for _, screened := range s.entries {
+		for _, listed := range screened.names {
+			score := nameSimilarity(tokens, listed)
+			if score > best.Score {
+				best = Match{
+					Reference:  screened.entry.Reference,
+					ListedName: screened.entry.Name,
+					Score:      score,
+				}
+			}
+		}
+	}
+
+	return best, best.Score >= s.threshold
+}
+
+// tokenize lowercases name, strips diacritics and punctuation and returns its words sorted,
+// so "Doe, John" and "John Doe" compare equal.
+func tokenize(name string) []string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// drop combining marks left over from decomposed accents
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	tokens := strings.Fields(b.String())
+	sort.Strings(tokens)
+	return tokens
+}
+
+// nameSimilarity scores two tokenized names between 0 and 1. It takes the better of comparing the
+// whole names and comparing every word of the shorter name with its closest word in the longer
+// one, which catches names listed with extra middle names. A single word is only compared whole,
+// so a common first name alone does not match.
+func nameSimilarity(a, b []string) float64 {
+	score := jaroWinkler(strings.Join(a, " "), strings.Join(b, " "))
+
+	shorter, longer := a, b
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+	if len(shorter) < 2 {
+		return score
+	}
+
+	var total float64
+	for _, word := range shorter {
+		var bestWord float64
+		for _, other := range longer {
+			if s := jaroWinkler(word, other); s > bestWord {
+				bestWord = s
+			}
+		}
+		total += bestWord
+	}
+
+	if tokenScore := total / float64(len(shorter)); tokenScore > score {
+		return tokenScore
+	}
+	return score
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of a and b.
+func jaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	window := max(len(s1), len(s2))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		for j := max(0, i-window); j < min(len(s2), i+window+1); j++ {
+			if matched2[j] || s1[i] != s2[j] {
+				continue
+			}
+			matched1[i], matched2[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
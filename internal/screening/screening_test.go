@@ -0,0 +1,110 @@
+package screening
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testXML = `<?xml version="1.0" encoding="UTF-8"?>
+<CONSOLIDATED_LIST dateGenerated="2024-01-01T00:00:00.000Z">
+  <INDIVIDUALS>
+    <INDIVIDUAL>
+      <DATAID>1</DATAID>
+      <FIRST_NAME>JOHN</FIRST_NAME>
+      <SECOND_NAME>ALEXANDER</SECOND_NAME>
+      <THIRD_NAME>DOE</THIRD_NAME>
+      <REFERENCE_NUMBER>QDi.001</REFERENCE_NUMBER>
+      <INDIVIDUAL_ALIAS>
+        <QUALITY>Good</QUALITY>
+        <ALIAS_NAME>Johnny Doe</ALIAS_NAME>
+      </INDIVIDUAL_ALIAS>
+    </INDIVIDUAL>
+  </INDIVIDUALS>
+  <ENTITIES>
+    <ENTITY>
+      <DATAID>2</DATAID>
+      <FIRST_NAME>ACME TRADING COMPANY</FIRST_NAME>
+      <REFERENCE_NUMBER>QDe.002</REFERENCE_NUMBER>
+      <ENTITY_ALIAS>
+        <ALIAS_NAME>Acme Trading Co</ALIAS_NAME>
+      </ENTITY_ALIAS>
+    </ENTITY>
+  </ENTITIES>
+</CONSOLIDATED_LIST>`
+
+func TestParseXML(t *testing.T) {
+	entries, err := ParseXML(strings.NewReader(testXML))
+	require.NoError(t, err)
+	require.Equal(t, []Entry{
+		{Reference: "QDi.001", Name: "JOHN ALEXANDER DOE", Aliases: []string{"Johnny Doe"}},
+		{Reference: "QDe.002", Name: "ACME TRADING COMPANY", Aliases: []string{"Acme Trading Co"}},
+	}, entries)
+}
+
+func TestParseCSV(t *testing.T) {
+	entries, err := ParseCSV(strings.NewReader("reference,name,aliases\nQDi.001,John Alexander Doe,Johnny Doe; J. Doe\nQDi.002,,\nQDi.003,Jane Roe,\n"))
+	require.NoError(t, err)
+	require.Equal(t, []Entry{
+		{Reference: "QDi.001", Name: "John Alexander Doe", Aliases: []string{"Johnny Doe", "J. Doe"}},
+		{Reference: "QDi.003", Name: "Jane Roe"},
+	}, entries)
+
+	_, err = ParseCSV(strings.NewReader("reference,aliases\nQDi.001,Johnny\n"))
+	require.Error(t, err)
+}
+
+func TestLoadList(t *testing.T) {
+	list, err := LoadList("")
+	require.NoError(t, err)
+	require.Empty(t, list.Entries)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "consolidated.xml")
+	require.NoError(t, os.WriteFile(path, []byte(testXML), 0o600))
+
+	list, err = LoadList(path)
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 2)
+}
+
+func TestScreen(t *testing.T) {
+	entries, err := ParseXML(strings.NewReader(testXML))
+	require.NoError(t, err)
+	screener := NewScreener(&List{Entries: entries}, 0)
+
+	testCases := []struct {
+		name    string
+		matched bool
+	}{
+		{name: "John Alexander Doe", matched: true},
+		{name: "DOE, John Alexander", matched: true},
+		{name: "Jöhn Alexandér Dóe", matched: true},
+		{name: "Jon Alexander Doe", matched: true},
+		{name: "John Doe", matched: true},
+		{name: "Acme Trading Co.", matched: true},
+		{name: "John", matched: false},
+		{name: "Maria Garcia", matched: false},
+		{name: "", matched: false},
+	}
+
+	for _, tc := range testCases {
+		match, matched := screener.Screen(tc.name)
+		require.Equal(t, tc.matched, matched, tc.name)
+		if tc.matched {
+			require.NotEmpty(t, match.Reference)
+			require.GreaterOrEqual(t, match.Score, DefaultThreshold)
+		}
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	require.Equal(t, 1.0, jaroWinkler("martha", "martha"))
+	require.InDelta(t, 0.961, jaroWinkler("martha", "marhta"), 0.001)
+	require.InDelta(t, 0.840, jaroWinkler("dwayne", "duane"), 0.001)
+	require.Equal(t, 0.0, jaroWinkler("abc", "xyz"))
+	require.Equal(t, 0.0, jaroWinkler("", "abc"))
+}
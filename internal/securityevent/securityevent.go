@@ -0,0 +1,55 @@
+// Package securityevent records the security history of a user's account: events the user should
+// be able to review, such as the account being locked after failed sign-in attempts.
+package securityevent
+
+import (
+	"context"
+	"log"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/google/uuid"
+)
+
+// Event types recorded in the security history.
+const (
+	TypeLoginLocked   = "login.locked"
+	TypeLoginUnlocked = "login.unlocked"
+	// TypeStepUp is a user authenticating again for a sensitive operation.
+	TypeStepUp = "login.step_up"
+	// TypeLoginNewDevice is a login from a device or client IP the user never signed in from.
+	TypeLoginNewDevice = "login.new_device"
+	// TypeDeviceConfirmed is a new device confirmed with the emailed code.
+	TypeDeviceConfirmed = "device.confirmed"
+	// TypeDeviceRemoved is a device removed from the trusted devices.
+	TypeDeviceRemoved = "device.removed"
+	// TypeSessionRefreshReuse is a refresh token used again after it was rotated, which revokes
+	// its session.
+	TypeSessionRefreshReuse = "session.refresh_reuse"
+)
+
+// Store is the data the security history writes to. db.Store satisfies it.
+type Store interface {
+	CreateSecurityEvent(ctx context.Context, arg db.CreateSecurityEventParams) (db.SecurityEvent, error)
+}
+
+// Event is one entry of the security history.
+type Event struct {
+	UserUUID  uuid.UUID
+	Type      string
+	ClientIP  string
+	UserAgent string
+}
+
+// Record adds event to the security history of the user. It only logs a failure, since the event
+// itself has already happened.
+func Record(ctx context.Context, store Store, event Event) {
+	_, err := store.CreateSecurityEvent(ctx, db.CreateSecurityEventParams{
+		UserUuid:  event.UserUUID,
+		EventType: event.Type,
+		ClientIp:  event.ClientIP,
+		UserAgent: event.UserAgent,
+	})
+	if err != nil {
+		log.Printf("Error: cannot record security event %s for user %s: %s", event.Type, event.UserUUID, err.Error())
+	}
+}
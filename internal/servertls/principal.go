@@ -0,0 +1,63 @@
+package servertls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Principal is a service authenticated by its client certificate.
+type Principal struct {
+	Identity  string
+	Role      string
+	ExpiresAt time.Time
+}
+
+// Principals maps the identity of client certificates to the role of their service principal.
+type Principals map[string]string
+
+// ParsePrincipals parses comma separated identity=role pairs, e.g.
+// "spiffe://bank/ledger=ledger_service,reporting=auditor". An identity may hold '=', a role may not.
+func ParsePrincipals(s string) (Principals, error) {
+	principals := make(Principals)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 || i == len(pair)-1 {
+			return nil, fmt.Errorf("invalid principal %q, want identity=role", pair)
+		}
+		principals[strings.TrimSpace(pair[:i])] = strings.TrimSpace(pair[i+1:])
+	}
+	return principals, nil
+}
+
+// Identity returns the identity of cert: its first URI SAN, as issued to SPIFFE workloads, or
+// else its subject common name.
+func Identity(cert *x509.Certificate) string {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	return cert.Subject.CommonName
+}
+
+// Lookup returns the principal of the client certificate verified on the connection state.
+// Certificates that were not verified against the client CAs are never mapped.
+func (p Principals) Lookup(state tls.ConnectionState) (Principal, bool) {
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return Principal{}, false
+	}
+
+	cert := state.VerifiedChains[0][0]
+	identity := Identity(cert)
+	role, ok := p[identity]
+	if !ok {
+		return Principal{}, false
+	}
+	return Principal{Identity: identity, Role: role, ExpiresAt: cert.NotAfter}, true
+}
@@ -0,0 +1,179 @@
+// Package servertls builds the TLS configuration of the listeners. The certificate, its key and
+// the CAs trusted for client certificates are read from disk and reloaded when the files change,
+// so renewed certificates are served without a restart. Client certificates can stand in for
+// credentials: Principals maps their identity to the role of a service principal.
+package servertls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Config locates the files of a listener. Zero values fall back to DefaultConfig.
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile makes the listener verify client certificates against its CAs. Clients may
+	// connect without a certificate unless RequireClientCert is set.
+	ClientCAFile      string
+	RequireClientCert bool
+	// ReloadInterval is how often the files are checked for changes, at most once per handshake.
+	ReloadInterval time.Duration
+}
+
+// DefaultConfig returns the configuration used unless configured otherwise.
+func DefaultConfig() Config {
+	return Config{
+		ReloadInterval: time.Minute,
+	}
+}
+
+// Certificates serves the certificate of a listener and the CAs trusted for its clients, reloaded
+// when the files change.
+type Certificates struct {
+	config Config
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	checkedAt time.Time
+}
+
+// New loads the files of config. It fails when they cannot be loaded, later reloads keep the
+// previous certificate until the files load again.
+func New(config Config) (*Certificates, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("certificate and key files are required")
+	}
+	if config.RequireClientCert && config.ClientCAFile == "" {
+		return nil, errors.New("requiring client certificates needs a client CA file")
+	}
+	if config.ReloadInterval <= 0 {
+		config.ReloadInterval = DefaultConfig().ReloadInterval
+	}
+
+	c := &Certificates{config: config}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ServerConfig returns the TLS configuration of the listener. Every handshake is served the
+// latest certificate and client CAs.
+func (c *Certificates) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: c.configForClient,
+	}
+}
+
+func (c *Certificates) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	c.reloadIfChanged()
+
+	c.mu.RLock()
+	cert, clientCAs := c.cert, c.clientCAs
+	c.mu.RUnlock()
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*cert},
+		// both gRPC and net/http negotiate HTTP/2, which the returned config must still offer
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	if clientCAs != nil {
+		config.ClientCAs = clientCAs
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+		if c.config.RequireClientCert {
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return config, nil
+}
+
+func (c *Certificates) files() []string {
+	files := []string{c.config.CertFile, c.config.KeyFile}
+	if c.config.ClientCAFile != "" {
+		files = append(files, c.config.ClientCAFile)
+	}
+	return files
+}
+
+// reloadIfChanged reloads the files when one of them changed since the last load, checking at
+// most once per ReloadInterval.
+func (c *Certificates) reloadIfChanged() {
+	c.mu.Lock()
+	if time.Since(c.checkedAt) < c.config.ReloadInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.checkedAt = time.Now()
+	modTimes := c.modTimes
+	c.mu.Unlock()
+
+	changed := false
+	for _, file := range c.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			log.Error().Err(err).Str("file", file).Msg("Cannot check TLS file for changes")
+			return
+		}
+		if !info.ModTime().Equal(modTimes[file]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	// a certificate renewed in several writes may not load yet, the next check tries again
+	if err := c.load(); err != nil {
+		log.Error().Err(err).Msg("Cannot reload TLS certificate, keeping the previous one")
+		return
+	}
+	log.Info().Str("file", c.config.CertFile).Msg("Reloaded TLS certificate")
+}
+
+func (c *Certificates) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range c.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.config.CertFile, c.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("cannot load certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if c.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("cannot read client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.New("client CA file holds no certificate")
+		}
+	}
+
+	c.mu.Lock()
+	c.cert = &cert
+	c.clientCAs = clientCAs
+	c.modTimes = modTimes
+	c.checkedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
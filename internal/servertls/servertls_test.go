@@ -0,0 +1,233 @@
+package servertls_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fajaramaulana/simple_bank_project/internal/servertls"
+	"github.com/stretchr/testify/require"
+)
+
+type issued struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issue creates a certificate for template signed by parent, or self-signed when parent is nil.
+func issue(t *testing.T, template *x509.Certificate, parent *issued) issued {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return issued{cert: cert, key: key}
+}
+
+func newCA(t *testing.T) issued {
+	return issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+}
+
+func newServerCert(t *testing.T, ca issued, name string) issued {
+	return issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		DNSNames:    []string{name},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+}
+
+func newClientCert(t *testing.T, ca issued, template *x509.Certificate) issued {
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	return issue(t, template, &ca)
+}
+
+func writeCert(t *testing.T, dir, name string, cert issued) (string, string) {
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+
+	keyDER, err := x509.MarshalECPrivateKey(cert.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.cert.Raw}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func clientConfig(ca issued, client *issued) *tls.Config {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	config := &tls.Config{RootCAs: roots, ServerName: "bank.test"}
+	if client != nil {
+		config.Certificates = []tls.Certificate{{Certificate: [][]byte{client.cert.Raw}, PrivateKey: client.key}}
+	}
+	return config
+}
+
+// handshake connects a client to the server config and returns the state seen by each side.
+func handshake(t *testing.T, server, client *tls.Config) (tls.ConnectionState, tls.ConnectionState, error) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	serverTLS := tls.Server(serverConn, server)
+	errs := make(chan error, 1)
+	go func() {
+		err := serverTLS.Handshake()
+		if err != nil {
+			serverConn.Close()
+		}
+		errs <- err
+	}()
+
+	clientTLS := tls.Client(clientConn, client)
+	clientErr := clientTLS.Handshake()
+	if clientErr == nil {
+		// TLS 1.3 reports a refused client certificate on the first read
+		clientConn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		if _, err := clientTLS.Read(make([]byte, 1)); err != nil && !isTimeout(err) {
+			clientErr = err
+		}
+	}
+	if clientErr != nil {
+		clientConn.Close()
+	}
+	if serverErr := <-errs; serverErr != nil {
+		return tls.ConnectionState{}, tls.ConnectionState{}, serverErr
+	}
+	return serverTLS.ConnectionState(), clientTLS.ConnectionState(), clientErr
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+func TestReloadsChangedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCA(t)
+	first := newServerCert(t, ca, "bank.test")
+	certFile, keyFile := writeCert(t, dir, "server", first)
+
+	certs, err := servertls.New(servertls.Config{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Millisecond})
+	require.NoError(t, err)
+
+	_, state, err := handshake(t, certs.ServerConfig(), clientConfig(ca, nil))
+	require.NoError(t, err)
+	require.Equal(t, first.cert.SerialNumber, state.PeerCertificates[0].SerialNumber)
+
+	// a half written renewal keeps the previous certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+	time.Sleep(10 * time.Millisecond)
+	_, state, err = handshake(t, certs.ServerConfig(), clientConfig(ca, nil))
+	require.NoError(t, err)
+	require.Equal(t, first.cert.SerialNumber, state.PeerCertificates[0].SerialNumber)
+
+	renewed := newServerCert(t, ca, "bank.test")
+	writeCert(t, dir, "server", renewed)
+	require.Eventually(t, func() bool {
+		_, state, err := handshake(t, certs.ServerConfig(), clientConfig(ca, nil))
+		return err == nil && state.PeerCertificates[0].SerialNumber.Cmp(renewed.cert.SerialNumber) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCA(t)
+	certFile, keyFile := writeCert(t, dir, "server", newServerCert(t, ca, "bank.test"))
+	caFile, _ := writeCert(t, dir, "ca", ca)
+
+	principals, err := servertls.ParsePrincipals("spiffe://bank.test/ledger=ledger_service, reporting=auditor")
+	require.NoError(t, err)
+
+	ledgerURI, err := url.Parse("spiffe://bank.test/ledger")
+	require.NoError(t, err)
+	ledger := newClientCert(t, ca, &x509.Certificate{Subject: pkix.Name{CommonName: "ignored"}, URIs: []*url.URL{ledgerURI}})
+	reporting := newClientCert(t, ca, &x509.Certificate{Subject: pkix.Name{CommonName: "reporting"}})
+	unknown := newClientCert(t, ca, &x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}})
+	untrusted := newClientCert(t, newCA(t), &x509.Certificate{Subject: pkix.Name{CommonName: "reporting"}})
+
+	optional, err := servertls.New(servertls.Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	require.NoError(t, err)
+
+	state, _, err := handshake(t, optional.ServerConfig(), clientConfig(ca, &ledger))
+	require.NoError(t, err)
+	principal, ok := principals.Lookup(state)
+	require.True(t, ok)
+	require.Equal(t, "spiffe://bank.test/ledger", principal.Identity)
+	require.Equal(t, "ledger_service", principal.Role)
+	require.WithinDuration(t, ledger.cert.NotAfter, principal.ExpiresAt, time.Second)
+
+	state, _, err = handshake(t, optional.ServerConfig(), clientConfig(ca, &reporting))
+	require.NoError(t, err)
+	principal, ok = principals.Lookup(state)
+	require.True(t, ok)
+	require.Equal(t, "auditor", principal.Role)
+
+	state, _, err = handshake(t, optional.ServerConfig(), clientConfig(ca, &unknown))
+	require.NoError(t, err)
+	_, ok = principals.Lookup(state)
+	require.False(t, ok)
+
+	state, _, err = handshake(t, optional.ServerConfig(), clientConfig(ca, nil))
+	require.NoError(t, err)
+	_, ok = principals.Lookup(state)
+	require.False(t, ok)
+
+	_, _, err = handshake(t, optional.ServerConfig(), clientConfig(ca, &untrusted))
+	require.Error(t, err)
+
+	required, err := servertls.New(servertls.Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, RequireClientCert: true})
+	require.NoError(t, err)
+
+	_, _, err = handshake(t, required.ServerConfig(), clientConfig(ca, nil))
+	require.Error(t, err)
+	_, _, err = handshake(t, required.ServerConfig(), clientConfig(ca, &reporting))
+	require.NoError(t, err)
+}
+
+func TestParsePrincipals(t *testing.T) {
+	principals, err := servertls.ParsePrincipals("")
+	require.NoError(t, err)
+	require.Empty(t, principals)
+
+	principals, err = servertls.ParsePrincipals("CN=a=b=role_a,b=role_b,")
+	require.NoError(t, err)
+	require.Equal(t, servertls.Principals{"CN=a=b": "role_a", "b": "role_b"}, principals)
+
+	for _, invalid := range []string{"identity", "=role", "identity="} {
+		_, err := servertls.ParsePrincipals(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	_, err = servertls.New(servertls.Config{CertFile: "server.crt"})
+	require.Error(t, err)
+}
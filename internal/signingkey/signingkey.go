@@ -0,0 +1,357 @@
+// Package signingkey manages the Ed25519 key pairs that sign tokens. Keys are kept in the database
+// so every instance signs with the same key, rotated on a schedule, and the public halves are
+// published as a JWKS so other services can verify tokens without being able to mint them. The
+// private keys are stored encrypted with a key-encryption key from the configuration, so the
+// database, its backups and replicas cannot mint tokens either.
+package signingkey
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+)
+
+// AlgorithmEdDSA is the JWT algorithm of the keys.
+const AlgorithmEdDSA = "EdDSA"
+
+var (
+	// ErrNoSigningKey is returned when no key may sign yet, for example before the keys were loaded.
+	ErrNoSigningKey = errors.New("no token signing key available")
+	// ErrUnknownKey is returned for a key ID that is not known or whose key has expired.
+	ErrUnknownKey = errors.New("unknown token signing key")
+	// ErrInvalidEncryptionKey is returned when the key-encryption key is missing or is not an
+	// AES-256 key.
+	ErrInvalidEncryptionKey = errors.New("token key encryption key must be 32 bytes")
+	// ErrUndecryptableKey is returned when a stored private key cannot be decrypted with the
+	// key-encryption key, because the key-encryption key is wrong or the key was tampered with.
+	ErrUndecryptableKey = errors.New("cannot decrypt token signing key")
+)
+
+// EncryptionKeySize is the size of the key-encryption key in bytes.
+const EncryptionKeySize = 32
+
+// Key is a token signing key pair.
+type Key struct {
+	ID         string
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+	// ActivatesAt is when the key starts to sign. It is published before that.
+	ActivatesAt time.Time
+	// ExpiresAt is when tokens signed with the key stop being accepted. It is zero until a newer
+	// key takes over.
+	ExpiresAt time.Time
+}
+
+func (k Key) expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// KeySet holds the keys in use. It is safe for concurrent use.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []Key
+}
+
+// NewKeySet creates a key set holding keys.
+func NewKeySet(keys ...Key) *KeySet {
+	set := &KeySet{}
+	set.Set(keys)
+	return set
+}
+
+// Set replaces the keys of the set.
+func (s *KeySet) Set(keys []Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append([]Key(nil), keys...)
+}
+
+// SigningKey returns the ID and the private key of the key that signs new tokens, which is the
+// latest active key.
+func (s *KeySet) SigningKey() (string, ed25519.PrivateKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	var signing *Key
+	for i, key := range s.keys {
+		if key.ActivatesAt.After(now) || key.expired(now) {
+			continue
+		}
+		if signing == nil || key.ActivatesAt.After(signing.ActivatesAt) {
+			signing = &s.keys[i]
+		}
+	}
+	if signing == nil {
+		return "", nil, ErrNoSigningKey
+	}
+	return signing.ID, signing.PrivateKey, nil
+}
+
+// PublicKey returns the public key of kid, as long as tokens signed with it are accepted.
+func (s *KeySet) PublicKey(kid string) (ed25519.PublicKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range s.keys {
+		if key.ID == kid && !key.expired(now) {
+			return key.PublicKey, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+// JWK is the public half of a key as a JSON Web Key (RFC 8037).
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys that verify tokens, including keys that do not sign yet.
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	if s == nil {
+		return jwks
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range s.keys {
+		if key.expired(now) {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key.PublicKey),
+			Kid: key.ID,
+			Use: "sig",
+			Alg: AlgorithmEdDSA,
+		})
+	}
+	return jwks
+}
+
+// jwksMaxAge is how long clients may cache the JWKS. New keys are published for longer than that
+// before they sign.
+const jwksMaxAge = 5 * time.Minute
+
+// Handler serves the JWKS of keys. A nil set serves an empty JWKS, as when tokens are not signed
+// with asymmetric keys.
+func Handler(keys *KeySet) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(jwksMaxAge.Seconds())))
+		_ = json.NewEncoder(w).Encode(keys.JWKS())
+	})
+}
+
+// Store is the data the rotator reads and writes. db.Store satisfies it.
+type Store interface {
+	ListTokenSigningKeys(ctx context.Context) ([]db.TokenSigningKey, error)
+	RotateTokenSigningKeyTx(ctx context.Context, param db.RotateTokenSigningKeyTxParam) (db.TokenSigningKey, error)
+}
+
+// Config tunes the rotation. Zero values fall back to DefaultConfig.
+type Config struct {
+	// RotationInterval is how long a key signs before the next one takes over.
+	RotationInterval time.Duration
+	// GracePeriod is how long tokens signed with a retired key are still accepted. It has to be
+	// at least as long as the longest token lives.
+	GracePeriod time.Duration
+}
+
+// DefaultConfig returns the rotation used unless configured otherwise.
+func DefaultConfig() Config {
+	return Config{
+		RotationInterval: 30 * 24 * time.Hour,
+		GracePeriod:      7 * 24 * time.Hour,
+	}
+}
+
+// RefreshInterval is how often Refresh should run so that every instance knows a new key before it
+// signs.
+const RefreshInterval = time.Minute
+
+// publishDelay is how long a new key is published before it signs. It covers a few refreshes and
+// the JWKS cache of other services.
+const publishDelay = 2 * jwksMaxAge
+
+// Rotator loads the keys from the database into a KeySet and rotates them when they are due.
+type Rotator struct {
+	store  Store
+	keys   *KeySet
+	aead   cipher.AEAD
+	config Config
+}
+
+// NewRotator creates a rotator that keeps keys up to date. The private keys are encrypted in the
+// database with encryptionKey, an AES-256 key.
+func NewRotator(store Store, keys *KeySet, encryptionKey []byte, config Config) (*Rotator, error) {
+	if len(encryptionKey) != EncryptionKeySize {
+		return nil, ErrInvalidEncryptionKey
+	}
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := DefaultConfig()
+	if config.RotationInterval <= 0 {
+		config.RotationInterval = defaults.RotationInterval
+	}
+	if config.GracePeriod <= 0 {
+		config.GracePeriod = defaults.GracePeriod
+	}
+
+	return &Rotator{store: store, keys: keys, aead: aead, config: config}, nil
+}
+
+// Refresh creates the next key when the latest one has signed for RotationInterval, or the first
+// key when there is none, and loads the keys into the key set. It fails when a stored key cannot be
+// decrypted.
+func (r *Rotator) Refresh(ctx context.Context) error {
+	rows, err := r.store.ListTokenSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if len(rows) == 0 || !rows[0].ActivatesAt.After(now.Add(-r.config.RotationInterval)) {
+		// the very first key signs right away, there is no token it could fail to verify yet
+		activatesAt := now
+		if len(rows) > 0 {
+			activatesAt = now.Add(publishDelay)
+		}
+
+		key, err := GenerateKey(activatesAt)
+		if err != nil {
+			return err
+		}
+		sealed, err := r.seal(key)
+		if err != nil {
+			return err
+		}
+
+		_, err = r.store.RotateTokenSigningKeyTx(ctx, db.RotateTokenSigningKeyTxParam{
+			Key:          sealed,
+			RotateBefore: now.Add(-r.config.RotationInterval),
+			ExpiresAt:    activatesAt.Add(r.config.GracePeriod),
+		})
+		if err != nil {
+			return err
+		}
+
+		rows, err = r.store.ListTokenSigningKeys(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	keys := make([]Key, 0, len(rows))
+	for _, row := range rows {
+		if row.Algorithm != AlgorithmEdDSA {
+			continue
+		}
+		privateKey, err := r.open(row)
+		if err != nil {
+			return err
+		}
+		key := Key{
+			ID:          row.Kid,
+			PrivateKey:  privateKey,
+			PublicKey:   ed25519.PublicKey(row.PublicKey),
+			ActivatesAt: row.ActivatesAt,
+		}
+		if row.ExpiresAt.Valid {
+			key.ExpiresAt = row.ExpiresAt.Time
+		}
+		keys = append(keys, key)
+	}
+	r.keys.Set(keys)
+
+	return nil
+}
+
+// GenerateKey creates a new key pair that starts to sign at activatesAt. Its ID is derived from the
+// public key.
+func GenerateKey(activatesAt time.Time) (Key, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Key{}, err
+	}
+
+	sum := sha256.Sum256(publicKey)
+	return Key{
+		ID:          hex.EncodeToString(sum[:16]),
+		PrivateKey:  privateKey,
+		PublicKey:   publicKey,
+		ActivatesAt: activatesAt,
+	}, nil
+}
+
+// seal returns the row storing key, its private key encrypted with AES-GCM. Only the seed is
+// encrypted, the private key is derived from it again. The key ID is authenticated along, so an
+// encrypted private key cannot be moved to another row.
+func (r *Rotator) seal(key Key) (db.CreateTokenSigningKeyParams, error) {
+	nonce := make([]byte, r.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return db.CreateTokenSigningKeyParams{}, err
+	}
+
+	return db.CreateTokenSigningKeyParams{
+		Kid:         key.ID,
+		Algorithm:   AlgorithmEdDSA,
+		PrivateKey:  r.aead.Seal(nonce, nonce, key.PrivateKey.Seed(), []byte(key.ID)),
+		PublicKey:   key.PublicKey,
+		ActivatesAt: key.ActivatesAt,
+	}, nil
+}
+
+// open decrypts the private key of row.
+func (r *Rotator) open(row db.TokenSigningKey) (ed25519.PrivateKey, error) {
+	nonceSize := r.aead.NonceSize()
+	if len(row.PrivateKey) < nonceSize {
+		return nil, ErrUndecryptableKey
+	}
+
+	seed, err := r.aead.Open(nil, row.PrivateKey[:nonceSize], row.PrivateKey[nonceSize:], []byte(row.Kid))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, ErrUndecryptableKey
+	}
+
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	if !privateKey.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(row.PublicKey)) {
+		return nil, ErrUndecryptableKey
+	}
+	return privateKey, nil
+}
@@ -0,0 +1,225 @@
+package signingkey_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/signingkey"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var encryptionKey = bytes.Repeat([]byte{0x42}, signingkey.EncryptionKeySize)
+
+func newRotator(t *testing.T, store signingkey.Store, keys *signingkey.KeySet, config signingkey.Config) *signingkey.Rotator {
+	rotator, err := signingkey.NewRotator(store, keys, encryptionKey, config)
+	require.NoError(t, err)
+	return rotator
+}
+
+func storedRow(params db.CreateTokenSigningKeyParams) db.TokenSigningKey {
+	return db.TokenSigningKey{
+		Kid:         params.Kid,
+		Algorithm:   params.Algorithm,
+		PrivateKey:  params.PrivateKey,
+		PublicKey:   params.PublicKey,
+		ActivatesAt: params.ActivatesAt,
+		CreatedAt:   params.ActivatesAt,
+	}
+}
+
+// storedKey returns a key activating at activatesAt as the rotator stores it, and its private key.
+func storedKey(t *testing.T, activatesAt time.Time) (db.TokenSigningKey, ed25519.PrivateKey) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	keys := signingkey.NewKeySet()
+
+	var row db.TokenSigningKey
+	store.EXPECT().ListTokenSigningKeys(gomock.Any()).Times(1).Return([]db.TokenSigningKey{}, nil)
+	store.EXPECT().RotateTokenSigningKeyTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, param db.RotateTokenSigningKeyTxParam) (db.TokenSigningKey, error) {
+			row = storedRow(param.Key)
+			return row, nil
+		})
+	store.EXPECT().ListTokenSigningKeys(gomock.Any()).Times(1).DoAndReturn(func(context.Context) ([]db.TokenSigningKey, error) {
+		return []db.TokenSigningKey{row}, nil
+	})
+	require.NoError(t, newRotator(t, store, keys, signingkey.DefaultConfig()).Refresh(context.Background()))
+
+	_, privateKey, err := keys.SigningKey()
+	require.NoError(t, err)
+
+	row.ActivatesAt, row.CreatedAt = activatesAt, activatesAt
+	return row, privateKey
+}
+
+func TestRefreshCreatesFirstKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	keys := signingkey.NewKeySet()
+	rotator := newRotator(t, store, keys, signingkey.DefaultConfig())
+
+	var created db.TokenSigningKey
+	gomock.InOrder(
+		store.EXPECT().ListTokenSigningKeys(gomock.Any()).Times(1).Return([]db.TokenSigningKey{}, nil),
+		store.EXPECT().RotateTokenSigningKeyTx(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, param db.RotateTokenSigningKeyTxParam) (db.TokenSigningKey, error) {
+				// the first key signs right away
+				require.WithinDuration(t, time.Now(), param.Key.ActivatesAt, time.Second)
+				created = storedRow(param.Key)
+				return created, nil
+			}),
+		store.EXPECT().ListTokenSigningKeys(gomock.Any()).Times(1).DoAndReturn(func(context.Context) ([]db.TokenSigningKey, error) {
+			return []db.TokenSigningKey{created}, nil
+		}),
+	)
+
+	require.NoError(t, rotator.Refresh(context.Background()))
+
+	kid, privateKey, err := keys.SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, created.Kid, kid)
+	require.Equal(t, ed25519.PublicKey(created.PublicKey), privateKey.Public())
+
+	// only the encrypted private key is stored
+	require.NotContains(t, string(created.PrivateKey), string(privateKey.Seed()))
+	require.NotEqual(t, ed25519.PrivateKeySize, len(created.PrivateKey))
+}
+
+func TestRefreshRejectsWrongEncryptionKey(t *testing.T) {
+	row, _ := storedKey(t, time.Now().Add(-time.Hour))
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	keys := signingkey.NewKeySet()
+	rotator, err := signingkey.NewRotator(store, keys, bytes.Repeat([]byte{0x24}, signingkey.EncryptionKeySize), signingkey.DefaultConfig())
+	require.NoError(t, err)
+
+	store.EXPECT().ListTokenSigningKeys(gomock.Any()).Times(1).Return([]db.TokenSigningKey{row}, nil)
+	store.EXPECT().RotateTokenSigningKeyTx(gomock.Any(), gomock.Any()).Times(0)
+
+	require.ErrorIs(t, rotator.Refresh(context.Background()), signingkey.ErrUndecryptableKey)
+	_, _, err = keys.SigningKey()
+	require.ErrorIs(t, err, signingkey.ErrNoSigningKey)
+
+	// an encrypted private key moved to another key ID does not decrypt either
+	row.Kid = "other"
+	store.EXPECT().ListTokenSigningKeys(gomock.Any()).Times(1).Return([]db.TokenSigningKey{row}, nil)
+	require.ErrorIs(t, newRotator(t, store, keys, signingkey.DefaultConfig()).Refresh(context.Background()), signingkey.ErrUndecryptableKey)
+}
+
+func TestNewRotatorRequiresEncryptionKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	for _, encryptionKey := range [][]byte{nil, make([]byte, 16)} {
+		_, err := signingkey.NewRotator(store, signingkey.NewKeySet(), encryptionKey, signingkey.DefaultConfig())
+		require.ErrorIs(t, err, signingkey.ErrInvalidEncryptionKey)
+	}
+}
+
+func TestRefreshRotatesDueKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	keys := signingkey.NewKeySet()
+	config := signingkey.Config{RotationInterval: 24 * time.Hour, GracePeriod: time.Hour}
+	rotator := newRotator(t, store, keys, config)
+
+	current, _ := storedKey(t, time.Now().Add(-25*time.Hour))
+	var next db.TokenSigningKey
+	gomock.InOrder(
+		store.EXPECT().ListTokenSigningKeys(gomock.Any()).Times(1).Return([]db.TokenSigningKey{current}, nil),
+		store.EXPECT().RotateTokenSigningKeyTx(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, param db.RotateTokenSigningKeyTxParam) (db.TokenSigningKey, error) {
+				// the next key is published before it signs, the current one signs until then and
+				// verifies for the grace period after
+				require.True(t, param.Key.ActivatesAt.After(time.Now()))
+				require.Equal(t, param.Key.ActivatesAt.Add(config.GracePeriod), param.ExpiresAt)
+				require.True(t, current.ActivatesAt.Before(param.RotateBefore))
+
+				next = storedRow(param.Key)
+				current.ExpiresAt = pgtype.Timestamptz{Time: param.ExpiresAt, Valid: true}
+				return next, nil
+			}),
+		store.EXPECT().ListTokenSigningKeys(gomock.Any()).Times(1).DoAndReturn(func(context.Context) ([]db.TokenSigningKey, error) {
+			return []db.TokenSigningKey{next, current}, nil
+		}),
+	)
+
+	require.NoError(t, rotator.Refresh(context.Background()))
+
+	kid, _, err := keys.SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, current.Kid, kid)
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	require.Equal(t, next.Kid, jwks.Keys[0].Kid)
+	require.Equal(t, current.Kid, jwks.Keys[1].Kid)
+}
+
+func TestRefreshKeepsRecentKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	keys := signingkey.NewKeySet()
+	rotator := newRotator(t, store, keys, signingkey.DefaultConfig())
+
+	current, _ := storedKey(t, time.Now().Add(-time.Hour))
+	store.EXPECT().ListTokenSigningKeys(gomock.Any()).Times(1).Return([]db.TokenSigningKey{current}, nil)
+	store.EXPECT().RotateTokenSigningKeyTx(gomock.Any(), gomock.Any()).Times(0)
+
+	require.NoError(t, rotator.Refresh(context.Background()))
+
+	kid, _, err := keys.SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, current.Kid, kid)
+}
+
+func TestHandlerServesJWKS(t *testing.T) {
+	row, privateKey := storedKey(t, time.Now().Add(-time.Hour))
+	keys := signingkey.NewKeySet(signingkey.Key{
+		ID:          row.Kid,
+		PrivateKey:  privateKey,
+		PublicKey:   row.PublicKey,
+		ActivatesAt: row.ActivatesAt,
+	}, signingkey.Key{
+		ID:          "expired",
+		PublicKey:   row.PublicKey,
+		ActivatesAt: row.ActivatesAt.Add(-time.Hour),
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	})
+
+	recorder := httptest.NewRecorder()
+	signingkey.Handler(keys).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var jwks signingkey.JWKS
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &jwks))
+	require.Len(t, jwks.Keys, 1)
+	require.Equal(t, signingkey.JWK{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(row.PublicKey),
+		Kid: row.Kid,
+		Use: "sig",
+		Alg: signingkey.AlgorithmEdDSA,
+	}, jwks.Keys[0])
+	// the private key is never published
+	require.NotContains(t, recorder.Body.String(), base64.RawURLEncoding.EncodeToString(privateKey.Seed()))
+
+	// without asymmetric keys the set is empty
+	recorder = httptest.NewRecorder()
+	signingkey.Handler(nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.JSONEq(t, `{"keys":[]}`, recorder.Body.String())
+}
@@ -0,0 +1,203 @@
+// Package stepup implements step-up authentication: sensitive operations need an access token of a
+// recent authentication, not just a valid one. The time the user authenticated travels in the token,
+// and a user asked to step up proves their identity again, with the password or a two-factor code,
+// to get an access token with a new authentication time.
+package stepup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/mfa"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/securityevent"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Operations that can require step-up authentication.
+const (
+	OperationTransfer       = "transfer"
+	OperationPasswordChange = "password_change"
+	OperationEmailChange    = "email_change"
+)
+
+// ErrInvalidCredentials is returned when stepping up with a wrong password or two-factor code.
+var ErrInvalidCredentials = errors.New("invalid password or two-factor code")
+
+// RequiredError is returned when an operation needs a more recent authentication than the one of
+// the access token. The client steps up and retries with the new access token.
+type RequiredError struct {
+	Operation string
+	// MaxAge is how long before the operation the user must have authenticated.
+	MaxAge time.Duration
+}
+
+func (e *RequiredError) Error() string {
+	return fmt.Sprintf("%s requires an authentication within the last %s", e.Operation, e.MaxAge)
+}
+
+// Rule is the step-up requirement of an operation.
+type Rule struct {
+	// MaxAge is how long after authenticating the user may perform the operation. A negative
+	// MaxAge never requires step-up for the operation.
+	MaxAge time.Duration
+	// MinAmount is the amount from which the operation requires step-up, for operations moving
+	// money.
+	MinAmount int64
+}
+
+// Config holds the rules of the operations. Rules that are missing, or zero values of a rule, fall
+// back to DefaultConfig.
+type Config struct {
+	Rules map[string]Rule
+}
+
+// DefaultConfig returns the rules used unless configured otherwise.
+func DefaultConfig() Config {
+	return Config{
+		Rules: map[string]Rule{
+			OperationTransfer:       {MaxAge: 5 * time.Minute, MinAmount: 10_000_000},
+			OperationPasswordChange: {MaxAge: 5 * time.Minute},
+			OperationEmailChange:    {MaxAge: 5 * time.Minute},
+		},
+	}
+}
+
+// Policy decides which operations require step-up authentication.
+type Policy struct {
+	rules map[string]Rule
+}
+
+// NewPolicy creates the policy of config.
+func NewPolicy(config Config) *Policy {
+	rules := make(map[string]Rule)
+	for operation, rule := range config.Rules {
+		rules[operation] = rule
+	}
+	for operation, defaults := range DefaultConfig().Rules {
+		rule := rules[operation]
+		if rule.MaxAge == 0 {
+			rule.MaxAge = defaults.MaxAge
+		}
+		if rule.MinAmount == 0 {
+			rule.MinAmount = defaults.MinAmount
+		}
+		rules[operation] = rule
+	}
+
+	return &Policy{rules: rules}
+}
+
+// Require returns a *RequiredError when operation, moving amount, needs a more recent
+// authentication than authTime. Operations that move no money pass an amount of zero. A zero
+// authTime, like the one of API keys, never satisfies a required step-up.
+func (p *Policy) Require(operation string, amount int64, authTime time.Time) error {
+	rule, ok := p.rules[operation]
+	if !ok || rule.MaxAge < 0 || amount < rule.MinAmount {
+		return nil
+	}
+
+	if authTime.IsZero() || time.Since(authTime) > rule.MaxAge {
+		return &RequiredError{Operation: operation, MaxAge: rule.MaxAge}
+	}
+
+	return nil
+}
+
+// Store is the data stepping up reads and writes. db.Store satisfies it.
+type Store interface {
+	mfa.Store
+	lockout.Store
+	GetUserByUserUUID(ctx context.Context, userUuid uuid.UUID) (db.GetUserByUserUUIDRow, error)
+	GetDetailLoginByUsername(ctx context.Context, username string) (db.GetDetailLoginByUsernameRow, error)
+}
+
+// Authenticator checks the credentials of users stepping up.
+type Authenticator struct {
+	store  Store
+	hasher *passwordhash.Hasher
+	guard  *lockout.Guard
+}
+
+// NewAuthenticator creates an authenticator counting wrong credentials as failed logins of guard.
+func NewAuthenticator(store Store, hasher *passwordhash.Hasher, guard *lockout.Guard) *Authenticator {
+	return &Authenticator{store: store, hasher: hasher, guard: guard}
+}
+
+// Authenticate checks the two-factor code of the user, or the password when code is empty, and
+// records the step-up in the security history of the user. Wrong credentials count towards the
+// login lockout of the user like failed logins, so a stolen access token cannot be used to guess
+// the password.
+func (a *Authenticator) Authenticate(ctx context.Context, userUUID uuid.UUID, password, code, clientIP, userAgent string) error {
+	user, err := a.store.GetUserByUserUUID(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	attempt := lockout.Attempt{Username: user.Username, ClientIP: clientIP, UserAgent: userAgent}
+	if err := a.guard.Check(ctx, attempt); err != nil {
+		return err
+	}
+
+	if code != "" {
+		err = a.verifyCode(ctx, userUUID, code)
+	} else {
+		err = a.verifyPassword(ctx, user.Username, password)
+	}
+	if errors.Is(err, ErrInvalidCredentials) {
+		if failErr := a.guard.Fail(ctx, attempt, &lockout.User{UUID: user.UserUuid, Email: user.Email}); failErr != nil {
+			return failErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := a.guard.Succeed(ctx, attempt); err != nil {
+		return err
+	}
+
+	securityevent.Record(ctx, a.store, securityevent.Event{
+		UserUUID:  userUUID,
+		Type:      securityevent.TypeStepUp,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+	})
+
+	return nil
+}
+
+func (a *Authenticator) verifyCode(ctx context.Context, userUUID uuid.UUID, code string) error {
+	totp, err := a.store.GetUserTOTP(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidCredentials
+		}
+		return err
+	}
+
+	err = mfa.VerifyCode(ctx, a.store, totp, code)
+	if errors.Is(err, mfa.ErrInvalidCode) || errors.Is(err, mfa.ErrNotEnabled) {
+		return ErrInvalidCredentials
+	}
+	return err
+}
+
+func (a *Authenticator) verifyPassword(ctx context.Context, username, password string) error {
+	user, err := a.store.GetDetailLoginByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.hasher.Verify(password, user.HashedPassword)
+	if errors.Is(err, passwordhash.ErrMismatchedPassword) {
+		return ErrInvalidCredentials
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package stepup_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	mockdb "github.com/fajaramaulana/simple_bank_project/db/mock"
+	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/lockout"
+	"github.com/fajaramaulana/simple_bank_project/internal/passwordhash"
+	"github.com/fajaramaulana/simple_bank_project/internal/securityevent"
+	"github.com/fajaramaulana/simple_bank_project/internal/stepup"
+	"github.com/fajaramaulana/simple_bank_project/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPolicyRequire(t *testing.T) {
+	policy := stepup.NewPolicy(stepup.Config{
+		Rules: map[string]stepup.Rule{
+			stepup.OperationTransfer:    {MaxAge: time.Minute, MinAmount: 1000},
+			stepup.OperationEmailChange: {MaxAge: -1},
+		},
+	})
+
+	testCases := []struct {
+		name      string
+		operation string
+		amount    int64
+		authTime  time.Time
+		required  bool
+	}{
+		{name: "RecentAuthentication", operation: stepup.OperationTransfer, amount: 1000, authTime: time.Now().Add(-30 * time.Second)},
+		{name: "OldAuthentication", operation: stepup.OperationTransfer, amount: 1000, authTime: time.Now().Add(-2 * time.Minute), required: true},
+		{name: "NoAuthentication", operation: stepup.OperationTransfer, amount: 1000, required: true},
+		{name: "BelowThreshold", operation: stepup.OperationTransfer, amount: 999, authTime: time.Now().Add(-2 * time.Minute)},
+		{name: "DefaultRule", operation: stepup.OperationPasswordChange, authTime: time.Now().Add(-6 * time.Minute), required: true},
+		{name: "Disabled", operation: stepup.OperationEmailChange, authTime: time.Now().Add(-time.Hour)},
+		{name: "UnknownOperation", operation: "unknown", authTime: time.Now().Add(-time.Hour)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Require(tc.operation, tc.amount, tc.authTime)
+			if !tc.required {
+				require.NoError(t, err)
+				return
+			}
+
+			var requiredErr *stepup.RequiredError
+			require.True(t, errors.As(err, &requiredErr))
+			require.Equal(t, tc.operation, requiredErr.Operation)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	password := util.RandomString(12)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	user := db.GetUserByUserUUIDRow{
+		UserUuid: util.RandomUUID(),
+		Username: util.RandomUsername(),
+		Email:    util.RandomEmail(),
+	}
+	detail := db.GetDetailLoginByUsernameRow{
+		UserUuid:       user.UserUuid,
+		Username:       user.Username,
+		HashedPassword: string(hashedPassword),
+	}
+
+	testCases := []struct {
+		name       string
+		password   string
+		code       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name:     "Password",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().GetDetailLoginByUsername(gomock.Any(), user.Username).Times(1).Return(detail, nil)
+				store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSecurityEventParams) (db.SecurityEvent, error) {
+						require.Equal(t, securityevent.TypeStepUp, arg.EventType)
+						return db.SecurityEvent{}, nil
+					})
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "WrongPassword",
+			password: password + "x",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().GetDetailLoginByUsername(gomock.Any(), user.Username).Times(1).Return(detail, nil)
+				store.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, stepup.ErrInvalidCredentials)
+			},
+		},
+		{
+			name: "CodeWithoutTOTP",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserUUID(gomock.Any(), user.UserUuid).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), user.UserUuid).Times(1).Return(db.UserTotp{}, pgx.ErrNoRows)
+				store.EXPECT().GetDetailLoginByUsername(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, stepup.ErrInvalidCredentials)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			hasher, err := passwordhash.NewHasher(passwordhash.Config{Algorithm: passwordhash.AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+			require.NoError(t, err)
+			rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+			guard := lockout.NewGuard(rdb, store, nil, lockout.DefaultConfig())
+
+			authenticator := stepup.NewAuthenticator(store, hasher, guard)
+			tc.checkError(t, authenticator.Authenticate(context.Background(), user.UserUuid, tc.password, tc.code, "1.1.1.1", "test"))
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderEvent     = "X-SimpleBank-Event"
+	HeaderDelivery  = "X-SimpleBank-Delivery"
+	HeaderTimestamp = "X-SimpleBank-Timestamp"
+	HeaderSignature = "X-SimpleBank-Signature"
+
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+
+	// MaxAttempts is the number of failed attempts after which a delivery is moved to the dead-letter state.
+	MaxAttempts = 8
+
+	baseRetryDelay  = 30 * time.Second
+	maxRetryDelay   = 6 * time.Hour
+	signaturePrefix = "v1="
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrExpiredTimestamp = errors.New("webhook timestamp outside tolerance")
+)
+
+// Delivery is a single signed POST of an event envelope to a subscriber.
+type Delivery struct {
+	ID        uuid.UUID
+	EventType string
+	URL       string
+	Secret    string
+	Payload   []byte
+}
+
+// NewSecret generates a random signing secret for a subscription.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature of the body for the given timestamp.
+// The timestamp is part of the signed message so a captured request cannot be replayed later.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a received webhook.
+// Receivers should reject requests whose timestamp is further than tolerance from now.
+func Verify(secret string, timestampHeader string, signature string, body []byte, tolerance time.Duration, now time.Time) error {
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	diff := now.Sub(time.Unix(timestamp, 0))
+	if diff > tolerance || diff < -tolerance {
+		return ErrExpiredTimestamp
+	}
+
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// NextRetryDelay returns how long to wait before the next attempt after the given number of failed attempts.
+func NextRetryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewSender(client *http.Client) *Sender {
+	return &Sender{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Send posts the delivery payload to the subscriber.
+// It returns the response status code, and an error if the request failed or the subscriber
+// did not answer with a 2xx status.
+func (s *Sender) Send(ctx context.Context, d Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderDelivery, d.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(d.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
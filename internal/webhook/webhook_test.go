@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendSignedDelivery(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+
+	payload := []byte(`{"id":"1","type":"TransferCompleted"}`)
+	delivery := Delivery{
+		ID:        uuid.New(),
+		EventType: "TransferCompleted",
+		Secret:    secret,
+		Payload:   payload,
+	}
+
+	received := make(chan *http.Request, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, payload, body)
+
+		err = Verify(secret, r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, 5*time.Minute, time.Now())
+		require.NoError(t, err)
+
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	delivery.URL = receiver.URL
+	statusCode, err := NewSender(receiver.Client()).Send(context.Background(), delivery)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, statusCode)
+
+	r := <-received
+	require.Equal(t, delivery.ID.String(), r.Header.Get(HeaderDelivery))
+	require.Equal(t, "TransferCompleted", r.Header.Get(HeaderEvent))
+}
+
+func TestSendNon2xxFails(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	statusCode, err := NewSender(receiver.Client()).Send(context.Background(), Delivery{
+		ID:      uuid.New(),
+		URL:     receiver.URL,
+		Secret:  "secret",
+		Payload: []byte(`{}`),
+	})
+	require.Error(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, statusCode)
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"hello":"world"}`)
+	now := time.Now()
+	timestamp := now.Unix()
+	signature := Sign("secret", timestamp, body)
+	header := strconv.FormatInt(timestamp, 10)
+
+	require.NoError(t, Verify("secret", header, signature, body, time.Minute, now))
+	require.ErrorIs(t, Verify("other", header, signature, body, time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("secret", header, signature, []byte(`{}`), time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("secret", header, signature, body, time.Minute, now.Add(2*time.Minute)), ErrExpiredTimestamp)
+	require.ErrorIs(t, Verify("secret", "not-a-number", signature, body, time.Minute, now), ErrInvalidSignature)
+}
+
+func TestNextRetryDelay(t *testing.T) {
+	require.Equal(t, 30*time.Second, NextRetryDelay(1))
+	require.Equal(t, time.Minute, NextRetryDelay(2))
+	require.Equal(t, 4*time.Minute, NextRetryDelay(4))
+	require.Equal(t, 6*time.Hour, NextRetryDelay(20))
+}
+
+func TestValidateURL(t *testing.T) {
+	for _, rawURL := range []string{"https://partner.example.com/hooks", "http://93.184.216.34:8080/hook", "https://[2606:4700::1111]/hook"} {
+		require.NoError(t, ValidateURL(rawURL), rawURL)
+	}
+
+	for _, rawURL := range []string{
+		"ftp://partner.example.com/hooks",
+		"http://localhost:8080/hook",
+		"http://api.localhost./hook",
+		"http://127.0.0.1/hook",
+		"http://10.0.0.5/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://100.64.0.1/hook",
+		"http://[::1]/hook",
+		"http://[::ffff:192.168.0.1]/hook",
+		"http://[fd00::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		require.ErrorIs(t, ValidateURL(rawURL), ErrForbiddenTarget, rawURL)
+	}
+}
+
+func TestClientRefusesInternalAddresses(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	// the loopback address is refused when dialing, whatever name resolved to it
+	_, err := NewSender(NewClient(time.Second)).Send(context.Background(), Delivery{
+		ID:      uuid.New(),
+		URL:     receiver.URL,
+		Secret:  "secret",
+		Payload: []byte(`{}`),
+	})
+	require.ErrorIs(t, err, ErrForbiddenTarget)
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	receiver := httptest.NewServer(http.RedirectHandler("http://127.0.0.1/internal", http.StatusTemporaryRedirect))
+	defer receiver.Close()
+
+	client := NewClient(time.Second)
+	client.Transport = receiver.Client().Transport
+
+	statusCode, err := NewSender(client).Send(context.Background(), Delivery{
+		ID:      uuid.New(),
+		URL:     receiver.URL,
+		Secret:  "secret",
+		Payload: []byte(`{}`),
+	})
+	require.Error(t, err)
+	require.Equal(t, http.StatusTemporaryRedirect, statusCode)
+}
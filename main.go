@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"encoding/hex"
 	"os"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
@@ -9,8 +12,11 @@ import (
 	"github.com/rs/zerolog/log"
 
 	db "github.com/fajaramaulana/simple_bank_project/db/sqlc"
+	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/runner"
 	"github.com/fajaramaulana/simple_bank_project/internal/grpcapi/setup"
 	setuphttp "github.com/fajaramaulana/simple_bank_project/internal/httpapi/setup"
+	"github.com/fajaramaulana/simple_bank_project/internal/signingkey"
+	"github.com/fajaramaulana/simple_bank_project/internal/webhook"
 	"github.com/fajaramaulana/simple_bank_project/util"
 )
 
@@ -34,33 +40,78 @@ func main() {
 	conn := setup.DbConnection(config)
 
 	// Create a database store
-	// store := setup.GetDbStore(config, conn)
+	store := setup.GetDbStore(config, conn)
 
-	// redisClient := setup.RedisConnection(config)
+	redisClient := setup.RedisConnection(config)
 
 	setup.InitializeDBMigrationsAndSeeder(config, conn)
 
+	signingKeys := tokenSigningKeys(config, store)
+
 	// Start the gateway server in a separate goroutine
-	// go runGatewayServer(config, store, redisClient)
+	// go runGatewayServer(config, store, redisClient, signingKeys)
+
+	go runner.SendEmails(context.Background(), redisClient, config)
+
+	go runner.SnapshotDailyBalances(context.Background(), store)
+
+	go runner.PurgeExpiredSessions(context.Background(), store, config.SessionRetention)
+
+	go runner.DispatchOutboxEvents(context.Background(), store, redisClient)
 
-	// go runner.SendVerificationEmails(context.Background(), redisClient, config)
+	go runner.DeliverWebhooks(context.Background(), store, webhook.NewSender(webhook.NewClient(10*time.Second)))
 
-	runGinServer(config, conn)
+	runGinServer(config, conn, redisClient, signingKeys)
 
 	// Start the gRPC server
-	// rungRPCServer(config, store, redisClient)
+	// rungRPCServer(config, store, redisClient, signingKeys)
+}
+
+// tokenSigningKeys loads the asymmetric token signing keys, creating the first one if needed, and
+// keeps them rotated. It returns nil when tokens are encrypted with the symmetric key.
+func tokenSigningKeys(config util.Config, store db.Store) *signingkey.KeySet {
+	if config.TokenSigningAlgorithm != signingkey.AlgorithmEdDSA {
+		return nil
+	}
+
+	// tokens signed with a retired key have to verify for as long as they live
+	gracePeriod := config.TokenKeyGracePeriod
+	if gracePeriod < config.RefreshTokenDuration {
+		gracePeriod = config.RefreshTokenDuration
+	}
+
+	encryptionKey, err := hex.DecodeString(config.TokenKeyEncryptionKey)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot decode token key encryption key")
+	}
+
+	keys := signingkey.NewKeySet()
+	rotator, err := signingkey.NewRotator(store, keys, encryptionKey, signingkey.Config{
+		RotationInterval: config.TokenKeyRotationInterval,
+		GracePeriod:      gracePeriod,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot create token signing key rotator")
+	}
+	if err := rotator.Refresh(context.Background()); err != nil {
+		log.Fatal().Err(err).Msg("Cannot load token signing keys")
+	}
+
+	go runner.RefreshTokenSigningKeys(context.Background(), rotator)
+
+	return keys
 }
 
-func runGinServer(config util.Config, conn *pgxpool.Pool) {
-	setuphttp.InitializeAndStartAppHTTPApi(config, conn)
+func runGinServer(config util.Config, conn *pgxpool.Pool, redisClient *redis.Client, signingKeys *signingkey.KeySet) {
+	setuphttp.InitializeAndStartAppHTTPApi(config, conn, redisClient, signingKeys)
 }
 
 // rungRPCServer starts the gRPC server using the provided configuration and database store.
-func rungRPCServer(config util.Config, store db.Store, redisClient *redis.Client) {
-	setup.InitializeAndStartAppGRPCApi(config, store, redisClient)
+func rungRPCServer(config util.Config, store db.Store, redisClient *redis.Client, signingKeys *signingkey.KeySet) {
+	setup.InitializeAndStartAppGRPCApi(config, store, redisClient, signingKeys)
 }
 
 // runGatewayServer starts the gateway server using the provided configuration and database store.
-func runGatewayServer(config util.Config, store db.Store, redisClient *redis.Client) {
-	setup.InitializeAndStartGatewayServer(config, store, redisClient)
+func runGatewayServer(config util.Config, store db.Store, redisClient *redis.Client, signingKeys *signingkey.KeySet) {
+	setup.InitializeAndStartGatewayServer(config, store, redisClient, signingKeys)
 }
@@ -0,0 +1,208 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.27.2
+// source: rpc_unlock_login.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UnlockLoginRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *UnlockLoginRequest) Reset() {
+	*x = UnlockLoginRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_unlock_login_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnlockLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockLoginRequest) ProtoMessage() {}
+
+func (x *UnlockLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_unlock_login_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockLoginRequest.ProtoReflect.Descriptor instead.
+func (*UnlockLoginRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_unlock_login_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UnlockLoginRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type UnlockLoginResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *UnlockLoginResponse) Reset() {
+	*x = UnlockLoginResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_unlock_login_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnlockLoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockLoginResponse) ProtoMessage() {}
+
+func (x *UnlockLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_unlock_login_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockLoginResponse.ProtoReflect.Descriptor instead.
+func (*UnlockLoginResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_unlock_login_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UnlockLoginResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_rpc_unlock_login_proto protoreflect.FileDescriptor
+
+var file_rpc_unlock_login_proto_rawDesc = []byte{
+	0x0a, 0x16, 0x72, 0x70, 0x63, 0x5f, 0x75, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6c, 0x6f, 0x67,
+	0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22, 0x28, 0x0a, 0x12,
+	0x55, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0x2f, 0x0a, 0x13, 0x55, 0x6e, 0x6c, 0x6f, 0x63, 0x6b,
+	0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x66, 0x61, 0x6a, 0x61, 0x72, 0x61, 0x6d, 0x61, 0x75, 0x6c,
+	0x61, 0x6e, 0x61, 0x2f, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x62, 0x61, 0x6e, 0x6b, 0x5f,
+	0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_rpc_unlock_login_proto_rawDescOnce sync.Once
+	file_rpc_unlock_login_proto_rawDescData = file_rpc_unlock_login_proto_rawDesc
+)
+
+func file_rpc_unlock_login_proto_rawDescGZIP() []byte {
+	file_rpc_unlock_login_proto_rawDescOnce.Do(func() {
+		file_rpc_unlock_login_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_unlock_login_proto_rawDescData)
+	})
+	return file_rpc_unlock_login_proto_rawDescData
+}
+
+var file_rpc_unlock_login_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_unlock_login_proto_goTypes = []any{
+	(*UnlockLoginRequest)(nil),  // 0: pb.UnlockLoginRequest
+	(*UnlockLoginResponse)(nil), // 1: pb.UnlockLoginResponse
+}
+var file_rpc_unlock_login_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_rpc_unlock_login_proto_init() }
+func file_rpc_unlock_login_proto_init() {
+	if File_rpc_unlock_login_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_unlock_login_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*UnlockLoginRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_unlock_login_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*UnlockLoginResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_unlock_login_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_unlock_login_proto_goTypes,
+		DependencyIndexes: file_rpc_unlock_login_proto_depIdxs,
+		MessageInfos:      file_rpc_unlock_login_proto_msgTypes,
+	}.Build()
+	File_rpc_unlock_login_proto = out.File
+	file_rpc_unlock_login_proto_rawDesc = nil
+	file_rpc_unlock_login_proto_goTypes = nil
+	file_rpc_unlock_login_proto_depIdxs = nil
+}
@@ -29,6 +29,71 @@ type Config struct {
 	MailPort             int           `mapstructure:"MAIL_PORT"`
 	MailUser             string        `mapstructure:"MAIL_USER"`
 	MailPassword         string        `mapstructure:"MAIL_PASSWORD"`
+	RiskRulesFile        string        `mapstructure:"RISK_RULES_FILE"`
+	SanctionsListFile    string        `mapstructure:"SANCTIONS_LIST_FILE"`
+	SanctionsThreshold   float64       `mapstructure:"SANCTIONS_MATCH_THRESHOLD"`
+	PermissionCacheTTL   time.Duration `mapstructure:"PERMISSION_CACHE_TTL"`
+	SessionRetention     time.Duration `mapstructure:"SESSION_RETENTION"`
+	LoginMaxFailures     int           `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	// TokenSigningAlgorithm is EdDSA to sign tokens with rotating asymmetric keys, anything else
+	// encrypts them with TokenSymmetricKey.
+	TokenSigningAlgorithm    string        `mapstructure:"TOKEN_SIGNING_ALGORITHM"`
+	TokenKeyRotationInterval time.Duration `mapstructure:"TOKEN_KEY_ROTATION_INTERVAL"`
+	TokenKeyGracePeriod      time.Duration `mapstructure:"TOKEN_KEY_GRACE_PERIOD"`
+	// TokenKeyEncryptionKey is the hex encoded AES-256 key the private token signing keys are
+	// encrypted with in the database. It is required with EdDSA.
+	TokenKeyEncryptionKey       string `mapstructure:"TOKEN_KEY_ENCRYPTION_KEY"`
+	PasswordMinLength           int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMinCharacterClasses int    `mapstructure:"PASSWORD_MIN_CHARACTER_CLASSES"`
+	// PasswordHistorySize is how many recent passwords, the current one included, cannot be reused.
+	PasswordHistorySize int `mapstructure:"PASSWORD_HISTORY_SIZE"`
+	// PasswordHashAlgorithm is argon2id or bcrypt. Hashes of the other algorithm are still
+	// verified, and replaced at the next login.
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	// PasswordArgon2Memory is the memory used per argon2id hash in KiB.
+	PasswordArgon2Memory      uint32 `mapstructure:"PASSWORD_ARGON2_MEMORY"`
+	PasswordArgon2Iterations  uint32 `mapstructure:"PASSWORD_ARGON2_ITERATIONS"`
+	PasswordArgon2Parallelism uint8  `mapstructure:"PASSWORD_ARGON2_PARALLELISM"`
+	PasswordBcryptCost        int    `mapstructure:"PASSWORD_BCRYPT_COST"`
+	// EmailVerificationRequired restricts creating accounts and transferring money to users who
+	// verified their email address.
+	EmailVerificationRequired       bool          `mapstructure:"EMAIL_VERIFICATION_REQUIRED"`
+	EmailVerificationResendInterval time.Duration `mapstructure:"EMAIL_VERIFICATION_RESEND_INTERVAL"`
+	// PasswordResetRequestInterval is the least time between two password reset codes for an email
+	// address, PasswordResetMaxRequestsPerIP the number of codes a client IP can request per hour.
+	PasswordResetRequestInterval  time.Duration `mapstructure:"PASSWORD_RESET_REQUEST_INTERVAL"`
+	PasswordResetMaxRequestsPerIP int           `mapstructure:"PASSWORD_RESET_MAX_REQUESTS_PER_IP"`
+	// StepUpTransferMaxAge is how long after authenticating a user may transfer at least
+	// StepUpTransferMinAmount. A negative duration never asks transfers for a recent authentication.
+	StepUpTransferMaxAge       time.Duration `mapstructure:"STEP_UP_TRANSFER_MAX_AGE"`
+	StepUpTransferMinAmount    int64         `mapstructure:"STEP_UP_TRANSFER_MIN_AMOUNT"`
+	StepUpPasswordChangeMaxAge time.Duration `mapstructure:"STEP_UP_PASSWORD_CHANGE_MAX_AGE"`
+	StepUpEmailChangeMaxAge    time.Duration `mapstructure:"STEP_UP_EMAIL_CHANGE_MAX_AGE"`
+	// DeviceConfirmationRequired makes a login from a device the user does not trust wait until the
+	// device is confirmed with a code emailed to the user.
+	DeviceConfirmationRequired bool          `mapstructure:"DEVICE_CONFIRMATION_REQUIRED"`
+	DeviceConfirmationCodeTTL  time.Duration `mapstructure:"DEVICE_CONFIRMATION_CODE_TTL"`
+	// TokenRevocationCacheTTL is how long an instance caches whether an access token is revoked,
+	// so a revocation made by another instance can take that long to take effect.
+	TokenRevocationCacheTTL time.Duration `mapstructure:"TOKEN_REVOCATION_CACHE_TTL"`
+	// TLS certificates and keys of the HTTP, gRPC and gateway listeners, PEM encoded. A listener
+	// without a certificate serves plain text. The files are reloaded when they change on disk,
+	// checked at most every TLSReloadInterval.
+	HTTPTLSCertFile    string        `mapstructure:"HTTP_TLS_CERT_FILE"`
+	HTTPTLSKeyFile     string        `mapstructure:"HTTP_TLS_KEY_FILE"`
+	GRPCTLSCertFile    string        `mapstructure:"GRPC_TLS_CERT_FILE"`
+	GRPCTLSKeyFile     string        `mapstructure:"GRPC_TLS_KEY_FILE"`
+	GatewayTLSCertFile string        `mapstructure:"GATEWAY_TLS_CERT_FILE"`
+	GatewayTLSKeyFile  string        `mapstructure:"GATEWAY_TLS_KEY_FILE"`
+	TLSReloadInterval  time.Duration `mapstructure:"TLS_RELOAD_INTERVAL"`
+	// GRPCClientCAFile makes the gRPC server verify client certificates against its CAs. With
+	// GRPCClientCertRequired clients without a certificate are refused.
+	GRPCClientCAFile       string `mapstructure:"GRPC_CLIENT_CA_FILE"`
+	GRPCClientCertRequired bool   `mapstructure:"GRPC_CLIENT_CERT_REQUIRED"`
+	// GRPCClientPrincipals maps the identity of client certificates to the role of a service
+	// principal, as comma separated identity=role pairs. See servertls.ParsePrincipals.
+	GRPCClientPrincipals string `mapstructure:"GRPC_CLIENT_PRINCIPALS"`
 }
 
 // LoadConfig reads configuration from file or environment variables.
@@ -67,6 +132,44 @@ func LoadConfig(path string) (config Config, err error) {
 		_ = os.Setenv("MAIL_PORT", viper.GetString("MAIL_PORT"))
 		_ = os.Setenv("MAIL_USER", viper.GetString("MAIL_USER"))
 		_ = os.Setenv("MAIL_PASSWORD", viper.GetString("MAIL_PASSWORD"))
+		_ = os.Setenv("RISK_RULES_FILE", viper.GetString("RISK_RULES_FILE"))
+		_ = os.Setenv("SANCTIONS_LIST_FILE", viper.GetString("SANCTIONS_LIST_FILE"))
+		_ = os.Setenv("SANCTIONS_MATCH_THRESHOLD", viper.GetString("SANCTIONS_MATCH_THRESHOLD"))
+		_ = os.Setenv("PERMISSION_CACHE_TTL", viper.GetString("PERMISSION_CACHE_TTL"))
+		_ = os.Setenv("SESSION_RETENTION", viper.GetString("SESSION_RETENTION"))
+		_ = os.Setenv("LOGIN_MAX_FAILURES", viper.GetString("LOGIN_MAX_FAILURES"))
+		_ = os.Setenv("LOGIN_LOCKOUT_DURATION", viper.GetString("LOGIN_LOCKOUT_DURATION"))
+		_ = os.Setenv("TOKEN_SIGNING_ALGORITHM", viper.GetString("TOKEN_SIGNING_ALGORITHM"))
+		_ = os.Setenv("TOKEN_KEY_ROTATION_INTERVAL", viper.GetString("TOKEN_KEY_ROTATION_INTERVAL"))
+		_ = os.Setenv("TOKEN_KEY_GRACE_PERIOD", viper.GetString("TOKEN_KEY_GRACE_PERIOD"))
+		_ = os.Setenv("TOKEN_KEY_ENCRYPTION_KEY", viper.GetString("TOKEN_KEY_ENCRYPTION_KEY"))
+		_ = os.Setenv("PASSWORD_MIN_LENGTH", viper.GetString("PASSWORD_MIN_LENGTH"))
+		_ = os.Setenv("PASSWORD_MIN_CHARACTER_CLASSES", viper.GetString("PASSWORD_MIN_CHARACTER_CLASSES"))
+		_ = os.Setenv("PASSWORD_HISTORY_SIZE", viper.GetString("PASSWORD_HISTORY_SIZE"))
+		_ = os.Setenv("PASSWORD_HASH_ALGORITHM", viper.GetString("PASSWORD_HASH_ALGORITHM"))
+		_ = os.Setenv("PASSWORD_ARGON2_MEMORY", viper.GetString("PASSWORD_ARGON2_MEMORY"))
+		_ = os.Setenv("PASSWORD_ARGON2_ITERATIONS", viper.GetString("PASSWORD_ARGON2_ITERATIONS"))
+		_ = os.Setenv("PASSWORD_ARGON2_PARALLELISM", viper.GetString("PASSWORD_ARGON2_PARALLELISM"))
+		_ = os.Setenv("PASSWORD_BCRYPT_COST", viper.GetString("PASSWORD_BCRYPT_COST"))
+		_ = os.Setenv("EMAIL_VERIFICATION_REQUIRED", viper.GetString("EMAIL_VERIFICATION_REQUIRED"))
+		_ = os.Setenv("EMAIL_VERIFICATION_RESEND_INTERVAL", viper.GetString("EMAIL_VERIFICATION_RESEND_INTERVAL"))
+		_ = os.Setenv("STEP_UP_TRANSFER_MAX_AGE", viper.GetString("STEP_UP_TRANSFER_MAX_AGE"))
+		_ = os.Setenv("STEP_UP_TRANSFER_MIN_AMOUNT", viper.GetString("STEP_UP_TRANSFER_MIN_AMOUNT"))
+		_ = os.Setenv("STEP_UP_PASSWORD_CHANGE_MAX_AGE", viper.GetString("STEP_UP_PASSWORD_CHANGE_MAX_AGE"))
+		_ = os.Setenv("STEP_UP_EMAIL_CHANGE_MAX_AGE", viper.GetString("STEP_UP_EMAIL_CHANGE_MAX_AGE"))
+		_ = os.Setenv("DEVICE_CONFIRMATION_REQUIRED", viper.GetString("DEVICE_CONFIRMATION_REQUIRED"))
+		_ = os.Setenv("DEVICE_CONFIRMATION_CODE_TTL", viper.GetString("DEVICE_CONFIRMATION_CODE_TTL"))
+		_ = os.Setenv("TOKEN_REVOCATION_CACHE_TTL", viper.GetString("TOKEN_REVOCATION_CACHE_TTL"))
+		_ = os.Setenv("HTTP_TLS_CERT_FILE", viper.GetString("HTTP_TLS_CERT_FILE"))
+		_ = os.Setenv("HTTP_TLS_KEY_FILE", viper.GetString("HTTP_TLS_KEY_FILE"))
+		_ = os.Setenv("GRPC_TLS_CERT_FILE", viper.GetString("GRPC_TLS_CERT_FILE"))
+		_ = os.Setenv("GRPC_TLS_KEY_FILE", viper.GetString("GRPC_TLS_KEY_FILE"))
+		_ = os.Setenv("GATEWAY_TLS_CERT_FILE", viper.GetString("GATEWAY_TLS_CERT_FILE"))
+		_ = os.Setenv("GATEWAY_TLS_KEY_FILE", viper.GetString("GATEWAY_TLS_KEY_FILE"))
+		_ = os.Setenv("TLS_RELOAD_INTERVAL", viper.GetString("TLS_RELOAD_INTERVAL"))
+		_ = os.Setenv("GRPC_CLIENT_CA_FILE", viper.GetString("GRPC_CLIENT_CA_FILE"))
+		_ = os.Setenv("GRPC_CLIENT_CERT_REQUIRED", viper.GetString("GRPC_CLIENT_CERT_REQUIRED"))
+		_ = os.Setenv("GRPC_CLIENT_PRINCIPALS", viper.GetString("GRPC_CLIENT_PRINCIPALS"))
 
 		err = viper.Unmarshal(&config)
 		return config, err
@@ -95,6 +198,44 @@ func LoadConfig(path string) (config Config, err error) {
 		viper.BindEnv("MAIL_PORT")
 		viper.BindEnv("MAIL_USER")
 		viper.BindEnv("MAIL_PASSWORD")
+		viper.BindEnv("RISK_RULES_FILE")
+		viper.BindEnv("SANCTIONS_LIST_FILE")
+		viper.BindEnv("SANCTIONS_MATCH_THRESHOLD")
+		viper.BindEnv("PERMISSION_CACHE_TTL")
+		viper.BindEnv("SESSION_RETENTION")
+		viper.BindEnv("LOGIN_MAX_FAILURES")
+		viper.BindEnv("LOGIN_LOCKOUT_DURATION")
+		viper.BindEnv("TOKEN_SIGNING_ALGORITHM")
+		viper.BindEnv("TOKEN_KEY_ROTATION_INTERVAL")
+		viper.BindEnv("TOKEN_KEY_GRACE_PERIOD")
+		viper.BindEnv("TOKEN_KEY_ENCRYPTION_KEY")
+		viper.BindEnv("PASSWORD_MIN_LENGTH")
+		viper.BindEnv("PASSWORD_MIN_CHARACTER_CLASSES")
+		viper.BindEnv("PASSWORD_HISTORY_SIZE")
+		viper.BindEnv("PASSWORD_HASH_ALGORITHM")
+		viper.BindEnv("PASSWORD_ARGON2_MEMORY")
+		viper.BindEnv("PASSWORD_ARGON2_ITERATIONS")
+		viper.BindEnv("PASSWORD_ARGON2_PARALLELISM")
+		viper.BindEnv("PASSWORD_BCRYPT_COST")
+		viper.BindEnv("EMAIL_VERIFICATION_REQUIRED")
+		viper.BindEnv("EMAIL_VERIFICATION_RESEND_INTERVAL")
+		viper.BindEnv("STEP_UP_TRANSFER_MAX_AGE")
+		viper.BindEnv("STEP_UP_TRANSFER_MIN_AMOUNT")
+		viper.BindEnv("STEP_UP_PASSWORD_CHANGE_MAX_AGE")
+		viper.BindEnv("STEP_UP_EMAIL_CHANGE_MAX_AGE")
+		viper.BindEnv("DEVICE_CONFIRMATION_REQUIRED")
+		viper.BindEnv("DEVICE_CONFIRMATION_CODE_TTL")
+		viper.BindEnv("TOKEN_REVOCATION_CACHE_TTL")
+		viper.BindEnv("HTTP_TLS_CERT_FILE")
+		viper.BindEnv("HTTP_TLS_KEY_FILE")
+		viper.BindEnv("GRPC_TLS_CERT_FILE")
+		viper.BindEnv("GRPC_TLS_KEY_FILE")
+		viper.BindEnv("GATEWAY_TLS_CERT_FILE")
+		viper.BindEnv("GATEWAY_TLS_KEY_FILE")
+		viper.BindEnv("TLS_RELOAD_INTERVAL")
+		viper.BindEnv("GRPC_CLIENT_CA_FILE")
+		viper.BindEnv("GRPC_CLIENT_CERT_REQUIRED")
+		viper.BindEnv("GRPC_CLIENT_PRINCIPALS")
 
 		// Unmarshal the config into the struct
 		err = viper.Unmarshal(&config)